@@ -0,0 +1,43 @@
+package cachego
+
+import "sync"
+
+// accessBuffer batches the accesses of an LRU cache, so lookups only take the read lock
+// and the recency list is relinked once per batch under the write lock.
+// The batches are striped through a sync.Pool, which hands each P its own batch in the common case,
+// so concurrent lookups rarely contend on the buffer either.
+// Accesses still pending in a batch that the pool drops are lost, which only makes the recency order less exact.
+type accessBuffer[K comparable, V any] struct {
+	size    int
+	batches sync.Pool
+}
+
+func newAccessBuffer[K comparable, V any](size int) *accessBuffer[K, V] {
+	if size <= 0 {
+		return nil
+	}
+
+	b := &accessBuffer[K, V]{size: size}
+	b.batches.New = func() any {
+		batch := make([]*node[K, V], 0, size)
+		return &batch
+	}
+
+	return b
+}
+
+// push records an access of the node, and calls apply with a full batch of accesses.
+func (b *accessBuffer[K, V]) push(n *node[K, V], apply func([]*node[K, V])) {
+	batch := b.batches.Get().(*[]*node[K, V])
+	*batch = append(*batch, n)
+
+	if len(*batch) >= b.size {
+		apply(*batch)
+		for i := range *batch {
+			(*batch)[i] = nil
+		}
+		*batch = (*batch)[:0]
+	}
+
+	b.batches.Put(batch)
+}
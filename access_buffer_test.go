@@ -0,0 +1,71 @@
+package cachego
+
+import (
+	"sync"
+	"testing"
+)
+
+// nolint:errcheck
+func TestAccessBuffer(t *testing.T) {
+	// a batch of one access is applied right away
+	cache := NewLRUCacheWithOpts[int, int](Opts{Size: 2, AccessBuffer: 1})
+	cache.Set(1, 1)
+	cache.Set(2, 2)
+	cache.Get(1)
+	cache.Set(3, 3)
+	if _, err := cache.Get(2); err == nil {
+		t.Errorf("expected key 2 to be evicted")
+	}
+
+	// pending accesses don't promote the entry yet
+	cache = NewLRUCacheWithOpts[int, int](Opts{Size: 2, AccessBuffer: 4})
+	cache.Set(1, 1)
+	cache.Set(2, 2)
+	if v, err := cache.Get(1); err != nil || v != 1 {
+		t.Errorf("expected 1, got %v (%v)", v, err)
+	}
+	cache.Set(3, 3)
+	if _, err := cache.Get(1); err == nil {
+		t.Errorf("expected key 1 to be evicted")
+	}
+}
+
+func TestAccessBufferPromote(t *testing.T) {
+	l := NewLRUCacheWithOpts[int, int](Opts{Size: 3, AccessBuffer: 8}).(*lru[int, int])
+	l.Set(1, 1) // nolint:errcheck
+	l.Set(2, 2) // nolint:errcheck
+	l.Set(3, 3) // nolint:errcheck
+
+	one, two := l.cache[1], l.cache[2]
+	l.Delete(2) // nolint:errcheck
+
+	// removed nodes are skipped, the rest are promoted in access order
+	l.promote([]*node[int, int]{two, one})
+	if l.head != one || l.tail.key != 3 {
+		t.Errorf("expected 1 at the head and 3 at the tail, got %v and %v", l.head.key, l.tail.key)
+	}
+}
+
+// nolint:errcheck
+func TestAccessBufferConcurrent(t *testing.T) {
+	cache := NewLRUCacheWithOpts[int, int](Opts{Size: 50, AccessBuffer: 16})
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := (g*1000 + i) % 100
+				if _, err := cache.Get(key); err != nil {
+					cache.Set(key, key)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if size := cache.(StatsProvider).Stats().Size; size != 50 {
+		t.Errorf("expected 50 entries, got %v", size)
+	}
+}
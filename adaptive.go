@@ -0,0 +1,103 @@
+package cachego
+
+import (
+	"fmt"
+	"time"
+)
+
+// AdaptiveTTLOpts configures AdaptiveTTL.
+type AdaptiveTTLOpts struct {
+	// Min is the ttl of the keys which weren't requested recently. Defaults to a minute.
+	Min time.Duration
+	// Max is the ttl of the keys requested at least Hot times recently. Defaults to ten times Min.
+	Max time.Duration
+	// Hot is the number of recent requests of a key from which it gets the Max ttl. Defaults to 10.
+	Hot int
+	// Keys is about the number of distinct keys requested in a while, which sizes the counters of the requests.
+	// Defaults to 1000.
+	Keys int
+}
+
+type adaptiveTTL[K comparable, V any] struct {
+	c      Cache[K, V]
+	ttl    TTLSetter[K, V]
+	opts   AdaptiveTTLOpts
+	sketch *sketch
+}
+
+// AdaptiveTTL wraps the cache so the keys requested often are stored for longer: every Get and Lookup of a key
+// counts as a request, and Set stores the value with a ttl between Min and Max growing with the recent requests
+// of the key, so the hot keys are loaded from the backend less often while the cold ones don't linger.
+// The requests are estimated with counters halved over time, in constant memory, so the ttl of a key
+// drops back once it cools down. SetWithTTL stores the value with the given ttl.
+//
+// It panics if the cache doesn't implement TTLSetter.
+// The returned cache only implements Cache, Lookuper, TTLSetter and FrequencyEstimator.
+func AdaptiveTTL[K comparable, V any](c Cache[K, V], opts AdaptiveTTLOpts) Cache[K, V] {
+	ttl, ok := c.(TTLSetter[K, V])
+	if !ok {
+		panic(fmt.Sprintf("cachego: adaptive ttl of cache %T which doesn't set ttls", c))
+	}
+
+	if opts.Min <= 0 {
+		opts.Min = time.Minute
+	}
+	if opts.Max < opts.Min {
+		opts.Max = opts.Min * 10
+	}
+	if opts.Hot <= 0 {
+		opts.Hot = 10
+	}
+	if opts.Keys <= 0 {
+		opts.Keys = 1000
+	}
+
+	return &adaptiveTTL[K, V]{c: c, ttl: ttl, opts: opts, sketch: newSketch(opts.Keys)}
+}
+
+// Get retrieves the value of the key, counting a request of the key.
+func (a *adaptiveTTL[K, V]) Get(key K) (V, error) {
+	a.sketch.increment(hashKey(key))
+	return a.c.Get(key)
+}
+
+// Lookup retrieves the value of the key just like Get, reporting whether it was found.
+func (a *adaptiveTTL[K, V]) Lookup(key K) (V, bool) {
+	a.sketch.increment(hashKey(key))
+	return LookupValue(a.c, key)
+}
+
+// Set stores the value with a ttl depending on the recent requests of the key.
+func (a *adaptiveTTL[K, V]) Set(key K, value V) error {
+	return a.ttl.SetWithTTL(key, value, a.ttlOf(key))
+}
+
+// SetWithTTL stores the value with the given ttl.
+func (a *adaptiveTTL[K, V]) SetWithTTL(key K, value V, ttl time.Duration) error {
+	return a.ttl.SetWithTTL(key, value, ttl)
+}
+
+// EstimateFrequency returns the estimated number of recent requests of the key the ttls are based on.
+func (a *adaptiveTTL[K, V]) EstimateFrequency(key K) uint64 {
+	return a.sketch.estimate(hashKey(key))
+}
+
+// Delete removes the key.
+func (a *adaptiveTTL[K, V]) Delete(key K) error {
+	return a.c.Delete(key)
+}
+
+// Clear removes all the entries. The counted requests are kept.
+func (a *adaptiveTTL[K, V]) Clear() error {
+	return a.c.Clear()
+}
+
+// ttlOf returns the ttl of the key: Min plus the share of Max-Min of its requests out of Hot.
+func (a *adaptiveTTL[K, V]) ttlOf(key K) time.Duration {
+	n := a.sketch.estimate(hashKey(key))
+	if n >= uint64(a.opts.Hot) {
+		return a.opts.Max
+	}
+
+	return a.opts.Min + (a.opts.Max-a.opts.Min)*time.Duration(n)/time.Duration(a.opts.Hot)
+}
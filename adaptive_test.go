@@ -0,0 +1,74 @@
+package cachego
+
+import (
+	"testing"
+	"time"
+)
+
+// nolint:errcheck
+func TestAdaptiveTTL(t *testing.T) {
+	lru := NewLRUCacheWithOpts[string, int](Opts{Size: 10})
+	c := AdaptiveTTL(lru, AdaptiveTTLOpts{Min: time.Minute, Max: 11 * time.Minute, Hot: 5})
+
+	expires := func(key string) time.Duration {
+		_, info, err := lru.(Inspector[string, int]).GetWithInfo(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return time.Until(info.Expires).Round(time.Minute)
+	}
+
+	// cold
+	c.Set("cold", 1)
+	if ttl := expires("cold"); ttl != time.Minute {
+		t.Errorf("expected %v, got %v", time.Minute, ttl)
+	}
+
+	// requested twice: 2/5 of the way
+	c.Get("warm")
+	LookupValue(c, "warm")
+	c.Set("warm", 1)
+	if ttl := expires("warm"); ttl != 5*time.Minute {
+		t.Errorf("expected %v, got %v", 5*time.Minute, ttl)
+	}
+
+	// hot, bounded by Max
+	for i := 0; i < 20; i++ {
+		c.Get("hot")
+	}
+	c.Set("hot", 1)
+	if ttl := expires("hot"); ttl != 11*time.Minute {
+		t.Errorf("expected %v, got %v", 11*time.Minute, ttl)
+	}
+	if n := c.(FrequencyEstimator[string]).EstimateFrequency("hot"); n != 20 {
+		t.Errorf("expected 20, got %v", n)
+	}
+
+	c.(TTLSetter[string, int]).SetWithTTL("hot", 2, time.Hour)
+	if ttl := expires("hot"); ttl != time.Hour {
+		t.Errorf("expected %v, got %v", time.Hour, ttl)
+	}
+	if v, err := c.Get("hot"); err != nil || v != 2 {
+		t.Errorf("expected 2, got %v (%v)", v, err)
+	}
+
+	c.Delete("hot")
+	c.Clear()
+	if n := lru.(ExtendedCache[string, int]).Len(); n != 0 {
+		t.Errorf("expected an empty cache, got %v entries", n)
+	}
+}
+
+func TestAdaptiveTTLDefaults(t *testing.T) {
+	a := AdaptiveTTL(NewCache[int, int](Opts{}), AdaptiveTTLOpts{}).(*adaptiveTTL[int, int])
+	if a.opts.Min != time.Minute || a.opts.Max != 10*time.Minute || a.opts.Hot != 10 {
+		t.Errorf("expected the default options, got %+v", a.opts)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic for a cache without ttls")
+		}
+	}()
+	AdaptiveTTL(NewSyncMapCache[int, int](10), AdaptiveTTLOpts{})
+}
@@ -0,0 +1,438 @@
+// Package admin serves HTTP endpoints to inspect and operate the caches of a process, registered by name:
+//
+//	GET    /caches                     lists the registered caches, with their stats
+//	GET    /caches/{name}/stats        returns the stats of the cache
+//	GET    /caches/{name}/hotkeys      returns the hottest keys of the cache
+//	GET    /caches/{name}/keys/{key}   returns the value and metadata of an entry, without counting an access
+//	DELETE /caches/{name}/keys/{key}   invalidates an entry
+//	POST   /caches/{name}/entries      stores the entries of the snapshot in the body, e.g. [{"key": "a", "value": 1}]
+//	GET    /caches/{name}/events       streams the events of the cache as server-sent events, if it emits any
+//	POST   /caches/{name}/clear        clears the cache
+//	POST   /caches/{name}/resize       resizes the cache to the size in the body, e.g. {"size": 1000}
+//
+// Responses are JSON, and errors are returned as {"error": "..."}. The endpoints a cache doesn't support
+// (e.g. hot keys of a cache not tracking them) return 501. Every request goes through Opts.Auth first.
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// maxEntries bounds the size of the snapshots stored with POST /caches/{name}/entries.
+const maxEntries = 64 << 20
+
+// errUnsupported is returned by the operations a cache doesn't implement.
+var errUnsupported = errors.New("the cache doesn't support this operation")
+
+// Opts configures the admin endpoints.
+type Opts struct {
+	// Auth authorizes every request, which is rejected with 403 and the returned error unless it returns nil.
+	// It is required, to not expose the caches by mistake: use AllowAll to opt out explicitly.
+	Auth func(r *http.Request) error
+}
+
+// AllowAll authorizes every request, e.g. when the admin endpoints are served on a private port.
+func AllowAll(*http.Request) error {
+	return nil
+}
+
+// Admin is an http.Handler serving the admin endpoints of the registered caches. It is thread-safe.
+type Admin struct {
+	auth   func(r *http.Request) error
+	mx     *sync.RWMutex
+	caches map[string]*registered
+}
+
+// registered holds the operations of a cache, with its key and value types erased.
+type registered struct {
+	stats   func() (cachego.Stats, error)
+	hotKeys func() (any, error)
+	inspect func(key string) (any, error)
+	delete  func(ctx context.Context, key string) error
+	load    func(ctx context.Context, r io.Reader) (int, error)
+	clear   func(ctx context.Context) error
+	resize  func(size int32) error
+	// events is nil for the caches not emitting events.
+	events *hub
+}
+
+// New creates a new admin handler, without any cache. It panics if Opts.Auth is nil.
+func New(opts Opts) *Admin {
+	if opts.Auth == nil {
+		panic("admin: Opts.Auth is required")
+	}
+
+	return &Admin{auth: opts.Auth, mx: &sync.RWMutex{}, caches: make(map[string]*registered)}
+}
+
+// KeyOpts configures the decoding of the keys of a registered cache from the URLs.
+type KeyOpts[K comparable] struct {
+	// DecodeKey converts the path segment of the URLs (unescaped) to a key.
+	// Defaults to the segment itself for string keys, and to JSON otherwise.
+	DecodeKey func(key string) (K, error)
+}
+
+// Register adds the cache under the name, replacing any cache registered under it.
+// The values and hot keys are returned as JSON, so they must be supported by encoding/json.
+// The events of a cache are read from its Events channel once an event stream is opened,
+// so the channel must not have another reader.
+func Register[K comparable, V any](a *Admin, name string, c cachego.Cache[K, V], opts KeyOpts[K]) {
+	if opts.DecodeKey == nil {
+		opts.DecodeKey = func(s string) (K, error) {
+			var key K
+			if p, ok := any(&key).(*string); ok {
+				*p = s
+				return key, nil
+			}
+			err := json.Unmarshal([]byte(s), &key)
+			return key, err
+		}
+	}
+
+	decode := func(s string) (K, error) {
+		key, err := opts.DecodeKey(s)
+		if err != nil {
+			return key, &badRequest{fmt.Errorf("invalid key: %w", err)}
+		}
+		return key, nil
+	}
+
+	r := &registered{
+		stats: func() (cachego.Stats, error) {
+			if p, ok := c.(cachego.StatsProvider); ok {
+				return p.Stats(), nil
+			}
+			return cachego.Stats{}, errUnsupported
+		},
+		hotKeys: func() (any, error) {
+			t, ok := c.(cachego.HotKeyTracker[K])
+			if !ok {
+				return nil, errUnsupported
+			}
+			type hotKey struct {
+				Key   K      `json:"key"`
+				Count uint64 `json:"count"`
+			}
+			keys := []hotKey{}
+			for _, h := range t.HotKeys() {
+				keys = append(keys, hotKey{Key: h.Key, Count: h.Count})
+			}
+			return keys, nil
+		},
+		inspect: func(s string) (any, error) {
+			key, err := decode(s)
+			if err != nil {
+				return nil, err
+			}
+			return inspect(c, key)
+		},
+		delete: func(ctx context.Context, s string) error {
+			key, err := decode(s)
+			if err != nil {
+				return err
+			}
+			return cachego.DeleteCtx(ctx, c, key)
+		},
+		load: func(ctx context.Context, r io.Reader) (int, error) {
+			return load(ctx, c, r)
+		},
+		clear: func(ctx context.Context) error {
+			return cachego.ClearCtx(ctx, c)
+		},
+		resize: func(size int32) error {
+			if r, ok := c.(cachego.Resizable); ok {
+				return r.Resize(size)
+			}
+			return errUnsupported
+		},
+	}
+
+	if s, ok := c.(cachego.EventSource[K, V]); ok && s.Events() != nil {
+		r.events = newHub(s.Events())
+	}
+
+	a.mx.Lock()
+	if old, ok := a.caches[name]; ok && old.events != nil {
+		old.events.close()
+	}
+	a.caches[name] = r
+	a.mx.Unlock()
+}
+
+// Unregister removes the cache registered under the name, if any, ending its event streams.
+func (a *Admin) Unregister(name string) {
+	a.mx.Lock()
+	if r, ok := a.caches[name]; ok && r.events != nil {
+		r.events.close()
+	}
+	delete(a.caches, name)
+	a.mx.Unlock()
+}
+
+// entry is the JSON form of an inspected entry.
+type entry[K comparable, V any] struct {
+	Key        K          `json:"key"`
+	Value      V          `json:"value"`
+	Created    *time.Time `json:"created,omitempty"`
+	Updated    *time.Time `json:"updated,omitempty"`
+	LastAccess *time.Time `json:"last_access,omitempty"`
+	Accesses   *uint64    `json:"accesses,omitempty"`
+	Expires    *time.Time `json:"expires,omitempty"`
+	Size       *int       `json:"size,omitempty"`
+}
+
+// inspect returns the entry of the key, with its metadata if the cache keeps any, without counting an access.
+func inspect[K comparable, V any](c cachego.Cache[K, V], key K) (any, error) {
+	in, ok := c.(cachego.Inspector[K, V])
+	if !ok {
+		v, ok := cachego.LookupValue(c, key)
+		if !ok {
+			return nil, fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+		}
+		return entry[K, V]{Key: key, Value: v}, nil
+	}
+
+	v, info, err := in.GetWithInfo(key)
+	if err != nil {
+		return nil, err
+	}
+
+	e := entry[K, V]{Key: key, Value: v, Created: &info.Created, Updated: &info.Updated, Accesses: &info.Accesses, Size: &info.Size}
+	if !info.LastAccess.IsZero() {
+		e.LastAccess = &info.LastAccess
+	}
+	if !info.Expires.IsZero() {
+		e.Expires = &info.Expires
+	}
+	return e, nil
+}
+
+// load stores the entries of the snapshot read, in the format persisted by the caches,
+// and returns the number of entries stored. It stops at the first entry the cache rejects.
+func load[K comparable, V any](ctx context.Context, c cachego.Cache[K, V], r io.Reader) (int, error) {
+	var entries []struct {
+		Key   K `json:"key"`
+		Value V `json:"value"`
+	}
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return 0, &badRequest{fmt.Errorf("invalid snapshot: %w", err)}
+	}
+
+	for i, e := range entries {
+		if err := cachego.SetCtx(ctx, c, e.Key, e.Value); err != nil {
+			return i, fmt.Errorf("storing entry %d of %d failed: %w", i+1, len(entries), err)
+		}
+	}
+
+	return len(entries), nil
+}
+
+type badRequest struct{ error }
+
+func (e *badRequest) Unwrap() error { return e.error }
+
+func (a *Admin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := a.auth(r); err != nil {
+		writeError(w, http.StatusForbidden, err)
+		return
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.EscapedPath(), "/"), "/", 4)
+	if parts[0] != "caches" {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+	if len(parts) == 1 || (len(parts) == 2 && parts[1] == "") {
+		a.serveList(w, r)
+		return
+	}
+
+	name, err := url.PathUnescape(parts[1])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	a.mx.RLock()
+	c, ok := a.caches[name]
+	a.mx.RUnlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("cache %q is not registered", name))
+		return
+	}
+
+	op := ""
+	if len(parts) > 2 {
+		op = parts[2]
+	}
+	switch {
+	case op == "stats" && len(parts) == 3:
+		if allow(w, r, http.MethodGet) {
+			s, err := c.stats()
+			respond(w, statsJSON(s), err)
+		}
+
+	case op == "hotkeys" && len(parts) == 3:
+		if allow(w, r, http.MethodGet) {
+			keys, err := c.hotKeys()
+			respond(w, keys, err)
+		}
+
+	case op == "keys" && len(parts) == 4:
+		key, err := url.PathUnescape(parts[3])
+		if err != nil || key == "" {
+			writeError(w, http.StatusBadRequest, errors.New("invalid key"))
+			return
+		}
+		if r.Method == http.MethodDelete {
+			respond(w, nil, c.delete(r.Context(), key))
+		} else if allow(w, r, http.MethodGet, http.MethodDelete) {
+			e, err := c.inspect(key)
+			respond(w, e, err)
+		}
+
+	case op == "entries" && len(parts) == 3:
+		if allow(w, r, http.MethodPost) {
+			n, err := c.load(r.Context(), http.MaxBytesReader(w, r.Body, maxEntries))
+			if err != nil {
+				respond(w, nil, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]int{"stored": n})
+		}
+
+	case op == "events" && len(parts) == 3:
+		if allow(w, r, http.MethodGet) {
+			serveEvents(w, r, c.events)
+		}
+
+	case op == "clear" && len(parts) == 3:
+		if allow(w, r, http.MethodPost) {
+			respond(w, nil, c.clear(r.Context()))
+		}
+
+	case op == "resize" && len(parts) == 3:
+		if allow(w, r, http.MethodPost) {
+			var body struct {
+				Size int32 `json:"size"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Size <= 0 {
+				writeError(w, http.StatusBadRequest, errors.New(`expected a positive size, e.g. {"size": 1000}`))
+				return
+			}
+			respond(w, nil, c.resize(body.Size))
+		}
+
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+// serveList lists the registered caches, with their stats if they keep any.
+func (a *Admin) serveList(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+
+	a.mx.RLock()
+	names := make([]string, 0, len(a.caches))
+	for name := range a.caches {
+		names = append(names, name)
+	}
+	a.mx.RUnlock()
+	sort.Strings(names)
+
+	type cache struct {
+		Name  string         `json:"name"`
+		Stats map[string]any `json:"stats,omitempty"`
+	}
+	caches := make([]cache, 0, len(names))
+	for _, name := range names {
+		a.mx.RLock()
+		c, ok := a.caches[name]
+		a.mx.RUnlock()
+		if !ok {
+			continue
+		}
+		entry := cache{Name: name}
+		if s, err := c.stats(); err == nil {
+			entry.Stats = statsJSON(s)
+		}
+		caches = append(caches, entry)
+	}
+
+	writeJSON(w, http.StatusOK, caches)
+}
+
+func statsJSON(s cachego.Stats) map[string]any {
+	return map[string]any{
+		"hits":           s.Hits,
+		"misses":         s.Misses,
+		"sets":           s.Sets,
+		"deletes":        s.Deletes,
+		"evictions":      s.Evictions,
+		"expirations":    s.Expirations,
+		"rejections":     s.Rejections,
+		"size":           s.Size,
+		"bytes":          s.Bytes,
+		"hit_ratio":      s.HitRatio(),
+		"uptime_seconds": s.Uptime.Seconds(),
+	}
+}
+
+// allow reports whether the method of the request is one of the given ones, and rejects the request otherwise.
+func allow(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	return false
+}
+
+// respond writes the result of an operation, or its error with the matching status.
+func respond(w http.ResponseWriter, v any, err error) {
+	var bad *badRequest
+	switch {
+	case err == nil && v == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case err == nil:
+		writeJSON(w, http.StatusOK, v)
+	case errors.As(err, &bad):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, cachego.ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, errUnsupported):
+		writeError(w, http.StatusNotImplemented, err)
+	case errors.Is(err, cachego.ErrReadOnly):
+		writeError(w, http.StatusForbidden, err)
+	case errors.Is(err, cachego.ErrCacheFull):
+		writeError(w, http.StatusInsufficientStorage, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
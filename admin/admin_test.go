@@ -0,0 +1,163 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/noam-g4/cachego"
+)
+
+type response struct {
+	status int
+	body   string
+}
+
+func do(t *testing.T, h http.Handler, method, path, body string) response {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	b, err := io.ReadAll(rec.Result().Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return response{status: rec.Code, body: strings.TrimSpace(string(b))}
+}
+
+// nolint:errcheck
+func TestAdmin(t *testing.T) {
+	a := New(Opts{Auth: AllowAll})
+	users := cachego.NewCache[string, string](cachego.Opts{Size: 10, HotKeys: 2})
+	users.Set("a", "alice")
+	users.Set("b", "bob")
+	users.Get("a")
+	users.Get("a")
+	users.Get("b")
+	Register(a, "users", users, KeyOpts[string]{})
+	Register[int, int](a, "ids", cachego.NewNopCache[int, int](), KeyOpts[int]{})
+
+	for _, tc := range []struct {
+		method, path, body string
+		expected           response
+	}{
+		{http.MethodGet, "/caches/users/hotkeys", "", response{http.StatusOK, `[{"key":"a","count":2},{"key":"b","count":1}]`}},
+		{http.MethodGet, "/caches/users/keys/c", "", response{http.StatusNotFound, `{"error":"key c not found"}`}},
+		{http.MethodDelete, "/caches/users/keys/b", "", response{http.StatusNoContent, ""}},
+		{http.MethodDelete, "/caches/users/keys/b", "", response{http.StatusNotFound, `{"error":"key b not found"}`}},
+		{http.MethodPost, "/caches/users/resize", `{"size": 1}`, response{http.StatusNoContent, ""}},
+		{http.MethodPost, "/caches/users/resize", `{"size": 0}`, response{http.StatusBadRequest, `{"error":"expected a positive size, e.g. {\"size\": 1000}"}`}},
+		{http.MethodGet, "/caches/users/resize", "", response{http.StatusMethodNotAllowed, `{"error":"method not allowed"}`}},
+		{http.MethodGet, "/caches/ids/keys/1", "", response{http.StatusNotFound, `{"error":"key 1 not found"}`}},
+		{http.MethodGet, "/caches/ids/keys/x", "", response{http.StatusBadRequest, `{"error":"invalid key: invalid character 'x' looking for beginning of value"}`}},
+		{http.MethodGet, "/caches/ids/stats", "", response{http.StatusNotImplemented, `{"error":"the cache doesn't support this operation"}`}},
+		{http.MethodGet, "/caches/ids/hotkeys", "", response{http.StatusNotImplemented, `{"error":"the cache doesn't support this operation"}`}},
+		{http.MethodPost, "/caches/ids/resize", `{"size": 1}`, response{http.StatusNotImplemented, `{"error":"the cache doesn't support this operation"}`}},
+		{http.MethodGet, "/caches/other/stats", "", response{http.StatusNotFound, `{"error":"cache \"other\" is not registered"}`}},
+		{http.MethodGet, "/other", "", response{http.StatusNotFound, `{"error":"not found"}`}},
+	} {
+		if got := do(t, a, tc.method, tc.path, tc.body); got != tc.expected {
+			t.Errorf("%v %v: expected %+v, got %+v", tc.method, tc.path, tc.expected, got)
+		}
+	}
+
+	// inspecting an entry doesn't count as an access
+	res := do(t, a, http.MethodGet, "/caches/users/keys/a", "")
+	var e struct {
+		Key      string `json:"key"`
+		Value    string `json:"value"`
+		Accesses uint64 `json:"accesses"`
+	}
+	if err := json.Unmarshal([]byte(res.body), &e); err != nil || e.Key != "a" || e.Value != "alice" || e.Accesses != 2 {
+		t.Errorf("expected a: alice with 2 accesses, got %v", res.body)
+	}
+	do(t, a, http.MethodGet, "/caches/users/keys/a", "")
+	if hits := users.(cachego.StatsProvider).Stats().Hits; hits != 3 {
+		t.Errorf("expected %v hits, got %v", 3, hits)
+	}
+
+	res = do(t, a, http.MethodGet, "/caches", "")
+	var list []struct {
+		Name  string         `json:"name"`
+		Stats map[string]any `json:"stats"`
+	}
+	json.Unmarshal([]byte(res.body), &list)
+	if len(list) != 2 || list[0].Name != "ids" || list[0].Stats != nil || list[1].Name != "users" || list[1].Stats["size"] != 1.0 {
+		t.Errorf("expected ids and users with 1 entry, got %v", res.body)
+	}
+
+	if got := do(t, a, http.MethodPost, "/caches/users/clear", ""); got.status != http.StatusNoContent {
+		t.Errorf("expected %v, got %+v", http.StatusNoContent, got)
+	}
+	if got := do(t, a, http.MethodGet, "/caches/users/stats", ""); !strings.Contains(got.body, `"size":0`) {
+		t.Errorf("expected an empty cache, got %+v", got)
+	}
+
+	a.Unregister("users")
+	if got := do(t, a, http.MethodGet, "/caches/users/stats", ""); got.status != http.StatusNotFound {
+		t.Errorf("expected %v, got %+v", http.StatusNotFound, got)
+	}
+}
+
+func TestAdminAuth(t *testing.T) {
+	a := New(Opts{Auth: func(r *http.Request) error {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			return errors.New("unauthorized")
+		}
+		return nil
+	}})
+	Register(a, "users", cachego.NewCache[string, string](cachego.Opts{Size: 10}), KeyOpts[string]{})
+
+	if got := do(t, a, http.MethodPost, "/caches/users/clear", ""); got.status != http.StatusNoContent {
+		t.Errorf("expected %v, got %+v", http.StatusNoContent, got)
+	}
+
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/caches/users/clear", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected %v, got %v", http.StatusForbidden, rec.Code)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic without Auth")
+		}
+	}()
+	New(Opts{})
+}
+
+// nolint:errcheck
+func TestAdminEntries(t *testing.T) {
+	a := New(Opts{Auth: AllowAll})
+	ids := cachego.NewCache[int, string](cachego.Opts{Size: 2})
+	Register(a, "ids", ids, KeyOpts[int]{})
+	Register[int, int](a, "ro", cachego.ReadOnly(cachego.NewNopCache[int, int]()), KeyOpts[int]{})
+
+	for _, tc := range []struct {
+		path, body string
+		expected   response
+	}{
+		{"/caches/ids/entries", `[{"key": 1, "value": "a"}, {"key": 2, "value": "b"}]`, response{http.StatusOK, `{"stored":2}`}},
+		{"/caches/ids/entries", `[{"key": 3, "value": "c"}]`, response{http.StatusInsufficientStorage, `{"error":"storing entry 1 of 1 failed: key 3: cache is full"}`}},
+		{"/caches/ro/entries", `[{"key": 1, "value": 1}]`, response{http.StatusForbidden, `{"error":"storing entry 1 of 1 failed: set key 1: cache is read-only"}`}},
+	} {
+		if got := do(t, a, http.MethodPost, tc.path, tc.body); got != tc.expected {
+			t.Errorf("%v: expected %+v, got %+v", tc.path, tc.expected, got)
+		}
+	}
+
+	if got := do(t, a, http.MethodPost, "/caches/ids/entries", `{"1": "a"}`); got.status != http.StatusBadRequest || !strings.Contains(got.body, "invalid snapshot") {
+		t.Errorf("expected %v, got %+v", http.StatusBadRequest, got)
+	}
+	if v, err := ids.Get(2); err != nil || v != "b" {
+		t.Errorf("expected %v, got %v, %v", "b", v, err)
+	}
+}
@@ -0,0 +1,161 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// heartbeat is the interval of the comments sent on idle event streams, so proxies keep them open.
+var heartbeat = 15 * time.Second
+
+// streamBuffer is the number of events buffered for each stream. The events of streams too slow to keep up are dropped.
+const streamBuffer = 256
+
+// message is an event sent on the streams, with its type and JSON.
+type message struct {
+	event string
+	data  []byte
+}
+
+// hub broadcasts the events of a cache, encoded in JSON, to the streams subscribed to them.
+// It reads the events from the first subscription until the cache is unregistered.
+type hub struct {
+	mx      sync.Mutex
+	pump    func(ctx context.Context, send func(m message))
+	cancel  context.CancelFunc
+	closed  bool
+	streams map[chan message]struct{}
+}
+
+func newHub[K comparable, V any](events <-chan cachego.Event[K, V]) *hub {
+	type event struct {
+		Type   string    `json:"type"`
+		Key    K         `json:"key"`
+		Value  V         `json:"value"`
+		Reason string    `json:"reason,omitempty"`
+		Time   time.Time `json:"time"`
+	}
+
+	pump := func(ctx context.Context, send func(m message)) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				ev := event{Type: e.Type.String(), Key: e.Key, Value: e.Value, Time: e.Time}
+				if e.Type != cachego.EventSet {
+					ev.Reason = e.Reason.String()
+				}
+				if b, err := json.Marshal(ev); err == nil {
+					send(message{event: ev.Type, data: b})
+				}
+			}
+		}
+	}
+
+	return &hub{pump: pump, streams: make(map[chan message]struct{})}
+}
+
+// subscribe returns a stream of the events, and a function to unsubscribe from it.
+func (h *hub) subscribe() (<-chan message, func()) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	if h.cancel == nil && !h.closed {
+		ctx, cancel := context.WithCancel(context.Background())
+		h.cancel = cancel
+		go h.pump(ctx, h.send)
+	}
+
+	stream := make(chan message, streamBuffer)
+	if h.closed {
+		close(stream)
+		return stream, func() {}
+	}
+	h.streams[stream] = struct{}{}
+
+	return stream, func() {
+		h.mx.Lock()
+		if _, ok := h.streams[stream]; ok {
+			delete(h.streams, stream)
+			close(stream)
+		}
+		h.mx.Unlock()
+	}
+}
+
+func (h *hub) send(m message) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	for stream := range h.streams {
+		select {
+		case stream <- m:
+		default:
+		}
+	}
+}
+
+// close stops reading the events, and ends the streams.
+func (h *hub) close() {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	h.closed = true
+	if h.cancel != nil {
+		h.cancel()
+	}
+	for stream := range h.streams {
+		delete(h.streams, stream)
+		close(stream)
+	}
+}
+
+// serveEvents streams the events of the cache as server-sent events, until the client goes away
+// or the cache is unregistered.
+func serveEvents(w http.ResponseWriter, r *http.Request, h *hub) {
+	if h == nil {
+		respond(w, nil, errUnsupported)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	stream, unsubscribe := h.subscribe()
+	defer unsubscribe()
+
+	tick := time.NewTicker(heartbeat)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-tick.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case m, ok := <-stream:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", m.event, m.data)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// nolint:errcheck
+func TestEvents(t *testing.T) {
+	a := New(Opts{Auth: AllowAll})
+	users := cachego.NewLRUCacheWithOpts[string, string](cachego.Opts{Size: 1, Events: 10})
+	Register(a, "users", users, KeyOpts[string]{})
+	Register[int, int](a, "ids", cachego.NewNopCache[int, int](), KeyOpts[int]{})
+	s := httptest.NewServer(a)
+	defer s.Close()
+
+	if got := do(t, a, http.MethodGet, "/caches/ids/events", ""); got.status != http.StatusNotImplemented {
+		t.Errorf("expected %v, got %+v", http.StatusNotImplemented, got)
+	}
+
+	res, err := http.Get(s.URL + "/caches/users/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected %v, got %v", "text/event-stream", ct)
+	}
+
+	users.Set("a", "alice")
+	users.Set("b", "bob")
+	users.Delete("b")
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			if line := scanner.Text(); line != "" {
+				lines <- line
+			}
+		}
+	}()
+
+	for _, expected := range []string{
+		"event: set", `data: {"type":"set","key":"a","value":"alice"`,
+		"event: set", `data: {"type":"set","key":"b","value":"bob"`,
+		"event: evict", `data: {"type":"evict","key":"a","value":"alice","reason":"capacity"`,
+		"event: delete", `data: {"type":"delete","key":"b","value":"bob","reason":"deleted"`,
+	} {
+		select {
+		case line := <-lines:
+			if !strings.HasPrefix(line, expected) {
+				t.Errorf("expected %v, got %v", expected, line)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected %v", expected)
+		}
+	}
+
+	// unregistering the cache ends the stream
+	a.Unregister("users")
+	select {
+	case line, ok := <-lines:
+		if ok {
+			t.Errorf("expected the stream to end, got %v", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected the stream to end")
+	}
+}
@@ -0,0 +1,67 @@
+package cachego
+
+import (
+	"math/rand"
+	"time"
+)
+
+const defaultAdmissionReject = 0.9
+
+// AdmissionOpts configures the throttling of the new keys of the LRU cache under overload: while it evicts more
+// entries per second than MaxEvictionRate, the keys it never saw are rejected at random, so a burst of one-off keys
+// doesn't churn out the entries in use. A key rejected once is admitted the next time it is set,
+// and the keys already cached are always updated.
+type AdmissionOpts struct {
+	// MaxEvictionRate is the number of evictions per second above which the new keys are throttled.
+	// If less than or equal to zero, the new keys are always admitted.
+	MaxEvictionRate float64
+	// Reject is the probability of rejecting a key never seen while throttling, between 0 and 1. Defaults to 0.9.
+	Reject float64
+	// Interval is the period the eviction rate is measured over. Defaults to a second.
+	Interval time.Duration
+}
+
+// admission decides whether the new keys are admitted. Its methods must be called with the lock of the cache held.
+type admission struct {
+	opts AdmissionOpts
+	// seen counts the keys offered to the cache, admitted or not.
+	seen *sketch
+	// start is the beginning of the current interval, and evictions the evictions counted by then.
+	start      time.Time
+	evictions  uint64
+	throttling bool
+}
+
+func newAdmission(opts AdmissionOpts, size int32) *admission {
+	if opts.MaxEvictionRate <= 0 {
+		return nil
+	}
+	if opts.Reject <= 0 || opts.Reject > 1 {
+		opts.Reject = defaultAdmissionReject
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+
+	return &admission{opts: opts, seen: newSketch(int(size))}
+}
+
+// admit reports whether a new key of the given hash is admitted, given the evictions the cache counted so far.
+func (a *admission) admit(sum uint64, now time.Time, evictions uint64) bool {
+	if a == nil {
+		return true
+	}
+
+	if a.start.IsZero() {
+		a.start, a.evictions = now, evictions
+	} else if elapsed := now.Sub(a.start); elapsed >= a.opts.Interval {
+		a.throttling = float64(evictions-a.evictions)/elapsed.Seconds() > a.opts.MaxEvictionRate
+		a.start, a.evictions = now, evictions
+	}
+
+	if a.seen.increment(sum) > 1 || !a.throttling {
+		return true
+	}
+
+	return rand.Float64() >= a.opts.Reject
+}
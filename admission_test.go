@@ -0,0 +1,60 @@
+package cachego
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// nolint:errcheck
+func TestAdmission(t *testing.T) {
+	clock := newFakeClock()
+	c := NewLRUCacheWithOpts[int, int](Opts{
+		Size:      10,
+		Clock:     clock,
+		Admission: AdmissionOpts{MaxEvictionRate: 5, Reject: 1},
+	})
+	for i := 0; i < 10; i++ {
+		c.Set(i, i)
+	}
+
+	// 20 evictions in a second
+	for i := 10; i < 30; i++ {
+		if err := c.Set(i, i); err != nil {
+			t.Fatalf("expected the keys to be admitted before throttling, got %v", err)
+		}
+	}
+	clock.Advance(time.Second)
+
+	if err := c.Set(100, 100); !errors.Is(err, ErrCacheFull) {
+		t.Fatalf("expected a never seen key to be rejected, got %v", err)
+	}
+	if c.(ExtendedCache[int, int]).Has(100) {
+		t.Errorf("expected 100 not to be cached")
+	}
+	if err := c.Set(100, 100); err != nil {
+		t.Errorf("expected a key seen before to be admitted, got %v", err)
+	}
+	if err := c.Set(29, 0); err != nil {
+		t.Errorf("expected an update to be admitted, got %v", err)
+	}
+	if stats := c.(StatsProvider).Stats(); stats.Rejections != 1 {
+		t.Errorf("expected 1 rejection, got %v", stats.Rejections)
+	}
+
+	// a quiet second stops the throttling
+	clock.Advance(time.Second)
+	if err := c.Set(200, 200); err != nil {
+		t.Errorf("expected the new keys to be admitted again, got %v", err)
+	}
+}
+
+func TestAdmissionDisabled(t *testing.T) {
+	if a := newAdmission(AdmissionOpts{}, 10); a != nil || !a.admit(1, time.Now(), 100) {
+		t.Errorf("expected no admission control")
+	}
+	a := newAdmission(AdmissionOpts{MaxEvictionRate: 1}, 10)
+	if a.opts.Reject != defaultAdmissionReject || a.opts.Interval != time.Second {
+		t.Errorf("expected the default options, got %+v", a.opts)
+	}
+}
@@ -0,0 +1,177 @@
+package cachego
+
+import "sync"
+
+type arena[K comparable, V any] struct {
+	index  map[K]int32 // slot of every key
+	keys   []K         // key of every slot
+	values []V         // value of every slot
+	mx     *sync.RWMutex
+	stats  *counters
+}
+
+// NewArenaCache creates a new thread-safe cache that stores its values in a single preallocated slice,
+// indexed by a map of slots, rather than as individual heap objects.
+// It suits fixed-size values such as small structs: the values are contiguous in memory,
+// and setting a key allocates nothing beyond the growth of the index.
+// The entries are kept dense: deleting a key moves the last entry into its slot.
+// If the size is less than or equal to zero, a default size of 100 will be used.
+// Like the simple cache, it returns an error wrapping ErrCacheFull when setting a new key once the size is reached.
+func NewArenaCache[K comparable, V any](size int32) Cache[K, V] {
+	if size <= 0 {
+		size = defaultSize
+	}
+
+	return &arena[K, V]{
+		index:  make(map[K]int32, size),
+		keys:   make([]K, 0, size),
+		values: make([]V, 0, size),
+		mx:     &sync.RWMutex{},
+		stats:  newCounters(systemClock{}),
+	}
+}
+
+// Set stores the value in the slot of the key, or in a new slot if the key is new.
+// If the key is new and the cache is full, it returns an error wrapping ErrCacheFull.
+// This method is thread-safe.
+func (a *arena[K, V]) Set(key K, value V) error {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	if i, ok := a.index[key]; ok {
+		a.values[i] = value
+	} else {
+		if len(a.values) == cap(a.values) {
+			a.stats.rejections.Add(1)
+			return cacheFull(key)
+		}
+		a.index[key] = int32(len(a.values))
+		a.keys = append(a.keys, key)
+		a.values = append(a.values, value)
+	}
+
+	a.stats.sets.Add(1)
+	return nil
+}
+
+// Get retrieves the value associated with the given key.
+// If the key is not found, the zero value of the value type and an error will be returned.
+// This method is thread-safe.
+func (a *arena[K, V]) Get(key K) (V, error) {
+	v, ok := a.Lookup(key)
+	if !ok {
+		return v, notFound(key)
+	}
+
+	return v, nil
+}
+
+// Lookup retrieves the value just like Get, but reports a miss with false instead of building an error.
+// This method is thread-safe.
+func (a *arena[K, V]) Lookup(key K) (V, bool) {
+	a.mx.RLock()
+	defer a.mx.RUnlock()
+
+	var v V
+	i, ok := a.index[key]
+	if ok {
+		v = a.values[i]
+	}
+
+	a.stats.lookup(ok)
+	return v, ok
+}
+
+// Delete removes the key, moving the last entry into its slot.
+// If the key is not found, an error will be returned.
+// This method is thread-safe.
+func (a *arena[K, V]) Delete(key K) error {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	i, ok := a.index[key]
+	if !ok {
+		return notFound(key)
+	}
+
+	last := int32(len(a.values) - 1)
+	if i != last {
+		a.keys[i] = a.keys[last]
+		a.values[i] = a.values[last]
+		a.index[a.keys[i]] = i
+	}
+
+	// zero the vacated slot so it doesn't keep the references of the value alive
+	var emptyKey K
+	var emptyValue V
+	a.keys[last] = emptyKey
+	a.values[last] = emptyValue
+	a.keys = a.keys[:last]
+	a.values = a.values[:last]
+	delete(a.index, key)
+
+	a.stats.deletes.Add(1)
+	return nil
+}
+
+// Clear removes every entry, keeping the preallocated slots.
+// This method is thread-safe.
+func (a *arena[K, V]) Clear() error {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	var emptyKey K
+	var emptyValue V
+	for i := range a.values {
+		a.keys[i] = emptyKey
+		a.values[i] = emptyValue
+	}
+
+	a.index = make(map[K]int32, cap(a.values))
+	a.keys = a.keys[:0]
+	a.values = a.values[:0]
+	return nil
+}
+
+// Stats returns a snapshot of the cache counters.
+// This method is thread-safe.
+func (a *arena[K, V]) Stats() Stats {
+	a.mx.RLock()
+	used := int32(len(a.values))
+	a.mx.RUnlock()
+
+	return a.stats.snapshot(used)
+}
+
+// Len returns the number of entries in the cache.
+// This method is thread-safe.
+func (a *arena[K, V]) Len() int {
+	a.mx.RLock()
+	defer a.mx.RUnlock()
+
+	return len(a.values)
+}
+
+// Has reports whether the key is in the cache, without counting as an access.
+// This method is thread-safe.
+func (a *arena[K, V]) Has(key K) bool {
+	a.mx.RLock()
+	defer a.mx.RUnlock()
+
+	_, ok := a.index[key]
+	return ok
+}
+
+// Keys returns the keys of the cache, in slot order.
+// This method is thread-safe.
+func (a *arena[K, V]) Keys() []K {
+	a.mx.RLock()
+	defer a.mx.RUnlock()
+
+	return append([]K(nil), a.keys...)
+}
+
+// Close does nothing, since the cache starts no background work. It always returns nil.
+func (a *arena[K, V]) Close() error {
+	return nil
+}
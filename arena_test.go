@@ -0,0 +1,57 @@
+package cachego
+
+import "testing"
+
+type point struct {
+	X, Y, Z float64
+}
+
+// nolint:errcheck
+func TestArenaCache(t *testing.T) {
+	c := NewArenaCache[string, point](3)
+
+	c.Set("a", point{X: 1})
+	c.Set("b", point{X: 2})
+	c.Set("c", point{X: 3})
+
+	// Set (full)
+	if err := c.Set("d", point{}); err == nil {
+		t.Errorf("Set returned nil error when cache is full")
+	}
+
+	// Set (update when full)
+	if err := c.Set("a", point{X: 10}); err != nil {
+		t.Errorf("Set returned error when updating a key: %s", err)
+	}
+
+	// deleting moves the last entry into the freed slot
+	if err := c.Delete("a"); err != nil {
+		t.Errorf("Delete returned error: %s", err)
+	}
+	if err := c.Delete("a"); err == nil {
+		t.Errorf("Delete returned nil error when key not found")
+	}
+
+	for k, x := range map[string]float64{"b": 2, "c": 3} {
+		if v, err := c.Get(k); err != nil || v.X != x {
+			t.Errorf("expected %v, got %v (%v)", x, v.X, err)
+		}
+	}
+
+	if err := c.Set("d", point{X: 4}); err != nil {
+		t.Errorf("Set returned error: %s", err)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { c.Set("d", point{X: 5}) }); allocs != 0 {
+		t.Errorf("expected no allocations on Set, got %v", allocs)
+	}
+
+	c.Clear()
+	if _, err := c.Get("b"); err == nil {
+		t.Errorf("Get returned nil error after Clear")
+	}
+
+	if stats := c.(StatsProvider).Stats(); stats.Size != 0 || stats.Deletes != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,215 @@
+// Package azure provides a cachego.File implementation that persists the cache snapshot
+// as a block blob in an Azure Blob Storage container.
+package azure
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/noam-g4/cachego"
+	"github.com/noam-g4/cachego/internal/uri"
+)
+
+const apiVersion = "2021-08-06"
+
+// Opts configures a cache file stored as a block blob in an Azure Blob Storage container.
+type Opts struct {
+	// Endpoint is the base URL of the blob service (e.g. "http://127.0.0.1:10000/devstoreaccount1" for Azurite).
+	// If empty, "https://<Account>.blob.core.windows.net" is used.
+	Endpoint  string
+	Account   string
+	Container string
+	Blob      string
+
+	// AccountKey is the base64 encoded storage account key used for Shared Key authorization.
+	// SASToken is used instead when AccountKey is empty.
+	AccountKey string
+	SASToken   string
+
+	// EncryptionScope optionally selects the encryption scope used to encrypt the blob on Dump.
+	EncryptionScope string
+
+	// Client is the HTTP client used for requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// file is an implementation of the cachego.File interface.
+// It loads and dumps the cache data from and to a blob in an Azure storage container.
+type file struct {
+	opts   Opts
+	key    []byte
+	keyErr error
+	now    func() time.Time
+}
+
+// NewFile creates a new instance of the cachego.File interface backed by a block blob in an Azure storage container.
+// The returned file implements cachego.ContextFile, so its requests carry the context of the cache operation.
+// If the account key is not valid base64, every Load and Dump will return an error.
+func NewFile(opts Opts) cachego.File {
+	if opts.Endpoint == "" {
+		opts.Endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", opts.Account)
+	}
+
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	key, err := base64.StdEncoding.DecodeString(opts.AccountKey)
+	if err != nil {
+		err = fmt.Errorf("invalid account key: %w", err)
+	}
+
+	return &file{opts: opts, key: key, keyErr: err, now: time.Now}
+}
+
+// Load downloads the blob and returns its contents as a byte slice.
+// If the operation is successful, it returns the read data and a nil error.
+// If the request fails, it returns a non-nil error, wrapping cachego.ErrNoSnapshot if the blob does not exist.
+func (a *file) Load() ([]byte, error) {
+	return a.LoadContext(context.Background())
+}
+
+// LoadContext downloads the blob just like Load, sending the request with the given context.
+func (a *file) LoadContext(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.url(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.do(req)
+}
+
+// Dump uploads the given data as a block blob, replacing any previous contents.
+// If the operation is successful, it returns a nil error.
+// If the request fails, it returns a non-nil error.
+func (a *file) Dump(data []byte) error {
+	return a.DumpContext(context.Background(), data)
+}
+
+// DumpContext uploads the blob just like Dump, sending the request with the given context.
+func (a *file) DumpContext(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, a.url(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	if a.opts.EncryptionScope != "" {
+		req.Header.Set("x-ms-encryption-scope", a.opts.EncryptionScope)
+	}
+
+	_, err = a.do(req)
+	return err
+}
+
+func (a *file) url() string {
+	blob := uri.Encode(strings.TrimPrefix(a.opts.Blob, "/"), false)
+	u := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(a.opts.Endpoint, "/"), a.opts.Container, blob)
+	if len(a.key) == 0 && a.opts.SASToken != "" {
+		u += "?" + strings.TrimPrefix(a.opts.SASToken, "?")
+	}
+
+	return u
+}
+
+func (a *file) do(req *http.Request) ([]byte, error) {
+	if a.keyErr != nil {
+		return nil, a.keyErr
+	}
+
+	req.Header.Set("x-ms-date", a.now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", apiVersion)
+	if len(a.key) > 0 {
+		a.sign(req)
+	}
+
+	res, err := a.opts.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotFound && req.Method == http.MethodGet {
+		return nil, fmt.Errorf("azure %s %s: %s: %w", req.Method, req.URL.Path, res.Status, cachego.ErrNoSnapshot)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("azure %s %s: %s", req.Method, req.URL.Path, res.Status)
+	}
+
+	return body, nil
+}
+
+// sign authorizes the request with the Shared Key scheme of the Blob service.
+func (a *file) sign(req *http.Request) {
+	length := ""
+	if req.ContentLength > 0 {
+		length = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	var msHeaders []string
+	for k := range req.Header {
+		if k := strings.ToLower(k); strings.HasPrefix(k, "x-ms-") {
+			msHeaders = append(msHeaders, k)
+		}
+	}
+	sort.Strings(msHeaders)
+
+	var b strings.Builder
+	for _, h := range []string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		length,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, superseded by x-ms-date
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	} {
+		b.WriteString(h + "\n")
+	}
+
+	for _, k := range msHeaders {
+		b.WriteString(k + ":" + strings.TrimSpace(req.Header.Get(k)) + "\n")
+	}
+
+	b.WriteString("/" + a.opts.Account + req.URL.EscapedPath())
+
+	q := req.URL.Query()
+	params := make([]string, 0, len(q))
+	for k := range q {
+		params = append(params, k)
+	}
+	sort.Strings(params)
+	for _, k := range params {
+		vals := q[k]
+		sort.Strings(vals)
+		b.WriteString("\n" + strings.ToLower(k) + ":" + strings.Join(vals, ","))
+	}
+
+	h := hmac.New(sha256.New, a.key)
+	h.Write([]byte(b.String()))
+	signature := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+	req.Header.Set("Authorization", "SharedKey "+a.opts.Account+":"+signature)
+}
@@ -0,0 +1,84 @@
+package azure
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/noam-g4/cachego"
+)
+
+func TestFile(t *testing.T) {
+	blobs := map[string][]byte{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorized := strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey account:") ||
+			r.URL.Query().Get("sig") == "signature"
+		if !authorized || r.Header.Get("x-ms-version") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPut:
+			if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			blobs[r.URL.Path], _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			data, ok := blobs[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		}
+	}))
+	defer srv.Close()
+
+	file := NewFile(Opts{
+		Endpoint:   srv.URL,
+		Account:    "account",
+		Container:  "container",
+		Blob:       "cache.json",
+		AccountKey: "c2VjcmV0",
+	})
+
+	// loading a missing blob
+	if _, err := file.Load(); !errors.Is(err, cachego.ErrNoSnapshot) {
+		t.Errorf("expected %v, got %v", cachego.ErrNoSnapshot, err)
+	}
+
+	if err := file.Dump([]byte(`{"a":"one"}`)); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	// reading the same blob with a sas token
+	sas := NewFile(Opts{
+		Endpoint:  srv.URL,
+		Account:   "account",
+		Container: "container",
+		Blob:      "cache.json",
+		SASToken:  "?sv=2021-08-06&sig=signature",
+	})
+
+	data, err := sas.Load()
+	if err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	if string(data) != `{"a":"one"}` {
+		t.Errorf("expected %v, got %v", `{"a":"one"}`, string(data))
+	}
+
+	// an invalid account key
+	invalid := NewFile(Opts{Endpoint: srv.URL, AccountKey: "%%%"})
+	if err := invalid.Dump(nil); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
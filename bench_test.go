@@ -0,0 +1,161 @@
+package cachego
+
+import (
+	"strconv"
+	"testing"
+)
+
+const benchKeys = 1024
+
+func benchCaches() map[string]func() Cache[string, int] {
+	return map[string]func() Cache[string, int]{
+		"simple": func() Cache[string, int] { return NewCache[string, int](Opts{Size: benchKeys}) },
+		"lru":    func() Cache[string, int] { return NewLRUCache[string, int](benchKeys) },
+		"lru-buffered": func() Cache[string, int] {
+			return NewLRUCacheWithOpts[string, int](Opts{Size: benchKeys, AccessBuffer: 64})
+		},
+		"lru-hotkeys": func() Cache[string, int] {
+			return NewLRUCacheWithOpts[string, int](Opts{Size: benchKeys, HotKeys: 16})
+		},
+		"cow":     func() Cache[string, int] { return NewCopyOnWriteCache[string, int](benchKeys) },
+		"syncmap": func() Cache[string, int] { return NewSyncMapCache[string, int](benchKeys) },
+		"arena":   func() Cache[string, int] { return NewArenaCache[string, int](benchKeys) },
+		"sharded": func() Cache[string, int] {
+			return NewShardedCache(ShardedOpts[string, int]{Shards: 16, New: func(int) Cache[string, int] {
+				return NewCache[string, int](Opts{Size: benchKeys})
+			}})
+		},
+	}
+}
+
+func benchKeySet() []string {
+	keys := make([]string, benchKeys)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	return keys
+}
+
+// nolint:errcheck
+func BenchmarkGet(b *testing.B) {
+	keys := benchKeySet()
+	for name, newCache := range benchCaches() {
+		b.Run(name, func(b *testing.B) {
+			c := newCache()
+			for i, k := range keys {
+				c.Set(k, i)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c.Get(keys[i%benchKeys])
+			}
+		})
+	}
+}
+
+// nolint:errcheck
+func BenchmarkGetParallel(b *testing.B) {
+	keys := benchKeySet()
+	for name, newCache := range benchCaches() {
+		b.Run(name, func(b *testing.B) {
+			c := newCache()
+			for i, k := range keys {
+				c.Set(k, i)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					c.Get(keys[i%benchKeys])
+					i++
+				}
+			})
+		})
+	}
+}
+
+// nolint:errcheck
+func BenchmarkSet(b *testing.B) {
+	keys := benchKeySet()
+	for name, newCache := range benchCaches() {
+		b.Run(name, func(b *testing.B) {
+			c := newCache()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c.Set(keys[i%benchKeys], i)
+			}
+		})
+	}
+}
+
+// nolint:errcheck
+func BenchmarkMixed(b *testing.B) {
+	keys := benchKeySet()
+	for name, newCache := range benchCaches() {
+		b.Run(name, func(b *testing.B) {
+			c := newCache()
+			for i, k := range keys {
+				c.Set(k, i)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					// 90% reads, 10% writes
+					if i%10 == 0 {
+						c.Set(keys[i%benchKeys], i)
+					} else {
+						c.Get(keys[i%benchKeys])
+					}
+					i++
+				}
+			})
+		})
+	}
+}
+
+// nolint:errcheck
+func BenchmarkMiss(b *testing.B) {
+	keys := benchKeySet()
+	for name, newCache := range benchCaches() {
+		c := newCache()
+		b.Run(name+"/get", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.Get(keys[i%benchKeys])
+			}
+		})
+		b.Run(name+"/lookup", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				LookupValue(c, keys[i%benchKeys])
+			}
+		})
+	}
+}
+
+// nolint:errcheck
+func TestGetHitAllocs(t *testing.T) {
+	keys := benchKeySet()
+	for name, newCache := range benchCaches() {
+		c := newCache()
+		for i, k := range keys {
+			c.Set(k, i)
+		}
+
+		allocs := testing.AllocsPerRun(100, func() {
+			c.Get(keys[1])
+		})
+		if allocs != 0 {
+			t.Errorf("%s: expected no allocations on a hit, got %v", name, allocs)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package cachego
+
+import "math"
+
+// bloom is a bloom filter of key hashes, sized for a number of keys at a false positive rate.
+// It is not thread-safe.
+type bloom struct {
+	bits []uint64
+	mask uint64
+	k    int
+	// added counts the keys added since the last reset, up to max, the number of keys it was sized for.
+	added int
+	max   int
+}
+
+// newBloom creates a filter holding the given number of keys with the false positive rate.
+func newBloom(keys int, fp float64) *bloom {
+	if keys <= 0 {
+		keys = 1
+	}
+
+	m := uint64(64)
+	for float64(m) < -float64(keys)*math.Log(fp)/(math.Ln2*math.Ln2) {
+		m *= 2
+	}
+	k := int(math.Round(float64(m) / float64(keys) * math.Ln2))
+	if k < 1 {
+		k = 1
+	} else if k > 16 {
+		k = 16
+	}
+
+	return &bloom{bits: make([]uint64, m/64), mask: m - 1, k: k, max: keys}
+}
+
+// add adds the hash to the filter, and reports whether it was (probably) in it already.
+func (b *bloom) add(sum uint64) bool {
+	h1, h2 := sum, sum>>32|1
+
+	found := true
+	for i := 0; i < b.k; i++ {
+		bit := (h1 + uint64(i)*h2) & b.mask
+		if w := &b.bits[bit/64]; *w&(1<<(bit%64)) == 0 {
+			*w |= 1 << (bit % 64)
+			found = false
+		}
+	}
+	if !found {
+		b.added++
+	}
+
+	return found
+}
+
+// has reports whether the hash is (probably) in the filter.
+func (b *bloom) has(sum uint64) bool {
+	h1, h2 := sum, sum>>32|1
+
+	for i := 0; i < b.k; i++ {
+		bit := (h1 + uint64(i)*h2) & b.mask
+		if b.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// full reports whether the filter holds the number of keys it was sized for,
+// beyond which its false positive rate grows.
+func (b *bloom) full() bool {
+	return b.added >= b.max
+}
+
+// reset empties the filter.
+func (b *bloom) reset() {
+	for i := range b.bits {
+		b.bits[i] = 0
+	}
+	b.added = 0
+}
@@ -0,0 +1,290 @@
+// Package bolt provides a cachego.Cache implementation whose entries live on disk in a bbolt database,
+// so datasets larger than the available memory can still be served with cache semantics.
+package bolt
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/noam-g4/cachego"
+	"go.etcd.io/bbolt"
+)
+
+const (
+	defaultMemSize = 100
+	headerSize     = 8 // expiry
+)
+
+type cache[K comparable, V any] struct {
+	db     *bbolt.DB
+	bucket []byte
+	ttl    int16 // in seconds
+	index  map[K]int64
+	mem    cachego.Cache[K, V]
+	mx     *sync.Mutex
+	purge  int64 // unix nano time of the next purge of the expired entries
+}
+
+type Opts struct {
+	// Bucket is the name of the bbolt bucket holding the entries. Defaults to "cachego".
+	Bucket string
+	// MemSize is the number of decoded values kept in the in-memory LRU layer. Defaults to 100.
+	MemSize int32
+	// TTL is the time to live of every entry, in seconds. If less than or equal to zero, entries do not expire.
+	TTL int16
+}
+
+// NewCache creates a new thread-safe instance of a disk-backed cache stored in the given bbolt database.
+// Keys and values are encoded as JSON. Every key (and its expiry) is indexed in memory on creation,
+// while only the most recently used values are kept decoded in memory.
+// The database is owned by the caller, who is responsible for closing it once the cache is no longer used.
+// It returns an error if the bucket cannot be created or the existing entries cannot be indexed.
+func NewCache[K comparable, V any](db *bbolt.DB, opts Opts) (cachego.Cache[K, V], error) {
+	bucket := opts.Bucket
+	if bucket == "" {
+		bucket = "cachego"
+	}
+
+	memSize := int32(defaultMemSize)
+	if opts.MemSize > 0 {
+		memSize = opts.MemSize
+	}
+
+	c := &cache[K, V]{
+		db:     db,
+		bucket: []byte(bucket),
+		ttl:    opts.TTL,
+		index:  make(map[K]int64),
+		mem:    cachego.NewLRUCache[K, V](memSize),
+		mx:     &sync.Mutex{},
+	}
+
+	err := db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(c.bucket)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UnixNano()
+		cur := b.Cursor()
+		for k, v := cur.First(); k != nil; {
+			if len(v) < headerSize {
+				return fmt.Errorf("entry of key %q is corrupt: %v bytes is shorter than its header", k, len(v))
+			}
+
+			expires := int64(binary.BigEndian.Uint64(v[:headerSize]))
+			if expires > 0 && expires <= now {
+				if err := cur.Delete(); err != nil {
+					return err
+				}
+				k, v = cur.Seek(k)
+				continue
+			}
+
+			var key K
+			if err := json.Unmarshal(k, &key); err != nil {
+				return fmt.Errorf("error unmarshalling key %q: %w", k, err)
+			}
+			c.index[key] = expires
+
+			k, v = cur.Next()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Set stores the provided value under the given key on disk and in the in-memory layer.
+// If the key already exists in the cache, the associated value will be updated and its ttl reset.
+// With a TTL, it also purges the expired entries from disk, at most once per TTL.
+// This method is thread-safe.
+func (c *cache[K, V]) Set(key K, value V) error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	k, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	v, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	var expires int64
+	if c.ttl > 0 {
+		now := time.Now()
+		expires = now.Add(time.Duration(c.ttl) * time.Second).UnixNano()
+		if now.UnixNano() >= c.purge {
+			if err := c.purgeExpired(now.UnixNano()); err != nil {
+				return err
+			}
+			c.purge = expires
+		}
+	}
+
+	entry := make([]byte, headerSize+len(v))
+	binary.BigEndian.PutUint64(entry, uint64(expires))
+	copy(entry[headerSize:], v)
+
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(c.bucket).Put(k, entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	c.index[key] = expires
+	return c.mem.Set(key, value)
+}
+
+// Get retrieves the value associated with the given key, reading it from disk if it is not in the in-memory layer.
+// If the key is not found or has expired, the zero value of the value type and an error will be returned.
+// This method is thread-safe.
+func (c *cache[K, V]) Get(key K) (V, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	var empty V
+
+	expires, ok := c.index[key]
+	if !ok {
+		return empty, fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+	}
+
+	if expires > 0 && expires <= time.Now().UnixNano() {
+		if err := c.delete(key); err != nil {
+			return empty, err
+		}
+		return empty, fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+	}
+
+	if v, err := c.mem.Get(key); err == nil {
+		return v, nil
+	}
+
+	k, err := json.Marshal(key)
+	if err != nil {
+		return empty, err
+	}
+
+	var value V
+	err = c.db.View(func(tx *bbolt.Tx) error {
+		entry := tx.Bucket(c.bucket).Get(k)
+		if entry == nil {
+			return fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+		}
+		if len(entry) < headerSize {
+			return fmt.Errorf("entry of key %v is corrupt: %v bytes is shorter than its header", key, len(entry))
+		}
+		return json.Unmarshal(entry[headerSize:], &value)
+	})
+	if err != nil {
+		return empty, err
+	}
+
+	c.mem.Set(key, value) // nolint:errcheck
+	return value, nil
+}
+
+// Delete removes the key-value pair associated with the given key from disk and from the in-memory layer.
+// If the key is not found, an error will be returned.
+// This method is thread-safe.
+func (c *cache[K, V]) Delete(key K) error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if _, ok := c.index[key]; !ok {
+		return fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+	}
+
+	return c.delete(key)
+}
+
+// Clear removes every entry from disk and from the in-memory layer.
+// This method is thread-safe.
+func (c *cache[K, V]) Clear() error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	err := c.db.Update(func(tx *bbolt.Tx) error {
+		if err := tx.DeleteBucket(c.bucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket(c.bucket)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	c.index = make(map[K]int64)
+	return c.mem.Clear()
+}
+
+func (c *cache[K, V]) delete(key K) error {
+	k, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	err = c.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(c.bucket).Delete(k)
+	})
+	if err != nil {
+		return err
+	}
+
+	delete(c.index, key)
+	c.mem.Delete(key) // nolint:errcheck
+	return nil
+}
+
+// purgeExpired removes the entries whose ttl lapsed from disk and from the in-memory layer, in a single transaction.
+func (c *cache[K, V]) purgeExpired(now int64) error {
+	var expired []K
+	var keys [][]byte
+	for key, expires := range c.index {
+		if expires > 0 && expires <= now {
+			k, err := json.Marshal(key)
+			if err != nil {
+				return err
+			}
+			expired = append(expired, key)
+			keys = append(keys, k)
+		}
+	}
+
+	if len(expired) == 0 {
+		return nil
+	}
+
+	err := c.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(c.bucket)
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range expired {
+		delete(c.index, key)
+		c.mem.Delete(key) // nolint:errcheck
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package bolt
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+func openDB(t *testing.T, path string) *bbolt.DB {
+	db, err := bbolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	return db
+}
+
+func TestBoltCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	db := openDB(t, path)
+
+	c, err := NewCache[int, string](db, Opts{MemSize: 1})
+	if err != nil {
+		t.Fatalf("NewCache returned error: %s", err)
+	}
+
+	// Set (more entries than the in-memory layer holds)
+	for i, v := range []string{"zero", "one", "two"} {
+		if err := c.Set(i, v); err != nil {
+			t.Errorf("Set returned error: %s", err)
+		}
+	}
+
+	// Get (served from disk)
+	v, err := c.Get(0)
+	if err != nil {
+		t.Errorf("Get returned error: %s", err)
+	}
+
+	if v != "zero" {
+		t.Errorf("Get returned incorrect value: %s", v)
+	}
+
+	// Get (not found)
+	if _, err := c.Get(3); err == nil {
+		t.Errorf("Get returned nil error when key not found")
+	}
+
+	// Delete
+	if err := c.Delete(1); err != nil {
+		t.Errorf("Delete returned error: %s", err)
+	}
+
+	if err := c.Delete(1); err == nil {
+		t.Errorf("Delete returned nil error when key not found")
+	}
+
+	db.Close()
+
+	// reopening the database restores the entries
+	db = openDB(t, path)
+	defer db.Close()
+
+	c, err = NewCache[int, string](db, Opts{MemSize: 1})
+	if err != nil {
+		t.Fatalf("NewCache returned error: %s", err)
+	}
+
+	if v, err := c.Get(2); err != nil || v != "two" {
+		t.Errorf("Get returned %v (%v) after reopening", v, err)
+	}
+
+	if _, err := c.Get(1); err == nil {
+		t.Errorf("Get returned nil error for a deleted key after reopening")
+	}
+
+	// Clear
+	if err := c.Clear(); err != nil {
+		t.Errorf("Clear returned error: %s", err)
+	}
+
+	if _, err := c.Get(2); err == nil {
+		t.Errorf("Get returned nil error after Clear")
+	}
+}
+
+func TestBoltCacheTTL(t *testing.T) {
+	db := openDB(t, filepath.Join(t.TempDir(), "cache.db"))
+	defer db.Close()
+
+	c, err := NewCache[string, string](db, Opts{TTL: 1})
+	if err != nil {
+		t.Fatalf("NewCache returned error: %s", err)
+	}
+
+	if err := c.Set("a", "one"); err != nil {
+		t.Errorf("Set returned error: %s", err)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+
+	if _, err := c.Get("a"); err == nil {
+		t.Errorf("Get returned nil error after TTL")
+	}
+}
+
+func TestBoltCachePurge(t *testing.T) {
+	db := openDB(t, filepath.Join(t.TempDir(), "cache.db"))
+	defer db.Close()
+
+	c, err := NewCache[string, string](db, Opts{TTL: 1})
+	if err != nil {
+		t.Fatalf("NewCache returned error: %s", err)
+	}
+
+	c.Set("a", "one") // nolint:errcheck
+	time.Sleep(1100 * time.Millisecond)
+
+	// a Set after the TTL purges the expired entries, even though they are never read again
+	c.Set("b", "two") // nolint:errcheck
+
+	db.View(func(tx *bbolt.Tx) error { // nolint:errcheck
+		if v := tx.Bucket([]byte("cachego")).Get([]byte(`"a"`)); v != nil {
+			t.Errorf("expected the expired entry to be purged, got %s", v)
+		}
+		return nil
+	})
+}
+
+func TestBoltCacheCorrupt(t *testing.T) {
+	db := openDB(t, filepath.Join(t.TempDir(), "cache.db"))
+	defer db.Close()
+
+	db.Update(func(tx *bbolt.Tx) error { // nolint:errcheck
+		b, _ := tx.CreateBucketIfNotExists([]byte("cachego"))
+		return b.Put([]byte(`"a"`), []byte("short"))
+	})
+
+	if _, err := NewCache[string, string](db, Opts{}); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
@@ -0,0 +1,254 @@
+// Package cachefs caches the contents and the Stat results of the files of an fs.FS in a cachego.Cache,
+// for the file systems which are slow to read, e.g. over the network or object storage:
+//
+//	fsys := cachefs.New(remote, cachego.NewLRUCacheWithOpts[string, cachefs.Entry](cachego.Opts{MaxBytes: 64 << 20}), cachefs.Opts{})
+//	http.Handle("/", http.FileServer(http.FS(fsys)))
+//
+// The files which don't exist are cached as well. Directories are listed from the file system every time.
+package cachefs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// Entry is a file stored in the cache: its FileInfo, and its contents unless it was only stat'ed.
+type Entry struct {
+	Name    string
+	Size    int64
+	Mode    fs.FileMode
+	ModTime time.Time
+	// Data is the contents of the file, if HasData is set.
+	Data    []byte
+	HasData bool
+	// NotExist is set if the file doesn't exist.
+	NotExist bool
+	// Expires is the time the entry expires at, or zero if it doesn't expire before the cache evicts it.
+	Expires time.Time
+}
+
+// Opts configures an FS.
+type Opts struct {
+	// MaxFileBytes bounds the size of the files whose contents are cached. The larger ones are read from
+	// the file system, while their Stat results are still cached. Defaults to 1 MiB.
+	MaxFileBytes int64
+	// TTL is the time to live of the entries, after which the files are read again, e.g. to follow their changes.
+	// Defaults to the entries not expiring before the cache evicts them.
+	TTL time.Duration
+	// Clock tells the time the entries are stored and expire at. Defaults to the system clock.
+	Clock cachego.Clock
+	// Logger receives the errors of the cache, which are otherwise served as misses. Defaults to discarding them.
+	Logger cachego.Logger
+}
+
+// FS is an fs.FS serving the files of another one from the cache. It implements fs.StatFS, fs.ReadFileFS
+// and fs.ReadDirFS, and is thread-safe.
+type FS struct {
+	fsys fs.FS
+	c    cachego.Cache[string, Entry]
+	opts Opts
+}
+
+// New creates an FS caching the files of the file system in the cache, under their path.
+func New(fsys fs.FS, c cachego.Cache[string, Entry], opts Opts) *FS {
+	if opts.MaxFileBytes <= 0 {
+		opts.MaxFileBytes = 1 << 20
+	}
+	if opts.Logger == nil {
+		opts.Logger = nopLogger{}
+	}
+
+	return &FS{fsys: fsys, c: c, opts: opts}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
+
+// Open opens the file, from its cached contents if possible.
+func (f *FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if e, ok := f.lookup(name); ok {
+		if e.NotExist {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		if e.HasData {
+			return &cachedFile{Reader: bytes.NewReader(e.Data), info: info{e}}, nil
+		}
+	}
+
+	file, err := f.fsys.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			f.store(name, Entry{NotExist: true})
+		}
+		return nil, err
+	}
+
+	fi, err := file.Stat()
+	if err != nil || !fi.Mode().IsRegular() || fi.Size() > f.opts.MaxFileBytes {
+		if err == nil {
+			f.store(name, entry(fi))
+		}
+		return file, nil
+	}
+
+	e, err := f.read(name, file, fi)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedFile{Reader: bytes.NewReader(e.Data), info: info{e}}, nil
+}
+
+// read reads the contents of the open file, and caches them along with its FileInfo.
+func (f *FS) read(name string, file fs.File, fi fs.FileInfo) (Entry, error) {
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, f.opts.MaxFileBytes+1))
+	if err != nil {
+		return Entry{}, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+
+	e := entry(fi)
+	if int64(len(data)) > f.opts.MaxFileBytes {
+		// the file grew since it was stat'ed
+		rest, err := io.ReadAll(file)
+		if err != nil {
+			return Entry{}, &fs.PathError{Op: "read", Path: name, Err: err}
+		}
+		e.Data, e.Size = append(data, rest...), int64(len(data)+len(rest))
+		return e, nil
+	}
+
+	e.Data, e.Size, e.HasData = data, int64(len(data)), true
+	f.store(name, e)
+	return e, nil
+}
+
+// Stat returns the FileInfo of the file, from the cache if possible.
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if e, ok := f.lookup(name); ok {
+		if e.NotExist {
+			return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+		}
+		return info{e}, nil
+	}
+
+	fi, err := fs.Stat(f.fsys, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			f.store(name, Entry{NotExist: true})
+		}
+		return nil, err
+	}
+
+	f.store(name, entry(fi))
+	return fi, nil
+}
+
+// ReadFile returns the contents of the file, from the cache if possible. The caller may modify them.
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	file, err := f.Open(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: unwrap(err)}
+	}
+	defer file.Close()
+
+	if c, ok := file.(*cachedFile); ok {
+		return bytes.Clone(c.info.e.Data), nil
+	}
+	return io.ReadAll(file)
+}
+
+func unwrap(err error) error {
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		return pe.Err
+	}
+
+	return err
+}
+
+// ReadDir lists the directory from the file system.
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(f.fsys, name)
+}
+
+// Invalidate removes the cached entry of the file, e.g. after it changed.
+func (f *FS) Invalidate(name string) error {
+	if err := f.c.Delete(name); err != nil && !errors.Is(err, cachego.ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+func (f *FS) lookup(name string) (Entry, bool) {
+	e, err := f.c.Get(name)
+	if err != nil {
+		if !errors.Is(err, cachego.ErrNotFound) {
+			f.opts.Logger.Printf("cachefs: getting %q failed: %v", name, err)
+		}
+		return Entry{}, false
+	}
+	if !e.Expires.IsZero() && !f.now().Before(e.Expires) {
+		return Entry{}, false
+	}
+
+	return e, true
+}
+
+func (f *FS) store(name string, e Entry) {
+	if f.opts.TTL > 0 {
+		e.Expires = f.now().Add(f.opts.TTL)
+	}
+	if err := f.c.Set(name, e); err != nil {
+		f.opts.Logger.Printf("cachefs: storing %q failed: %v", name, err)
+	}
+}
+
+func (f *FS) now() time.Time {
+	if f.opts.Clock == nil {
+		return time.Now()
+	}
+
+	return f.opts.Clock.Now()
+}
+
+func entry(fi fs.FileInfo) Entry {
+	return Entry{Name: fi.Name(), Size: fi.Size(), Mode: fi.Mode(), ModTime: fi.ModTime()}
+}
+
+// info is the FileInfo of an entry.
+type info struct {
+	e Entry
+}
+
+func (i info) Name() string       { return i.e.Name }
+func (i info) Size() int64        { return i.e.Size }
+func (i info) Mode() fs.FileMode  { return i.e.Mode }
+func (i info) ModTime() time.Time { return i.e.ModTime }
+func (i info) IsDir() bool        { return i.e.Mode.IsDir() }
+func (i info) Sys() any           { return nil }
+
+// cachedFile is a file open from its cached contents. It implements io.Seeker and io.ReaderAt, as the files of os.
+type cachedFile struct {
+	*bytes.Reader
+	info info
+}
+
+func (f *cachedFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *cachedFile) Close() error { return nil }
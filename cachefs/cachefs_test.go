@@ -0,0 +1,125 @@
+package cachefs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) add(d time.Duration) { c.now = c.now.Add(d) }
+
+func (c *fakeClock) NewTimer(time.Duration) cachego.Timer { panic("not used") }
+
+// counting counts the files opened and stat'ed by name.
+type counting struct {
+	fs.FS
+	mx    sync.Mutex
+	calls map[string]int
+}
+
+func (c *counting) count(name string) {
+	c.mx.Lock()
+	c.calls[name]++
+	c.mx.Unlock()
+}
+
+func (c *counting) Open(name string) (fs.File, error) {
+	c.count(name)
+	return c.FS.Open(name)
+}
+
+func (c *counting) Stat(name string) (fs.FileInfo, error) {
+	c.count(name)
+	return fs.Stat(c.FS, name)
+}
+
+func (c *counting) get(name string) int {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.calls[name]
+}
+
+func newFS() fstest.MapFS {
+	return fstest.MapFS{
+		"a.txt":       {Data: []byte("hello"), ModTime: time.Unix(1, 0)},
+		"dir/b.txt":   {Data: []byte("world")},
+		"dir/big.bin": {Data: []byte(strings.Repeat("x", 100))},
+		"empty":       {Data: []byte{}},
+	}
+}
+
+func TestFS(t *testing.T) {
+	fsys := New(newFS(), cachego.NewLRUCache[string, Entry](100), Opts{MaxFileBytes: 10})
+	if err := fstest.TestFS(fsys, "a.txt", "dir/b.txt", "dir/big.bin", "empty"); err != nil {
+		t.Fatal(err)
+	}
+	// again, from the cache
+	if err := fstest.TestFS(fsys, "a.txt", "dir/b.txt", "dir/big.bin", "empty"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCaching(t *testing.T) {
+	under := &counting{FS: newFS(), calls: make(map[string]int)}
+	clock := &fakeClock{now: time.Now()}
+	fsys := New(under, cachego.NewLRUCache[string, Entry](100), Opts{MaxFileBytes: 10, TTL: time.Minute, Clock: clock})
+
+	for i := 0; i < 3; i++ {
+		if b, err := fs.ReadFile(fsys, "a.txt"); err != nil || string(b) != "hello" {
+			t.Errorf("expected %v, got %v (%v)", "hello", string(b), err)
+		}
+		if b, err := fs.ReadFile(fsys, "dir/big.bin"); err != nil || len(b) != 100 {
+			t.Errorf("expected %v bytes, got %v (%v)", 100, len(b), err)
+		}
+		if fi, err := fsys.Stat("dir/big.bin"); err != nil || fi.Size() != 100 {
+			t.Errorf("expected %v bytes, got %v (%v)", 100, fi, err)
+		}
+		if _, err := fsys.Open("missing"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected %v, got %v", fs.ErrNotExist, err)
+		}
+	}
+
+	for name, expected := range map[string]int{"a.txt": 1, "dir/big.bin": 3, "missing": 1} {
+		if n := under.get(name); n != expected {
+			t.Errorf("%v: expected %v reads, got %v", name, expected, n)
+		}
+	}
+
+	// the cached file is seekable, like the ones of os
+	f, err := fsys.Open("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.(io.Seeker).Seek(1, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := io.ReadAll(f); err != nil || string(b) != "ello" {
+		t.Errorf("expected %v, got %v (%v)", "ello", string(b), err)
+	}
+
+	// the entries expire after the ttl
+	clock.add(time.Minute)
+	fs.ReadFile(fsys, "a.txt") // nolint:errcheck
+	if n := under.get("a.txt"); n != 2 {
+		t.Errorf("expected %v reads, got %v", 2, n)
+	}
+
+	fsys.Invalidate("a.txt")   // nolint:errcheck
+	fs.ReadFile(fsys, "a.txt") // nolint:errcheck
+	if n := under.get("a.txt"); n != 3 {
+		t.Errorf("expected %v reads, got %v", 3, n)
+	}
+}
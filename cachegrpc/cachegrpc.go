@@ -0,0 +1,53 @@
+// Package cachegrpc serves a cachego.Cache over gRPC, and provides a client implementing cachego.Cache,
+// so remote and local caches are interchangeable behind the interface.
+// Interceptor caches the replies of the unary RPCs of any gRPC client.
+//
+// The service is defined in cache.proto, so clients and servers in other languages can be generated from it.
+// The package implements the gRPC protocol over the HTTP/2 support of net/http, so the module stays free
+// of the gRPC dependency: the server is an http.Handler, and the client takes an *http.Client.
+// Both must speak HTTP/2, which net/http does over TLS; plaintext connections need an h2c handler
+// and transport, e.g. from golang.org/x/net/http2.
+package cachegrpc
+
+import "encoding/json"
+
+// Opts configures the encoding of the keys and values, which must match between the server and its clients.
+type Opts[K comparable, V any] struct {
+	// EncodeKey and DecodeKey convert the keys to and from the bytes of the messages.
+	// Default to the keys themselves for string keys, and to JSON otherwise.
+	EncodeKey func(key K) ([]byte, error)
+	DecodeKey func(data []byte) (K, error)
+	// Marshal and Unmarshal encode and decode the values. Default to encoding/json.
+	Marshal   func(value V) ([]byte, error)
+	Unmarshal func(data []byte, value *V) error
+}
+
+func (o Opts[K, V]) withDefaults() Opts[K, V] {
+	if o.EncodeKey == nil {
+		o.EncodeKey = func(key K) ([]byte, error) {
+			if s, ok := any(key).(string); ok {
+				return []byte(s), nil
+			}
+			return json.Marshal(key)
+		}
+	}
+	if o.DecodeKey == nil {
+		o.DecodeKey = func(data []byte) (K, error) {
+			var key K
+			if p, ok := any(&key).(*string); ok {
+				*p = string(data)
+				return key, nil
+			}
+			err := json.Unmarshal(data, &key)
+			return key, err
+		}
+	}
+	if o.Marshal == nil {
+		o.Marshal = func(value V) ([]byte, error) { return json.Marshal(value) }
+	}
+	if o.Unmarshal == nil {
+		o.Unmarshal = func(data []byte, value *V) error { return json.Unmarshal(data, value) }
+	}
+
+	return o
+}
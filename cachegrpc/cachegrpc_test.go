@@ -0,0 +1,142 @@
+package cachegrpc
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+	"github.com/noam-g4/cachego/cachetest"
+)
+
+// newClient serves the cache over HTTP/2 and TLS, and returns a client of it.
+func newClient[K comparable, V any](t *testing.T, c cachego.Cache[K, V]) *Client[K, V] {
+	s := httptest.NewUnstartedServer(NewServer(c, Opts[K, V]{}))
+	s.EnableHTTP2 = true
+	s.StartTLS()
+	t.Cleanup(s.Close)
+
+	return NewClient(s.URL, s.Client(), Opts[K, V]{})
+}
+
+func TestConformance(t *testing.T) {
+	cachetest.TestCache(t, func() cachego.Cache[string, string] {
+		return newClient[string, string](t, cachego.NewCache[string, string](cachego.Opts{Size: 1000}))
+	})
+}
+
+// nolint:errcheck
+func TestClient(t *testing.T) {
+	local := cachego.NewCache[int, []string](cachego.Opts{Size: 1})
+	c := newClient[int, []string](t, local)
+
+	if err := c.Set(1, []string{"a", "b"}); err != nil {
+		t.Errorf("expected %v, got %v", nil, err)
+	}
+	if v, err := local.Get(1); err != nil || len(v) != 2 || v[1] != "b" {
+		t.Errorf("expected %v, got %v, %v", []string{"a", "b"}, v, err)
+	}
+
+	var e *Error
+	err := c.Set(2, nil)
+	if !errors.Is(err, cachego.ErrCacheFull) || !errors.As(err, &e) || e.Code != ResourceExhausted {
+		t.Errorf("expected %v, got %v", cachego.ErrCacheFull, err)
+	}
+	if _, err := c.Get(2); !errors.Is(err, cachego.ErrNotFound) {
+		t.Errorf("expected %v, got %v", cachego.ErrNotFound, err)
+	}
+	if err := c.Delete(2); !errors.Is(err, cachego.ErrNotFound) || err.Error() != "rpc error: code = NotFound desc = key 2 not found" {
+		t.Errorf("expected %v, got %v", cachego.ErrNotFound, err)
+	}
+
+	ro := newClient[int, int](t, cachego.ReadOnly(cachego.NewNopCache[int, int]()))
+	if err := ro.Set(1, 1); !errors.Is(err, cachego.ErrReadOnly) {
+		t.Errorf("expected %v, got %v", cachego.ErrReadOnly, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GetCtx(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+
+	down := NewClient[int, int]("https://127.0.0.1:1", nil, Opts[int, int]{})
+	down.hc = c.hc
+	if err := down.Set(1, 1); !errors.As(err, &e) || e.Code != Unavailable {
+		t.Errorf("expected %v, got %v", Unavailable, err)
+	}
+}
+
+// nolint:errcheck
+func TestWatch(t *testing.T) {
+	local := cachego.NewCache[string, int](cachego.Opts{Size: 10})
+	c := newClient[string, int](t, local)
+
+	values, stop := c.Watch("a")
+	local.Set("b", 1)
+	local.Set("a", 2)
+
+	select {
+	case v := <-values:
+		if v != 2 {
+			t.Errorf("expected %v, got %v", 2, v)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected a value")
+	}
+
+	c.Set("a", 3)
+	if v := <-values; v != 3 {
+		t.Errorf("expected %v, got %v", 3, v)
+	}
+
+	stop()
+	if _, ok := <-values; ok {
+		t.Errorf("expected the channel to be closed")
+	}
+	stop()
+
+	// caches that cannot be watched end the stream
+	values, stop = newClient[string, int](t, cachego.NewNopCache[string, int]()).Watch("a")
+	defer stop()
+	select {
+	case _, ok := <-values:
+		if ok {
+			t.Errorf("expected the channel to be closed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected the channel to be closed")
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	for _, d := range []time.Duration{time.Nanosecond, 1500 * time.Millisecond, time.Hour, 10000 * time.Hour} {
+		got, ok := parseTimeout(encodeTimeout(d))
+		if !ok || got < d {
+			t.Errorf("expected at least %v, got %v", d, got)
+		}
+	}
+
+	for _, s := range []string{"", "1", "1x", "-1S", "123456789S"} {
+		if _, ok := parseTimeout(s); ok {
+			t.Errorf("expected %q to be invalid", s)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	m := message{key: []byte("key"), value: []byte("value")}
+
+	// unknown fields are skipped
+	b := append(m.marshal(), 0x18, 0x01)
+	var got message
+	if err := got.unmarshal(b); err != nil || string(got.key) != "key" || string(got.value) != "value" {
+		t.Errorf("expected %+v, got %+v, %v", m, got, err)
+	}
+
+	if err := got.unmarshal([]byte{0x0a, 0x05, 'a'}); err == nil {
+		t.Errorf("expected an error for a truncated message")
+	}
+}
@@ -0,0 +1,272 @@
+package cachegrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client is a cachego.Cache calling a remote cache served by NewServer (or any server of cache.proto).
+// Its errors wrap the cachego errors matching the status of the calls (see Error). It is thread-safe.
+type Client[K comparable, V any] struct {
+	target string
+	hc     *http.Client
+	opts   Opts[K, V]
+}
+
+// NewClient creates a new client of the server at the target URL (e.g. "https://cache.internal:8443"),
+// calling it with the given HTTP client, which must speak HTTP/2.
+func NewClient[K comparable, V any](target string, hc *http.Client, opts Opts[K, V]) *Client[K, V] {
+	return &Client[K, V]{target: strings.TrimSuffix(target, "/"), hc: hc, opts: opts.withDefaults()}
+}
+
+// Set stores the value in the remote cache.
+func (c *Client[K, V]) Set(key K, value V) error {
+	return c.SetCtx(context.Background(), key, value)
+}
+
+// Get retrieves the value from the remote cache.
+func (c *Client[K, V]) Get(key K) (V, error) {
+	return c.GetCtx(context.Background(), key)
+}
+
+// Delete removes the key from the remote cache.
+func (c *Client[K, V]) Delete(key K) error {
+	return c.DeleteCtx(context.Background(), key)
+}
+
+// Clear removes every entry of the remote cache.
+func (c *Client[K, V]) Clear() error {
+	return c.ClearCtx(context.Background())
+}
+
+// SetCtx stores the value in the remote cache, unless the context is done first.
+func (c *Client[K, V]) SetCtx(ctx context.Context, key K, value V) error {
+	k, err := c.opts.EncodeKey(key)
+	if err != nil {
+		return err
+	}
+	v, err := c.opts.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.call(ctx, "Set", message{key: k, value: v})
+	return err
+}
+
+// GetCtx retrieves the value from the remote cache, unless the context is done first.
+func (c *Client[K, V]) GetCtx(ctx context.Context, key K) (V, error) {
+	var v V
+	k, err := c.opts.EncodeKey(key)
+	if err != nil {
+		return v, err
+	}
+
+	res, err := c.call(ctx, "Get", message{key: k})
+	if err != nil {
+		return v, err
+	}
+
+	err = c.opts.Unmarshal(res.value, &v)
+	return v, err
+}
+
+// DeleteCtx removes the key from the remote cache, unless the context is done first.
+func (c *Client[K, V]) DeleteCtx(ctx context.Context, key K) error {
+	k, err := c.opts.EncodeKey(key)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.call(ctx, "Delete", message{key: k})
+	return err
+}
+
+// ClearCtx removes every entry of the remote cache, unless the context is done first.
+func (c *Client[K, V]) ClearCtx(ctx context.Context) error {
+	_, err := c.call(ctx, "Clear", message{})
+	return err
+}
+
+// Watch returns a channel that receives the new value whenever the key is set in the remote cache,
+// and a function that stops the watch and closes the channel, as cachego.Watchable.
+// The channel holds a single value: if the receiver falls behind, it only gets the latest value.
+// The watch is registered by the server when Watch returns. If the call fails or the stream ends,
+// e.g. because the server doesn't support it, the channel is closed.
+func (c *Client[K, V]) Watch(key K) (<-chan V, func()) {
+	ch := make(chan V, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	res, err := c.open(ctx, key)
+	if err != nil {
+		cancel()
+		close(ch)
+		return ch, func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer close(ch)
+		defer res.Body.Close()
+
+		for {
+			m, err := readFrame(res.Body)
+			if err != nil {
+				return
+			}
+			var v V
+			if c.opts.Unmarshal(m.value, &v) != nil {
+				continue
+			}
+
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- v
+		}
+	}()
+
+	once := sync.Once{}
+	return ch, func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+}
+
+// open starts the Watch call of the key, and returns the response once the server registered the watch.
+func (c *Client[K, V]) open(ctx context.Context, key K) (*http.Response, error) {
+	k, err := c.opts.EncodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.do(ctx, "Watch", message{key: k})
+	if err != nil {
+		return nil, err
+	}
+
+	// a status in the headers ends the call right away
+	if st := res.Header.Get("Grpc-Status"); st != "" {
+		res.Body.Close()
+		return nil, fmt.Errorf("cachegrpc: watch ended with status %s", st)
+	}
+
+	return res, nil
+}
+
+// call runs a unary call, and returns its response.
+func (c *Client[K, V]) call(ctx context.Context, method string, req message) (message, error) {
+	res, err := c.do(ctx, method, req)
+	if err != nil {
+		return message{}, err
+	}
+	defer res.Body.Close()
+
+	m, merr := readFrame(res.Body)
+	if merr != nil && merr != io.EOF {
+		return m, contextErr(ctx, merr)
+	}
+	// the status is in the trailers, read along with the end of the body
+	if _, err := io.Copy(io.Discard, res.Body); err != nil {
+		return m, contextErr(ctx, err)
+	}
+
+	if err := callErr(res); err != nil {
+		return m, err
+	}
+	if merr == io.EOF {
+		return m, &Error{Code: Internal, Message: "the response holds no message"}
+	}
+
+	return m, nil
+}
+
+// do sends the request of a call, and returns the response once its headers are received.
+func (c *Client[K, V]) do(ctx context.Context, method string, req message) (*http.Response, error) {
+	body := &bytes.Buffer{}
+	writeFrame(body, req)
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, c.target+service+method, body)
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/grpc")
+	r.Header.Set("Te", "trailers")
+	if deadline, ok := ctx.Deadline(); ok {
+		r.Header.Set("Grpc-Timeout", encodeTimeout(time.Until(deadline)))
+	}
+
+	res, err := c.hc.Do(r)
+	if err != nil {
+		return nil, contextErr(ctx, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, &Error{Code: httpCode(res.StatusCode), Message: "unexpected HTTP status " + res.Status}
+	}
+
+	return res, nil
+}
+
+// callErr returns the error of the status of a call, from its trailers or, for errors only, its headers.
+func callErr(res *http.Response) error {
+	st, msg := res.Trailer.Get("Grpc-Status"), res.Trailer.Get("Grpc-Message")
+	if st == "" {
+		st, msg = res.Header.Get("Grpc-Status"), res.Header.Get("Grpc-Message")
+	}
+	if st == "" {
+		return &Error{Code: Internal, Message: "the response holds no status"}
+	}
+
+	code, err := strconv.Atoi(st)
+	if err != nil {
+		return &Error{Code: Unknown, Message: "invalid status " + st}
+	}
+	if Code(code) == OK {
+		return nil
+	}
+
+	return &Error{Code: Code(code), Message: decodeMessage(msg)}
+}
+
+// httpCode maps the HTTP status of a failed call to a gRPC code, as gRPC does.
+func httpCode(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return Internal
+	case http.StatusUnauthorized:
+		return Unauthenticated
+	case http.StatusForbidden:
+		return PermissionDenied
+	case http.StatusNotFound:
+		return Unimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return Unavailable
+	default:
+		return Unknown
+	}
+}
+
+// contextErr returns the error of the context if it interrupted the call.
+func contextErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	return &Error{Code: Unavailable, Message: err.Error()}
+}
@@ -0,0 +1,134 @@
+package cachegrpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/noam-g4/cachego"
+)
+
+// CachedReply is a reply of an RPC stored in the cache by an Interceptor.
+type CachedReply struct {
+	// Reply is the reply, marshaled with protobuf.
+	Reply []byte
+	// Expires is the time the reply expires at, or zero if it doesn't expire before the cache evicts it.
+	Expires time.Time
+}
+
+// Invoker sends an RPC, as grpc.UnaryInvoker without the connection and call options.
+type Invoker func(ctx context.Context, method string, req, reply any) error
+
+// InterceptorOpts configures the RPCs cached by an Interceptor.
+type InterceptorOpts struct {
+	// Methods are the full names of the methods cached (e.g. "/users.Users/GetUser"), with the time to live
+	// of their replies, or 0 for the replies to not expire before the cache evicts them.
+	// The methods must be idempotent, since their replies are reused for the same requests.
+	Methods map[string]time.Duration
+	// Clock tells the time the replies are stored and expire at. Defaults to the system clock.
+	Clock cachego.Clock
+	// Logger receives the errors of the cache, which are otherwise served as misses. Defaults to discarding them.
+	Logger cachego.Logger
+}
+
+// Interceptor caches the replies of unary RPCs, under their method and the hash of their request.
+// It works with the clients of google.golang.org/grpc through a unary client interceptor:
+//
+//	grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+//		return i.Invoke(ctx, method, req, reply, func(ctx context.Context, method string, req, reply any) error {
+//			return invoker(ctx, method, req, reply, cc, opts...)
+//		})
+//	})
+//
+// Only the requests and replies that are protobuf messages are cached, and failed RPCs never are.
+type Interceptor struct {
+	c    cachego.Cache[string, CachedReply]
+	opts InterceptorOpts
+}
+
+// NewInterceptor creates an interceptor caching the replies in the cache.
+func NewInterceptor(c cachego.Cache[string, CachedReply], opts InterceptorOpts) *Interceptor {
+	if opts.Logger == nil {
+		opts.Logger = nopLogger{}
+	}
+
+	return &Interceptor{c: c, opts: opts}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
+
+// Invoke sets the reply to the one cached for the request if there is a fresh one,
+// and invokes the RPC otherwise, caching its reply.
+func (i *Interceptor) Invoke(ctx context.Context, method string, req, reply any, invoke Invoker) error {
+	ttl, ok := i.opts.Methods[method]
+	if !ok {
+		return invoke(ctx, method, req, reply)
+	}
+	in, ok := req.(proto.Message)
+	out, ok2 := reply.(proto.Message)
+	if !ok || !ok2 {
+		return invoke(ctx, method, req, reply)
+	}
+
+	key, err := Key(method, in)
+	if err != nil {
+		return invoke(ctx, method, req, reply)
+	}
+
+	if cached, err := cachego.GetCtx(ctx, i.c, key); err == nil {
+		if cached.Expires.IsZero() || i.now().Before(cached.Expires) {
+			err := proto.Unmarshal(cached.Reply, out)
+			if err == nil {
+				return nil
+			}
+			i.opts.Logger.Printf("cachegrpc: unmarshaling the reply of %q failed: %v", method, err)
+			proto.Reset(out)
+		}
+	} else if !errors.Is(err, cachego.ErrNotFound) {
+		i.opts.Logger.Printf("cachegrpc: getting the reply of %q failed: %v", method, err)
+	}
+
+	if err := invoke(ctx, method, req, reply); err != nil {
+		return err
+	}
+
+	b, err := proto.Marshal(out)
+	if err != nil {
+		return nil
+	}
+	cached := CachedReply{Reply: b}
+	if ttl > 0 {
+		cached.Expires = i.now().Add(ttl)
+	}
+	if err := cachego.SetCtx(ctx, i.c, key, cached); err != nil {
+		i.opts.Logger.Printf("cachegrpc: storing the reply of %q failed: %v", method, err)
+	}
+
+	return nil
+}
+
+// Key returns the key the reply to the request is cached under: the method and the hash of the request,
+// marshaled deterministically.
+func Key(method string, req proto.Message) (string, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return method + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+func (i *Interceptor) now() time.Time {
+	if i.opts.Clock == nil {
+		return time.Now()
+	}
+
+	return i.opts.Clock.Now()
+}
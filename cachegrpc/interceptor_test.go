@@ -0,0 +1,74 @@
+package cachegrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/noam-g4/cachego"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) NewTimer(time.Duration) cachego.Timer { panic("not used") }
+
+func TestInterceptor(t *testing.T) {
+	calls := 0
+	invoke := func(ctx context.Context, method string, req, reply any) error {
+		calls++
+		if req.(*wrapperspb.StringValue).Value == "fail" {
+			return errors.New("unavailable")
+		}
+		reply.(*wrapperspb.StringValue).Value = req.(*wrapperspb.StringValue).Value + "!"
+		return nil
+	}
+	clock := &fakeClock{now: time.Now()}
+	i := NewInterceptor(cachego.NewCache[string, CachedReply](cachego.Opts{Size: 10}), InterceptorOpts{
+		Methods: map[string]time.Duration{"/test.Echo/Short": time.Minute, "/test.Echo/Long": 0},
+		Clock:   clock,
+	})
+
+	call := func(method, value string) (string, error) {
+		reply := &wrapperspb.StringValue{}
+		err := i.Invoke(context.Background(), method, wrapperspb.String(value), reply, invoke)
+		return reply.Value, err
+	}
+
+	for _, tc := range []struct {
+		method, value string
+		calls         int
+	}{
+		{"/test.Echo/Short", "a", 1},
+		{"/test.Echo/Short", "a", 1},
+		{"/test.Echo/Short", "b", 2},
+		{"/test.Echo/Long", "a", 3},
+		{"/test.Echo/Long", "a", 3},
+		// not cached
+		{"/test.Echo/Other", "a", 4},
+		{"/test.Echo/Other", "a", 5},
+	} {
+		if got, err := call(tc.method, tc.value); err != nil || got != tc.value+"!" || calls != tc.calls {
+			t.Errorf("%v %v: expected %v after %v calls, got %v after %v calls, %v", tc.method, tc.value, tc.value+"!", tc.calls, got, calls, err)
+		}
+	}
+
+	// the errors aren't cached
+	call("/test.Echo/Short", "fail") // nolint:errcheck
+	if _, err := call("/test.Echo/Short", "fail"); err == nil || calls != 7 {
+		t.Errorf("expected an error after %v calls, got %v after %v calls", 7, err, calls)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	call("/test.Echo/Short", "a") // nolint:errcheck
+	call("/test.Echo/Long", "a")  // nolint:errcheck
+	if calls != 8 {
+		t.Errorf("expected %v calls, got %v", 8, calls)
+	}
+}
@@ -0,0 +1,177 @@
+package cachegrpc
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/noam-g4/cachego"
+)
+
+type server[K comparable, V any] struct {
+	c    cachego.Cache[K, V]
+	opts Opts[K, V]
+}
+
+// NewServer returns a handler serving the cache as the gRPC service of cache.proto.
+// Watch is served if the cache implements cachego.Watchable, and reports Unimplemented otherwise.
+func NewServer[K comparable, V any](c cachego.Cache[K, V], opts Opts[K, V]) http.Handler {
+	return &server[K, V]{c: c, opts: opts.withDefaults()}
+}
+
+func (s *server[K, V]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "gRPC requests only", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	ctx := r.Context()
+	if d, ok := parseTimeout(r.Header.Get("Grpc-Timeout")); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	w.Header().Set("Content-Type", "application/grpc")
+
+	req, err := readFrame(r.Body)
+	if err != nil {
+		finish(w, &Error{Code: InvalidArgument, Message: "reading the request failed: " + err.Error()})
+		return
+	}
+
+	switch strings.TrimPrefix(r.URL.Path, service) {
+	case "Get":
+		finish(w, s.get(ctx, w, req))
+	case "Set":
+		finish(w, s.set(ctx, w, req))
+	case "Delete":
+		finish(w, s.delete(ctx, w, req))
+	case "Clear":
+		finish(w, s.clear(ctx, w))
+	case "Watch":
+		finish(w, s.watch(ctx, w, req))
+	default:
+		finish(w, &Error{Code: Unimplemented, Message: "unknown method " + r.URL.Path})
+	}
+}
+
+// finish writes the status of the call in the trailers.
+func finish(w http.ResponseWriter, err error) {
+	code, msg := OK, ""
+	if err != nil {
+		st := status(err)
+		code, msg = st.Code, st.Message
+	}
+
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(int(code)))
+	if msg != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", encodeMessage(msg))
+	}
+}
+
+func (s *server[K, V]) key(m message) (K, error) {
+	key, err := s.opts.DecodeKey(m.key)
+	if err != nil {
+		return key, &Error{Code: InvalidArgument, Message: "decoding the key failed: " + err.Error()}
+	}
+
+	return key, nil
+}
+
+func (s *server[K, V]) get(ctx context.Context, w http.ResponseWriter, req message) error {
+	key, err := s.key(req)
+	if err != nil {
+		return err
+	}
+
+	v, err := cachego.GetCtx(ctx, s.c, key)
+	if err != nil {
+		return err
+	}
+	data, err := s.opts.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return writeFrame(w, message{value: data})
+}
+
+func (s *server[K, V]) set(ctx context.Context, w http.ResponseWriter, req message) error {
+	key, err := s.key(req)
+	if err != nil {
+		return err
+	}
+
+	var v V
+	if err := s.opts.Unmarshal(req.value, &v); err != nil {
+		return &Error{Code: InvalidArgument, Message: "decoding the value failed: " + err.Error()}
+	}
+	if err := cachego.SetCtx(ctx, s.c, key, v); err != nil {
+		return err
+	}
+
+	return writeFrame(w, message{})
+}
+
+func (s *server[K, V]) delete(ctx context.Context, w http.ResponseWriter, req message) error {
+	key, err := s.key(req)
+	if err != nil {
+		return err
+	}
+
+	if err := cachego.DeleteCtx(ctx, s.c, key); err != nil {
+		return err
+	}
+
+	return writeFrame(w, message{})
+}
+
+func (s *server[K, V]) clear(ctx context.Context, w http.ResponseWriter) error {
+	if err := cachego.ClearCtx(ctx, s.c); err != nil {
+		return err
+	}
+
+	return writeFrame(w, message{})
+}
+
+// watch streams the new values of the key until the call is canceled.
+func (s *server[K, V]) watch(ctx context.Context, w http.ResponseWriter, req message) error {
+	c, ok := s.c.(cachego.Watchable[K, V])
+	if !ok {
+		return &Error{Code: Unimplemented, Message: "the cache cannot be watched"}
+	}
+	key, err := s.key(req)
+	if err != nil {
+		return err
+	}
+
+	values, stop := c.Watch(key)
+	defer stop()
+
+	// the headers tell the client the watch is registered
+	flusher, _ := w.(http.Flusher)
+	w.WriteHeader(http.StatusOK)
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case v := <-values:
+			data, err := s.opts.Marshal(v)
+			if err != nil {
+				return err
+			}
+			if err := writeFrame(w, message{value: data}); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package cachegrpc
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/noam-g4/cachego"
+)
+
+// service is the full name of the service in cache.proto, prefixing the paths of its methods.
+const service = "/cachego.v1.Cache/"
+
+// maxMessage bounds the size of the messages read, as gRPC does by default.
+const maxMessage = 4 << 20
+
+// message is any of the messages of cache.proto, which all hold a key (1) and/or a value (2).
+type message struct {
+	key   []byte
+	value []byte
+}
+
+func (m message) marshal() []byte {
+	var b []byte
+	if len(m.key) > 0 {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, m.key)
+	}
+	if len(m.value) > 0 {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, m.value)
+	}
+
+	return b
+}
+
+func (m *message) unmarshal(b []byte) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if (num == 1 || num == 2) && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			if num == 1 {
+				m.key = append([]byte(nil), v...)
+			} else {
+				m.value = append([]byte(nil), v...)
+			}
+			b = b[n:]
+			continue
+		}
+
+		// unknown fields are skipped, for compatibility
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+
+	return nil
+}
+
+// writeFrame writes the message with its gRPC prefix: an uncompressed flag and its length.
+func writeFrame(w io.Writer, m message) error {
+	data := m.marshal()
+	frame := make([]byte, 5, 5+len(data))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+
+	_, err := w.Write(append(frame, data...))
+	return err
+}
+
+// readFrame reads a message with its gRPC prefix. It returns io.EOF if the stream ended before it.
+func readFrame(r io.Reader) (message, error) {
+	var m message
+	prefix := make([]byte, 5)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return m, errors.New("cachegrpc: truncated message")
+		}
+		return m, err
+	}
+
+	if prefix[0] != 0 {
+		return m, &Error{Code: Unimplemented, Message: "compressed messages are not supported"}
+	}
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if size > maxMessage {
+		return m, &Error{Code: ResourceExhausted, Message: fmt.Sprintf("message of %d bytes is larger than %d", size, maxMessage)}
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return m, errors.New("cachegrpc: truncated message")
+	}
+	if err := m.unmarshal(data); err != nil {
+		return m, &Error{Code: InvalidArgument, Message: err.Error()}
+	}
+
+	return m, nil
+}
+
+// Code is a gRPC status code.
+type Code int
+
+// The gRPC status codes used by the service.
+const (
+	OK                Code = 0
+	Canceled          Code = 1
+	Unknown           Code = 2
+	InvalidArgument   Code = 3
+	DeadlineExceeded  Code = 4
+	NotFound          Code = 5
+	PermissionDenied  Code = 7
+	ResourceExhausted Code = 8
+	OutOfRange        Code = 11
+	Unimplemented     Code = 12
+	Internal          Code = 13
+	Unavailable       Code = 14
+	Unauthenticated   Code = 16
+)
+
+func (c Code) String() string {
+	switch c {
+	case OK:
+		return "OK"
+	case Canceled:
+		return "Canceled"
+	case Unknown:
+		return "Unknown"
+	case InvalidArgument:
+		return "InvalidArgument"
+	case DeadlineExceeded:
+		return "DeadlineExceeded"
+	case NotFound:
+		return "NotFound"
+	case PermissionDenied:
+		return "PermissionDenied"
+	case ResourceExhausted:
+		return "ResourceExhausted"
+	case OutOfRange:
+		return "OutOfRange"
+	case Unimplemented:
+		return "Unimplemented"
+	case Internal:
+		return "Internal"
+	case Unavailable:
+		return "Unavailable"
+	case Unauthenticated:
+		return "Unauthenticated"
+	default:
+		return "Code(" + strconv.Itoa(int(c)) + ")"
+	}
+}
+
+// Error is a gRPC status other than OK. It wraps the cachego error matching its code,
+// e.g. cachego.ErrNotFound for NotFound, so the errors of the client can be checked as the local ones.
+type Error struct {
+	Code    Code
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc error: code = %v desc = %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	switch e.Code {
+	case NotFound:
+		return cachego.ErrNotFound
+	case ResourceExhausted:
+		return cachego.ErrCacheFull
+	case OutOfRange:
+		return cachego.ErrEntryTooLarge
+	case PermissionDenied:
+		return cachego.ErrReadOnly
+	case Canceled:
+		return context.Canceled
+	case DeadlineExceeded:
+		return context.DeadlineExceeded
+	default:
+		return nil
+	}
+}
+
+// status returns the gRPC status matching the error of a cache.
+func status(err error) *Error {
+	var e *Error
+	switch {
+	case errors.As(err, &e):
+		return e
+	case errors.Is(err, cachego.ErrNotFound):
+		return &Error{Code: NotFound, Message: err.Error()}
+	case errors.Is(err, cachego.ErrCacheFull):
+		return &Error{Code: ResourceExhausted, Message: err.Error()}
+	case errors.Is(err, cachego.ErrEntryTooLarge):
+		return &Error{Code: OutOfRange, Message: err.Error()}
+	case errors.Is(err, cachego.ErrReadOnly):
+		return &Error{Code: PermissionDenied, Message: err.Error()}
+	case errors.Is(err, context.Canceled):
+		return &Error{Code: Canceled, Message: err.Error()}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Code: DeadlineExceeded, Message: err.Error()}
+	default:
+		return &Error{Code: Internal, Message: err.Error()}
+	}
+}
+
+// encodeMessage percent-encodes the grpc-message, as the specification requires.
+func encodeMessage(msg string) string {
+	b := strings.Builder{}
+	for i := 0; i < len(msg); i++ {
+		if c := msg[i]; c >= 0x20 && c <= 0x7e && c != '%' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+func decodeMessage(msg string) string {
+	if s, err := url.PathUnescape(msg); err == nil {
+		return s
+	}
+
+	return msg
+}
+
+// encodeTimeout formats the grpc-timeout of the remaining time, in the finest unit fitting the 8 digits allowed.
+func encodeTimeout(d time.Duration) string {
+	if d <= 0 {
+		return "1n"
+	}
+
+	for _, u := range []struct {
+		unit string
+		d    time.Duration
+	}{{"n", time.Nanosecond}, {"u", time.Microsecond}, {"m", time.Millisecond}, {"S", time.Second}, {"M", time.Minute}} {
+		if n := (d + u.d - 1) / u.d; n < 1e8 {
+			return strconv.FormatInt(int64(n), 10) + u.unit
+		}
+	}
+
+	return strconv.FormatInt(int64((d+time.Hour-1)/time.Hour), 10) + "H"
+}
+
+// parseTimeout parses a grpc-timeout header.
+func parseTimeout(s string) (time.Duration, bool) {
+	if len(s) < 2 || len(s) > 9 {
+		return 0, false
+	}
+
+	n, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+
+	units := map[byte]time.Duration{
+		'H': time.Hour, 'M': time.Minute, 'S': time.Second, 'm': time.Millisecond, 'u': time.Microsecond, 'n': time.Nanosecond,
+	}
+	unit, ok := units[s[len(s)-1]]
+	if !ok {
+		return 0, false
+	}
+
+	return time.Duration(n) * unit, true
+}
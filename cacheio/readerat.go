@@ -0,0 +1,135 @@
+// Package cacheio caches the data read from slow readers in a cachego.Cache, e.g. objects read by range
+// from S3 or over HTTP.
+package cacheio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/noam-g4/cachego"
+)
+
+// ReaderAtOpts configures a ReaderAt.
+type ReaderAtOpts struct {
+	// BlockSize is the size of the blocks the data is read and cached by. Defaults to 64 KiB.
+	BlockSize int
+	// Logger receives the errors of the cache, which are otherwise served as misses. Defaults to discarding them.
+	Logger cachego.Logger
+}
+
+// ReaderAt is an io.ReaderAt reading the data of another one by blocks, cached under their index,
+// so the repeated reads of the same ranges are served from the cache:
+//
+//	r := cacheio.NewReaderAt(object, cachego.NewLRUCache[int64, []byte](256), cacheio.ReaderAtOpts{})
+//
+// The blocks missing for a read are fetched with a single read of the underlying reader when they are contiguous.
+// The data is assumed not to change. It is thread-safe if the underlying reader is, as io.ReaderAt requires.
+type ReaderAt struct {
+	r    io.ReaderAt
+	c    cachego.Cache[int64, []byte]
+	opts ReaderAtOpts
+}
+
+// NewReaderAt creates a reader caching the blocks of the reader in the cache.
+// The cache must not be shared with other readers.
+func NewReaderAt(r io.ReaderAt, c cachego.Cache[int64, []byte], opts ReaderAtOpts) *ReaderAt {
+	if opts.BlockSize <= 0 {
+		opts.BlockSize = 64 << 10
+	}
+	if opts.Logger == nil {
+		opts.Logger = nopLogger{}
+	}
+
+	return &ReaderAt{r: r, c: c, opts: opts}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
+
+// ReadAt reads len(p) bytes at the offset, from the cached blocks and the underlying reader.
+// It returns io.EOF if the data ends before len(p) bytes are read.
+func (ra *ReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("cacheio: negative offset")
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	size := int64(ra.opts.BlockSize)
+	first, last := off/size, (off+int64(len(p))-1)/size
+	blocks := make([][]byte, last-first+1)
+	for i := range blocks {
+		if b, ok := cachego.LookupValue(ra.c, first+int64(i)); ok {
+			blocks[i] = b
+		}
+	}
+
+	for i := 0; i < len(blocks); {
+		if blocks[i] != nil {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(blocks) && blocks[j] == nil {
+			j++
+		}
+		if err := ra.fetch(first+int64(i), blocks[i:j]); err != nil {
+			return 0, err
+		}
+		i = j
+	}
+
+	n := 0
+	for i, b := range blocks {
+		start := off + int64(n) - (first+int64(i))*size
+		if start < int64(len(b)) {
+			n += copy(p[n:], b[start:])
+		}
+		if len(b) < ra.opts.BlockSize {
+			// the data ends in this block
+			break
+		}
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// fetch reads the contiguous blocks starting at the index, and caches them. The blocks past the end
+// of the data are set empty.
+func (ra *ReaderAt) fetch(index int64, blocks [][]byte) error {
+	size := ra.opts.BlockSize
+	buf := make([]byte, len(blocks)*size)
+	n, err := ra.r.ReadAt(buf, index*int64(size))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if err == nil && n < len(buf) {
+		return fmt.Errorf("cacheio: short read of %v bytes at %v without an error", n, index*int64(size))
+	}
+
+	for i := range blocks {
+		start, end := i*size, (i+1)*size
+		if end > n {
+			end = n
+		}
+		if start >= end {
+			blocks[i] = []byte{}
+			continue
+		}
+
+		// copied, so evicting some of the blocks frees their memory
+		blocks[i] = bytes.Clone(buf[start:end])
+		if err := ra.c.Set(index+int64(i), blocks[i]); err != nil {
+			ra.opts.Logger.Printf("cacheio: storing block %v failed: %v", index+int64(i), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package cacheio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/noam-g4/cachego"
+)
+
+// recording records the ranges read from the reader.
+type recording struct {
+	r     io.ReaderAt
+	mx    sync.Mutex
+	reads [][2]int64
+}
+
+func (r *recording) ReadAt(p []byte, off int64) (int, error) {
+	r.mx.Lock()
+	r.reads = append(r.reads, [2]int64{off, int64(len(p))})
+	r.mx.Unlock()
+	return r.r.ReadAt(p, off)
+}
+
+func TestReaderAt(t *testing.T) {
+	data := make([]byte, 100)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	under := &recording{r: bytes.NewReader(data)}
+	ra := NewReaderAt(under, cachego.NewLRUCache[int64, []byte](100), ReaderAtOpts{BlockSize: 16})
+
+	for _, tc := range []struct {
+		off, n int
+		reads  [][2]int64 // the new reads of the underlying reader
+		err    error
+	}{
+		{20, 10, [][2]int64{{16, 16}}, nil},
+		{18, 12, nil, nil},
+		// the missing blocks around a cached one are fetched separately
+		{0, 60, [][2]int64{{0, 16}, {32, 32}}, nil},
+		{90, 20, [][2]int64{{80, 32}}, io.EOF},
+		{95, 5, nil, nil},
+		{100, 1, nil, io.EOF},
+		{200, 1, [][2]int64{{192, 16}}, io.EOF},
+	} {
+		before := len(under.reads)
+		p := make([]byte, tc.n)
+		n, err := ra.ReadAt(p, int64(tc.off))
+
+		end := tc.off + tc.n
+		if end > len(data) {
+			end = len(data)
+		}
+		var expected []byte
+		if tc.off < len(data) {
+			expected = data[tc.off:end]
+		}
+		if !errors.Is(err, tc.err) || !bytes.Equal(p[:n], expected) {
+			t.Errorf("%v+%v: expected %v (%v), got %v (%v)", tc.off, tc.n, expected, tc.err, p[:n], err)
+		}
+		if reads := under.reads[before:]; fmt.Sprint(reads) != fmt.Sprint(tc.reads) {
+			t.Errorf("%v+%v: expected the reads %v, got %v", tc.off, tc.n, tc.reads, reads)
+		}
+	}
+}
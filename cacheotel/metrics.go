@@ -0,0 +1,93 @@
+// Package cacheotel instruments cachego caches with OpenTelemetry.
+// It lives in its own package so the core cachego package stays free of the OpenTelemetry dependency.
+package cacheotel
+
+import (
+	"context"
+	"time"
+
+	"github.com/noam-g4/cachego"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/noam-g4/cachego/cacheotel"
+
+// Opts configures the instrumentation of a cache.
+type Opts struct {
+	// Name is recorded as the "cache.name" attribute of every measurement.
+	Name string
+	// MeterProvider creates the meter recording the metrics. If nil, the global MeterProvider is used.
+	MeterProvider metric.MeterProvider
+	// TracerProvider creates the tracer starting the spans of TraceFile. If nil, the global TracerProvider is used.
+	TracerProvider trace.TracerProvider
+}
+
+// Instrument wraps the cache to record OpenTelemetry metrics:
+// the cachego.hits and cachego.misses counters, the cachego.operation.duration histogram of every operation,
+// and, if the cache implements cachego.StatsProvider, the cachego.entries gauge.
+// It returns an error if the instruments cannot be created.
+func Instrument[K comparable, V any](cache cachego.Cache[K, V], opts Opts) (cachego.Cache[K, V], error) {
+	provider := opts.MeterProvider
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+
+	meter := provider.Meter(instrumentationName)
+	name := attribute.String("cache.name", opts.Name)
+
+	hits, err := meter.Int64Counter("cachego.hits", metric.WithDescription("Number of lookups that found the key."))
+	if err != nil {
+		return nil, err
+	}
+
+	misses, err := meter.Int64Counter("cachego.misses", metric.WithDescription("Number of lookups that didn't find the key."))
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := meter.Float64Histogram("cachego.operation.duration",
+		metric.WithDescription("Latency of cache operations."), metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	if stats, ok := cache.(cachego.StatsProvider); ok {
+		_, err := meter.Int64ObservableGauge("cachego.entries",
+			metric.WithDescription("Current number of entries."),
+			metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+				o.Observe(int64(stats.Stats().Size), metric.WithAttributes(name))
+				return nil
+			}),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r := &recorder{hits: hits, misses: misses, duration: duration, name: name}
+	return cachego.Wrap(cache, cachego.MetricsMiddleware[K, V](r)), nil
+}
+
+type recorder struct {
+	hits     metric.Int64Counter
+	misses   metric.Int64Counter
+	duration metric.Float64Histogram
+	name     attribute.KeyValue
+}
+
+func (r *recorder) ObserveOp(op cachego.Op, d time.Duration, err error) {
+	ctx := context.Background()
+
+	r.duration.Record(ctx, d.Seconds(), metric.WithAttributes(r.name, attribute.String("cache.op", string(op))))
+
+	if op == cachego.OpGet {
+		if err == nil {
+			r.hits.Add(ctx, 1, metric.WithAttributes(r.name))
+		} else {
+			r.misses.Add(ctx, 1, metric.WithAttributes(r.name))
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package cacheotel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/noam-g4/cachego"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// nolint:errcheck
+func TestInstrument(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	cache, err := Instrument(cachego.NewCache[int, string](cachego.Opts{Size: 2}), Opts{Name: "users", MeterProvider: provider})
+	if err != nil {
+		t.Fatalf("Instrument returned error: %s", err)
+	}
+
+	cache.Set(1, "one")
+	cache.Get(1)
+	cache.Get(1)
+	cache.Get(2)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect returned error: %s", err)
+	}
+
+	metrics := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+
+	for name, expected := range map[string]int64{"cachego.hits": 2, "cachego.misses": 1} {
+		sum, ok := metrics[name].(metricdata.Sum[int64])
+		if !ok || len(sum.DataPoints) != 1 {
+			t.Errorf("expected one %s data point, got %v", name, metrics[name])
+			continue
+		}
+		if got := sum.DataPoints[0].Value; got != expected {
+			t.Errorf("expected %v %s, got %v", expected, name, got)
+		}
+		if v, _ := sum.DataPoints[0].Attributes.Value("cache.name"); v.AsString() != "users" {
+			t.Errorf("expected cache.name users, got %v", v.AsString())
+		}
+	}
+
+	gauge, ok := metrics["cachego.entries"].(metricdata.Gauge[int64])
+	if !ok || len(gauge.DataPoints) != 1 || gauge.DataPoints[0].Value != 1 {
+		t.Errorf("expected 1 entry, got %v", metrics["cachego.entries"])
+	}
+
+	// one histogram per operation
+	histogram, ok := metrics["cachego.operation.duration"].(metricdata.Histogram[float64])
+	if !ok || len(histogram.DataPoints) != 2 {
+		t.Errorf("expected 2 duration histograms, got %v", metrics["cachego.operation.duration"])
+	}
+}
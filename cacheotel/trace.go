@@ -0,0 +1,103 @@
+package cacheotel
+
+import (
+	"context"
+
+	"github.com/noam-g4/cachego"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceFile wraps the file a cache is persisted to so every load and dump runs in a
+// "cachego.file.load" or "cachego.file.dump" span.
+// The returned file implements cachego.ContextFile, so the spans are children of the span in the context
+// of the cache operation (e.g. ClearCtx), and the context is passed on to the file if it implements
+// cachego.ContextFile too.
+func TraceFile(file cachego.File, opts Opts) cachego.ContextFile {
+	provider := opts.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return &tracedFile{
+		file:   file,
+		tracer: provider.Tracer(instrumentationName),
+		name:   attribute.String("cache.name", opts.Name),
+	}
+}
+
+type tracedFile struct {
+	file   cachego.File
+	tracer trace.Tracer
+	name   attribute.KeyValue
+}
+
+func (f *tracedFile) Load() ([]byte, error) {
+	return f.LoadContext(context.Background())
+}
+
+func (f *tracedFile) Dump(data []byte) error {
+	return f.DumpContext(context.Background(), data)
+}
+
+func (f *tracedFile) LoadContext(ctx context.Context) ([]byte, error) {
+	ctx, span := f.tracer.Start(ctx, "cachego.file.load", trace.WithAttributes(f.name))
+	defer span.End()
+
+	var data []byte
+	var err error
+	if cf, ok := f.file.(cachego.ContextFile); ok {
+		data, err = cf.LoadContext(ctx)
+	} else {
+		data, err = f.file.Load()
+	}
+
+	span.SetAttributes(attribute.Int("cache.file.bytes", len(data)))
+	end(span, err)
+	return data, err
+}
+
+func (f *tracedFile) DumpContext(ctx context.Context, data []byte) error {
+	ctx, span := f.tracer.Start(ctx, "cachego.file.dump",
+		trace.WithAttributes(f.name, attribute.Int("cache.file.bytes", len(data))))
+	defer span.End()
+
+	var err error
+	if cf, ok := f.file.(cachego.ContextFile); ok {
+		err = cf.DumpContext(ctx, data)
+	} else {
+		err = f.file.Dump(data)
+	}
+
+	end(span, err)
+	return err
+}
+
+// TraceLoader wraps the loader of a read-through cache (see cachego.TypedOpts.Loader) so every load
+// runs in a "cachego.loader.load" span, a child of the span in the context of the cache operation (e.g. GetCtx).
+func TraceLoader[K comparable, V any](loader cachego.Loader[K, V], opts Opts) cachego.Loader[K, V] {
+	provider := opts.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	tracer := provider.Tracer(instrumentationName)
+	name := attribute.String("cache.name", opts.Name)
+	return func(ctx context.Context, key K) (V, error) {
+		ctx, span := tracer.Start(ctx, "cachego.loader.load", trace.WithAttributes(name))
+		defer span.End()
+
+		v, err := loader(ctx, key)
+		end(span, err)
+		return v, err
+	}
+}
+
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,80 @@
+package cacheotel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/noam-g4/cachego"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// nolint:errcheck
+func TestTraceFile(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+
+	file := TraceFile(cachego.NewMemoryCacheFile(), Opts{Name: "users", TracerProvider: provider})
+	cache := cachego.NewCache[int, string](cachego.Opts{Size: 2, File: file})
+	cache.Set(1, "one")
+	cache.(cachego.ContextCache[int, string]).ClearCtx(ctx)
+	parent.End()
+
+	spans := recorder.Ended()
+	names := make([]string, len(spans))
+	for i, s := range spans {
+		names[i] = s.Name()
+	}
+
+	// the load on creation fails since the file is empty
+	if len(spans) != 3 || names[0] != "cachego.file.load" || names[1] != "cachego.file.dump" {
+		t.Fatalf("expected load, dump and request spans, got %v", names)
+	}
+
+	if spans[0].Status().Code.String() != "Error" {
+		t.Errorf("expected the failed load to be recorded, got %v", spans[0].Status())
+	}
+
+	if spans[1].Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("expected the dump span to be a child of the request span")
+	}
+}
+
+// nolint:errcheck
+func TestTraceLoad(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+
+	file := cachego.NewMemoryCacheFile()
+	file.Dump([]byte(`{"1":"one"}`))
+	cache := cachego.NewCache(cachego.Opts{
+		Size:    2,
+		File:    TraceFile(file, Opts{TracerProvider: provider}),
+		Context: ctx,
+	}, cachego.TypedOpts[int, string]{
+		Loader: TraceLoader(func(ctx context.Context, key int) (string, error) { return "two", nil }, Opts{TracerProvider: provider}),
+	})
+	cachego.GetCtx(ctx, cache, 2)
+	parent.End()
+
+	spans := recorder.Ended()
+	names := make([]string, len(spans))
+	for i, s := range spans {
+		names[i] = s.Name()
+	}
+
+	// the load on creation and the load of the missing key are both children of the request
+	if len(spans) != 3 || names[0] != "cachego.file.load" || names[1] != "cachego.loader.load" {
+		t.Fatalf("expected file load, loader and request spans, got %v", names)
+	}
+
+	for _, s := range spans[:2] {
+		if s.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("expected the %v span to be a child of the request span", s.Name())
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Package cacheprom exports cachego metrics to Prometheus.
+// It lives in its own package so the core cachego package stays free of the Prometheus dependency.
+package cacheprom
+
+import (
+	"sync"
+	"time"
+
+	"github.com/noam-g4/cachego"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector is a prometheus.Collector exporting the stats of named caches,
+// plus the latency of the operations of the caches instrumented with Instrument.
+type Collector struct {
+	caches map[string]cachego.StatsProvider
+	mx     *sync.Mutex
+
+	hits        *prometheus.Desc
+	misses      *prometheus.Desc
+	sets        *prometheus.Desc
+	deletes     *prometheus.Desc
+	evictions   *prometheus.Desc
+	expirations *prometheus.Desc
+	entries     *prometheus.Desc
+	hitRatio    *prometheus.Desc
+	latency     *prometheus.HistogramVec
+}
+
+// NewCollector creates a new Collector whose metrics are prefixed with the namespace (e.g. "myapp_cachego_hits_total").
+// If the namespace is empty, the metrics are only prefixed with "cachego".
+func NewCollector(namespace string) *Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "cachego", name), help, []string{"cache"}, nil)
+	}
+
+	return &Collector{
+		caches: make(map[string]cachego.StatsProvider),
+		mx:     &sync.Mutex{},
+
+		hits:        desc("hits_total", "Number of lookups that found the key."),
+		misses:      desc("misses_total", "Number of lookups that didn't find the key."),
+		sets:        desc("sets_total", "Number of values stored."),
+		deletes:     desc("deletes_total", "Number of keys deleted."),
+		evictions:   desc("evictions_total", "Number of entries evicted for capacity."),
+		expirations: desc("expirations_total", "Number of entries whose ttl lapsed."),
+		entries:     desc("entries", "Current number of entries."),
+		hitRatio:    desc("hit_ratio", "Ratio of hits out of all lookups."),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "cachego",
+			Name:      "operation_duration_seconds",
+			Help:      "Latency of cache operations.",
+			Buckets:   []float64{.000001, .00001, .0001, .001, .01, .1, 1},
+		}, []string{"cache", "op"}),
+	}
+}
+
+// Add exports the stats of the cache under the given name, replacing any cache previously added with that name.
+// This method is thread-safe.
+func (c *Collector) Add(name string, cache cachego.StatsProvider) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.caches[name] = cache
+}
+
+// Remove stops exporting the stats of the cache with the given name.
+// This method is thread-safe.
+func (c *Collector) Remove(name string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	delete(c.caches, name)
+}
+
+// Recorder returns a cachego.MetricsRecorder observing operation latencies under the given cache name,
+// to be used with cachego.MetricsMiddleware.
+func (c *Collector) Recorder(name string) cachego.MetricsRecorder {
+	return recorder{latency: c.latency, name: name}
+}
+
+// Instrument exports the stats of the cache (if it keeps any) under the given name,
+// and wraps it to observe the latency of its operations.
+func Instrument[K comparable, V any](c *Collector, name string, cache cachego.Cache[K, V]) cachego.Cache[K, V] {
+	if stats, ok := cache.(cachego.StatsProvider); ok {
+		c.Add(name, stats)
+	}
+
+	return cachego.Wrap(cache, cachego.MetricsMiddleware[K, V](c.Recorder(name)))
+}
+
+// Describe sends the descriptors of every metric of the collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.hits, c.misses, c.sets, c.deletes, c.evictions, c.expirations, c.entries, c.hitRatio} {
+		ch <- d
+	}
+
+	c.latency.Describe(ch)
+}
+
+// Collect sends the current stats of every added cache and the observed latencies.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mx.Lock()
+	caches := make(map[string]cachego.StatsProvider, len(c.caches))
+	for name, cache := range c.caches {
+		caches[name] = cache
+	}
+	c.mx.Unlock()
+
+	for name, cache := range caches {
+		s := cache.Stats()
+
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits), name)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses), name)
+		ch <- prometheus.MustNewConstMetric(c.sets, prometheus.CounterValue, float64(s.Sets), name)
+		ch <- prometheus.MustNewConstMetric(c.deletes, prometheus.CounterValue, float64(s.Deletes), name)
+		ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(s.Evictions), name)
+		ch <- prometheus.MustNewConstMetric(c.expirations, prometheus.CounterValue, float64(s.Expirations), name)
+		ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(s.Size), name)
+		ch <- prometheus.MustNewConstMetric(c.hitRatio, prometheus.GaugeValue, s.HitRatio(), name)
+	}
+
+	c.latency.Collect(ch)
+}
+
+type recorder struct {
+	latency *prometheus.HistogramVec
+	name    string
+}
+
+func (r recorder) ObserveOp(op cachego.Op, duration time.Duration, err error) {
+	r.latency.WithLabelValues(r.name, string(op)).Observe(duration.Seconds())
+}
@@ -0,0 +1,52 @@
+package cacheprom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/noam-g4/cachego"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// nolint:errcheck
+func TestCollector(t *testing.T) {
+	collector := NewCollector("app")
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(collector); err != nil {
+		t.Fatalf("Register returned error: %s", err)
+	}
+
+	cache := Instrument(collector, "users", cachego.NewLRUCache[int, string](1))
+	cache.Set(1, "one")
+	cache.Set(2, "two")
+	cache.Get(2)
+	cache.Get(1)
+
+	expected := `
+# HELP app_cachego_evictions_total Number of entries evicted for capacity.
+# TYPE app_cachego_evictions_total counter
+app_cachego_evictions_total{cache="users"} 1
+# HELP app_cachego_hit_ratio Ratio of hits out of all lookups.
+# TYPE app_cachego_hit_ratio gauge
+app_cachego_hit_ratio{cache="users"} 0.5
+# HELP app_cachego_entries Current number of entries.
+# TYPE app_cachego_entries gauge
+app_cachego_entries{cache="users"} 1
+`
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"app_cachego_evictions_total", "app_cachego_hit_ratio", "app_cachego_entries")
+	if err != nil {
+		t.Error(err)
+	}
+
+	// one latency histogram per operation
+	if n := testutil.CollectAndCount(collector.latency); n != 2 {
+		t.Errorf("expected 2 latency histograms, got %v", n)
+	}
+
+	collector.Remove("users")
+	if n, err := testutil.GatherAndCount(reg, "app_cachego_hits_total"); err != nil || n != 0 {
+		t.Errorf("expected no metrics after Remove, got %v (%v)", n, err)
+	}
+}
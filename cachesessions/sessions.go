@@ -0,0 +1,158 @@
+// Package cachesessions stores the sessions of web apps in a cachego.Cache, e.g. the one of the redis package,
+// under random IDs, so they expire along with the entries of the cache.
+//
+// It implements the storage side of a gorilla/sessions Store, which the Store of the app wraps in a few lines,
+// keeping the session ID in the cookie:
+//
+//	type SessionStore struct{ *cachesessions.Store }
+//
+//	func (s SessionStore) Get(r *http.Request, name string) (*sessions.Session, error) {
+//		return sessions.GetRegistry(r).Get(s, name)
+//	}
+//
+//	func (s SessionStore) New(r *http.Request, name string) (*sessions.Session, error) {
+//		session := sessions.NewSession(s, name)
+//		session.Options = &sessions.Options{Path: "/", MaxAge: 86400 * 30, HttpOnly: true}
+//		session.IsNew = true
+//		if c, err := r.Cookie(name); err == nil {
+//			values, err := s.Load(r.Context(), c.Value)
+//			if err == nil {
+//				session.ID, session.Values, session.IsNew = c.Value, values, false
+//			} else if !errors.Is(err, cachego.ErrNotFound) {
+//				return session, err
+//			}
+//		}
+//		return session, nil
+//	}
+//
+//	func (s SessionStore) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+//		if session.Options.MaxAge < 0 {
+//			http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+//			return s.Delete(r.Context(), session.ID)
+//		}
+//		if session.ID == "" {
+//			session.ID = cachesessions.NewID()
+//		}
+//		maxAge := time.Duration(session.Options.MaxAge) * time.Second
+//		if err := s.Store.Save(r.Context(), session.ID, session.Values, maxAge); err != nil {
+//			return err
+//		}
+//		http.SetCookie(w, sessions.NewCookie(session.Name(), session.ID, session.Options))
+//		return nil
+//	}
+//
+// The values are encoded with encoding/gob, as with the stores of gorilla/sessions, so the types stored
+// in the sessions other than the basic ones must be registered with gob.Register.
+package cachesessions
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// Record is a session stored in the cache.
+type Record struct {
+	// Values are the values of the session, encoded with encoding/gob.
+	Values []byte
+	// Expires is the time the session expires at.
+	Expires time.Time
+}
+
+// Opts configures a Store.
+type Opts struct {
+	// MaxAge is the lifetime of the sessions saved without one. Defaults to 30 days.
+	MaxAge time.Duration
+	// Clock tells the time the sessions are saved and expire at. Defaults to the system clock.
+	Clock cachego.Clock
+}
+
+// Store loads and saves the values of sessions by ID. If the cache implements cachego.TTLSetter,
+// the sessions are stored with their lifetime, so they are removed once they expire. It is thread-safe.
+type Store struct {
+	c    cachego.Cache[string, Record]
+	opts Opts
+}
+
+// New creates a store saving the sessions in the cache.
+func New(c cachego.Cache[string, Record], opts Opts) *Store {
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = 30 * 24 * time.Hour
+	}
+
+	return &Store{c: c, opts: opts}
+}
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewID returns a new random session ID, of 256 bits.
+func NewID() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("cachesessions: reading random bytes failed: %v", err))
+	}
+
+	return encoding.EncodeToString(b)
+}
+
+// Load returns the values of the session. If the session doesn't exist or expired, it returns an error
+// wrapping cachego.ErrNotFound.
+func (s *Store) Load(ctx context.Context, id string) (map[any]any, error) {
+	r, err := cachego.GetCtx(ctx, s.c, id)
+	if err != nil {
+		return nil, err
+	}
+	if !s.now().Before(r.Expires) {
+		return nil, fmt.Errorf("session %v %w", id, cachego.ErrNotFound)
+	}
+
+	values := make(map[any]any)
+	if err := gob.NewDecoder(bytes.NewReader(r.Values)).Decode(&values); err != nil {
+		return nil, fmt.Errorf("cachesessions: decoding session %v: %w", id, err)
+	}
+
+	return values, nil
+}
+
+// Save stores the values of the session, expiring after maxAge, or Opts.MaxAge if maxAge <= 0.
+func (s *Store) Save(ctx context.Context, id string, values map[any]any, maxAge time.Duration) error {
+	if maxAge <= 0 {
+		maxAge = s.opts.MaxAge
+	}
+
+	b := &bytes.Buffer{}
+	if err := gob.NewEncoder(b).Encode(values); err != nil {
+		return fmt.Errorf("cachesessions: encoding session %v: %w", id, err)
+	}
+
+	r := Record{Values: b.Bytes(), Expires: s.now().Add(maxAge)}
+	if ts, ok := s.c.(cachego.TTLSetter[string, Record]); ok {
+		return ts.SetWithTTL(id, r, maxAge)
+	}
+
+	return cachego.SetCtx(ctx, s.c, id, r)
+}
+
+// Delete removes the session. Sessions which don't exist are not an error.
+func (s *Store) Delete(ctx context.Context, id string) error {
+	if err := cachego.DeleteCtx(ctx, s.c, id); err != nil && !errors.Is(err, cachego.ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) now() time.Time {
+	if s.opts.Clock == nil {
+		return time.Now()
+	}
+
+	return s.opts.Clock.Now()
+}
@@ -0,0 +1,82 @@
+package cachesessions
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) NewTimer(time.Duration) cachego.Timer { panic("not used") }
+
+type user struct {
+	Name string
+}
+
+func TestStore(t *testing.T) {
+	gob.Register(user{})
+	clock := &fakeClock{now: time.Now()}
+	s := New(cachego.NewCache[string, Record](cachego.Opts{Size: 10}), Opts{MaxAge: time.Hour, Clock: clock})
+	ctx := context.Background()
+
+	id := NewID()
+	if len(id) != 52 || id == NewID() {
+		t.Errorf("expected a random ID of 52 characters, got %v", id)
+	}
+	if _, err := s.Load(ctx, id); !errors.Is(err, cachego.ErrNotFound) {
+		t.Errorf("expected %v, got %v", cachego.ErrNotFound, err)
+	}
+
+	if err := s.Save(ctx, id, map[any]any{"user": user{Name: "ada"}, 1: 2}, 0); err != nil {
+		t.Fatal(err)
+	}
+	values, err := s.Load(ctx, id)
+	if err != nil || values["user"] != (user{Name: "ada"}) || values[1] != 2 {
+		t.Errorf("expected the saved values, got %v (%v)", values, err)
+	}
+
+	// the session expires after the default max age
+	clock.now = clock.now.Add(time.Hour)
+	if _, err := s.Load(ctx, id); !errors.Is(err, cachego.ErrNotFound) {
+		t.Errorf("expected %v, got %v", cachego.ErrNotFound, err)
+	}
+
+	if err := s.Save(ctx, id, map[any]any{}, 2*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Load(ctx, id); err != nil {
+		t.Errorf("expected the session, got %v", err)
+	}
+	if err := s.Delete(ctx, id); err != nil {
+		t.Errorf("Delete returned error: %s", err)
+	}
+	if err := s.Delete(ctx, id); err != nil {
+		t.Errorf("expected deleting a missing session to succeed, got %v", err)
+	}
+}
+
+func TestStoreTTLSetter(t *testing.T) {
+	c := cachego.NewLRUCache[string, Record](10)
+	s := New(c, Opts{})
+
+	start := time.Now()
+	if err := s.Save(context.Background(), "id", map[any]any{"a": 1}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	_, info, err := c.(cachego.Inspector[string, Record]).GetWithInfo("id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl := info.Expires.Sub(start); ttl < time.Minute || ttl > time.Minute+time.Second {
+		t.Errorf("expected the entry to expire in %v, got %v", time.Minute, ttl)
+	}
+}
@@ -0,0 +1,175 @@
+// Package cachetest provides utilities for testing code built on cachego:
+// a conformance suite any cachego.Cache implementation can run, a deterministic fake cache,
+// and a fake clock to drive the ttl of the built-in caches without waiting for it.
+package cachetest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/noam-g4/cachego"
+)
+
+// TestCache runs the conformance suite against the caches created by the factory,
+// checking the semantics documented on the cachego.Cache interface.
+// The factory is called for every subtest, and must return an empty cache holding at least 10 entries.
+// Caches implementing io.Closer are closed at the end of the subtest.
+func TestCache(t *testing.T, factory func() cachego.Cache[string, string]) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, c cachego.Cache[string, string])
+	}{
+		{"SetGet", testSetGet},
+		{"Overwrite", testOverwrite},
+		{"GetMissing", testGetMissing},
+		{"Delete", testDelete},
+		{"DeleteMissing", testDeleteMissing},
+		{"Clear", testClear},
+		{"Concurrent", testConcurrent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := factory()
+			if closer, ok := c.(io.Closer); ok {
+				t.Cleanup(func() {
+					if err := closer.Close(); err != nil {
+						t.Errorf("Close returned error: %s", err)
+					}
+				})
+			}
+
+			tt.test(t, c)
+		})
+	}
+}
+
+func testSetGet(t *testing.T, c cachego.Cache[string, string]) {
+	for i := 0; i < 10; i++ {
+		if err := c.Set(key(i), value(i)); err != nil {
+			t.Fatalf("Set returned error: %s", err)
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		if v, err := c.Get(key(i)); err != nil || v != value(i) {
+			t.Errorf("expected %v, got %v (%v)", value(i), v, err)
+		}
+	}
+}
+
+func testOverwrite(t *testing.T, c cachego.Cache[string, string]) {
+	if err := c.Set("a", "one"); err != nil {
+		t.Fatalf("Set returned error: %s", err)
+	}
+	if err := c.Set("a", "two"); err != nil {
+		t.Fatalf("Set returned error: %s", err)
+	}
+
+	if v, err := c.Get("a"); err != nil || v != "two" {
+		t.Errorf("expected two, got %v (%v)", v, err)
+	}
+}
+
+func testGetMissing(t *testing.T, c cachego.Cache[string, string]) {
+	v, err := c.Get("missing")
+	if !errors.Is(err, cachego.ErrNotFound) {
+		t.Errorf("expected %v, got %v", cachego.ErrNotFound, err)
+	}
+	if v != "" {
+		t.Errorf("expected the zero value, got %v", v)
+	}
+}
+
+func testDelete(t *testing.T, c cachego.Cache[string, string]) {
+	if err := c.Set("a", "one"); err != nil {
+		t.Fatalf("Set returned error: %s", err)
+	}
+	if err := c.Set("b", "two"); err != nil {
+		t.Fatalf("Set returned error: %s", err)
+	}
+
+	if err := c.Delete("a"); err != nil {
+		t.Errorf("Delete returned error: %s", err)
+	}
+	if _, err := c.Get("a"); !errors.Is(err, cachego.ErrNotFound) {
+		t.Errorf("expected %v, got %v", cachego.ErrNotFound, err)
+	}
+	if v, err := c.Get("b"); err != nil || v != "two" {
+		t.Errorf("expected two, got %v (%v)", v, err)
+	}
+
+	// a deleted key can be set again
+	if err := c.Set("a", "three"); err != nil {
+		t.Errorf("Set returned error: %s", err)
+	}
+	if v, err := c.Get("a"); err != nil || v != "three" {
+		t.Errorf("expected three, got %v (%v)", v, err)
+	}
+}
+
+func testDeleteMissing(t *testing.T, c cachego.Cache[string, string]) {
+	if err := c.Delete("missing"); !errors.Is(err, cachego.ErrNotFound) {
+		t.Errorf("expected %v, got %v", cachego.ErrNotFound, err)
+	}
+}
+
+func testClear(t *testing.T, c cachego.Cache[string, string]) {
+	for i := 0; i < 10; i++ {
+		if err := c.Set(key(i), value(i)); err != nil {
+			t.Fatalf("Set returned error: %s", err)
+		}
+	}
+
+	if err := c.Clear(); err != nil {
+		t.Errorf("Clear returned error: %s", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		if _, err := c.Get(key(i)); !errors.Is(err, cachego.ErrNotFound) {
+			t.Errorf("expected %v, got %v", cachego.ErrNotFound, err)
+		}
+	}
+
+	// the cache is usable, and holds as many entries, after Clear
+	for i := 0; i < 10; i++ {
+		if err := c.Set(key(i), value(i)); err != nil {
+			t.Errorf("Set returned error: %s", err)
+		}
+	}
+}
+
+// testConcurrent uses the cache from several goroutines, to be run with the race detector.
+func testConcurrent(t *testing.T, c cachego.Cache[string, string]) {
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				k := key((g + i) % 10)
+				if err := c.Set(k, value(g)); err != nil && !errors.Is(err, cachego.ErrCacheFull) {
+					t.Errorf("Set returned error: %s", err)
+				}
+				if _, err := c.Get(k); err != nil && !errors.Is(err, cachego.ErrNotFound) {
+					t.Errorf("Get returned error: %s", err)
+				}
+				if err := c.Delete(k); err != nil && !errors.Is(err, cachego.ErrNotFound) {
+					t.Errorf("Delete returned error: %s", err)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func key(i int) string {
+	return fmt.Sprintf("key-%d", i)
+}
+
+func value(i int) string {
+	return fmt.Sprintf("value-%d", i)
+}
@@ -0,0 +1,41 @@
+package cachetest
+
+import (
+	"testing"
+
+	"github.com/noam-g4/cachego"
+)
+
+func TestConformance(t *testing.T) {
+	factories := map[string]func() cachego.Cache[string, string]{
+		"fake":   func() cachego.Cache[string, string] { return NewFake[string, string]() },
+		"simple": func() cachego.Cache[string, string] { return cachego.NewCache[string, string](cachego.Opts{Size: 10}) },
+		"lru":    func() cachego.Cache[string, string] { return cachego.NewLRUCache[string, string](10) },
+		"cow":    func() cachego.Cache[string, string] { return cachego.NewCopyOnWriteCache[string, string](10) },
+		"arena":  func() cachego.Cache[string, string] { return cachego.NewArenaCache[string, string](10) },
+		"syncmap": func() cachego.Cache[string, string] {
+			return cachego.NewSyncMapCache[string, string](10)
+		},
+		"slab": func() cachego.Cache[string, string] {
+			return cachego.NewSlabCache[string, string](cachego.SlabOpts[string]{})
+		},
+		"sharded": func() cachego.Cache[string, string] {
+			return cachego.NewShardedCache(cachego.ShardedOpts[string, string]{})
+		},
+		"ring": func() cachego.Cache[string, string] {
+			return cachego.NewRing(map[string]cachego.Cache[string, string]{
+				"a": cachego.NewCache[string, string](cachego.Opts{Size: 10}),
+				"b": cachego.NewCache[string, string](cachego.Opts{Size: 10}),
+			}, cachego.RingOpts[string]{Replicas: 2})
+		},
+		"ttl": func() cachego.Cache[string, string] {
+			return cachego.NewLRUCacheWithOpts[string, string](cachego.Opts{Size: 10, TTL: 60})
+		},
+	}
+
+	for name, factory := range factories {
+		t.Run(name, func(t *testing.T) {
+			TestCache(t, factory)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package cachetest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// Clock is a fake cachego.Clock whose time only moves on Advance, to test the ttl of a cache
+// (see cachego.Opts.Clock) without waiting for it. It is thread-safe.
+type Clock struct {
+	mx     *sync.Mutex
+	now    time.Time
+	timers []*timer
+}
+
+type timer struct {
+	c        chan time.Time
+	deadline time.Time
+	clock    *Clock
+}
+
+// NewClock creates a new fake clock set to the given time.
+func NewClock(now time.Time) *Clock {
+	return &Clock{mx: &sync.Mutex{}, now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return c.now
+}
+
+// NewTimer creates a timer firing once the clock is advanced by the duration.
+func (c *Clock) NewTimer(d time.Duration) cachego.Timer {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	t := &timer{c: make(chan time.Time, 1), deadline: c.now.Add(d), clock: c}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the time of the clock forward, firing the timers whose deadline passed.
+// The caches expire their entries asynchronously, shortly after Advance returns.
+func (c *Clock) Advance(d time.Duration) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.c <- c.now
+	}
+	c.timers = pending
+}
+
+// Pending returns the number of timers waiting for the clock to advance.
+// A cache with entries to expire holds a pending timer once it sleeps until the next deadline,
+// so waiting for one before calling Advance makes sure the cache sees the new time.
+func (c *Clock) Pending() int {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return len(c.timers)
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *timer) Stop() bool {
+	t.clock.mx.Lock()
+	defer t.clock.mx.Unlock()
+
+	for i, p := range t.clock.timers {
+		if p == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,36 @@
+package cachetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// nolint:errcheck
+func TestClock(t *testing.T) {
+	clock := NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	expired := make(chan string, 1)
+	c := cachego.NewCache(cachego.Opts{Size: 1, TTL: 60, Clock: clock}, cachego.TypedOpts[string, int]{
+		OnExpire: func(key string, value int) { expired <- key },
+	})
+
+	c.Set("a", 1)
+	for clock.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	clock.Advance(time.Minute)
+	select {
+	case key := <-expired:
+		if key != "a" {
+			t.Errorf("expected a, got %v", key)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected a to expire")
+	}
+
+	if _, err := c.Get("a"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
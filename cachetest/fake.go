@@ -0,0 +1,126 @@
+package cachetest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/noam-g4/cachego"
+)
+
+// Call is a call made to a Fake cache.
+type Call[K comparable] struct {
+	Op  cachego.Op
+	Key K // the zero value for Clear
+}
+
+// Fake is a deterministic, unbounded in-memory cache that records the calls made to it,
+// and can be made to fail, to test the code using a cache.
+// Its entries never expire nor are evicted. It is thread-safe.
+type Fake[K comparable, V any] struct {
+	mx     *sync.Mutex
+	data   map[K]V
+	calls  []Call[K]
+	errors map[cachego.Op]error
+}
+
+// NewFake creates a new empty fake cache.
+func NewFake[K comparable, V any]() *Fake[K, V] {
+	return &Fake[K, V]{mx: &sync.Mutex{}, data: make(map[K]V), errors: make(map[cachego.Op]error)}
+}
+
+// Fail makes the calls of the given operation return the error, without changing the cache.
+// A nil error restores the operation.
+func (f *Fake[K, V]) Fail(op cachego.Op, err error) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	if err == nil {
+		delete(f.errors, op)
+		return
+	}
+	f.errors[op] = err
+}
+
+// Calls returns the calls made to the cache so far, in order.
+func (f *Fake[K, V]) Calls() []Call[K] {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	return append([]Call[K](nil), f.calls...)
+}
+
+// Len returns the number of entries in the cache.
+func (f *Fake[K, V]) Len() int {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	return len(f.data)
+}
+
+// Set stores the value under the key, unless Set was made to fail.
+func (f *Fake[K, V]) Set(key K, value V) error {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	if err := f.call(cachego.OpSet, key); err != nil {
+		return err
+	}
+
+	f.data[key] = value
+	return nil
+}
+
+// Get returns the value under the key, or an error wrapping cachego.ErrNotFound if it is missing.
+func (f *Fake[K, V]) Get(key K) (V, error) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	var empty V
+	if err := f.call(cachego.OpGet, key); err != nil {
+		return empty, err
+	}
+
+	v, ok := f.data[key]
+	if !ok {
+		return empty, fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+	}
+
+	return v, nil
+}
+
+// Delete removes the key, or returns an error wrapping cachego.ErrNotFound if it is missing.
+func (f *Fake[K, V]) Delete(key K) error {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	if err := f.call(cachego.OpDelete, key); err != nil {
+		return err
+	}
+
+	if _, ok := f.data[key]; !ok {
+		return fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+	}
+
+	delete(f.data, key)
+	return nil
+}
+
+// Clear removes every entry, unless Clear was made to fail.
+func (f *Fake[K, V]) Clear() error {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	var empty K
+	if err := f.call(cachego.OpClear, empty); err != nil {
+		return err
+	}
+
+	f.data = make(map[K]V)
+	return nil
+}
+
+// call records the call, and returns the error the operation was made to fail with.
+func (f *Fake[K, V]) call(op cachego.Op, key K) error {
+	f.calls = append(f.calls, Call[K]{Op: op, Key: key})
+	return f.errors[op]
+}
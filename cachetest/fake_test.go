@@ -0,0 +1,32 @@
+package cachetest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/noam-g4/cachego"
+)
+
+// nolint:errcheck
+func TestFake(t *testing.T) {
+	f := NewFake[string, int]()
+	errDown := errors.New("down")
+
+	f.Set("a", 1)
+	f.Fail(cachego.OpGet, errDown)
+	if _, err := f.Get("a"); !errors.Is(err, errDown) {
+		t.Errorf("expected %v, got %v", errDown, err)
+	}
+
+	f.Fail(cachego.OpGet, nil)
+	if v, err := f.Get("a"); err != nil || v != 1 {
+		t.Errorf("expected 1, got %v (%v)", v, err)
+	}
+
+	f.Clear()
+	expected := []Call[string]{{cachego.OpSet, "a"}, {cachego.OpGet, "a"}, {cachego.OpGet, "a"}, {cachego.OpClear, ""}}
+	if calls := f.Calls(); !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected %v, got %v", expected, calls)
+	}
+}
@@ -0,0 +1,124 @@
+package cachetrace
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// Config is a cache configuration a trace is replayed against.
+type Config struct {
+	// Name identifies the configuration in the results.
+	Name string
+	// Options configure the cache, as passed to cachego.New.
+	Options []cachego.Option
+}
+
+// Result is the outcome of replaying a trace against a configuration.
+type Result struct {
+	Name   string
+	Hits   uint64
+	Misses uint64
+	// Evictions counts the entries evicted for capacity or memory pressure.
+	Evictions uint64
+	// Rejections counts the keys rejected by a full cache.
+	Rejections uint64
+}
+
+// HitRatio returns the ratio of hits out of all lookups, or 0 if there were no lookups.
+func (r Result) HitRatio() float64 {
+	if total := r.Hits + r.Misses; total > 0 {
+		return float64(r.Hits) / float64(total)
+	}
+
+	return 0
+}
+
+// Simulate replays the trace against a new cache of every configuration, and returns their results in order.
+// A get missing the cache stores the key, as the caller would have after loading its value, so the
+// configurations are compared on the keys they would have been asked for rather than the recorded sets only.
+//
+// The caches are given a clock following the times of the trace, but the entries don't expire during the replay,
+// since the caches expire them in the background. Their values are empty, so the configurations bounded by bytes
+// only weigh the keys.
+func Simulate[K comparable](trace *Reader[K], configs ...Config) ([]Result, error) {
+	clock := &replayClock{}
+	results := make([]Result, len(configs))
+	caches := make([]cachego.Cache[K, struct{}], len(configs))
+	for i, cfg := range configs {
+		r := &results[i]
+		r.Name = cfg.Name
+		options := append(append([]cachego.Option{}, cfg.Options...),
+			cachego.WithClock(clock),
+			cachego.WithOnEvict(func(_ K, _ struct{}, reason cachego.Reason) {
+				if reason == cachego.ReasonCapacity || reason == cachego.ReasonMemory {
+					r.Evictions++
+				}
+			}),
+		)
+		caches[i] = cachego.New[K, struct{}](options...)
+	}
+	defer func() {
+		for _, c := range caches {
+			if closer, ok := c.(io.Closer); ok {
+				closer.Close() // nolint:errcheck
+			}
+		}
+	}()
+
+	for {
+		a, err := trace.Next()
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		clock.now.Store(a.Time.UnixNano())
+		for i, c := range caches {
+			replay(c, a, &results[i])
+		}
+	}
+}
+
+func replay[K comparable](c cachego.Cache[K, struct{}], a Access[K], r *Result) {
+	switch a.Op {
+	case cachego.OpGet:
+		if _, ok := cachego.LookupValue(c, a.Key); ok {
+			r.Hits++
+			return
+		}
+		r.Misses++
+		fallthrough
+	case cachego.OpSet:
+		if c.Set(a.Key, struct{}{}) != nil {
+			r.Rejections++
+		}
+	case cachego.OpDelete:
+		c.Delete(a.Key) // nolint:errcheck
+	case cachego.OpClear:
+		c.Clear() // nolint:errcheck
+	}
+}
+
+// replayClock tells the time of the access being replayed. Its timers never fire.
+type replayClock struct {
+	now atomic.Int64
+}
+
+func (c *replayClock) Now() time.Time {
+	return time.Unix(0, c.now.Load())
+}
+
+func (c *replayClock) NewTimer(time.Duration) cachego.Timer {
+	return idleTimer{}
+}
+
+type idleTimer struct{}
+
+func (idleTimer) C() <-chan time.Time { return nil }
+
+func (idleTimer) Stop() bool { return true }
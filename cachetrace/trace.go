@@ -0,0 +1,142 @@
+// Package cachetrace records the access stream of a cache to a writer, and replays the recorded traces
+// against other cache configurations to compare their hit ratios and evictions offline, e.g. to choose
+// the size or the policy of a cache from real traffic:
+//
+//	rec := cachetrace.NewRecorder[string, User](trace, cachetrace.Opts{})
+//	users := cachego.Wrap(cache, rec.Middleware())
+//	...
+//	rec.Flush()
+//
+//	results, err := cachetrace.Simulate(cachetrace.NewReader[string](trace),
+//		cachetrace.Config{Name: "lru", Options: []cachego.Option{cachego.WithPolicy(cachego.PolicyLRU)}},
+//		cachetrace.Config{Name: "lfu", Options: []cachego.Option{cachego.WithPolicy(cachego.PolicyLFU)}},
+//	)
+package cachetrace
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// Access is a single operation of a trace.
+type Access[K comparable] struct {
+	Time time.Time
+	Op   cachego.Op
+	// Key is the key of the operation, or the zero value for cachego.OpClear.
+	Key K
+	// Hit reports whether a cachego.OpGet found the key in the recorded cache.
+	Hit bool
+}
+
+// record is the encoding of an Access: a JSON object per line, the time in unix nanoseconds.
+type record[K comparable] struct {
+	Time int64      `json:"t"`
+	Op   cachego.Op `json:"op"`
+	Key  K          `json:"key"`
+	Hit  bool       `json:"hit,omitempty"`
+}
+
+// Opts configures a Recorder.
+type Opts struct {
+	// Clock tells the time of the accesses. Defaults to the system clock.
+	Clock cachego.Clock
+	// Logger receives the errors writing the trace, which don't fail the cache operations. Defaults to discarding them.
+	Logger cachego.Logger
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
+
+// Recorder writes the accesses of the caches wrapped by its middleware to a writer, as JSON lines.
+// The keys must be encodable to JSON. The writes are buffered: call Flush once done recording.
+// It is thread-safe.
+type Recorder[K comparable, V any] struct {
+	opts Opts
+
+	mx  sync.Mutex
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+// NewRecorder creates a recorder writing to the writer.
+func NewRecorder[K comparable, V any](w io.Writer, opts Opts) *Recorder[K, V] {
+	if opts.Logger == nil {
+		opts.Logger = nopLogger{}
+	}
+
+	bw := bufio.NewWriter(w)
+	return &Recorder[K, V]{opts: opts, w: bw, enc: json.NewEncoder(bw)}
+}
+
+// Middleware returns a middleware recording the operations of the cache it wraps (see cachego.Wrap).
+func (r *Recorder[K, V]) Middleware() cachego.Middleware[K, V] {
+	return cachego.Intercept[K, V](func(op cachego.Op, key K, call func() error) error {
+		err := call()
+		r.Record(Access[K]{Time: r.now(), Op: op, Key: key, Hit: op == cachego.OpGet && err == nil})
+		return err
+	})
+}
+
+// Record writes the access to the trace, logging the error if it fails.
+func (r *Recorder[K, V]) Record(a Access[K]) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if err := r.enc.Encode(record[K]{Time: a.Time.UnixNano(), Op: a.Op, Key: a.Key, Hit: a.Hit}); err != nil {
+		r.opts.Logger.Printf("cachetrace: recording %v %v: %v", a.Op, a.Key, err)
+	}
+}
+
+// Flush writes the buffered accesses to the writer.
+func (r *Recorder[K, V]) Flush() error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	return r.w.Flush()
+}
+
+func (r *Recorder[K, V]) now() time.Time {
+	if r.opts.Clock == nil {
+		return time.Now()
+	}
+
+	return r.opts.Clock.Now()
+}
+
+// Reader reads the accesses of a trace written by a Recorder.
+type Reader[K comparable] struct {
+	dec  *json.Decoder
+	line int
+}
+
+// NewReader creates a reader of the trace.
+func NewReader[K comparable](r io.Reader) *Reader[K] {
+	return &Reader[K]{dec: json.NewDecoder(bufio.NewReader(r))}
+}
+
+// Next returns the next access of the trace, or io.EOF at its end.
+func (r *Reader[K]) Next() (Access[K], error) {
+	var rec record[K]
+	if err := r.dec.Decode(&rec); err != nil {
+		if err == io.EOF {
+			return Access[K]{}, err
+		}
+		return Access[K]{}, fmt.Errorf("cachetrace: reading access %d: %w", r.line+1, err)
+	}
+	r.line++
+
+	switch rec.Op {
+	case cachego.OpGet, cachego.OpSet, cachego.OpDelete, cachego.OpClear:
+	default:
+		return Access[K]{}, fmt.Errorf("cachetrace: unknown operation %q of access %d", rec.Op, r.line)
+	}
+
+	return Access[K]{Time: time.Unix(0, rec.Time), Op: rec.Op, Key: rec.Key, Hit: rec.Hit}, nil
+}
@@ -0,0 +1,127 @@
+package cachetrace
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.now = c.now.Add(time.Second)
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(time.Duration) cachego.Timer {
+	panic("not used")
+}
+
+// nolint:errcheck
+func TestRecorder(t *testing.T) {
+	buf := &bytes.Buffer{}
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	rec := NewRecorder[string, int](buf, Opts{Clock: clock})
+	c := cachego.Wrap(cachego.NewCache[string, int](cachego.Opts{}), rec.Middleware())
+
+	c.Get("a")
+	c.Set("a", 1)
+	c.Get("a")
+	c.Delete("a")
+	c.Clear()
+	if err := rec.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Access[string]{
+		{Time: time.Unix(1001, 0), Op: cachego.OpGet, Key: "a"},
+		{Time: time.Unix(1002, 0), Op: cachego.OpSet, Key: "a"},
+		{Time: time.Unix(1003, 0), Op: cachego.OpGet, Key: "a", Hit: true},
+		{Time: time.Unix(1004, 0), Op: cachego.OpDelete, Key: "a"},
+		{Time: time.Unix(1005, 0), Op: cachego.OpClear},
+	}
+	r := NewReader[string](buf)
+	for _, e := range expected {
+		a, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !a.Time.Equal(e.Time) || a.Op != e.Op || a.Key != e.Key || a.Hit != e.Hit {
+			t.Errorf("expected %+v, got %+v", e, a)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected %v, got %v", io.EOF, err)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	r := NewReader[int](strings.NewReader(`{"t":1,"op":"get","key":1}` + "\n" + `{"t":2,"op":"put","key":2}` + "\n"))
+	if _, err := r.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Next(); err == nil || !strings.Contains(err.Error(), "access 2") {
+		t.Errorf("expected an error of the unknown operation of access 2, got %v", err)
+	}
+
+	r = NewReader[int](strings.NewReader(`{"t":1,"op":"get","key":"a"}`))
+	if _, err := r.Next(); err == nil || errors.Is(err, io.EOF) {
+		t.Errorf("expected an error decoding the key, got %v", err)
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	buf := &bytes.Buffer{}
+	rec := NewRecorder[int, struct{}](buf, Opts{})
+	start := time.Unix(1000, 0)
+	// a scan over 3 keys, then a hot key used 3 times before each of them
+	for i := 0; i < 30; i++ {
+		rec.Record(Access[int]{Time: start.Add(time.Duration(i) * time.Second), Op: cachego.OpGet, Key: i % 3})
+	}
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 3; j++ {
+			rec.Record(Access[int]{Time: start, Op: cachego.OpGet, Key: 100})
+		}
+		rec.Record(Access[int]{Time: start, Op: cachego.OpGet, Key: 200 + i})
+	}
+	rec.Record(Access[int]{Time: start, Op: cachego.OpClear})
+	rec.Record(Access[int]{Time: start, Op: cachego.OpGet, Key: 100})
+	if err := rec.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := Simulate(NewReader[int](bytes.NewReader(buf.Bytes())),
+		Config{Name: "lru-2", Options: []cachego.Option{cachego.WithPolicy(cachego.PolicyLRU), cachego.WithSize(2)}},
+		Config{Name: "lru-3", Options: []cachego.Option{cachego.WithPolicy(cachego.PolicyLRU), cachego.WithSize(3)}},
+		Config{Name: "none-3", Options: []cachego.Option{cachego.WithSize(3)}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Result{
+		// the scan thrashes, then the hot key stays while the new keys evict each other
+		{Name: "lru-2", Hits: 29, Misses: 42, Evictions: 39},
+		{Name: "lru-3", Hits: 56, Misses: 15, Evictions: 11},
+		// the hot key is rejected until the clear
+		{Name: "none-3", Hits: 27, Misses: 44, Rejections: 40},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %v results, got %v", len(expected), len(results))
+	}
+	for i, r := range results {
+		if r != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], r)
+		}
+	}
+	if ratio := results[1].HitRatio(); ratio != 56.0/71 {
+		t.Errorf("expected %v, got %v", 56.0/71, ratio)
+	}
+}
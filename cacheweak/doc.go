@@ -0,0 +1,4 @@
+// Package cacheweak provides a cachego.Cache holding its values through weak pointers.
+// It requires Go 1.24 or later, and is empty when built with an older toolchain,
+// so the core cachego package keeps supporting the Go version declared in go.mod.
+package cacheweak
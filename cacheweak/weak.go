@@ -0,0 +1,146 @@
+//go:build go1.24
+
+package cacheweak
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+	"weak"
+
+	"github.com/noam-g4/cachego"
+)
+
+type cache[K comparable, V any] struct {
+	data    map[K]weak.Pointer[V]
+	mx      *sync.RWMutex
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+	sets    atomic.Uint64
+	deletes atomic.Uint64
+	created time.Time
+}
+
+// NewCache creates a new thread-safe cache that holds its values through weak pointers,
+// so the garbage collector can reclaim a value once nothing outside the cache references it.
+// A reclaimed value is a miss, and its key is dropped from the cache shortly after.
+// It suits canonicalization caches, e.g. sharing large parsed objects while they are in use,
+// and needs no size since the entries live only as long as their values are referenced.
+func NewCache[K comparable, V any]() cachego.Cache[K, *V] {
+	return &cache[K, V]{
+		data:    make(map[K]weak.Pointer[V]),
+		mx:      &sync.RWMutex{},
+		created: time.Now(),
+	}
+}
+
+// Set stores a weak pointer to the value under the given key. It returns an error if the value is nil.
+// This method is thread-safe.
+func (c *cache[K, V]) Set(key K, value *V) error {
+	if value == nil {
+		return fmt.Errorf("value of key %v is nil", key)
+	}
+
+	p := weak.Make(value)
+	runtime.AddCleanup(value, func(key K) { c.collect(key, p) }, key)
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.data[key] = p
+	c.sets.Add(1)
+	return nil
+}
+
+// collect drops the key once its value was reclaimed, unless the key was set to another value since.
+func (c *cache[K, V]) collect(key K, p weak.Pointer[V]) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.data[key] == p {
+		delete(c.data, key)
+	}
+}
+
+// Get retrieves the value associated with the given key.
+// If the key is not found, or its value was reclaimed, nil and an error will be returned.
+// This method is thread-safe.
+func (c *cache[K, V]) Get(key K) (*V, error) {
+	if v, ok := c.Lookup(key); ok {
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+}
+
+// Lookup retrieves the value just like Get, but reports a miss with false instead of building an error.
+// This method is thread-safe.
+func (c *cache[K, V]) Lookup(key K) (*V, bool) {
+	c.mx.RLock()
+	p, ok := c.data[key]
+	c.mx.RUnlock()
+
+	var v *V
+	if ok {
+		v = p.Value()
+	}
+
+	if v != nil {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+
+	return v, v != nil
+}
+
+// Delete removes the key from the cache.
+// If the key is not found, or its value was reclaimed, an error will be returned.
+// This method is thread-safe.
+func (c *cache[K, V]) Delete(key K) error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	p, ok := c.data[key]
+	if !ok {
+		return fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+	}
+
+	delete(c.data, key)
+	if p.Value() == nil {
+		return fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+	}
+
+	c.deletes.Add(1)
+	return nil
+}
+
+// Clear removes every key from the cache.
+// This method is thread-safe.
+func (c *cache[K, V]) Clear() error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.data = make(map[K]weak.Pointer[V])
+	return nil
+}
+
+// Stats returns a snapshot of the cache counters. The size counts the keys whose values
+// were reclaimed but not yet dropped.
+// This method is thread-safe.
+func (c *cache[K, V]) Stats() cachego.Stats {
+	c.mx.RLock()
+	used := int32(len(c.data))
+	c.mx.RUnlock()
+
+	return cachego.Stats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Sets:    c.sets.Load(),
+		Deletes: c.deletes.Load(),
+		Size:    used,
+		Uptime:  time.Since(c.created),
+	}
+}
@@ -0,0 +1,52 @@
+//go:build go1.24
+
+package cacheweak
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+type parsed struct {
+	data [1 << 10]byte
+}
+
+func TestWeakCache(t *testing.T) {
+	c := NewCache[string, parsed]()
+
+	if err := c.Set("nil", nil); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	kept := &parsed{}
+	c.Set("kept", kept)      // nolint:errcheck
+	c.Set("lost", &parsed{}) // nolint:errcheck
+
+	runtime.GC()
+
+	if v, err := c.Get("kept"); err != nil || v != kept {
+		t.Errorf("expected the kept value, got %v (%v)", v, err)
+	}
+
+	if _, err := c.Get("lost"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	// the key of the reclaimed value is dropped by its cleanup
+	deadline := time.Now().Add(time.Second)
+	for c.(cachego.StatsProvider).Stats().Size != 1 && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	if size := c.(cachego.StatsProvider).Stats().Size; size != 1 {
+		t.Errorf("expected 1, got %v", size)
+	}
+
+	if err := c.Delete("kept"); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+	runtime.KeepAlive(kept)
+}
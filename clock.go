@@ -0,0 +1,57 @@
+package cachego
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time to a cache: when its entries are set, read and expire.
+// It defaults to the system clock, and may be replaced by a fake one to test or replay
+// time based behavior, such as the ttl, without waiting for it.
+type Clock interface {
+	Now() time.Time
+	// NewTimer creates a timer sending the current time on its channel once the duration elapsed.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event timer created by a Clock, like time.Timer.
+type Timer interface {
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, and reports whether it was still pending.
+	Stop() bool
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTimer(d time.Duration) Timer { return systemTimer{time.NewTimer(d)} }
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time { return t.Timer.C }
+
+// every calls the function whenever the interval elapsed, as told by the clock, until the context is done.
+// The next interval starts once the function returns.
+func every(ctx context.Context, clock Clock, interval time.Duration, f func()) {
+	for {
+		timer := clock.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+			f()
+		}
+	}
+}
+
+func clockOrSystem(c Clock) Clock {
+	if c == nil {
+		return systemClock{}
+	}
+
+	return c
+}
@@ -0,0 +1,166 @@
+package cachego
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose time only moves on Advance.
+type fakeClock struct {
+	mx     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	c        chan time.Time
+	deadline time.Time
+	clock    *fakeClock
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	t := &fakeTimer{c: make(chan time.Time, 1), deadline: c.now.Add(d), clock: c}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the time forward, firing the timers whose deadline passed.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.c <- c.now
+	}
+	c.timers = pending
+}
+
+// wait waits for a timer to be pending, i.e. for the cache to sleep until its next deadline.
+func (c *fakeClock) wait(t *testing.T) {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		c.mx.Lock()
+		n := len(c.timers)
+		c.mx.Unlock()
+		if n > 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("expected a pending timer")
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mx.Lock()
+	defer t.clock.mx.Unlock()
+
+	for i, p := range t.clock.timers {
+		if p == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// nolint:errcheck
+func TestClock(t *testing.T) {
+	clock := newFakeClock()
+	expired := make(chan string, 1)
+	caches := map[string]Cache[string, int]{
+		"simple": NewCache(Opts{Size: 1, TTL: 1, Clock: clock}, TypedOpts[string, int]{
+			OnExpire: func(key string, value int) { expired <- "simple" },
+		}),
+		"lru": NewLRUCacheWithOpts(Opts{Size: 1, TTL: 1, Clock: clock}, TypedOpts[string, int]{
+			OnExpire: func(key string, value int) { expired <- "lru" },
+		}),
+	}
+
+	for name, c := range caches {
+		t.Run(name, func(t *testing.T) {
+			c.Set("a", 1)
+
+			_, info, _ := c.(Inspector[string, int]).GetWithInfo("a")
+			if want := clock.Now().Add(time.Second); !info.Expires.Equal(want) {
+				t.Errorf("expected %v, got %v", want, info.Expires)
+			}
+
+			clock.wait(t)
+			clock.Advance(999 * time.Millisecond)
+			if _, err := c.Get("a"); err != nil {
+				t.Errorf("expected a before its ttl, got %v", err)
+			}
+
+			clock.Advance(time.Millisecond)
+			select {
+			case got := <-expired:
+				if got != name {
+					t.Errorf("expected %v, got %v", name, got)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("expected a to expire")
+			}
+
+			if _, err := c.Get("a"); err == nil {
+				t.Errorf("expected error, got nil")
+			}
+		})
+	}
+}
+
+// nolint:errcheck
+func TestClockIntervals(t *testing.T) {
+	clock := newFakeClock()
+	file := NewMemoryCacheFile()
+	file.Dump([]byte(`{"a":1}`))
+
+	c := NewCache[string, int](Opts{Size: 2, File: file, Reload: time.Minute, Events: 1, Reclaim: 1, Clock: clock})
+	defer c.(io.Closer).Close()
+
+	// the events are timed by the clock
+	c.Delete("a")
+	if e := <-c.(EventSource[string, int]).Events(); !e.Time.Equal(clock.Now()) {
+		t.Errorf("expected an event at %v, got %v", clock.Now(), e.Time)
+	}
+	if e := <-c.(Reclaimable[string, int]).Reclaimed(); !e.Time.Equal(clock.Now()) {
+		t.Errorf("expected a reclaimed entry at %v, got %v", clock.Now(), e.Time)
+	}
+
+	// the snapshot is reloaded once the interval elapsed on the clock
+	file.Dump([]byte(`{"b":2}`))
+	clock.wait(t)
+	clock.Advance(time.Minute)
+	eventually(t, func() bool {
+		_, err := c.Get("b")
+		return err == nil
+	})
+
+	if uptime := c.(StatsProvider).Stats().Uptime; uptime != time.Minute {
+		t.Errorf("expected an uptime of a minute, got %v", uptime)
+	}
+}
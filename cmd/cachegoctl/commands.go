@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// matcher selects the entries whose key and value match the patterns given with -key and -value.
+type matcher struct {
+	key, value *regexp.Regexp
+}
+
+func (m *matcher) flags(fs *flag.FlagSet) (key, value *string) {
+	return fs.String("key", "", "select the entries whose `key` matches the pattern"),
+		fs.String("value", "", "select the entries whose `value` (in JSON) matches the pattern")
+}
+
+func (m *matcher) compile(key, value string) (err error) {
+	if key != "" {
+		if m.key, err = regexp.Compile(key); err != nil {
+			return fmt.Errorf("invalid -key: %w", err)
+		}
+	}
+	if value != "" {
+		if m.value, err = regexp.Compile(value); err != nil {
+			return fmt.Errorf("invalid -value: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (m *matcher) match(e entry) bool {
+	return (m.key == nil || m.key.MatchString(keyString(e.Key))) &&
+		(m.value == nil || m.value.MatchString(jsonString(e.Value)))
+}
+
+// selectEntries returns the entries matched, or the ones not matched if keep is false.
+func (m *matcher) selectEntries(entries []entry, keep bool) []entry {
+	selected := []entry{}
+	for _, e := range entries {
+		if m.match(e) == keep {
+			selected = append(selected, e)
+		}
+	}
+
+	return selected
+}
+
+// parse parses the flags of the command, and checks it got the number of arguments expected.
+func parse(fs *flag.FlagSet, args []string, e *env, names ...string) bool {
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "usage: cachegoctl %s [flags] %s\n", fs.Name(), strings.Join(names, " "))
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return false
+	}
+	if fs.NArg() != len(names) {
+		fs.Usage()
+		return false
+	}
+
+	return true
+}
+
+func fail(e *env, err error) int {
+	fmt.Fprintf(e.stderr, "cachegoctl: %v\n", err)
+	return 1
+}
+
+// list prints the entries of the snapshot matched, one per line: the key, a tab, and the JSON of the value.
+func list(args []string, e *env) int {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	m := &matcher{}
+	key, value := m.flags(fs)
+	format := fs.String("format", "", "the `format` of the file: json, gob or msgpack")
+	if !parse(fs, args, e, "FILE") {
+		return 2
+	}
+	if err := m.compile(*key, *value); err != nil {
+		return fail(e, err)
+	}
+
+	entries, err := readSnapshot(fs.Arg(0), *format, e.stdin)
+	if err != nil {
+		return fail(e, err)
+	}
+
+	for _, en := range m.selectEntries(entries, true) {
+		fmt.Fprintf(e.stdout, "%s\t%s\n", keyString(en.Key), jsonString(en.Value))
+	}
+
+	return 0
+}
+
+// filter writes the entries of the snapshot matched, to stdout by default.
+func filter(args []string, e *env) int {
+	return rewrite("filter", "-", true, args, e)
+}
+
+// prune removes the entries of the snapshot matched, in place by default.
+func prune(args []string, e *env) int {
+	return rewrite("prune", "", false, args, e)
+}
+
+// rewrite writes the entries of the snapshot selected, in the same format, to the output or in place if it is empty.
+func rewrite(name, out string, keep bool, args []string, e *env) int {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	m := &matcher{}
+	key, value := m.flags(fs)
+	format := fs.String("format", "", "the `format` of the files: json, gob or msgpack")
+	fs.StringVar(&out, "o", out, "the `file` to write to, - for stdout (defaults to the file itself for prune)")
+	if !parse(fs, args, e, "FILE") {
+		return 2
+	}
+	if *key == "" && *value == "" {
+		return fail(e, errors.New("expected -key or -value"))
+	}
+	if err := m.compile(*key, *value); err != nil {
+		return fail(e, err)
+	}
+
+	in := fs.Arg(0)
+	if out == "" {
+		out = in
+	}
+	f, err := formatOf(in, *format)
+	if err != nil {
+		return fail(e, err)
+	}
+
+	entries, err := readSnapshot(in, f, e.stdin)
+	if err != nil {
+		return fail(e, err)
+	}
+
+	selected := m.selectEntries(entries, keep)
+	if err := writeSnapshot(out, f, selected, e.stdout); err != nil {
+		return fail(e, err)
+	}
+
+	if out != "-" {
+		fmt.Fprintf(e.stderr, "wrote %d of %d entries to %s\n", len(selected), len(entries), out)
+	}
+	return 0
+}
+
+// diff prints the entries added to the second snapshot (+), removed from it (-) and changed (~), sorted by key.
+func diff(args []string, e *env) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	format := fs.String("format", "", "the `format` of the files: json, gob or msgpack")
+	if !parse(fs, args, e, "A", "B") {
+		return 2
+	}
+
+	a, err := readSnapshot(fs.Arg(0), *format, e.stdin)
+	if err != nil {
+		return fail(e, err)
+	}
+	b, err := readSnapshot(fs.Arg(1), *format, e.stdin)
+	if err != nil {
+		return fail(e, err)
+	}
+
+	old := make(map[string]string, len(a))
+	for _, en := range a {
+		old[keyString(en.Key)] = jsonString(en.Value)
+	}
+
+	type line struct{ key, text string }
+	var lines []line
+	for _, en := range b {
+		k, v := keyString(en.Key), jsonString(en.Value)
+		prev, ok := old[k]
+		switch {
+		case !ok:
+			lines = append(lines, line{k, "+ " + k + "\t" + v})
+		case prev != v:
+			lines = append(lines, line{k, "~ " + k + "\t" + prev + " -> " + v})
+		}
+		delete(old, k)
+	}
+	for k, v := range old {
+		lines = append(lines, line{k, "- " + k + "\t" + v})
+	}
+
+	sort.Slice(lines, func(i, j int) bool { return lines[i].key < lines[j].key })
+	for _, l := range lines {
+		fmt.Fprintln(e.stdout, l.text)
+	}
+
+	if len(lines) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// convert rewrites the snapshot in another format.
+func convert(args []string, e *env) int {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	from := fs.String("from", "", "the `format` of the input: json, gob or msgpack")
+	to := fs.String("to", "", "the `format` of the output: json, gob or msgpack")
+	if !parse(fs, args, e, "IN", "OUT") {
+		return 2
+	}
+
+	entries, err := readSnapshot(fs.Arg(0), *from, e.stdin)
+	if err != nil {
+		return fail(e, err)
+	}
+	if err := writeSnapshot(fs.Arg(1), *to, entries, e.stdout); err != nil {
+		return fail(e, err)
+	}
+
+	return 0
+}
+
+// headers collects the headers given with -H, as curl does.
+type headers http.Header
+
+func (h headers) String() string {
+	return fmt.Sprint(http.Header(h))
+}
+
+func (h headers) Set(s string) error {
+	name, value, ok := strings.Cut(s, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("expected a header as Name: value, got %q", s)
+	}
+
+	http.Header(h).Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	return nil
+}
+
+// push stores the entries of the snapshot in a cache of a running instance, through its admin endpoints.
+func push(args []string, e *env) int {
+	fs := flag.NewFlagSet("push", flag.ContinueOnError)
+	addr := fs.String("addr", "", "the `URL` the admin endpoints are served at, e.g. http://localhost:8080/admin")
+	name := fs.String("cache", "", "the `name` of the cache, as registered with the admin endpoints")
+	format := fs.String("format", "", "the `format` of the file: json, gob or msgpack")
+	timeout := fs.Duration("timeout", 30*time.Second, "the `timeout` of the request")
+	h := headers{}
+	fs.Var(h, "H", "a `header` to send, e.g. \"Authorization: Bearer ...\" (repeatable)")
+	if !parse(fs, args, e, "FILE") {
+		return 2
+	}
+	if *addr == "" || *name == "" {
+		return fail(e, errors.New("expected -addr and -cache"))
+	}
+
+	entries, err := readSnapshot(fs.Arg(0), *format, e.stdin)
+	if err != nil {
+		return fail(e, err)
+	}
+	body, err := encode(entries, formatJSON)
+	if err != nil {
+		return fail(e, err)
+	}
+
+	u := strings.TrimSuffix(*addr, "/") + "/caches/" + url.PathEscape(*name) + "/entries"
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return fail(e, err)
+	}
+	req.Header = http.Header(h)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := (&http.Client{Timeout: *timeout}).Do(req)
+	if err != nil {
+		return fail(e, err)
+	}
+	defer res.Body.Close()
+
+	var result struct {
+		Stored int    `json:"stored"`
+		Error  string `json:"error"`
+	}
+	b, _ := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err := json.Unmarshal(b, &result); err != nil || res.StatusCode != http.StatusOK {
+		msg := result.Error
+		if msg == "" {
+			msg = strings.TrimSpace(string(b))
+		}
+		return fail(e, fmt.Errorf("pushing to %s: %s: %s", u, res.Status, msg))
+	}
+
+	fmt.Fprintf(e.stdout, "stored %d of %d entries in %s\n", result.Stored, len(entries), *name)
+	return 0
+}
@@ -0,0 +1,78 @@
+// Command cachegoctl inspects and edits the snapshot files persisted by the caches, and pushes them into
+// running instances through the admin endpoints.
+//
+// Usage:
+//
+//	cachegoctl list [-key RE] [-value RE] [-format F] FILE
+//	cachegoctl filter [-key RE] [-value RE] [-format F] [-o OUT] FILE
+//	cachegoctl prune [-key RE] [-value RE] [-format F] [-o OUT] FILE
+//	cachegoctl diff [-format F] A B
+//	cachegoctl convert [-from F] [-to F] IN OUT
+//	cachegoctl push -addr URL -cache NAME [-H HEADER]... [-format F] FILE
+//
+// The files are JSON snapshots ([{"key": ..., "value": ...}]), gob or msgpack encoded lists of the same entries.
+// The format is inferred from the extension (.json, .gob, .msgpack or .mp) unless given, defaulting to JSON.
+// A FILE of "-" is read from stdin, and an OUT of "-" is written to stdout.
+//
+// The patterns match the keys themselves if they are strings and their JSON otherwise, and the JSON of the values.
+// Diff exits with 1 if the snapshots differ, as diff(1).
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// command runs a subcommand with its arguments, and returns the exit code.
+type command func(args []string, env *env) int
+
+// env holds the standard streams of the commands, so they can be run from the tests.
+type env struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+var commands = map[string]command{
+	"list":    list,
+	"filter":  filter,
+	"prune":   prune,
+	"diff":    diff,
+	"convert": convert,
+	"push":    push,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], &env{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}))
+}
+
+func run(args []string, e *env) int {
+	if len(args) == 0 {
+		usage(e.stderr)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		if args[0] != "help" && args[0] != "-h" && args[0] != "-help" {
+			fmt.Fprintf(e.stderr, "cachegoctl: unknown command %q\n", args[0])
+		}
+		usage(e.stderr)
+		return 2
+	}
+
+	return cmd(args[1:], e)
+}
+
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "usage: cachegoctl <%s> [flags] [files]\n", strings.Join(names, "|"))
+	fmt.Fprintln(w, "run cachegoctl <command> -h for the flags of a command")
+}
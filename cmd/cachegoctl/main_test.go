@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/noam-g4/cachego"
+	"github.com/noam-g4/cachego/admin"
+)
+
+const snapshot = `[{"key":"a","value":1},{"key":"b","value":{"name":"bob","tags":["x",null,true]}},{"key":"c","value":-1.5}]`
+
+// cachegoctl runs the command, and returns its exit code and outputs.
+func cachegoctl(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	code := run(args, &env{stdin: strings.NewReader(stdin), stdout: stdout, stderr: stderr})
+	return code, stdout.String(), stderr.String()
+}
+
+func write(t *testing.T, name, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConvert(t *testing.T) {
+	expected, err := decode([]byte(snapshot), formatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := write(t, "snapshot.json", snapshot)
+	for _, name := range []string{"snapshot.gob", "snapshot.msgpack"} {
+		out := filepath.Join(t.TempDir(), name)
+		if code, _, stderr := cachegoctl(t, "", "convert", in, out); code != 0 {
+			t.Fatalf("expected %v, got %v: %v", 0, code, stderr)
+		}
+
+		code, stdout, _ := cachegoctl(t, "", "convert", out, "-")
+		got, err := decode([]byte(stdout), formatJSON)
+		if code != 0 || err != nil || !reflect.DeepEqual(got, expected) {
+			t.Errorf("%v: expected %v, got %v, %v", name, expected, got, err)
+		}
+	}
+
+	if code, _, stderr := cachegoctl(t, "", "convert", "-to", "xml", in, "-"); code != 1 || !strings.Contains(stderr, `unknown format "xml"`) {
+		t.Errorf("expected an unknown format, got %v: %v", code, stderr)
+	}
+}
+
+func TestMsgpack(t *testing.T) {
+	for _, v := range []any{
+		nil, true, "", strings.Repeat("s", 40), strings.Repeat("s", 300),
+		map[string]any{"a": []any{"b"}}, make([]any, 20),
+	} {
+		b, err := appendMsgpack(nil, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, rest, err := readMsgpack(b)
+		if err != nil || len(rest) != 0 || !reflect.DeepEqual(got, v) {
+			t.Errorf("expected %v, got %v, %v", v, got, err)
+		}
+	}
+
+	for _, n := range []string{"0", "127", "128", "-32", "-33", "-129", "70000", "-70000", "5000000000", "-5000000000", "18446744073709551615", "0.25", "1e+100"} {
+		b, err := appendMsgpack(nil, json.Number(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _, err := readMsgpack(b); err != nil || got != json.Number(n) {
+			t.Errorf("expected %v, got %v, %v", n, got, err)
+		}
+	}
+
+	if _, err := decodeMsgpackSnapshot([]byte{0x91, 0x82, 0xa3}); err != errTruncated {
+		t.Errorf("expected %v, got %v", errTruncated, err)
+	}
+}
+
+func TestListFilterPrune(t *testing.T) {
+	in := write(t, "snapshot.json", snapshot)
+
+	code, stdout, _ := cachegoctl(t, "", "list", "-value", "bob", in)
+	if expected := "b\t{\"name\":\"bob\",\"tags\":[\"x\",null,true]}\n"; code != 0 || stdout != expected {
+		t.Errorf("expected %q, got %v: %q", expected, code, stdout)
+	}
+
+	code, stdout, _ = cachegoctl(t, snapshot, "filter", "-key", "^[ac]$", "-")
+	if expected := `[{"key":"a","value":1},{"key":"c","value":-1.5}]` + "\n"; code != 0 || stdout != expected {
+		t.Errorf("expected %q, got %v: %q", expected, code, stdout)
+	}
+
+	if code, _, stderr := cachegoctl(t, "", "prune", in); code != 1 || !strings.Contains(stderr, "expected -key or -value") {
+		t.Errorf("expected a missing pattern, got %v: %v", code, stderr)
+	}
+	if code, _, stderr := cachegoctl(t, "", "prune", "-key", "a", in); code != 0 || stderr != "wrote 2 of 3 entries to "+in+"\n" {
+		t.Errorf("expected 2 entries written, got %v: %v", code, stderr)
+	}
+	if code, stdout, _ := cachegoctl(t, "", "list", in); code != 0 || strings.Count(stdout, "\n") != 2 || strings.HasPrefix(stdout, "a") {
+		t.Errorf("expected b and c, got %v: %q", code, stdout)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := write(t, "a.json", snapshot)
+	b := write(t, "b.json", `{"a":1,"c":2,"d":"new"}`)
+
+	code, stdout, _ := cachegoctl(t, "", "diff", a, b)
+	expected := "- b\t{\"name\":\"bob\",\"tags\":[\"x\",null,true]}\n~ c\t-1.5 -> 2\n+ d\t\"new\"\n"
+	if code != 1 || stdout != expected {
+		t.Errorf("expected %q, got %v: %q", expected, code, stdout)
+	}
+
+	if code, stdout, _ := cachegoctl(t, "", "diff", a, a); code != 0 || stdout != "" {
+		t.Errorf("expected no difference, got %v: %q", code, stdout)
+	}
+}
+
+func TestPush(t *testing.T) {
+	a := admin.New(admin.Opts{Auth: func(r *http.Request) error {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			return errors.New("unauthorized")
+		}
+		return nil
+	}})
+	c := cachego.NewCache[string, any](cachego.Opts{Size: 2})
+	admin.Register(a, "users", c, admin.KeyOpts[string]{})
+	s := httptest.NewServer(http.StripPrefix("/admin", a))
+	defer s.Close()
+
+	in := write(t, "snapshot.gob", "")
+	cachegoctl(t, snapshot, "convert", "-from", "json", "-", in)
+
+	code, stdout, stderr := cachegoctl(t, "", "push", "-addr", s.URL+"/admin", "-cache", "users", "-H", "Authorization: Bearer secret", "-key", "a", in)
+	if code != 2 || !strings.Contains(stderr, "flag provided but not defined: -key") {
+		t.Errorf("expected an undefined flag, got %v: %v", code, stderr)
+	}
+
+	code, stdout, stderr = cachegoctl(t, "", "push", "-addr", s.URL+"/admin", "-cache", "users", "-H", "Authorization: Bearer secret", in)
+	if code != 1 || !strings.Contains(stderr, "507 Insufficient Storage: storing entry 3 of 3 failed") {
+		t.Errorf("expected a full cache, got %v: %v, %v", code, stdout, stderr)
+	}
+	if v, err := c.Get("b"); err != nil || v.(map[string]any)["name"] != "bob" {
+		t.Errorf("expected bob, got %v, %v", v, err)
+	}
+
+	c.Clear()
+	cachegoctl(t, `[{"key":"a","value":"x"}]`, "convert", "-", in)
+	code, stdout, _ = cachegoctl(t, "", "push", "-addr", s.URL+"/admin/", "-cache", "users", "-H", "Authorization: Bearer secret", in)
+	if expected := "stored 1 of 1 entries in users\n"; code != 0 || stdout != expected {
+		t.Errorf("expected %q, got %v: %q", expected, code, stdout)
+	}
+
+	if code, _, stderr := cachegoctl(t, "", "push", "-addr", s.URL+"/admin", "-cache", "users", in); code != 1 || !strings.Contains(stderr, "403 Forbidden") {
+		t.Errorf("expected %v, got %v: %v", http.StatusForbidden, code, stderr)
+	}
+}
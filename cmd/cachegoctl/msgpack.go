@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// The snapshot is encoded as a msgpack array of maps holding a "key" and a "value", as the JSON snapshots.
+// Only the types decoded from JSON are supported, so the module stays free of a msgpack dependency.
+
+var errTruncated = errors.New("msgpack: truncated data")
+
+func encodeMsgpackSnapshot(entries []entry) ([]byte, error) {
+	list := make([]any, len(entries))
+	for i, e := range entries {
+		list[i] = map[string]any{"key": e.Key, "value": e.Value}
+	}
+
+	return appendMsgpack(nil, list)
+}
+
+func decodeMsgpackSnapshot(data []byte) ([]entry, error) {
+	v, rest, err := readMsgpack(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("msgpack: %d bytes after the snapshot", len(rest))
+	}
+
+	list, ok := v.([]any)
+	if !ok {
+		return nil, errors.New("msgpack: the snapshot is not an array")
+	}
+
+	entries := make([]entry, 0, len(list))
+	for _, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, errors.New("msgpack: an entry is not a map")
+		}
+		entries = append(entries, entry{Key: m["key"], Value: m["value"]})
+	}
+
+	return entries, nil
+}
+
+// appendMsgpack appends the msgpack encoding of a value decoded from JSON.
+func appendMsgpack(b []byte, v any) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xc0), nil
+
+	case bool:
+		if v {
+			return append(b, 0xc3), nil
+		}
+		return append(b, 0xc2), nil
+
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return appendInt(b, n), nil
+		}
+		if n, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return appendUint(b, n), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return appendFloat(b, f), nil
+
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<63 {
+			return appendInt(b, int64(v)), nil
+		}
+		return appendFloat(b, v), nil
+
+	case string:
+		n := len(v)
+		switch {
+		case n < 32:
+			b = append(b, 0xa0|byte(n))
+		case n <= math.MaxUint8:
+			b = append(b, 0xd9, byte(n))
+		case n <= math.MaxUint16:
+			b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+		default:
+			b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+		}
+		return append(b, v...), nil
+
+	case []any:
+		n := len(v)
+		switch {
+		case n < 16:
+			b = append(b, 0x90|byte(n))
+		case n <= math.MaxUint16:
+			b = binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+		default:
+			b = binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+		}
+		var err error
+		for _, item := range v {
+			if b, err = appendMsgpack(b, item); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+
+	case map[string]any:
+		n := len(v)
+		switch {
+		case n < 16:
+			b = append(b, 0x80|byte(n))
+		case n <= math.MaxUint16:
+			b = binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+		default:
+			b = binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+		}
+		keys := make([]string, 0, n)
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var err error
+		for _, k := range keys {
+			b, _ = appendMsgpack(b, k)
+			if b, err = appendMsgpack(b, v[k]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+
+	return nil, fmt.Errorf("msgpack: unsupported type %T", v)
+}
+
+func appendInt(b []byte, n int64) []byte {
+	switch {
+	case n >= 0:
+		return appendUint(b, uint64(n))
+	case n >= -32:
+		return append(b, byte(n))
+	case n >= math.MinInt8:
+		return append(b, 0xd0, byte(n))
+	case n >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(n))
+	case n >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(n))
+	}
+
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(n))
+}
+
+func appendUint(b []byte, n uint64) []byte {
+	switch {
+	case n < 128:
+		return append(b, byte(n))
+	case n <= math.MaxUint8:
+		return append(b, 0xcc, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(n))
+	}
+
+	return binary.BigEndian.AppendUint64(append(b, 0xcf), n)
+}
+
+func appendFloat(b []byte, f float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(f))
+}
+
+// readMsgpack decodes a value, as encoding/json with UseNumber would: numbers as json.Number,
+// binary data as strings, and map keys as strings (their JSON if they are not strings).
+func readMsgpack(b []byte) (any, []byte, error) {
+	if len(b) == 0 {
+		return nil, nil, errTruncated
+	}
+
+	c, b := b[0], b[1:]
+	switch {
+	case c <= 0x7f:
+		return json.Number(strconv.Itoa(int(c))), b, nil
+	case c >= 0xe0:
+		return json.Number(strconv.Itoa(int(int8(c)))), b, nil
+	case c&0xe0 == 0xa0:
+		return readString(b, int(c&0x1f))
+	case c&0xf0 == 0x90:
+		return readArray(b, int(c&0x0f))
+	case c&0xf0 == 0x80:
+		return readMap(b, int(c&0x0f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, b, nil
+	case 0xc2:
+		return false, b, nil
+	case 0xc3:
+		return true, b, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, b, err := readUint(b, 1<<(c-0xcc))
+		return json.Number(strconv.FormatUint(n, 10)), b, err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		n, b, err := readUint(b, size)
+		// sign extend from the size read
+		shift := 64 - 8*size
+		return json.Number(strconv.FormatInt(int64(n<<shift)>>shift, 10)), b, err
+	case 0xca:
+		n, b, err := readUint(b, 4)
+		return floatNumber(float64(math.Float32frombits(uint32(n)))), b, err
+	case 0xcb:
+		n, b, err := readUint(b, 8)
+		return floatNumber(math.Float64frombits(n)), b, err
+	case 0xd9, 0xda, 0xdb, 0xc4, 0xc5, 0xc6:
+		sizes := map[byte]int{0xd9: 1, 0xda: 2, 0xdb: 4, 0xc4: 1, 0xc5: 2, 0xc6: 4}
+		n, b, err := readUint(b, sizes[c])
+		if err != nil {
+			return nil, nil, err
+		}
+		return readString(b, int(n))
+	case 0xdc, 0xdd:
+		n, b, err := readUint(b, 2<<(c-0xdc))
+		if err != nil {
+			return nil, nil, err
+		}
+		return readArray(b, int(n))
+	case 0xde, 0xdf:
+		n, b, err := readUint(b, 2<<(c-0xde))
+		if err != nil {
+			return nil, nil, err
+		}
+		return readMap(b, int(n))
+	}
+
+	return nil, nil, fmt.Errorf("msgpack: unsupported type 0x%02x", c)
+}
+
+func floatNumber(f float64) json.Number {
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+func readUint(b []byte, size int) (uint64, []byte, error) {
+	if len(b) < size {
+		return 0, nil, errTruncated
+	}
+
+	var n uint64
+	for _, c := range b[:size] {
+		n = n<<8 | uint64(c)
+	}
+
+	return n, b[size:], nil
+}
+
+func readString(b []byte, n int) (any, []byte, error) {
+	if n < 0 || len(b) < n {
+		return nil, nil, errTruncated
+	}
+
+	return string(b[:n]), b[n:], nil
+}
+
+func readArray(b []byte, n int) (any, []byte, error) {
+	// every item takes a byte at least
+	if n < 0 || len(b) < n {
+		return nil, nil, errTruncated
+	}
+
+	list := make([]any, n)
+	for i := range list {
+		var err error
+		if list[i], b, err = readMsgpack(b); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return list, b, nil
+}
+
+func readMap(b []byte, n int) (any, []byte, error) {
+	if n < 0 || len(b) < 2*n {
+		return nil, nil, errTruncated
+	}
+
+	m := make(map[string]any, n)
+	for i := 0; i < n; i++ {
+		var k, v any
+		var err error
+		if k, b, err = readMsgpack(b); err != nil {
+			return nil, nil, err
+		}
+		if v, b, err = readMsgpack(b); err != nil {
+			return nil, nil, err
+		}
+		m[keyString(k)] = v
+	}
+
+	return m, b, nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The formats of the snapshot files. JSON is the format persisted by the caches: a list of {"key", "value"} objects.
+// Gob and msgpack hold the same list, for the tools consuming them.
+const (
+	formatJSON    = "json"
+	formatGob     = "gob"
+	formatMsgpack = "msgpack"
+)
+
+// entry is an entry of a snapshot. The keys and values hold the values decoded from JSON (with the numbers
+// as json.Number, so they round-trip exactly), whatever the format of the file.
+type entry struct {
+	Key   any `json:"key"`
+	Value any `json:"value"`
+}
+
+func init() {
+	gob.Register(map[string]any{})
+	gob.Register([]any{})
+	gob.Register(json.Number(""))
+}
+
+// formatOf returns the format of the file: the given one if set, or the one of its extension, defaulting to JSON.
+func formatOf(path, format string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".gob":
+			format = formatGob
+		case ".msgpack", ".mp":
+			format = formatMsgpack
+		default:
+			format = formatJSON
+		}
+	}
+
+	switch format {
+	case formatJSON, formatGob, formatMsgpack:
+		return format, nil
+	}
+
+	return "", fmt.Errorf("unknown format %q: expected json, gob or msgpack", format)
+}
+
+// readSnapshot reads the entries of the snapshot file, or of stdin if the path is "-".
+func readSnapshot(path, format string, stdin io.Reader) ([]entry, error) {
+	format, err := formatOf(path, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := decode(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return entries, nil
+}
+
+func decode(data []byte, format string) ([]entry, error) {
+	var entries []entry
+	switch format {
+	case formatGob:
+		err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entries)
+		return entries, err
+
+	case formatMsgpack:
+		return decodeMsgpackSnapshot(data)
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '{' {
+		// the snapshots persisted as a JSON object, before entry lists
+		var m map[string]any
+		if err := d.Decode(&m); err != nil {
+			return nil, err
+		}
+		for k, v := range m {
+			entries = append(entries, entry{Key: k, Value: v})
+		}
+		sortEntries(entries)
+		return entries, nil
+	}
+
+	err := d.Decode(&entries)
+	return entries, err
+}
+
+// writeSnapshot writes the entries to the snapshot file, or to stdout if the path is "-".
+// Files are replaced atomically, so a snapshot is never left half written.
+func writeSnapshot(path, format string, entries []entry, stdout io.Writer) error {
+	format, err := formatOf(path, format)
+	if err != nil {
+		return err
+	}
+
+	data, err := encode(entries, format)
+	if err != nil {
+		return err
+	}
+
+	if path == "-" {
+		_, err := stdout.Write(data)
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func encode(entries []entry, format string) ([]byte, error) {
+	if entries == nil {
+		entries = []entry{}
+	}
+
+	switch format {
+	case formatGob:
+		b := &bytes.Buffer{}
+		err := gob.NewEncoder(b).Encode(entries)
+		return b.Bytes(), err
+
+	case formatMsgpack:
+		return encodeMsgpackSnapshot(entries)
+	}
+
+	b, err := json.Marshal(entries)
+	return append(b, '\n'), err
+}
+
+// keyString returns the text identifying the key: the key itself if it is a string, and its JSON otherwise.
+func keyString(key any) string {
+	if s, ok := key.(string); ok {
+		return s
+	}
+
+	return jsonString(key)
+}
+
+// jsonString returns the JSON of the value. Map keys are sorted, so equal values have the same JSON.
+func jsonString(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
+
+// sortEntries sorts the entries by key, for stable outputs.
+func sortEntries(entries []entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return keyString(entries[i].Key) < keyString(entries[j].Key)
+	})
+}
@@ -0,0 +1,125 @@
+package cachego
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Codec encodes the values of a cache to bytes and back, so the caches of bytes (e.g. with WithCompression,
+// or the remote backends) can hold values of any type in a single representation.
+type Codec[V any] interface {
+	Marshal(value V) ([]byte, error)
+	Unmarshal(data []byte, value *V) error
+}
+
+// JSONCodec encodes the values with encoding/json.
+type JSONCodec[V any] struct{}
+
+func (JSONCodec[V]) Marshal(value V) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (JSONCodec[V]) Unmarshal(data []byte, value *V) error {
+	return json.Unmarshal(data, value)
+}
+
+// GobCodec encodes the values with encoding/gob, which keeps the exported fields of structs of any type,
+// e.g. the ones holding interfaces registered with gob.Register.
+type GobCodec[V any] struct{}
+
+func (GobCodec[V]) Marshal(value V) ([]byte, error) {
+	b := &bytes.Buffer{}
+	if err := gob.NewEncoder(b).Encode(&value); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func (GobCodec[V]) Unmarshal(data []byte, value *V) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
+
+type encoded[K comparable, V any] struct {
+	c     Cache[K, []byte]
+	codec Codec[V]
+}
+
+// WithCodec wraps the given cache of bytes in a cache of values encoded by the codec on Set and decoded on Get,
+// so every Get returns a copy of the value stored. The wrapped cache may itself be a wrapper, e.g. of WithCompression.
+// Besides Cache, the wrapper implements Lookuper and io.Closer.
+func WithCodec[K comparable, V any](c Cache[K, []byte], codec Codec[V]) Cache[K, V] {
+	return &encoded[K, V]{c: c, codec: codec}
+}
+
+// NewBytesCache creates a new thread-safe LRU cache configured by the given options (see NewLRUCacheWithOpts),
+// storing the values encoded by the codec. With Opts.MaxBytes and Opts.MaxEntryBytes, the entries are weighed
+// by the size of their encoded value, rather than by an estimate of the memory held by the value.
+func NewBytesCache[K comparable, V any](opts Opts, codec Codec[V]) Cache[K, V] {
+	sizer := func(key K, value []byte) int { return estimateSize(key) + len(value) }
+	return WithCodec(NewLRUCacheWithOpts(opts, TypedOpts[K, []byte]{Sizer: sizer}), codec)
+}
+
+// Set encodes the value, and stores it in the wrapped cache.
+func (e *encoded[K, V]) Set(key K, value V) error {
+	data, err := e.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encoding value of key %v failed: %w", key, err)
+	}
+
+	return e.c.Set(key, data)
+}
+
+// Get retrieves the value from the wrapped cache, and decodes it.
+func (e *encoded[K, V]) Get(key K) (V, error) {
+	var value V
+	data, err := e.c.Get(key)
+	if err != nil {
+		return value, err
+	}
+
+	return value, e.decode(key, data, &value)
+}
+
+// Lookup retrieves the value just like Get, reporting whether it was found.
+// A value that cannot be decoded is reported as missing.
+func (e *encoded[K, V]) Lookup(key K) (V, bool) {
+	var value V
+	data, ok := LookupValue(e.c, key)
+	if !ok || e.decode(key, data, &value) != nil {
+		var empty V
+		return empty, false
+	}
+
+	return value, true
+}
+
+func (e *encoded[K, V]) decode(key K, data []byte, value *V) error {
+	if err := e.codec.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("decoding value of key %v failed: %w", key, err)
+	}
+
+	return nil
+}
+
+// Delete removes the key from the wrapped cache.
+func (e *encoded[K, V]) Delete(key K) error {
+	return e.c.Delete(key)
+}
+
+// Clear removes all the entries of the wrapped cache.
+func (e *encoded[K, V]) Clear() error {
+	return e.c.Clear()
+}
+
+// Close closes the wrapped cache if it implements io.Closer.
+func (e *encoded[K, V]) Close() error {
+	if closer, ok := e.c.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package cachego
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type codecUser struct {
+	Name string
+	Tags []string
+}
+
+func TestCodec(t *testing.T) {
+	for name, codec := range map[string]Codec[codecUser]{"json": JSONCodec[codecUser]{}, "gob": GobCodec[codecUser]{}} {
+		inner := NewCache[string, []byte](Opts{Size: 10})
+		c := WithCodec[string, codecUser](inner, codec)
+
+		u := codecUser{Name: "ann", Tags: []string{"a", "b"}}
+		if err := c.Set("ann", u); err != nil {
+			t.Errorf("%v: expected nil, got %v", name, err)
+		}
+
+		got, err := c.Get("ann")
+		if err != nil || got.Name != u.Name || len(got.Tags) != 2 {
+			t.Errorf("%v: expected %v, got %v (%v)", name, u, got, err)
+		}
+
+		// every Get decodes a copy
+		got.Tags[0] = "changed"
+		if again, _ := c.Get("ann"); again.Tags[0] != "a" {
+			t.Errorf("%v: expected a, got %v", name, again.Tags[0])
+		}
+
+		if _, err := c.Get("missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%v: expected ErrNotFound, got %v", name, err)
+		}
+
+		if _, ok := LookupValue(c, "ann"); !ok {
+			t.Errorf("%v: expected true, got false", name)
+		}
+
+		// a value that can't be decoded is reported as missing by Lookup
+		inner.Set("broken", []byte{0xff}) // nolint:errcheck
+		if _, err := c.Get("broken"); err == nil {
+			t.Errorf("%v: expected error, got nil", name)
+		}
+		if _, ok := LookupValue(c, "broken"); ok {
+			t.Errorf("%v: expected false, got true", name)
+		}
+	}
+}
+
+func TestCodecEncodeError(t *testing.T) {
+	c := WithCodec[string, func()](NewCache[string, []byte](Opts{}), JSONCodec[func()]{})
+
+	if err := c.Set("a", func() {}); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestBytesCache(t *testing.T) {
+	// the entries are weighed by the size of their encoded value
+	c := NewBytesCache[string, string](Opts{Size: 100, MaxBytes: 100}, JSONCodec[string]{})
+	defer c.(io.Closer).Close() // nolint:errcheck
+
+	for _, k := range []string{"a", "b", "c"} {
+		if err := c.Set(k, strings.Repeat("x", 30)); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+	}
+
+	if _, err := c.Get("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if got, err := c.Get("c"); err != nil || len(got) != 30 {
+		t.Errorf("expected 30, got %v (%v)", len(got), err)
+	}
+}
@@ -0,0 +1,121 @@
+package cachego
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Compressor compresses the values stored by WithCompression.
+// Any algorithm can be plugged in, e.g. snappy (Encode and Decode) or zstd (EncodeAll and DecodeAll).
+type Compressor interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+type CompressionOpts struct {
+	// Threshold is the size in bytes above which values are compressed. Defaults to 1024.
+	Threshold int
+	// Compressor compresses the values. Defaults to DEFLATE at its fastest level.
+	Compressor Compressor
+}
+
+// the first byte of every stored value tells whether the rest of it is compressed.
+const (
+	rawValue byte = iota
+	compressedValue
+)
+
+type compressed[K comparable] struct {
+	Cache[K, []byte]
+	threshold  int
+	compressor Compressor
+}
+
+// WithCompression wraps the given cache so that values larger than the threshold are compressed on Set
+// and decompressed on Get, trading CPU for memory. Values that don't shrink are stored as is.
+// The wrapped cache holds the values with a one byte header, so it must only be used through the returned cache.
+func WithCompression[K comparable](c Cache[K, []byte], opts CompressionOpts) Cache[K, []byte] {
+	w := &compressed[K]{Cache: c, threshold: opts.Threshold, compressor: opts.Compressor}
+	if w.threshold <= 0 {
+		w.threshold = 1024
+	}
+	if w.compressor == nil {
+		w.compressor = &flateCompressor{}
+	}
+
+	return w
+}
+
+// Set compresses the value if it is larger than the threshold, and stores it in the wrapped cache.
+func (c *compressed[K]) Set(key K, value []byte) error {
+	if len(value) > c.threshold {
+		data, err := c.compressor.Compress(value)
+		if err != nil {
+			return fmt.Errorf("compressing value of key %v failed: %w", key, err)
+		}
+
+		if len(data) < len(value) {
+			return c.Cache.Set(key, append([]byte{compressedValue}, data...))
+		}
+	}
+
+	return c.Cache.Set(key, append([]byte{rawValue}, value...))
+}
+
+// Get retrieves the value from the wrapped cache, decompressing it if needed.
+func (c *compressed[K]) Get(key K) ([]byte, error) {
+	data, err := c.Cache.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return nil, fmt.Errorf("value of key %v is missing its header", key)
+	}
+
+	if data[0] == rawValue {
+		return data[1:], nil
+	}
+
+	value, err := c.compressor.Decompress(data[1:])
+	if err != nil {
+		return nil, fmt.Errorf("decompressing value of key %v failed: %w", key, err)
+	}
+
+	return value, nil
+}
+
+// flateCompressor compresses with DEFLATE, reusing the writers.
+type flateCompressor struct {
+	writers sync.Pool
+}
+
+func (f *flateCompressor) Compress(data []byte) ([]byte, error) {
+	b := &bytes.Buffer{}
+	w, ok := f.writers.Get().(*flate.Writer)
+	if ok {
+		w.Reset(b)
+	} else {
+		w, _ = flate.NewWriter(b, flate.BestSpeed)
+	}
+	defer f.writers.Put(w)
+
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func (f *flateCompressor) Decompress(data []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
@@ -0,0 +1,51 @@
+package cachego
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestCompression(t *testing.T) {
+	inner := NewCache[string, []byte](Opts{Size: 10})
+	c := WithCompression(inner, CompressionOpts{Threshold: 100})
+
+	small := []byte("small")
+	large := bytes.Repeat([]byte("compressible "), 100)
+
+	for k, v := range map[string][]byte{"small": small, "large": large} {
+		if err := c.Set(k, v); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		if got, err := c.Get(k); err != nil || !bytes.Equal(got, v) {
+			t.Errorf("expected %v bytes, got %v (%v)", len(v), len(got), err)
+		}
+	}
+
+	// only the large value is compressed
+	if stored, _ := inner.Get("small"); len(stored) != len(small)+1 {
+		t.Errorf("expected %v, got %v", len(small)+1, len(stored))
+	}
+
+	if stored, _ := inner.Get("large"); len(stored) >= len(large) {
+		t.Errorf("expected less than %v, got %v", len(large), len(stored))
+	}
+
+	if _, err := c.Get("missing"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+type failingCompressor struct{}
+
+func (failingCompressor) Compress([]byte) ([]byte, error)   { return nil, errors.New("failed") }
+func (failingCompressor) Decompress([]byte) ([]byte, error) { return nil, errors.New("failed") }
+
+func TestCompressionError(t *testing.T) {
+	c := WithCompression(NewCache[string, []byte](Opts{}), CompressionOpts{Threshold: 1, Compressor: failingCompressor{}})
+
+	if err := c.Set("a", []byte("value")); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
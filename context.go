@@ -0,0 +1,60 @@
+package cachego
+
+import "context"
+
+// GetCtx retrieves the value associated with the given key from the cache, unless the context is done.
+// It uses GetCtx if the cache implements ContextCache, and checks the context before calling Get otherwise.
+func GetCtx[K comparable, V any](ctx context.Context, c Cache[K, V], key K) (V, error) {
+	if cc, ok := c.(ContextCache[K, V]); ok {
+		return cc.GetCtx(ctx, key)
+	}
+
+	if err := ctx.Err(); err != nil {
+		var empty V
+		return empty, err
+	}
+
+	return c.Get(key)
+}
+
+// SetCtx stores the value under the given key in the cache, unless the context is done.
+// It uses SetCtx if the cache implements ContextCache, and checks the context before calling Set otherwise.
+func SetCtx[K comparable, V any](ctx context.Context, c Cache[K, V], key K, value V) error {
+	if cc, ok := c.(ContextCache[K, V]); ok {
+		return cc.SetCtx(ctx, key, value)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return c.Set(key, value)
+}
+
+// DeleteCtx removes the key from the cache, unless the context is done.
+// It uses DeleteCtx if the cache implements ContextCache, and checks the context before calling Delete otherwise.
+func DeleteCtx[K comparable, V any](ctx context.Context, c Cache[K, V], key K) error {
+	if cc, ok := c.(ContextCache[K, V]); ok {
+		return cc.DeleteCtx(ctx, key)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return c.Delete(key)
+}
+
+// ClearCtx clears the cache, unless the context is done.
+// It uses ClearCtx if the cache implements ContextCache, and checks the context before calling Clear otherwise.
+func ClearCtx[K comparable, V any](ctx context.Context, c Cache[K, V]) error {
+	if cc, ok := c.(ContextCache[K, V]); ok {
+		return cc.ClearCtx(ctx)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return c.Clear()
+}
@@ -0,0 +1,86 @@
+package cachego
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// ctxRecorder is a victim cache recording the context of its operations.
+type ctxRecorder struct {
+	Cache[int, string]
+	ctx context.Context
+}
+
+func (r *ctxRecorder) GetCtx(ctx context.Context, key int) (string, error) {
+	r.ctx = ctx
+	return r.Get(key)
+}
+
+func (r *ctxRecorder) SetCtx(ctx context.Context, key int, value string) error {
+	r.ctx = ctx
+	return r.Set(key, value)
+}
+
+func (r *ctxRecorder) DeleteCtx(ctx context.Context, key int) error {
+	r.ctx = ctx
+	return r.Delete(key)
+}
+
+func (r *ctxRecorder) ClearCtx(ctx context.Context) error {
+	r.ctx = ctx
+	return r.Clear()
+}
+
+// nolint:errcheck
+func TestContextCache(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	caches := map[string]Cache[int, string]{
+		"simple": NewCache[int, string](Opts{Size: 2}),
+		"lru":    NewLRUCache[int, string](2),
+		"cow":    NewCopyOnWriteCache[int, string](2), // not a ContextCache
+	}
+
+	for name, c := range caches {
+		if err := SetCtx(cancelled, c, 1, "one"); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected %v, got %v", name, context.Canceled, err)
+		}
+		if _, err := c.Get(1); err == nil {
+			t.Errorf("%s: expected the cancelled Set not to store the value", name)
+		}
+
+		SetCtx(context.Background(), c, 1, "one")
+		if _, err := GetCtx(cancelled, c, 1); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected %v, got %v", name, context.Canceled, err)
+		}
+		if v, err := GetCtx(context.Background(), c, 1); err != nil || v != "one" {
+			t.Errorf("%s: expected one, got %v (%v)", name, v, err)
+		}
+
+		if err := DeleteCtx(cancelled, c, 1); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected %v, got %v", name, context.Canceled, err)
+		}
+		if err := ClearCtx(cancelled, c); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected %v, got %v", name, context.Canceled, err)
+		}
+		if v, err := c.Get(1); err != nil || v != "one" {
+			t.Errorf("%s: expected one, got %v (%v)", name, v, err)
+		}
+	}
+
+	// the lru cache passes the context on to its victim cache
+	victim := &ctxRecorder{Cache: NewCache[int, string](Opts{Size: 2})}
+	lru := NewLRUCacheWithOpts(Opts{Size: 1}, TypedOpts[int, string]{Victim: victim})
+	lru.Set(1, "one")
+	lru.Set(2, "two") // 1 is demoted
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	if v, err := GetCtx(ctx, lru, 1); err != nil || v != "one" {
+		t.Errorf("expected one, got %v (%v)", v, err)
+	}
+	if victim.ctx != ctx {
+		t.Errorf("expected the context to be passed to the victim cache")
+	}
+}
@@ -0,0 +1,145 @@
+package cachego
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type cow[K comparable, V any] struct {
+	size  int32
+	data  atomic.Pointer[map[K]V]
+	mx    *sync.Mutex // serializes the writers
+	stats *counters
+}
+
+// NewCopyOnWriteCache creates a new thread-safe cache for data that is read constantly but written rarely,
+// such as feature flags or configuration.
+// Reads load an immutable snapshot of the entries without any locking, while every write copies the entries
+// into a new snapshot, so writes cost O(n) and should stay rare.
+// If the size is less than or equal to zero, a default size of 100 will be used.
+// Like the simple cache, it returns an error wrapping ErrCacheFull when setting a new key once the size is reached.
+func NewCopyOnWriteCache[K comparable, V any](size int32) Cache[K, V] {
+	if size <= 0 {
+		size = defaultSize
+	}
+
+	c := &cow[K, V]{size: size, mx: &sync.Mutex{}, stats: newCounters(systemClock{})}
+	data := make(map[K]V)
+	c.data.Store(&data)
+	return c
+}
+
+// Set stores the value in a new snapshot of the entries.
+// If the key is new and the cache is full, it returns an error wrapping ErrCacheFull.
+// This method is thread-safe.
+func (c *cow[K, V]) Set(key K, value V) error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	old := *c.data.Load()
+	if _, ok := old[key]; !ok && int32(len(old)) >= c.size {
+		c.stats.rejections.Add(1)
+		return cacheFull(key)
+	}
+
+	data := make(map[K]V, len(old)+1)
+	for k, v := range old {
+		data[k] = v
+	}
+	data[key] = value
+
+	c.data.Store(&data)
+	c.stats.sets.Add(1)
+	return nil
+}
+
+// Get retrieves the value from the current snapshot of the entries, without locking.
+// If the key is not found, the zero value of the value type and an error will be returned.
+// This method is thread-safe.
+func (c *cow[K, V]) Get(key K) (V, error) {
+	v, ok := c.Lookup(key)
+	if !ok {
+		return v, notFound(key)
+	}
+
+	return v, nil
+}
+
+// Lookup retrieves the value just like Get, but reports a miss with false instead of building an error.
+// This method is thread-safe.
+func (c *cow[K, V]) Lookup(key K) (V, bool) {
+	v, ok := (*c.data.Load())[key]
+	c.stats.lookup(ok)
+	return v, ok
+}
+
+// Delete removes the key in a new snapshot of the entries.
+// If the key is not found, an error will be returned.
+// This method is thread-safe.
+func (c *cow[K, V]) Delete(key K) error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	old := *c.data.Load()
+	if _, ok := old[key]; !ok {
+		return notFound(key)
+	}
+
+	data := make(map[K]V, len(old))
+	for k, v := range old {
+		if k != key {
+			data[k] = v
+		}
+	}
+
+	c.data.Store(&data)
+	c.stats.deletes.Add(1)
+	return nil
+}
+
+// Clear replaces the entries with an empty snapshot.
+// This method is thread-safe.
+func (c *cow[K, V]) Clear() error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	data := make(map[K]V)
+	c.data.Store(&data)
+	return nil
+}
+
+// Stats returns a snapshot of the cache counters.
+// This method is thread-safe.
+func (c *cow[K, V]) Stats() Stats {
+	return c.stats.snapshot(int32(len(*c.data.Load())))
+}
+
+// Len returns the number of entries in the current snapshot.
+// This method is thread-safe.
+func (c *cow[K, V]) Len() int {
+	return len(*c.data.Load())
+}
+
+// Has reports whether the key is in the current snapshot, without counting as an access.
+// This method is thread-safe.
+func (c *cow[K, V]) Has(key K) bool {
+	_, ok := (*c.data.Load())[key]
+	return ok
+}
+
+// Keys returns the keys of the current snapshot, in no particular order.
+// This method is thread-safe.
+func (c *cow[K, V]) Keys() []K {
+	data := *c.data.Load()
+	keys := make([]K, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// Close does nothing, since the cache starts no background work. It always returns nil.
+func (c *cow[K, V]) Close() error {
+	return nil
+}
@@ -0,0 +1,69 @@
+package cachego
+
+import (
+	"sync"
+	"testing"
+)
+
+// nolint:errcheck
+func TestCopyOnWriteCache(t *testing.T) {
+	c := NewCopyOnWriteCache[string, bool](2)
+
+	if err := c.Set("a", true); err != nil {
+		t.Errorf("Set returned error: %s", err)
+	}
+	c.Set("b", false)
+
+	// Set (full)
+	if err := c.Set("c", true); err == nil {
+		t.Errorf("Set returned nil error when cache is full")
+	}
+
+	// Set (update when full)
+	if err := c.Set("b", true); err != nil {
+		t.Errorf("Set returned error when updating a key: %s", err)
+	}
+
+	if v, err := c.Get("b"); err != nil || !v {
+		t.Errorf("expected true, got %v (%v)", v, err)
+	}
+
+	if err := c.Delete("a"); err != nil {
+		t.Errorf("Delete returned error: %s", err)
+	}
+	if _, err := c.Get("a"); err == nil {
+		t.Errorf("Get returned nil error after Delete")
+	}
+
+	c.Clear()
+	if stats := c.(StatsProvider).Stats(); stats.Size != 0 || stats.Sets != 3 || stats.Deletes != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+// nolint:errcheck
+func TestCopyOnWriteCacheConcurrent(t *testing.T) {
+	c := NewCopyOnWriteCache[int, int](100)
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				c.Set(g*25+i, i)
+			}
+		}(g)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				c.Get(i % 100)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if size := c.(StatsProvider).Stats().Size; size != 100 {
+		t.Errorf("expected 100 entries, got %v", size)
+	}
+}
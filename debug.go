@@ -0,0 +1,102 @@
+package cachego
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+const defaultDebugValueLen = 64
+
+// DebugOpts configures a debug dump.
+type DebugOpts struct {
+	// MaxValueLen truncates the printed values to the given number of bytes. Defaults to 64.
+	// If negative, values are not truncated.
+	MaxValueLen int
+	// MaxEntries limits the number of printed entries. If less than or equal to zero, every entry is printed.
+	MaxEntries int
+}
+
+// Debugger is implemented by caches that can print their internal state for debugging.
+// Dumps are only produced on an explicit call, and values may be truncated,
+// so they are safe to take from a production process during an incident.
+type Debugger interface {
+	// DebugDump writes the counters and the entries of the cache, with their metadata,
+	// in the order the cache would drop them (i.e. recency order for the LRU cache, expiry order for the simple cache).
+	DebugDump(w io.Writer, opts DebugOpts) error
+	// DebugString returns the debug dump with the default options.
+	DebugString() string
+}
+
+// debugState is a copy of the state of a cache, taken under its lock and printed after it is released.
+type debugState struct {
+	kind    string
+	order   string
+	used    int32
+	size    int32
+	ttl     int16
+	now     time.Time // of the cache clock
+	stats   Stats
+	entries []debugEntry
+}
+
+type debugEntry struct {
+	key   any
+	value any
+	info  EntryInfo
+}
+
+func (d debugState) write(w io.Writer, opts DebugOpts) error {
+	if opts.MaxValueLen == 0 {
+		opts.MaxValueLen = defaultDebugValueLen
+	}
+
+	now := d.now
+	b := &bytes.Buffer{}
+
+	fmt.Fprintf(b, "%s cache: %d/%d entries", d.kind, d.used, d.size)
+	if d.ttl > 0 {
+		fmt.Fprintf(b, ", ttl %v", time.Duration(d.ttl)*time.Second)
+	}
+	s := d.stats
+	fmt.Fprintf(b, "\nstats: hits=%d misses=%d hit_ratio=%.2f sets=%d deletes=%d evictions=%d expirations=%d uptime=%v\n",
+		s.Hits, s.Misses, s.HitRatio(), s.Sets, s.Deletes, s.Evictions, s.Expirations, s.Uptime.Round(time.Millisecond))
+
+	fmt.Fprintf(b, "entries (%s):\n", d.order)
+	for i, e := range d.entries {
+		if opts.MaxEntries > 0 && i >= opts.MaxEntries {
+			fmt.Fprintf(b, "  ... %d more\n", len(d.entries)-i)
+			break
+		}
+
+		value := fmt.Sprintf("%#v", e.value)
+		if opts.MaxValueLen > 0 && len(value) > opts.MaxValueLen {
+			value = value[:opts.MaxValueLen] + "..."
+		}
+
+		fmt.Fprintf(b, "  %v => %s (age %v, accesses %d", e.key, value, now.Sub(e.info.Created).Round(time.Millisecond), e.info.Accesses)
+		if !e.info.LastAccess.IsZero() {
+			fmt.Fprintf(b, ", last access %v ago", now.Sub(e.info.LastAccess).Round(time.Millisecond))
+		}
+		if !e.info.Expires.IsZero() {
+			fmt.Fprintf(b, ", expires in %v", e.info.Expires.Sub(now).Round(time.Millisecond))
+		}
+		b.WriteString(")\n")
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// sortByExpiry orders the entries by pending expiration, then by age.
+func sortByExpiry(entries []debugEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].info, entries[j].info
+		if !a.Expires.Equal(b.Expires) {
+			return a.Expires.Before(b.Expires)
+		}
+		return a.Created.Before(b.Created)
+	})
+}
@@ -0,0 +1,58 @@
+package cachego
+
+import (
+	"strings"
+	"testing"
+)
+
+// nolint:errcheck
+func TestDebugString(t *testing.T) {
+	cache := NewLRUCache[int, string](3)
+	cache.Set(1, "one")
+	cache.Set(2, strings.Repeat("a", 100))
+	cache.Get(1)
+
+	s := cache.(Debugger).DebugString()
+
+	if !strings.HasPrefix(s, "lru cache: 2/3 entries\nstats: hits=1 misses=0 hit_ratio=1.00 sets=2") {
+		t.Errorf("unexpected header:\n%s", s)
+	}
+
+	// recency order
+	one, two := strings.Index(s, `1 => "one"`), strings.Index(s, `2 => "aaa`)
+	if one < 0 || two < 0 || one > two {
+		t.Errorf("expected key 1 before key 2:\n%s", s)
+	}
+
+	// truncated values
+	if strings.Contains(s, strings.Repeat("a", 64)) || !strings.Contains(s, "a...") {
+		t.Errorf("expected the long value to be truncated:\n%s", s)
+	}
+}
+
+// nolint:errcheck
+func TestDebugDump(t *testing.T) {
+	cache := NewCache[string, int](Opts{Size: 3, TTL: 60})
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+
+	b := &strings.Builder{}
+	if err := cache.(Debugger).DebugDump(b, DebugOpts{MaxEntries: 2}); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	s := b.String()
+
+	if !strings.HasPrefix(s, "simple cache: 3/3 entries, ttl 1m0s\n") {
+		t.Errorf("unexpected header:\n%s", s)
+	}
+
+	// pending expirations, the earliest first
+	if !strings.Contains(s, "entries (by expiry):\n  a => 1 (age") || !strings.Contains(s, "expires in") {
+		t.Errorf("expected a to expire first:\n%s", s)
+	}
+
+	if !strings.HasSuffix(s, "  ... 1 more\n") {
+		t.Errorf("expected the entries to be limited to 2:\n%s", s)
+	}
+}
@@ -0,0 +1,86 @@
+package cachego
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+)
+
+var (
+	ageBounds  = []time.Duration{time.Second, 10 * time.Second, time.Minute, 10 * time.Minute, time.Hour, 6 * time.Hour, 24 * time.Hour}
+	sizeBounds = []int{64, 256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20}
+)
+
+// AgeBucket counts the entries older than the previous bucket's bound and at most Le old.
+// The last bucket has no upper bound: its Le is math.MaxInt64.
+type AgeBucket struct {
+	Le    time.Duration
+	Count int
+}
+
+// SizeBucket counts the entries larger than the previous bucket's bound and at most Le bytes large.
+// The last bucket has no upper bound: its Le is math.MaxInt.
+type SizeBucket struct {
+	Le    int
+	Count int
+}
+
+// Distribution describes how the entries of a cache are distributed by age and serialized size.
+type Distribution struct {
+	// Ages are the entries by time since they were added to the cache.
+	Ages []AgeBucket
+	// Sizes are the entries by the size of their value encoded as JSON, as it is persisted.
+	Sizes []SizeBucket
+	// Bytes is the total size of the encoded values.
+	Bytes int
+}
+
+// DistributionProvider is implemented by caches that can describe the distribution of their entries.
+type DistributionProvider interface {
+	// Distribution returns the current distribution of the entries by age and serialized size.
+	// It encodes every value, so it is meant for occasional inspection rather than frequent polling.
+	Distribution() Distribution
+}
+
+func newDistribution() Distribution {
+	d := Distribution{
+		Ages:  make([]AgeBucket, len(ageBounds)+1),
+		Sizes: make([]SizeBucket, len(sizeBounds)+1),
+	}
+
+	for i, b := range ageBounds {
+		d.Ages[i].Le = b
+	}
+	d.Ages[len(ageBounds)].Le = math.MaxInt64
+
+	for i, b := range sizeBounds {
+		d.Sizes[i].Le = b
+	}
+	d.Sizes[len(sizeBounds)].Le = math.MaxInt
+
+	return d
+}
+
+// add counts an entry of the given age holding the given value.
+// Values that cannot be encoded are counted by their estimated size instead.
+func (d *Distribution) add(age time.Duration, value any) {
+	size := estimateSize(value)
+	if b, err := json.Marshal(value); err == nil {
+		size = len(b)
+	}
+	d.Bytes += size
+
+	for i := range d.Ages {
+		if age <= d.Ages[i].Le {
+			d.Ages[i].Count++
+			break
+		}
+	}
+
+	for i := range d.Sizes {
+		if size <= d.Sizes[i].Le {
+			d.Sizes[i].Count++
+			break
+		}
+	}
+}
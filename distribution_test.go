@@ -0,0 +1,38 @@
+package cachego
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// nolint:errcheck
+func TestDistribution(t *testing.T) {
+	for name, cache := range map[string]Cache[int, string]{
+		"simple": NewCache[int, string](Opts{Size: 10}),
+		"lru":    NewLRUCache[int, string](10),
+	} {
+		cache.Set(1, "a")
+		cache.Set(2, strings.Repeat("a", 100))
+		cache.Set(3, strings.Repeat("a", 1000))
+
+		d := cache.(DistributionProvider).Distribution()
+
+		// the values are encoded with their quotes
+		if d.Bytes != 3+102+1002 {
+			t.Errorf("%s: expected 1107 bytes, got %v", name, d.Bytes)
+		}
+
+		if d.Ages[0].Le != time.Second || d.Ages[0].Count != 3 {
+			t.Errorf("%s: expected 3 entries up to 1s old, got %+v", name, d.Ages[0])
+		}
+
+		counts := map[int]int{}
+		for _, b := range d.Sizes {
+			counts[b.Le] = b.Count
+		}
+		if counts[64] != 1 || counts[256] != 1 || counts[1024] != 1 {
+			t.Errorf("%s: expected one entry per bucket, got %+v", name, d.Sizes)
+		}
+	}
+}
@@ -0,0 +1,431 @@
+// Package dnscache resolves host names with a cache of the answers, so dialers re-resolving the same hosts
+// don't query the DNS servers every time.
+//
+// The answers expire after the TTL of their DNS records, bounded by Opts.MinTTL and Opts.MaxTTL, and the hosts
+// not found after the negative TTL of the SOA record of their zone (RFC 2308). The lookups are made by the pure Go
+// resolver of net, which reads the TTLs from the DNS responses it receives, so the system configuration
+// (resolv.conf and hosts file) is followed. Answers read from the hosts file have no TTL, and expire after MinTTL.
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/noam-g4/cachego"
+	"github.com/noam-g4/cachego/singleflight"
+)
+
+// Answer is the resolution of a host stored in the cache.
+type Answer struct {
+	// Addrs are the addresses of the host, IPv4 and IPv6.
+	Addrs []net.IPAddr
+	// NotFound is set if the host doesn't exist, or has no addresses.
+	NotFound bool
+	// Expires is the time the answer expires at.
+	Expires time.Time
+}
+
+// Opts configures a Resolver.
+type Opts struct {
+	// MinTTL and MaxTTL bound the TTLs of the answers. Default to 5 seconds and an hour.
+	MinTTL time.Duration
+	MaxTTL time.Duration
+	// Dial dials the DNS servers, e.g. to query other servers than the ones of resolv.conf. Defaults to net.Dialer.
+	Dial func(ctx context.Context, network, address string) (net.Conn, error)
+	// Dialer dials the addresses resolved by Resolver.DialContext. Defaults to a zero net.Dialer.
+	Dialer *net.Dialer
+	// Clock tells the time the answers are stored and expire at. Defaults to the system clock.
+	Clock cachego.Clock
+	// Logger receives the errors of the cache, which are otherwise served as misses. Defaults to discarding them.
+	Logger cachego.Logger
+}
+
+// Resolver looks up host names like net.Resolver, serving the answers from the cache until they expire.
+// If the cache implements cachego.TTLSetter, the answers are stored with their TTL, so they are removed
+// once they expire. It is thread-safe.
+type Resolver struct {
+	c        cachego.Cache[string, Answer]
+	opts     Opts
+	resolver *net.Resolver
+	flight   *singleflight.Group[string, Answer]
+}
+
+// New creates a resolver caching the answers in the cache, under the host names.
+func New(c cachego.Cache[string, Answer], opts Opts) *Resolver {
+	if opts.MinTTL <= 0 {
+		opts.MinTTL = 5 * time.Second
+	}
+	if opts.MaxTTL < opts.MinTTL {
+		opts.MaxTTL = time.Hour
+		if opts.MaxTTL < opts.MinTTL {
+			opts.MaxTTL = opts.MinTTL
+		}
+	}
+	if opts.Dial == nil {
+		opts.Dial = (&net.Dialer{}).DialContext
+	}
+	if opts.Dialer == nil {
+		opts.Dialer = &net.Dialer{}
+	}
+	if opts.Logger == nil {
+		opts.Logger = nopLogger{}
+	}
+
+	r := &Resolver{c: c, opts: opts, flight: &singleflight.Group[string, Answer]{}}
+	r.resolver = &net.Resolver{PreferGo: true, Dial: r.dial}
+	return r
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
+
+// LookupIPAddr looks up the IPv4 and IPv6 addresses of the host, as net.Resolver.LookupIPAddr.
+func (r *Resolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return []net.IPAddr{{IP: ip.AsSlice(), Zone: ip.Zone()}}, nil
+	}
+
+	key := strings.ToLower(strings.TrimSuffix(host, "."))
+	a, err := r.answer(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if a.NotFound {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	return append([]net.IPAddr(nil), a.Addrs...), nil
+}
+
+// LookupIP looks up the addresses of the host for the network, "ip", "ip4" or "ip6", as net.Resolver.LookupIP.
+func (r *Resolver) LookupIP(ctx context.Context, network, host string) ([]net.IP, error) {
+	if network != "ip" && network != "ip4" && network != "ip6" {
+		return nil, net.UnknownNetworkError(network)
+	}
+
+	addrs, err := r.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []net.IP
+	for _, a := range filter(addrs, network) {
+		ips = append(ips, a.IP)
+	}
+	if len(ips) == 0 {
+		return nil, &net.DNSError{Err: "no suitable address found", Name: host}
+	}
+
+	return ips, nil
+}
+
+// LookupNetIP looks up the addresses of the host for the network, "ip", "ip4" or "ip6", as net.Resolver.LookupNetIP.
+func (r *Resolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	ips, err := r.LookupIP(ctx, network, host)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]netip.Addr, 0, len(ips))
+	for _, ip := range ips {
+		if a, ok := netip.AddrFromSlice(ip); ok {
+			addrs = append(addrs, a)
+		}
+	}
+
+	return addrs, nil
+}
+
+// LookupHost looks up the addresses of the host, as net.Resolver.LookupHost.
+func (r *Resolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	addrs, err := r.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := make([]string, len(addrs))
+	for i, a := range addrs {
+		hosts[i] = a.String()
+	}
+
+	return hosts, nil
+}
+
+// DialContext connects to the address on the named network, as net.Dialer.DialContext, resolving its host
+// with the cache. The addresses of the host are tried in turn, until one accepts the connection.
+// It may be used as the DialContext of an http.Transport.
+func (r *Resolver) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := netip.ParseAddr(host); err == nil || host == "" {
+		return r.opts.Dialer.DialContext(ctx, network, address)
+	}
+
+	addrs, err := r.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var ipNetwork string
+	switch {
+	case strings.HasSuffix(network, "4"):
+		ipNetwork = "ip4"
+	case strings.HasSuffix(network, "6"):
+		ipNetwork = "ip6"
+	default:
+		ipNetwork = "ip"
+	}
+
+	errs := []error{}
+	for _, a := range filter(addrs, ipNetwork) {
+		conn, err := r.opts.Dialer.DialContext(ctx, network, net.JoinHostPort(a.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(errs) == 0 {
+		return nil, &net.DNSError{Err: "no suitable address found", Name: host}
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+func filter(addrs []net.IPAddr, network string) []net.IPAddr {
+	if network == "ip" {
+		return addrs
+	}
+
+	var filtered []net.IPAddr
+	for _, a := range addrs {
+		if (a.IP.To4() != nil) == (network == "ip4") {
+			filtered = append(filtered, a)
+		}
+	}
+
+	return filtered
+}
+
+// answer returns the answer cached for the host if it didn't expire, and resolves it otherwise.
+// Concurrent misses of a host share a single lookup.
+func (r *Resolver) answer(ctx context.Context, host string) (Answer, error) {
+	a, err := cachego.GetCtx(ctx, r.c, host)
+	if err == nil && r.now().Before(a.Expires) {
+		return a, nil
+	}
+	if err != nil && !errors.Is(err, cachego.ErrNotFound) {
+		if ctx.Err() != nil {
+			return Answer{}, err
+		}
+		r.opts.Logger.Printf("dnscache: getting %q failed: %v", host, err)
+	}
+
+	a, err, _ = r.flight.Do(host, func() (Answer, error) {
+		return r.resolve(ctx, host)
+	})
+	return a, err
+}
+
+// resolve looks up the host, and caches the answer for the TTL of its records.
+func (r *Resolver) resolve(ctx context.Context, host string) (Answer, error) {
+	rec := &recorder{ttl: -1, negative: -1}
+	addrs, err := r.resolver.LookupIPAddr(context.WithValue(ctx, recorderKey{}, rec), host)
+
+	var a Answer
+	ttl := rec.answerTTL()
+	var dnsErr *net.DNSError
+	switch {
+	case err == nil:
+		a.Addrs = addrs
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		a.NotFound = true
+		ttl = rec.negativeTTL()
+	default:
+		return Answer{}, err
+	}
+
+	if ttl < r.opts.MinTTL {
+		ttl = r.opts.MinTTL
+	}
+	if ttl > r.opts.MaxTTL {
+		ttl = r.opts.MaxTTL
+	}
+	a.Expires = r.now().Add(ttl)
+
+	if s, ok := r.c.(cachego.TTLSetter[string, Answer]); ok {
+		err = s.SetWithTTL(host, a, ttl)
+	} else {
+		err = cachego.SetCtx(ctx, r.c, host, a)
+	}
+	if err != nil {
+		r.opts.Logger.Printf("dnscache: storing %q failed: %v", host, err)
+	}
+
+	return a, nil
+}
+
+func (r *Resolver) now() time.Time {
+	if r.opts.Clock == nil {
+		return time.Now()
+	}
+
+	return r.opts.Clock.Now()
+}
+
+// dial dials a DNS server for the lookups, recording the TTLs of the responses read from it.
+func (r *Resolver) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := r.opts.Dial(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	rec, ok := ctx.Value(recorderKey{}).(*recorder)
+	if !ok {
+		return conn, nil
+	}
+
+	c := &recordingConn{Conn: conn, rec: rec}
+	// the resolver frames the messages on the packet connections only
+	if pc, ok := conn.(net.PacketConn); ok {
+		return &recordingPacketConn{recordingConn: c, pc: pc}, nil
+	}
+	c.stream = true
+	return c, nil
+}
+
+type recorderKey struct{}
+
+// recorder records the TTLs of the DNS responses of a lookup: the lowest TTL of the records answered,
+// and the lowest negative TTL of the responses without records.
+type recorder struct {
+	mx       sync.Mutex
+	ttl      time.Duration
+	negative time.Duration
+}
+
+func (rec *recorder) observe(msg []byte) {
+	var p dnsmessage.Parser
+	h, err := p.Start(msg)
+	if err != nil || !h.Response {
+		return
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return
+	}
+
+	answers, err := p.AllAnswers()
+	if err != nil {
+		return
+	}
+
+	ttl, negative := time.Duration(-1), time.Duration(-1)
+	for _, a := range answers {
+		switch a.Header.Type {
+		case dnsmessage.TypeA, dnsmessage.TypeAAAA, dnsmessage.TypeCNAME:
+			if d := time.Duration(a.Header.TTL) * time.Second; ttl < 0 || d < ttl {
+				ttl = d
+			}
+		}
+	}
+	if ttl < 0 && (h.RCode == dnsmessage.RCodeSuccess || h.RCode == dnsmessage.RCodeNameError) {
+		if authorities, err := p.AllAuthorities(); err == nil {
+			for _, a := range authorities {
+				if soa, ok := a.Body.(*dnsmessage.SOAResource); ok {
+					negative = time.Duration(soa.MinTTL) * time.Second
+					if d := time.Duration(a.Header.TTL) * time.Second; d < negative {
+						negative = d
+					}
+				}
+			}
+		}
+	}
+
+	rec.mx.Lock()
+	defer rec.mx.Unlock()
+	if ttl >= 0 && (rec.ttl < 0 || ttl < rec.ttl) {
+		rec.ttl = ttl
+	}
+	if negative >= 0 && (rec.negative < 0 || negative < rec.negative) {
+		rec.negative = negative
+	}
+}
+
+func (rec *recorder) answerTTL() time.Duration {
+	rec.mx.Lock()
+	defer rec.mx.Unlock()
+
+	return rec.ttl
+}
+
+func (rec *recorder) negativeTTL() time.Duration {
+	rec.mx.Lock()
+	defer rec.mx.Unlock()
+
+	return rec.negative
+}
+
+// recordingConn records the responses read from a DNS server, either as packets or as a stream of messages
+// prefixed with their length (RFC 1035 4.2.2).
+type recordingConn struct {
+	net.Conn
+	rec    *recorder
+	stream bool
+	buf    []byte
+}
+
+func (c *recordingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.record(b[:n])
+	}
+
+	return n, err
+}
+
+func (c *recordingConn) record(b []byte) {
+	if !c.stream {
+		c.rec.observe(b)
+		return
+	}
+
+	c.buf = append(c.buf, b...)
+	for len(c.buf) >= 2 {
+		size := int(c.buf[0])<<8 | int(c.buf[1])
+		if len(c.buf) < 2+size {
+			return
+		}
+		c.rec.observe(c.buf[2 : 2+size])
+		c.buf = c.buf[2+size:]
+	}
+}
+
+type recordingPacketConn struct {
+	*recordingConn
+	pc net.PacketConn
+}
+
+func (c *recordingPacketConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	n, addr, err := c.pc.ReadFrom(b)
+	if n > 0 {
+		c.record(b[:n])
+	}
+
+	return n, addr, err
+}
+
+func (c *recordingPacketConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	return c.pc.WriteTo(b, addr)
+}
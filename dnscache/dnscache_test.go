@@ -0,0 +1,260 @@
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/noam-g4/cachego"
+)
+
+type fakeClock struct {
+	mx  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) add(d time.Duration) {
+	c.mx.Lock()
+	c.now = c.now.Add(d)
+	c.mx.Unlock()
+}
+
+func (c *fakeClock) NewTimer(time.Duration) cachego.Timer { panic("not used") }
+
+// plain hides the TTLSetter of the cache, so the expiration is checked on reads only.
+type plain struct {
+	cachego.Cache[string, Answer]
+}
+
+type zone struct {
+	a   []net.IP
+	ttl uint32
+}
+
+// server is a DNS server answering the A queries of its zones, and NXDOMAIN for the other names,
+// with an SOA record with a negative TTL of a minute.
+type server struct {
+	conn    net.PacketConn
+	zones   map[string]zone
+	queries atomic.Int32
+}
+
+func newServer(t *testing.T, zones map[string]zone) *server {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	s := &server{conn: conn, zones: zones}
+	go s.serve()
+	return s
+}
+
+func (s *server) serve() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if res, err := s.respond(buf[:n]); err == nil {
+			s.conn.WriteTo(res, addr) // nolint:errcheck
+		}
+	}
+}
+
+func (s *server) respond(req []byte) ([]byte, error) {
+	var p dnsmessage.Parser
+	h, err := p.Start(req)
+	if err != nil {
+		return nil, err
+	}
+	q, err := p.Question()
+	if err != nil {
+		return nil, err
+	}
+	s.queries.Add(1)
+
+	z, ok := s.zones[strings.ToLower(q.Name.String())]
+	rh := dnsmessage.Header{ID: h.ID, Response: true, Authoritative: true, RecursionAvailable: true}
+	if !ok {
+		rh.RCode = dnsmessage.RCodeNameError
+	}
+
+	b := dnsmessage.NewBuilder(nil, rh)
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(q); err != nil {
+		return nil, err
+	}
+	if err := b.StartAnswers(); err != nil {
+		return nil, err
+	}
+
+	answered := false
+	if q.Type == dnsmessage.TypeA {
+		for _, ip := range z.a {
+			var a dnsmessage.AResource
+			copy(a.A[:], ip.To4())
+			if err := b.AResource(dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: z.ttl}, a); err != nil {
+				return nil, err
+			}
+			answered = true
+		}
+	}
+	if !answered {
+		if err := b.StartAuthorities(); err != nil {
+			return nil, err
+		}
+		soa := dnsmessage.SOAResource{
+			NS:     dnsmessage.MustNewName("ns.test."),
+			MBox:   dnsmessage.MustNewName("admin.test."),
+			MinTTL: 60,
+		}
+		if err := b.SOAResource(dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName("test."), Class: dnsmessage.ClassINET, TTL: 300}, soa); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.Finish()
+}
+
+func (s *server) dial(ctx context.Context, network, _ string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "udp", s.conn.LocalAddr().String())
+}
+
+func TestResolver(t *testing.T) {
+	s := newServer(t, map[string]zone{
+		"short.test.": {a: []net.IP{net.IPv4(10, 0, 0, 1)}, ttl: 1},
+		"long.test.":  {a: []net.IP{net.IPv4(10, 0, 0, 2), net.IPv4(10, 0, 0, 3)}, ttl: 30},
+	})
+	clock := &fakeClock{now: time.Now()}
+	r := New(plain{cachego.NewCache[string, Answer](cachego.Opts{Size: 10})}, Opts{Dial: s.dial, Clock: clock})
+	ctx := context.Background()
+
+	lookup := func(host string, expected string) {
+		t.Helper()
+		addrs, err := r.LookupHost(ctx, host)
+		if got := strings.Join(addrs, " "); err != nil || got != expected {
+			t.Errorf("%v: expected %q, got %q (%v)", host, expected, got, err)
+		}
+	}
+
+	lookup("long.test.", "10.0.0.2 10.0.0.3")
+	queries := s.queries.Load()
+	if queries == 0 {
+		t.Fatalf("expected the server to be queried")
+	}
+	lookup("LONG.test", "10.0.0.2 10.0.0.3")
+	lookup("short.test.", "10.0.0.1")
+	if n := s.queries.Load(); n != 2*queries {
+		t.Errorf("expected %v queries, got %v", 2*queries, n)
+	}
+
+	// the TTL of short.test is raised to the MinTTL
+	clock.add(4 * time.Second)
+	lookup("short.test.", "10.0.0.1")
+	if n := s.queries.Load(); n != 2*queries {
+		t.Errorf("expected %v queries, got %v", 2*queries, n)
+	}
+	clock.add(27 * time.Second)
+	lookup("short.test.", "10.0.0.1")
+	lookup("long.test.", "10.0.0.2 10.0.0.3")
+	if n := s.queries.Load(); n != 4*queries {
+		t.Errorf("expected %v queries, got %v", 4*queries, n)
+	}
+
+	// unknown hosts are cached for the negative TTL
+	var dnsErr *net.DNSError
+	if _, err := r.LookupIPAddr(ctx, "missing.test."); !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	queries = s.queries.Load()
+	clock.add(59 * time.Second)
+	if _, err := r.LookupIPAddr(ctx, "missing.test."); !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if n := s.queries.Load(); n != queries {
+		t.Errorf("expected %v queries, got %v", queries, n)
+	}
+
+	// IP addresses are not looked up
+	lookup("192.0.2.1", "192.0.2.1")
+	if n := s.queries.Load(); n != queries {
+		t.Errorf("expected %v queries, got %v", queries, n)
+	}
+
+	if _, err := r.LookupIP(ctx, "ip6", "long.test."); err == nil {
+		t.Errorf("expected an error for a host without IPv6 addresses")
+	}
+	if ips, err := r.LookupNetIP(ctx, "ip4", "long.test."); err != nil || len(ips) != 2 || ips[0].String() != "10.0.0.2" {
+		t.Errorf("expected %v, got %v (%v)", "[10.0.0.2 10.0.0.3]", ips, err)
+	}
+}
+
+func TestResolverTTLSetter(t *testing.T) {
+	s := newServer(t, map[string]zone{"host.test.": {a: []net.IP{net.IPv4(10, 0, 0, 1)}, ttl: 7200}})
+	c := cachego.NewLRUCache[string, Answer](10)
+	r := New(c, Opts{Dial: s.dial, MaxTTL: time.Minute})
+
+	start := time.Now()
+	if _, err := r.LookupIPAddr(context.Background(), "host.test."); err != nil {
+		t.Fatal(err)
+	}
+
+	// the TTL is lowered to the MaxTTL
+	_, info, err := c.(cachego.Inspector[string, Answer]).GetWithInfo("host.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl := info.Expires.Sub(start); ttl < time.Minute || ttl > time.Minute+time.Second {
+		t.Errorf("expected the entry to expire in %v, got %v", time.Minute, ttl)
+	}
+}
+
+func TestDialContext(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	s := newServer(t, map[string]zone{"local.test.": {a: []net.IP{net.IPv4(127, 0, 0, 1)}, ttl: 60}})
+	r := New(cachego.NewCache[string, Answer](cachego.Opts{Size: 10}), Opts{Dial: s.dial})
+
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	conn, err := r.DialContext(context.Background(), "tcp", net.JoinHostPort("local.test.", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	if _, err := r.DialContext(context.Background(), "tcp6", net.JoinHostPort("local.test.", port)); err == nil {
+		t.Errorf("expected an error for a host without IPv6 addresses")
+	}
+}
@@ -0,0 +1,52 @@
+package cachego
+
+import "time"
+
+// DoorkeeperOpts configures a bloom filter in front of the insertions of the LRU cache: a new key is only stored
+// the second time it is set within a window, so the keys requested once (one-hit wonders, common in CDN-like
+// workloads) don't evict the entries in use. The first Set of a key returns nil without storing it,
+// and is counted in Stats.Rejections. The keys already cached are always updated.
+type DoorkeeperOpts struct {
+	// Window is the period within which a key must be set twice to be stored.
+	// If less than or equal to zero, the new keys are stored on their first Set.
+	Window time.Duration
+	// Keys is the number of distinct keys the filter remembers, with a false positive rate of 1%.
+	// The filter is also emptied once it holds that many keys. Defaults to ten times the size of the cache,
+	// and at least 1000, since most of the keys set once are not cached.
+	Keys int
+}
+
+// doorkeeper remembers the keys set once. Its methods must be called with the lock of the cache held.
+type doorkeeper struct {
+	window time.Duration
+	filter *bloom
+	start  time.Time
+}
+
+func newDoorkeeper(opts DoorkeeperOpts, size int32) *doorkeeper {
+	if opts.Window <= 0 {
+		return nil
+	}
+	if opts.Keys <= 0 {
+		opts.Keys = int(size) * 10
+		if opts.Keys < 1000 {
+			opts.Keys = 1000
+		}
+	}
+
+	return &doorkeeper{window: opts.Window, filter: newBloom(opts.Keys, 0.01)}
+}
+
+// admit reports whether a new key of the given hash was seen within the window, remembering it otherwise.
+func (d *doorkeeper) admit(sum uint64, now time.Time) bool {
+	if d == nil {
+		return true
+	}
+
+	if now.Sub(d.start) >= d.window || d.filter.full() {
+		d.filter.reset()
+		d.start = now
+	}
+
+	return d.filter.add(sum)
+}
@@ -0,0 +1,85 @@
+package cachego
+
+import (
+	"testing"
+	"time"
+)
+
+// nolint:errcheck
+func TestDoorkeeper(t *testing.T) {
+	clock := newFakeClock()
+	c := NewLRUCacheWithOpts[int, int](Opts{Size: 3, Clock: clock, Doorkeeper: DoorkeeperOpts{Window: time.Minute}})
+	has := c.(ExtendedCache[int, int]).Has
+
+	for i := 0; i < 3; i++ {
+		c.Set(i, i)
+		c.Set(i, i)
+	}
+
+	// one-hit wonders don't evict the cached keys
+	for i := 100; i < 200; i++ {
+		if err := c.Set(i, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if !has(i) {
+			t.Errorf("expected %v to be cached", i)
+		}
+	}
+	if stats := c.(StatsProvider).Stats(); stats.Rejections != 103 {
+		t.Errorf("expected 103 rejections, got %v", stats.Rejections)
+	}
+
+	// updates are stored right away
+	c.Set(0, 10)
+	if v, _ := c.Get(0); v != 10 {
+		t.Errorf("expected 10, got %v", v)
+	}
+
+	// a key set twice within the window is stored
+	c.Set(300, 300)
+	c.Set(300, 300)
+	if !has(300) {
+		t.Errorf("expected 300 to be cached")
+	}
+
+	// but not once the window passed
+	c.Set(400, 400)
+	clock.Advance(time.Minute)
+	c.Set(400, 400)
+	if has(400) {
+		t.Errorf("expected 400 not to be cached")
+	}
+}
+
+func TestBloom(t *testing.T) {
+	b := newBloom(1000, 0.01)
+	for i := uint64(0); i < 1000; i++ {
+		if b.add(hashKey(i)) && i < 10 {
+			t.Errorf("expected %v not to be in the filter", i)
+		}
+	}
+	// the keys taken for ones added before aren't counted
+	if b.added < 980 {
+		t.Errorf("expected about 1000 keys added, got %v", b.added)
+	}
+
+	fp := 0
+	for i := uint64(0); i < 10000; i++ {
+		if i < 1000 && !b.has(hashKey(i)) {
+			t.Fatalf("expected %v to be in the filter", i)
+		}
+		if i >= 1000 && b.has(hashKey(i)) {
+			fp++
+		}
+	}
+	if fp > 9000*2/100 {
+		t.Errorf("expected a false positive rate about 1%%, got %v out of 9000", fp)
+	}
+
+	b.reset()
+	if b.has(hashKey(uint64(1))) || b.added != 0 {
+		t.Errorf("expected an empty filter")
+	}
+}
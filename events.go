@@ -0,0 +1,99 @@
+package cachego
+
+import "time"
+
+// EventType is the kind of mutation an Event describes.
+type EventType int
+
+const (
+	// EventSet is emitted when a value is stored.
+	EventSet EventType = iota
+	// EventDelete is emitted when a key is removed with Delete.
+	EventDelete
+	// EventEvict is emitted when an entry is evicted for capacity or removed with Clear.
+	EventEvict
+	// EventExpire is emitted when an entry's ttl lapses.
+	EventExpire
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventSet:
+		return "set"
+	case EventDelete:
+		return "delete"
+	case EventEvict:
+		return "evict"
+	case EventExpire:
+		return "expire"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes a single mutation of a cache.
+type Event[K comparable, V any] struct {
+	Type  EventType
+	Key   K
+	Value V
+	// Reason is the reason the entry was removed. It is only meaningful for removal events.
+	Reason Reason
+	Time   time.Time
+}
+
+// EventSource is implemented by caches that can stream their mutations.
+type EventSource[K comparable, V any] interface {
+	// Events returns the channel the cache mutations are delivered on.
+	// The channel is buffered; when the buffer is full, new events are dropped rather than blocking the cache.
+	// If the cache was not configured to emit events, the returned channel is nil.
+	Events() <-chan Event[K, V]
+}
+
+// emitter delivers events to a bounded channel without ever blocking.
+type emitter[K comparable, V any] struct {
+	events chan Event[K, V]
+	clock  Clock
+}
+
+func newEmitter[K comparable, V any](buffer int, clock Clock) emitter[K, V] {
+	if buffer <= 0 {
+		return emitter[K, V]{}
+	}
+
+	return emitter[K, V]{events: make(chan Event[K, V], buffer), clock: clock}
+}
+
+// Events returns the channel the cache mutations are delivered on.
+// The channel is buffered; when the buffer is full, new events are dropped rather than blocking the cache.
+// If the cache was not configured to emit events, the returned channel is nil.
+func (e emitter[K, V]) Events() <-chan Event[K, V] {
+	return e.events
+}
+
+func (e emitter[K, V]) emit(t EventType, key K, value V, reason Reason) {
+	if e.events == nil {
+		return
+	}
+
+	select {
+	case e.events <- Event[K, V]{Type: t, Key: key, Value: value, Reason: reason, Time: e.clock.Now()}:
+	default:
+	}
+}
+
+// emitRemoval emits the event matching the reason an entry was removed.
+func (e emitter[K, V]) emitRemoval(key K, value V, reason Reason) {
+	e.emit(removalEvent(reason), key, value, reason)
+}
+
+// removalEvent returns the type of the event describing an entry removed for the given reason.
+func removalEvent(reason Reason) EventType {
+	switch reason {
+	case ReasonDeleted, ReasonReloaded:
+		return EventDelete
+	case ReasonExpired:
+		return EventExpire
+	default:
+		return EventEvict
+	}
+}
@@ -0,0 +1,63 @@
+package cachego
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvents(t *testing.T) {
+	c := NewCache[int, string](Opts{Size: 3, TTL: 1, Events: 10})
+	events := c.(EventSource[int, string]).Events()
+
+	c.Set(1, "one")
+	c.Set(2, "two")
+	c.Delete(1)
+	c.Clear()
+	c.Set(3, "three")
+
+	expected := []Event[int, string]{
+		{Type: EventSet, Key: 1, Value: "one"},
+		{Type: EventSet, Key: 2, Value: "two"},
+		{Type: EventDelete, Key: 1, Value: "one", Reason: ReasonDeleted},
+		{Type: EventEvict, Key: 2, Value: "two", Reason: ReasonCleared},
+		{Type: EventSet, Key: 3, Value: "three"},
+		{Type: EventExpire, Key: 3, Value: "three", Reason: ReasonExpired},
+	}
+
+	for _, e := range expected {
+		select {
+		case got := <-events:
+			got.Time = time.Time{}
+			if got != e {
+				t.Errorf("expected %+v, got %+v", e, got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected %+v, got nothing", e)
+		}
+	}
+}
+
+func TestEventsDropPolicy(t *testing.T) {
+	c := NewCache[int, string](Opts{Size: 3, Events: 1})
+	events := c.(EventSource[int, string]).Events()
+
+	// the buffer is full after the first event, so the rest are dropped
+	c.Set(1, "one")
+	c.Set(2, "two")
+	c.Set(3, "three")
+
+	if e := <-events; e.Key != 1 {
+		t.Errorf("expected the first event to be kept, got %+v", e)
+	}
+
+	select {
+	case e := <-events:
+		t.Errorf("expected the other events to be dropped, got %+v", e)
+	default:
+	}
+
+	// no events are emitted unless configured
+	if NewCache[int, string](Opts{}).(EventSource[int, string]).Events() != nil {
+		t.Errorf("expected a nil channel")
+	}
+}
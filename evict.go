@@ -0,0 +1,38 @@
+package cachego
+
+// Reason describes why an entry was removed from a cache.
+type Reason int
+
+const (
+	// ReasonCapacity means the entry was evicted to make room for a new one.
+	ReasonCapacity Reason = iota
+	// ReasonExpired means the entry's ttl lapsed.
+	ReasonExpired
+	// ReasonDeleted means the entry was removed with Delete.
+	ReasonDeleted
+	// ReasonCleared means the entry was removed with Clear.
+	ReasonCleared
+	// ReasonReloaded means the entry was missing from a reloaded snapshot replacing the cache contents (see Opts.Reload).
+	ReasonReloaded
+	// ReasonMemory means the entry was evicted to relieve memory pressure (see MemoryOpts).
+	ReasonMemory
+)
+
+func (r Reason) String() string {
+	switch r {
+	case ReasonCapacity:
+		return "capacity"
+	case ReasonExpired:
+		return "expired"
+	case ReasonDeleted:
+		return "deleted"
+	case ReasonCleared:
+		return "cleared"
+	case ReasonReloaded:
+		return "reloaded"
+	case ReasonMemory:
+		return "memory"
+	default:
+		return "unknown"
+	}
+}
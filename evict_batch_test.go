@@ -0,0 +1,141 @@
+package cachego
+
+import "testing"
+
+// nolint:errcheck
+func TestEvictionBatch(t *testing.T) {
+	var evicted []int
+	cache := NewLRUCacheWithOpts(Opts{
+		Size:          10,
+		EvictionBatch: 4,
+	}, TypedOpts[int, int]{
+		OnEvict: func(k, _ int, _ Reason) { evicted = append(evicted, k) },
+	})
+
+	for i := 0; i < 10; i++ {
+		cache.Set(i, i)
+	}
+
+	// the first Set over capacity evicts a whole batch of the least recently used entries
+	cache.Set(10, 10)
+	if len(evicted) != 4 || evicted[0] != 0 || evicted[3] != 3 {
+		t.Errorf("expected 0 to 3 to be evicted, got %v", evicted)
+	}
+
+	// the next Sets fit without evicting
+	cache.Set(11, 11)
+	cache.Set(12, 12)
+	cache.Set(13, 13)
+	if len(evicted) != 4 {
+		t.Errorf("expected no more evictions, got %v", evicted)
+	}
+
+	if size := cache.(StatsProvider).Stats().Size; size != 10 {
+		t.Errorf("expected 10 entries, got %v", size)
+	}
+
+	// a batch larger than the cache is capped at its size
+	cache = NewLRUCacheWithOpts[int, int](Opts{Size: 2, EvictionBatch: 5})
+	cache.Set(1, 1)
+	cache.Set(2, 2)
+	cache.Set(3, 3)
+	if v, err := cache.Get(3); err != nil || v != 3 {
+		t.Errorf("expected 3, got %v (%v)", v, err)
+	}
+}
+
+// nolint:errcheck
+func TestLowWatermark(t *testing.T) {
+	var evicted []int
+	cache := NewLRUCacheWithOpts(Opts{
+		Size:         10,
+		LowWatermark: 0.7,
+	}, TypedOpts[int, int]{
+		OnEvict: func(k, _ int, _ Reason) { evicted = append(evicted, k) },
+	})
+
+	for i := 0; i < 11; i++ {
+		cache.Set(i, i)
+	}
+
+	// exceeding the size evicts down to 7 entries
+	if len(evicted) != 4 || evicted[0] != 0 || evicted[3] != 3 {
+		t.Errorf("expected 0 to 3 to be evicted, got %v", evicted)
+	}
+	if size := cache.(StatsProvider).Stats().Size; size != 7 {
+		t.Errorf("expected 7 entries, got %v", size)
+	}
+
+	// the cache fills up to its size again before the next burst
+	for i := 11; i < 14; i++ {
+		cache.Set(i, i)
+	}
+	if len(evicted) != 4 {
+		t.Errorf("expected no more evictions, got %v", evicted)
+	}
+
+	cache.Set(14, 14)
+	if len(evicted) != 8 {
+		t.Errorf("expected 8 evictions, got %v", evicted)
+	}
+
+	// a watermark rounding down to no entries still keeps the entry being set
+	evicted = nil
+	small := NewLRUCacheWithOpts(Opts{
+		Size:         2,
+		LowWatermark: 0.3,
+	}, TypedOpts[int, int]{
+		OnEvict: func(k, _ int, _ Reason) { evicted = append(evicted, k) },
+	})
+
+	for i := 0; i < 3; i++ {
+		small.Set(i, i)
+	}
+	if len(evicted) != 2 || evicted[0] != 0 || evicted[1] != 1 {
+		t.Errorf("expected 0 and 1 to be evicted, got %v", evicted)
+	}
+	if v, err := small.Get(2); err != nil || v != 2 {
+		t.Errorf("expected 2, got %v (%v)", v, err)
+	}
+}
+
+// nolint:errcheck
+func TestNoLowWatermark(t *testing.T) {
+	var evicted []int
+	cache := NewLRUCacheWithOpts(Opts{
+		Size: 10,
+	}, TypedOpts[int, int]{
+		OnEvict: func(k, _ int, _ Reason) { evicted = append(evicted, k) },
+	})
+
+	for i := 0; i < 12; i++ {
+		cache.Set(i, i)
+	}
+
+	// without a watermark, only the entries over the size are evicted
+	if len(evicted) != 2 || evicted[0] != 0 || evicted[1] != 1 {
+		t.Errorf("expected 0 and 1 to be evicted, got %v", evicted)
+	}
+	if size := cache.(StatsProvider).Stats().Size; size != 10 {
+		t.Errorf("expected 10 entries, got %v", size)
+	}
+}
+
+// nolint:errcheck
+func TestEvictionBatchResize(t *testing.T) {
+	var evicted []int
+	cache := NewLRUCacheWithOpts(Opts{Size: 10, EvictionBatch: 4}, TypedOpts[int, int]{
+		OnEvict: func(k, _ int, _ Reason) { evicted = append(evicted, k) },
+	})
+
+	// shrinking below the batch doesn't shrink the batch for good
+	cache.(Resizable).Resize(2)
+	cache.(Resizable).Resize(10)
+
+	for i := 0; i < 11; i++ {
+		cache.Set(i, i)
+	}
+	if len(evicted) != 4 {
+		t.Errorf("expected a batch of 4 evictions, got %v", evicted)
+	}
+}
@@ -0,0 +1,108 @@
+package cachego
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOnEvict(t *testing.T) {
+	mx := sync.Mutex{}
+	reasons := map[int]Reason{}
+
+	var c Cache[int, string]
+	c = NewCache(Opts{
+		Size: 3,
+		TTL:  1,
+	}, TypedOpts[int, string]{
+		OnEvict: func(key int, value string, reason Reason) {
+			mx.Lock()
+			defer mx.Unlock()
+
+			reasons[key] = reason
+			c.Get(key) // the callback may use the cache
+		},
+	})
+
+	c.Set(1, "one")
+	c.Delete(1)
+
+	c.Set(2, "two")
+	c.Clear()
+
+	c.Set(3, "three")
+	time.Sleep(1100 * time.Millisecond)
+
+	mx.Lock()
+	defer mx.Unlock()
+
+	for key, reason := range map[int]Reason{1: ReasonDeleted, 2: ReasonCleared, 3: ReasonExpired} {
+		if r, ok := reasons[key]; !ok || r != reason {
+			t.Errorf("expected key %v to be evicted with reason %v, got %v", key, reason, r)
+		}
+	}
+}
+
+func TestOnExpire(t *testing.T) {
+	expired := make(chan string, 2)
+
+	c := NewCache(Opts{
+		Size: 2,
+		TTL:  1,
+	}, TypedOpts[int, string]{
+		OnExpire: func(key int, value string) { expired <- value },
+	})
+
+	c.Set(1, "one")
+	c.Set(2, "two")
+	c.Delete(2) // deleted entries don't expire
+
+	select {
+	case v := <-expired:
+		if v != "one" {
+			t.Errorf("expected one to expire, got %v", v)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("expected OnExpire to be called")
+	}
+
+	select {
+	case v := <-expired:
+		t.Errorf("expected a single expiration, got %v", v)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// nolint:errcheck
+func TestRefreshedKeyExpiry(t *testing.T) {
+	expired := make(chan string, 2)
+
+	c := NewCache(Opts{Size: 2, TTL: 1}, TypedOpts[int, string]{
+		OnExpire: func(key int, value string) { expired <- value },
+	})
+
+	c.Set(1, "a")
+	time.Sleep(600 * time.Millisecond)
+	c.Set(1, "b")
+
+	// the timer of the first Set is stale, so the refreshed value outlives it
+	time.Sleep(600 * time.Millisecond)
+	if v, err := c.Get(1); err != nil || v != "b" {
+		t.Errorf("expected b, got %v (%v)", v, err)
+	}
+
+	select {
+	case v := <-expired:
+		if v != "b" {
+			t.Errorf("expected b to expire, got %v", v)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("expected OnExpire to be called")
+	}
+
+	select {
+	case v := <-expired:
+		t.Errorf("expected a single expiration, got %v", v)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
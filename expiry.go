@@ -0,0 +1,147 @@
+package cachego
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// expirer removes the entries of a cache once their ttl lapses, from a single goroutine
+// started with the first entry that expires and stopped along with the cache.
+// Every key has a single deadline, moved when the entry is set again with a ttl.
+// Deadlines are not removed when an entry is deleted or set without a ttl: the cache ignores the stale ones,
+// since the entry then expires at another time, if at all.
+type expirer[K comparable] struct {
+	mx        *sync.Mutex
+	deadlines deadlines[K]
+	index     map[K]*deadline[K] // the deadline of every key, to move it rather than push another one
+	wake      chan struct{}
+	start     *sync.Once
+	bg        background
+	clock     Clock
+	expire    func(key K, expires time.Time)
+}
+
+type deadline[K comparable] struct {
+	key     K
+	expires time.Time
+	index   int // in the heap
+}
+
+// deadlines is a min-heap of deadlines, the earliest one first.
+type deadlines[K comparable] []*deadline[K]
+
+func (d deadlines[K]) Len() int           { return len(d) }
+func (d deadlines[K]) Less(i, j int) bool { return d[i].expires.Before(d[j].expires) }
+func (d deadlines[K]) Swap(i, j int) {
+	d[i], d[j] = d[j], d[i]
+	d[i].index = i
+	d[j].index = j
+}
+func (d *deadlines[K]) Push(x any) {
+	x.(*deadline[K]).index = len(*d)
+	*d = append(*d, x.(*deadline[K]))
+}
+func (d *deadlines[K]) Pop() any {
+	old := *d
+	x := old[len(old)-1]
+	old[len(old)-1] = nil
+	*d = old[:len(old)-1]
+	return x
+}
+
+func newExpirer[K comparable](bg background, clock Clock, expire func(key K, expires time.Time)) *expirer[K] {
+	return &expirer[K]{
+		mx:     &sync.Mutex{},
+		index:  make(map[K]*deadline[K]),
+		wake:   make(chan struct{}, 1),
+		start:  &sync.Once{},
+		bg:     bg,
+		clock:  clock,
+		expire: expire,
+	}
+}
+
+// schedule calls the expire function of the key once the given time has passed,
+// replacing the deadline the key had. It does nothing once the cache is closed.
+func (e *expirer[K]) schedule(key K, expires time.Time) {
+	if e.bg.ctx.Err() != nil {
+		return
+	}
+
+	e.mx.Lock()
+	if d, ok := e.index[key]; ok {
+		d.expires = expires
+		heap.Fix(&e.deadlines, d.index)
+	} else {
+		d := &deadline[K]{key: key, expires: expires}
+		heap.Push(&e.deadlines, d)
+		e.index[key] = d
+	}
+	earliest := e.deadlines[0].key == key
+	e.mx.Unlock()
+
+	// a loop started here reads the deadlines before sleeping, so it needs no wake up
+	started := false
+	e.start.Do(func() {
+		started = true
+		e.bg.run(e.loop)
+	})
+
+	if earliest && !started {
+		select {
+		case e.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// loop expires the entries whose deadline passed, and sleeps until the next deadline or an earlier one is scheduled.
+func (e *expirer[K]) loop(ctx context.Context) {
+	for {
+		due, wait := e.due(e.clock.Now())
+		for _, d := range due {
+			e.expire(d.key, d.expires)
+		}
+
+		var timer Timer
+		var timeout <-chan time.Time
+		if wait >= 0 {
+			timer = e.clock.NewTimer(wait)
+			timeout = timer.C()
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-e.wake:
+		case <-timeout:
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// due pops the deadlines that passed, and returns how long until the next one, or -1 if there is none.
+func (e *expirer[K]) due(now time.Time) ([]*deadline[K], time.Duration) {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	var due []*deadline[K]
+	for len(e.deadlines) > 0 && !e.deadlines[0].expires.After(now) {
+		d := heap.Pop(&e.deadlines).(*deadline[K])
+		delete(e.index, d.key)
+		due = append(due, d)
+	}
+
+	if len(e.deadlines) == 0 {
+		return due, -1
+	}
+
+	return due, e.deadlines[0].expires.Sub(now)
+}
@@ -0,0 +1,102 @@
+package cachego
+
+import (
+	"io"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// goroutines waits for the number of goroutines to drop to at most n, and returns it.
+func goroutines(n int) int {
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > n && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return runtime.NumGoroutine()
+}
+
+// nolint:errcheck
+func TestExpiryLeak(t *testing.T) {
+	caches := map[string]func() Cache[int, int]{
+		"simple": func() Cache[int, int] { return NewCache[int, int](Opts{Size: 1000, TTL: 60}) },
+		"lru":    func() Cache[int, int] { return NewLRUCacheWithOpts[int, int](Opts{Size: 1000, TTL: 60}) },
+	}
+
+	for name, create := range caches {
+		t.Run(name, func(t *testing.T) {
+			before := goroutines(runtime.NumGoroutine())
+
+			c := create()
+			for i := 0; i < 1000; i++ {
+				c.Set(i, i)
+			}
+
+			// the entries expire from a single goroutine
+			if n := runtime.NumGoroutine(); n > before+1 {
+				t.Errorf("expected at most %v goroutines, got %v", before+1, n)
+			}
+
+			c.(io.Closer).Close()
+			if n := goroutines(before); n > before {
+				t.Errorf("expected %v goroutines after Close, got %v", before, n)
+			}
+		})
+	}
+}
+
+// nolint:errcheck
+func TestExpiryOrder(t *testing.T) {
+	expired := make(chan string, 2)
+	c := NewCache(Opts{Size: 3, TTL: 1}, TypedOpts[string, int]{
+		OnExpire: func(key string, value int) { expired <- key },
+	})
+	defer c.(io.Closer).Close()
+
+	c.Set("a", 1)
+	time.Sleep(300 * time.Millisecond)
+	c.Set("b", 2)
+	time.Sleep(300 * time.Millisecond)
+	c.Set("a", 3) // a now expires after b, and its first deadline is stale
+
+	for _, want := range []string{"b", "a"} {
+		select {
+		case key := <-expired:
+			if key != want {
+				t.Errorf("expected %v, got %v", want, key)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected %v to expire", want)
+		}
+	}
+}
+
+func TestExpirerDeadlines(t *testing.T) {
+	bg := newBackground()
+	now := time.Now()
+	e := newExpirer(bg, newFakeClock(), func(key int, expires time.Time) {})
+
+	// setting a key again moves its deadline rather than adding one
+	for i := 0; i < 100; i++ {
+		e.schedule(1, now.Add(time.Duration(100-i)*time.Second))
+	}
+	e.schedule(2, now.Add(time.Hour))
+
+	e.mx.Lock()
+	n, first := len(e.deadlines), e.deadlines[0]
+	e.mx.Unlock()
+	if n != 2 || first.key != 1 || !first.expires.Equal(now.Add(time.Second)) {
+		t.Errorf("expected 2 deadlines, the first of 1 in a second, got %v (%+v)", n, first)
+	}
+
+	// nothing is scheduled once the cache is closed
+	bg.stop()
+	e.schedule(3, now)
+	e.mx.Lock()
+	n = len(e.deadlines)
+	e.mx.Unlock()
+	if n != 2 {
+		t.Errorf("expected 2 deadlines, got %v", n)
+	}
+}
@@ -0,0 +1,44 @@
+package cachego
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+)
+
+// expvarMx guards the check-then-publish in PublishExpvar, since expvar.Publish panics on duplicate names.
+var expvarMx sync.Mutex
+
+// PublishExpvar publishes the stats of the cache under expvar, so they are served on /debug/vars.
+// Every stat is published as its own variable named "<prefix>.<stat>" (e.g. "users.hits", "users.hit_ratio")
+// and is read from the cache whenever the variables are served.
+// It returns an error, without publishing anything, if any of the names is already published.
+func PublishExpvar(prefix string, p StatsProvider) error {
+	vars := map[string]func(Stats) any{
+		"hits":           func(s Stats) any { return s.Hits },
+		"misses":         func(s Stats) any { return s.Misses },
+		"sets":           func(s Stats) any { return s.Sets },
+		"deletes":        func(s Stats) any { return s.Deletes },
+		"evictions":      func(s Stats) any { return s.Evictions },
+		"expirations":    func(s Stats) any { return s.Expirations },
+		"size":           func(s Stats) any { return s.Size },
+		"hit_ratio":      func(s Stats) any { return s.HitRatio() },
+		"uptime_seconds": func(s Stats) any { return s.Uptime.Seconds() },
+	}
+
+	expvarMx.Lock()
+	defer expvarMx.Unlock()
+
+	for name := range vars {
+		if expvar.Get(prefix+"."+name) != nil {
+			return fmt.Errorf("expvar %s.%s is already published", prefix, name)
+		}
+	}
+
+	for name, stat := range vars {
+		stat := stat
+		expvar.Publish(prefix+"."+name, expvar.Func(func() any { return stat(p.Stats()) }))
+	}
+
+	return nil
+}
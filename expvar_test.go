@@ -0,0 +1,38 @@
+package cachego
+
+import (
+	"expvar"
+	"testing"
+)
+
+// nolint:errcheck
+func TestPublishExpvar(t *testing.T) {
+	cache := NewCache[int, string](Opts{Size: 2, Expvar: "expvar_test"})
+	cache.Set(1, "one")
+	cache.Get(1)
+	cache.Get(2)
+
+	if v := expvar.Get("expvar_test.hits"); v == nil || v.String() != "1" {
+		t.Errorf("expected 1, got %v", v)
+	}
+
+	if v := expvar.Get("expvar_test.hit_ratio"); v == nil || v.String() != "0.5" {
+		t.Errorf("expected 0.5, got %v", v)
+	}
+
+	if v := expvar.Get("expvar_test.size"); v == nil || v.String() != "1" {
+		t.Errorf("expected 1, got %v", v)
+	}
+
+	// publishing twice under the same prefix
+	if err := PublishExpvar("expvar_test", cache.(StatsProvider)); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	// the lru cache publishes its stats too
+	lru := NewLRUCacheWithOpts[int, string](Opts{Size: 2, Expvar: "expvar_test_lru"})
+	lru.Set(1, "one")
+	if v := expvar.Get("expvar_test_lru.sets"); v == nil || v.String() != "1" {
+		t.Errorf("expected 1, got %v", v)
+	}
+}
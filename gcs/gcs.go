@@ -0,0 +1,182 @@
+// Package gcs provides a cachego.File implementation that persists the cache snapshot
+// as a single object in a Google Cloud Storage bucket.
+package gcs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/noam-g4/cachego"
+	"github.com/noam-g4/cachego/internal/uri"
+)
+
+const metadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// Opts configures a cache file stored as a single object in a Google Cloud Storage bucket.
+type Opts struct {
+	// Endpoint is the base URL of the storage service. If empty, "https://storage.googleapis.com" is used.
+	Endpoint string
+	Bucket   string
+	Object   string
+
+	// Token returns an OAuth2 access token used to authorize requests.
+	// If nil, tokens are fetched from the GCE metadata server, which is available
+	// on Compute Engine, GKE and Cloud Run.
+	Token func() (string, error)
+
+	// KMSKeyName optionally selects a customer-managed encryption key for Dump.
+	KMSKeyName string
+
+	// Client is the HTTP client used for requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// file is an implementation of the cachego.File interface.
+// It loads and dumps the cache data from and to an object in a GCS bucket.
+type file struct {
+	opts Opts
+}
+
+// NewFile creates a new instance of the cachego.File interface backed by an object in a GCS bucket.
+// The returned file implements cachego.ContextFile, so its requests carry the context of the cache operation.
+func NewFile(opts Opts) cachego.File {
+	if opts.Endpoint == "" {
+		opts.Endpoint = "https://storage.googleapis.com"
+	}
+
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	if opts.Token == nil {
+		opts.Token = (&metadataToken{client: opts.Client}).get
+	}
+
+	return &file{opts: opts}
+}
+
+// Load downloads the object and returns its contents as a byte slice.
+// If the operation is successful, it returns the read data and a nil error.
+// If the request fails, it returns a non-nil error, wrapping cachego.ErrNoSnapshot if the object does not exist.
+func (g *file) Load() ([]byte, error) {
+	return g.LoadContext(context.Background())
+}
+
+// LoadContext downloads the object just like Load, sending the request with the given context.
+func (g *file) LoadContext(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.url(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.do(req)
+}
+
+// Dump uploads the given data as the object, replacing any previous contents.
+// If the operation is successful, it returns a nil error.
+// If the request fails, it returns a non-nil error.
+func (g *file) Dump(data []byte) error {
+	return g.DumpContext(context.Background(), data)
+}
+
+// DumpContext uploads the object just like Dump, sending the request with the given context.
+func (g *file) DumpContext(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, g.url(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if g.opts.KMSKeyName != "" {
+		req.Header.Set("x-goog-encryption-kms-key-name", g.opts.KMSKeyName)
+	}
+
+	_, err = g.do(req)
+	return err
+}
+
+func (g *file) url() string {
+	object := uri.Encode(strings.TrimPrefix(g.opts.Object, "/"), false)
+	return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(g.opts.Endpoint, "/"), g.opts.Bucket, object)
+}
+
+func (g *file) do(req *http.Request) ([]byte, error) {
+	token, err := g.opts.Token()
+	if err != nil {
+		return nil, fmt.Errorf("gcs token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	res, err := g.opts.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotFound && req.Method == http.MethodGet {
+		return nil, fmt.Errorf("gcs %s %s: %s: %w", req.Method, req.URL.Path, res.Status, cachego.ErrNoSnapshot)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("gcs %s %s: %s", req.Method, req.URL.Path, res.Status)
+	}
+
+	return body, nil
+}
+
+// metadataToken fetches access tokens from the GCE metadata server and caches them until shortly before they expire.
+type metadataToken struct {
+	client  *http.Client
+	mx      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (m *metadataToken) get() (string, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if m.token != "" && time.Now().Before(m.expires) {
+		return m.token, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, metadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	res, err := m.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata server: %s", res.Status)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", err
+	}
+
+	m.token = body.AccessToken
+	m.expires = time.Now().Add(time.Duration(body.ExpiresIn)*time.Second - time.Minute)
+	return m.token, nil
+}
@@ -0,0 +1,60 @@
+package gcs
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/noam-g4/cachego"
+)
+
+func TestFile(t *testing.T) {
+	objects := map[string][]byte{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		}
+	}))
+	defer srv.Close()
+
+	file := NewFile(Opts{
+		Endpoint: srv.URL,
+		Bucket:   "bucket",
+		Object:   "cache.json",
+		Token:    func() (string, error) { return "token", nil },
+	})
+
+	// loading a missing object
+	if _, err := file.Load(); !errors.Is(err, cachego.ErrNoSnapshot) {
+		t.Errorf("expected %v, got %v", cachego.ErrNoSnapshot, err)
+	}
+
+	if err := file.Dump([]byte(`{"a":"one"}`)); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	data, err := file.Load()
+	if err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	if string(data) != `{"a":"one"}` {
+		t.Errorf("expected %v, got %v", `{"a":"one"}`, string(data))
+	}
+}
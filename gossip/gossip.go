@@ -0,0 +1,376 @@
+// Package gossip provides a cachego.Invalidator spreading the invalidations between the instances of a cluster
+// by gossip over UDP, so the caches of the instances converge without a central broker.
+//
+// Every node periodically sends the invalidations it recently learned, along with the peers it knows,
+// to a few random peers. Nodes discover each other from a few seed addresses, and forget the peers
+// they haven't heard from for a while. Delivery is eventual and best effort: an invalidation reaches
+// every node with high probability after a few gossip rounds, but may be lost during a partition.
+package gossip
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// maxPacket is the largest datagram sent, below the maximum UDP payload.
+const maxPacket = 60000
+
+// maxRumors is the number of rumors sent in a round at most, the oldest first, so bursts of invalidations
+// are spread over several rounds rather than exceeding a datagram.
+const maxRumors = 128
+
+// Opts configures a gossip node.
+type Opts struct {
+	// Bind is the UDP address the node listens on. Defaults to ":7946".
+	Bind string
+	// Advertise is the address the other nodes reach this node at. Defaults to the bound address,
+	// which must then be reachable by the other nodes (i.e. not a wildcard address).
+	Advertise string
+	// Seeds are the addresses of the nodes contacted to join the cluster. They are contacted again
+	// whenever the node knows no peer.
+	Seeds []string
+	// Interval is the time between gossip rounds. Defaults to 200 milliseconds.
+	Interval time.Duration
+	// Fanout is the number of random peers gossiped to every round. Defaults to 3.
+	Fanout int
+	// Retransmit is the number of rounds every invalidation is gossiped for by every node learning it.
+	// Defaults to 4, which reaches clusters of hundreds of nodes with the default fanout.
+	Retransmit int
+	// PeerTimeout is the time after which a silent peer is forgotten. Defaults to 10 seconds.
+	PeerTimeout time.Duration
+	// Logger receives the datagrams that cannot be sent or decoded. Defaults to discarding them.
+	Logger cachego.Logger
+}
+
+// Node is a member of the gossip cluster, and a cachego.Invalidator.
+type Node struct {
+	opts Opts
+	conn net.PacketConn
+	self string
+
+	mx      *sync.Mutex
+	peers   map[string]time.Time // last heard from
+	seen    map[string]time.Time // ids of the invalidations learned, and when
+	pending []*rumor
+	subs    map[int]func(msg cachego.Invalidation)
+	nextSub int
+
+	done chan struct{}
+	wg   *sync.WaitGroup
+	once *sync.Once
+}
+
+type rumor struct {
+	ID   string               `json:"id"`
+	Msg  cachego.Invalidation `json:"msg"`
+	left int                  // rounds left to gossip it
+}
+
+type packet struct {
+	From   string   `json:"from"`
+	Peers  []string `json:"peers,omitempty"`
+	Rumors []*rumor `json:"rumors,omitempty"`
+}
+
+// NewNode creates a new node listening on the bound address, and starts gossiping until Close.
+func NewNode(opts Opts) (*Node, error) {
+	if opts.Bind == "" {
+		opts.Bind = ":7946"
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 200 * time.Millisecond
+	}
+	if opts.Fanout <= 0 {
+		opts.Fanout = 3
+	}
+	if opts.Retransmit <= 0 {
+		opts.Retransmit = 4
+	}
+	if opts.PeerTimeout <= 0 {
+		opts.PeerTimeout = 10 * time.Second
+	}
+	if opts.Logger == nil {
+		opts.Logger = nopLogger{}
+	}
+
+	conn, err := net.ListenPacket("udp", opts.Bind)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Node{
+		opts:  opts,
+		conn:  conn,
+		self:  opts.Advertise,
+		mx:    &sync.Mutex{},
+		peers: make(map[string]time.Time),
+		seen:  make(map[string]time.Time),
+		subs:  make(map[int]func(msg cachego.Invalidation)),
+		done:  make(chan struct{}),
+		wg:    &sync.WaitGroup{},
+		once:  &sync.Once{},
+	}
+	if n.self == "" {
+		n.self = conn.LocalAddr().String()
+	}
+
+	n.wg.Add(2)
+	go n.receive()
+	go n.gossip()
+
+	return n, nil
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
+
+// Addr returns the address the node advertises to its peers.
+func (n *Node) Addr() string {
+	return n.self
+}
+
+// Peers returns the addresses of the peers the node currently knows.
+func (n *Node) Peers() []string {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	peers := make([]string, 0, len(n.peers))
+	for p := range n.peers {
+		peers = append(peers, p)
+	}
+
+	return peers
+}
+
+// Publish delivers the message to the subscribers of this node, and starts gossiping it to the cluster.
+// It returns once the message is queued; it never fails unless the node is closed.
+func (n *Node) Publish(ctx context.Context, msg cachego.Invalidation) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case <-n.done:
+		return errors.New("gossip: node is closed")
+	default:
+	}
+
+	n.learn(&rumor{ID: newID(), Msg: msg})
+	return nil
+}
+
+// Subscribe calls handle with every message published on any node of the cluster, until the context is done.
+// It always returns the error of the context, or an error once the node is closed.
+func (n *Node) Subscribe(ctx context.Context, handle func(msg cachego.Invalidation)) error {
+	n.mx.Lock()
+	id := n.nextSub
+	n.subs[id] = handle
+	n.nextSub++
+	n.mx.Unlock()
+
+	defer func() {
+		n.mx.Lock()
+		delete(n.subs, id)
+		n.mx.Unlock()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-n.done:
+		return errors.New("gossip: node is closed")
+	}
+}
+
+// Close stops gossiping and closes the socket. It always returns nil, and may be called more than once.
+func (n *Node) Close() error {
+	n.once.Do(func() {
+		close(n.done)
+		n.conn.Close()
+	})
+	n.wg.Wait()
+
+	return nil
+}
+
+// learn delivers a rumor heard for the first time to the subscribers, and queues it for gossip.
+func (n *Node) learn(r *rumor) {
+	n.mx.Lock()
+	if _, ok := n.seen[r.ID]; ok {
+		n.mx.Unlock()
+		return
+	}
+	n.seen[r.ID] = time.Now()
+	r.left = n.opts.Retransmit
+	n.pending = append(n.pending, r)
+
+	subs := make([]func(msg cachego.Invalidation), 0, len(n.subs))
+	for _, s := range n.subs {
+		subs = append(subs, s)
+	}
+	n.mx.Unlock()
+
+	for _, s := range subs {
+		s(r.Msg)
+	}
+}
+
+// receive merges the peers and learns the rumors of the packets received, until the socket is closed.
+func (n *Node) receive() {
+	defer n.wg.Done()
+
+	buf := make([]byte, 65536)
+	for {
+		size, _, err := n.conn.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-n.done:
+				return
+			default:
+			}
+			n.opts.Logger.Printf("gossip: receiving failed: %v", err)
+			continue
+		}
+
+		var p packet
+		if err := json.Unmarshal(buf[:size], &p); err != nil {
+			n.opts.Logger.Printf("gossip: skipping an invalid packet: %v", err)
+			continue
+		}
+
+		now := time.Now()
+		n.mx.Lock()
+		if p.From != "" && p.From != n.self {
+			n.peers[p.From] = now
+		}
+		for _, peer := range p.Peers {
+			if _, ok := n.peers[peer]; !ok && peer != n.self {
+				// heard of, not from: it is forgotten unless it shows up before the timeout
+				n.peers[peer] = now
+			}
+		}
+		n.mx.Unlock()
+
+		for _, r := range p.Rumors {
+			n.learn(r)
+		}
+	}
+}
+
+// gossip runs a round every interval, until the node is closed.
+func (n *Node) gossip() {
+	defer n.wg.Done()
+
+	ticker := time.NewTicker(n.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.done:
+			return
+		case <-ticker.C:
+			n.round(time.Now())
+		}
+	}
+}
+
+// round sends the pending rumors and a sample of the known peers to a few random peers.
+func (n *Node) round(now time.Time) {
+	n.mx.Lock()
+	for peer, last := range n.peers {
+		if now.Sub(last) > n.opts.PeerTimeout {
+			delete(n.peers, peer)
+		}
+	}
+	for id, at := range n.seen {
+		// long after every node stopped gossiping it, a rumor can't come back
+		if now.Sub(at) > 10*n.opts.PeerTimeout {
+			delete(n.seen, id)
+		}
+	}
+
+	targets := n.sample(n.opts.Fanout)
+	if len(targets) == 0 {
+		targets = n.opts.Seeds
+	}
+
+	rumors := n.pending
+	if len(rumors) > maxRumors {
+		rumors = rumors[:maxRumors]
+	}
+	p := packet{From: n.self, Peers: append(n.sample(16), n.self), Rumors: rumors}
+	data, err := json.Marshal(p)
+
+	pending := n.pending[:0]
+	for i, r := range n.pending {
+		if i < len(rumors) {
+			r.left--
+		}
+		if r.left > 0 {
+			pending = append(pending, r)
+		}
+	}
+	n.pending = pending
+	n.mx.Unlock()
+
+	if err != nil {
+		n.opts.Logger.Printf("gossip: encoding a packet failed: %v", err)
+		return
+	}
+	if len(data) > maxPacket {
+		n.opts.Logger.Printf("gossip: dropping a packet of %d bytes, larger than %d", len(data), maxPacket)
+		return
+	}
+
+	for _, t := range targets {
+		if t == n.self {
+			continue
+		}
+		addr, err := net.ResolveUDPAddr("udp", t)
+		if err != nil {
+			n.opts.Logger.Printf("gossip: resolving %v failed: %v", t, err)
+			continue
+		}
+		if _, err := n.conn.WriteTo(data, addr); err != nil {
+			n.opts.Logger.Printf("gossip: sending to %v failed: %v", t, err)
+		}
+	}
+}
+
+// sample returns up to k random known peers.
+func (n *Node) sample(k int) []string {
+	peers := make([]string, 0, len(n.peers))
+	for p := range n.peers {
+		peers = append(peers, p)
+	}
+
+	for i := 0; i < k && i < len(peers); i++ {
+		j := i + rand.Intn(len(peers)-i)
+		peers[i], peers[j] = peers[j], peers[i]
+	}
+	if len(peers) > k {
+		peers = peers[:k]
+	}
+
+	return peers
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := crand.Read(b); err != nil {
+		binary.BigEndian.PutUint64(b, uint64(time.Now().UnixNano()))
+	}
+
+	return hex.EncodeToString(b)
+}
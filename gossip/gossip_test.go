@@ -0,0 +1,80 @@
+package gossip
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+func newCluster(t *testing.T, size int) []*Node {
+	var nodes []*Node
+	for i := 0; i < size; i++ {
+		opts := Opts{Bind: "127.0.0.1:0", Interval: 10 * time.Millisecond}
+		if i > 0 {
+			// every node only knows the previous one
+			opts.Seeds = []string{nodes[i-1].Addr()}
+		}
+
+		n, err := NewNode(opts)
+		if err != nil {
+			t.Fatalf("NewNode returned error: %s", err)
+		}
+		t.Cleanup(func() { n.Close() })
+		nodes = append(nodes, n)
+	}
+
+	return nodes
+}
+
+func TestMembership(t *testing.T) {
+	nodes := newCluster(t, 4)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for _, n := range nodes {
+		for len(n.Peers()) < 3 {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected %v to discover 3 peers, got %v", n.Addr(), n.Peers())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+}
+
+// nolint:errcheck
+func TestInvalidation(t *testing.T) {
+	nodes := newCluster(t, 4)
+
+	first := cachego.WithInvalidation(cachego.NewCache[string, int](cachego.Opts{Size: 10}),
+		cachego.InvalidationOpts[string]{Invalidator: nodes[0]})
+	defer first.Close()
+
+	last := cachego.NewCache[string, int](cachego.Opts{Size: 10})
+	wrapped := cachego.WithInvalidation(last, cachego.InvalidationOpts[string]{Invalidator: nodes[3]})
+	defer wrapped.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for _, n := range []*Node{nodes[0], nodes[3]} {
+		for n.subscribers() == 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	last.Set("a", 0)
+	first.Set("a", 1)
+
+	for time.Now().Before(deadline) {
+		if _, err := last.Get("a"); errors.Is(err, cachego.ErrNotFound) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Errorf("expected a to be invalidated on the last node")
+}
+
+func (n *Node) subscribers() int {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+	return len(n.subs)
+}
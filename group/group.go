@@ -0,0 +1,265 @@
+// Package group loads values across a cluster of peers, groupcache-style: every key is owned by one peer,
+// chosen by consistent hashing, which runs the loader once for all the requests of the cluster
+// and caches the value, while the other peers fetch the value from the owner over HTTP.
+package group
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/noam-g4/cachego"
+	"github.com/noam-g4/cachego/singleflight"
+)
+
+// Loader loads the value of a key from the source of truth.
+// It returns an error wrapping cachego.ErrNotFound if the key doesn't exist.
+type Loader[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+// Opts configures a group.
+type Opts[K comparable, V any] struct {
+	// Name identifies the group in the URLs of the peers, so several groups can share a handler path.
+	Name string
+	// Loader loads the values of the keys owned by this peer. It is required.
+	Loader Loader[K, V]
+	// Cache holds the values of the keys owned by this peer. Defaults to an LRU cache of 1000 entries.
+	Cache cachego.Cache[K, V]
+	// Self is the base URL of this peer (e.g. "http://10.0.0.1:8080"), as listed in Peers.
+	Self string
+	// Peers are the base URLs of the peers, including Self. See SetPeers.
+	Peers []string
+	// BasePath is the path the handler of the peers is served at. Defaults to "/_cachego/".
+	BasePath string
+	// VirtualNodes is the number of points every peer takes on the hash ring. Defaults to 100.
+	VirtualNodes int
+	// EncodeKey and DecodeKey convert the keys to and from the strings sent to the peers.
+	// Default to the keys themselves for string keys, and to JSON otherwise.
+	EncodeKey func(key K) (string, error)
+	DecodeKey func(key string) (K, error)
+	// Marshal and Unmarshal encode and decode the values sent to the peers. Default to encoding/json.
+	Marshal   func(value V) ([]byte, error)
+	Unmarshal func(data []byte, value *V) error
+	// Client is the HTTP client fetching the values from the peers. Defaults to http.DefaultClient.
+	Client *http.Client
+	// Missing remembers the keys the loader reported missing, so the repeated requests of absent keys
+	// are answered with cachego.ErrNotFound without calling the loader again. If nil, every miss of the cache
+	// calls the loader.
+	Missing *cachego.NegativeFilter[K]
+}
+
+// Group loads the values of a cluster of peers. It is thread-safe.
+type Group[K comparable, V any] struct {
+	opts   Opts[K, V]
+	mx     *sync.RWMutex
+	ring   *cachego.Ring[K, V]
+	flight *singleflight.Group[K, V]
+}
+
+// New creates a new group. The peers reach it through ServeHTTP, which must be served at Opts.BasePath.
+func New[K comparable, V any](opts Opts[K, V]) *Group[K, V] {
+	if opts.Cache == nil {
+		opts.Cache = cachego.NewLRUCache[K, V](1000)
+	}
+	if opts.BasePath == "" {
+		opts.BasePath = "/_cachego/"
+	}
+	if opts.EncodeKey == nil {
+		opts.EncodeKey = func(key K) (string, error) {
+			if s, ok := any(key).(string); ok {
+				return s, nil
+			}
+			b, err := json.Marshal(key)
+			return string(b), err
+		}
+	}
+	if opts.DecodeKey == nil {
+		opts.DecodeKey = func(s string) (K, error) {
+			var key K
+			if p, ok := any(&key).(*string); ok {
+				*p = s
+				return key, nil
+			}
+			err := json.Unmarshal([]byte(s), &key)
+			return key, err
+		}
+	}
+	if opts.Marshal == nil {
+		opts.Marshal = func(value V) ([]byte, error) { return json.Marshal(value) }
+	}
+	if opts.Unmarshal == nil {
+		opts.Unmarshal = func(data []byte, value *V) error { return json.Unmarshal(data, value) }
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	g := &Group[K, V]{opts: opts, mx: &sync.RWMutex{}, flight: &singleflight.Group[K, V]{}}
+	g.SetPeers(opts.Peers...)
+	return g
+}
+
+// SetPeers replaces the peers of the group, e.g. when the cluster scales.
+// Only the keys of the peers joining or leaving change owner.
+func (g *Group[K, V]) SetPeers(peers ...string) {
+	members := make(map[string]cachego.Cache[K, V], len(peers))
+	for _, p := range peers {
+		// the ring only routes the keys: the values are fetched over HTTP
+		members[strings.TrimSuffix(p, "/")] = cachego.NewNopCache[K, V]()
+	}
+
+	ring := cachego.NewRing(members, cachego.RingOpts[K]{VirtualNodes: g.opts.VirtualNodes})
+
+	g.mx.Lock()
+	g.ring = ring
+	g.mx.Unlock()
+}
+
+// Get returns the value of the key: from the local cache or the loader if this peer owns the key,
+// or from its owner otherwise. Concurrent requests for a key share a single load or fetch,
+// and the owner shares a single load among the requests of every peer.
+// If the owner cannot be reached, the value is loaded locally (without being cached).
+func (g *Group[K, V]) Get(ctx context.Context, key K) (V, error) {
+	owner := g.owner(key)
+	if owner == "" || owner == strings.TrimSuffix(g.opts.Self, "/") {
+		return g.load(ctx, key)
+	}
+
+	v, err, _ := g.flight.Do(key, func() (V, error) { return g.fetch(ctx, owner, key) })
+	if err == nil || errors.Is(err, cachego.ErrNotFound) {
+		return v, err
+	}
+
+	return g.loadSource(ctx, key)
+}
+
+func (g *Group[K, V]) owner(key K) string {
+	g.mx.RLock()
+	defer g.mx.RUnlock()
+
+	if owners := g.ring.Owners(key); len(owners) > 0 {
+		return owners[0]
+	}
+
+	return ""
+}
+
+// load returns the value of a key owned by this peer, from the cache or the loader.
+func (g *Group[K, V]) load(ctx context.Context, key K) (V, error) {
+	if v, ok := cachego.LookupValue(g.opts.Cache, key); ok {
+		return v, nil
+	}
+
+	v, err, _ := g.flight.Do(key, func() (V, error) {
+		v, err := g.loadSource(ctx, key)
+		if err != nil {
+			return v, err
+		}
+
+		// a full cache still serves the loaded value
+		_ = cachego.SetCtx(ctx, g.opts.Cache, key, v)
+		return v, nil
+	})
+	return v, err
+}
+
+// loadSource calls the loader, unless the key is known to be missing.
+func (g *Group[K, V]) loadSource(ctx context.Context, key K) (V, error) {
+	if g.opts.Missing != nil && g.opts.Missing.Missing(key) {
+		var v V
+		return v, fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+	}
+
+	v, err := g.opts.Loader(ctx, key)
+	if g.opts.Missing != nil && errors.Is(err, cachego.ErrNotFound) {
+		g.opts.Missing.Add(key)
+	}
+	return v, err
+}
+
+// fetch requests the value from its owner.
+func (g *Group[K, V]) fetch(ctx context.Context, owner string, key K) (V, error) {
+	var v V
+	k, err := g.opts.EncodeKey(key)
+	if err != nil {
+		return v, err
+	}
+
+	u := owner + g.opts.BasePath + url.PathEscape(g.opts.Name) + "/" + url.PathEscape(k)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return v, err
+	}
+
+	res, err := g.opts.Client.Do(req)
+	if err != nil {
+		return v, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return v, err
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		err = g.opts.Unmarshal(body, &v)
+		return v, err
+	case http.StatusNotFound:
+		return v, fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+	}
+
+	return v, fmt.Errorf("fetching key %v from %v failed: %s: %s", key, owner, res.Status, bytes.TrimSpace(body))
+}
+
+// ServeHTTP serves the values of the keys owned by this peer to the other peers,
+// at Opts.BasePath followed by the group name and the key.
+func (g *Group[K, V]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name, k, ok := strings.Cut(strings.TrimPrefix(r.URL.EscapedPath(), g.opts.BasePath), "/")
+	if name, _ = url.PathUnescape(name); !ok || name != g.opts.Name {
+		http.NotFound(w, r)
+		return
+	}
+
+	k, err := url.PathUnescape(k)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	key, err := g.opts.DecodeKey(k)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	v, err := g.load(r.Context(), key)
+	if errors.Is(err, cachego.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := g.opts.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(data)
+}
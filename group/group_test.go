@@ -0,0 +1,202 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+type cluster struct {
+	groups  []*Group[string, string]
+	servers []*httptest.Server
+	loads   *atomic.Int64
+}
+
+// newCluster starts n peers sharing a slow loader, counting its calls.
+func newCluster(t *testing.T, n int) *cluster {
+	c := &cluster{loads: &atomic.Int64{}}
+	loader := func(ctx context.Context, key string) (string, error) {
+		c.loads.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		if key == "missing" {
+			return "", fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+		}
+		return "value of " + key, nil
+	}
+
+	handlers := make([]*handler, n)
+	peers := make([]string, n)
+	for i := range peers {
+		handlers[i] = &handler{}
+		s := httptest.NewServer(handlers[i])
+		t.Cleanup(s.Close)
+		c.servers = append(c.servers, s)
+		peers[i] = s.URL
+	}
+
+	for i := range peers {
+		g := New(Opts[string, string]{Name: "test", Loader: loader, Self: peers[i], Peers: peers})
+		handlers[i].set(g)
+		c.groups = append(c.groups, g)
+	}
+
+	return c
+}
+
+// handler lets the servers start before the groups know their URL.
+type handler struct {
+	mx sync.Mutex
+	h  http.Handler
+}
+
+func (h *handler) set(g http.Handler) {
+	h.mx.Lock()
+	h.h = g
+	h.mx.Unlock()
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mx.Lock()
+	g := h.h
+	h.mx.Unlock()
+	g.ServeHTTP(w, r)
+}
+
+func TestGroup(t *testing.T) {
+	c := newCluster(t, 3)
+	ctx := context.Background()
+
+	// concurrent requests on every peer share a single load
+	wg := sync.WaitGroup{}
+	for i := 0; i < 30; i++ {
+		g := c.groups[i%len(c.groups)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := g.Get(ctx, "key")
+			if err != nil || v != "value of key" {
+				t.Errorf("expected %v, got %v, %v", "value of key", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if loads := c.loads.Load(); loads != 1 {
+		t.Errorf("expected %v loads, got %v", 1, loads)
+	}
+
+	// the owner serves the cached value afterwards
+	for _, g := range c.groups {
+		if v, err := g.Get(ctx, "key"); err != nil || v != "value of key" {
+			t.Errorf("expected %v, got %v, %v", "value of key", v, err)
+		}
+	}
+	if loads := c.loads.Load(); loads != 1 {
+		t.Errorf("expected %v loads, got %v", 1, loads)
+	}
+
+	// every key is loaded once, by its owner
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprint("key", i)
+		for _, g := range c.groups {
+			if _, err := g.Get(ctx, key); err != nil {
+				t.Errorf("expected %v, got %v", nil, err)
+			}
+		}
+	}
+	if loads := c.loads.Load(); loads != 21 {
+		t.Errorf("expected %v loads, got %v", 21, loads)
+	}
+
+	for _, g := range c.groups {
+		if _, err := g.Get(ctx, "missing"); !errors.Is(err, cachego.ErrNotFound) {
+			t.Errorf("expected %v, got %v", cachego.ErrNotFound, err)
+		}
+	}
+}
+
+func TestGroupOwnerDown(t *testing.T) {
+	c := newCluster(t, 2)
+	ctx := context.Background()
+
+	// find a key owned by the second peer, then stop it
+	key := ""
+	for i := 0; key == ""; i++ {
+		if k := fmt.Sprint("key", i); c.groups[0].owner(k) == c.servers[1].URL {
+			key = k
+		}
+	}
+	c.servers[1].Close()
+
+	if v, err := c.groups[0].Get(ctx, key); err != nil || v != "value of "+key {
+		t.Errorf("expected %v, got %v, %v", "value of "+key, v, err)
+	}
+
+	// without the stopped peer, the first one owns every key
+	c.groups[0].SetPeers(c.servers[0].URL)
+	if owner := c.groups[0].owner(key); owner != c.servers[0].URL {
+		t.Errorf("expected %v, got %v", c.servers[0].URL, owner)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	c := newCluster(t, 1)
+
+	for _, tc := range []struct {
+		method, path string
+		status       int
+	}{
+		{http.MethodGet, "/_cachego/test/some%2Fkey", http.StatusOK},
+		{http.MethodGet, "/_cachego/test/missing", http.StatusNotFound},
+		{http.MethodGet, "/_cachego/other/key", http.StatusNotFound},
+		{http.MethodPost, "/_cachego/test/key", http.StatusMethodNotAllowed},
+	} {
+		req, err := http.NewRequest(tc.method, c.servers[0].URL+tc.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != tc.status {
+			t.Errorf("%v %v: expected %v, got %v", tc.method, tc.path, tc.status, res.StatusCode)
+		}
+	}
+}
+
+func TestGroupMissing(t *testing.T) {
+	var loads atomic.Int64
+	g := New(Opts[string, string]{
+		Loader: func(ctx context.Context, key string) (string, error) {
+			loads.Add(1)
+			if key == "missing" {
+				return "", fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+			}
+			return "value of " + key, nil
+		},
+		Missing: cachego.NewNegativeFilter[string](cachego.NegativeFilterOpts{}),
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := g.Get(context.Background(), "missing"); !errors.Is(err, cachego.ErrNotFound) {
+			t.Errorf("expected %v, got %v", cachego.ErrNotFound, err)
+		}
+		if v, err := g.Get(context.Background(), "a"); err != nil || v != "value of a" {
+			t.Errorf("expected value of a, got %v (%v)", v, err)
+		}
+	}
+	if n := loads.Load(); n != 2 {
+		t.Errorf("expected 2 loads, got %v", n)
+	}
+}
@@ -0,0 +1,72 @@
+package cachego
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// hashKey hashes the key with FNV-1a over the bytes of strings and integers,
+// the Hash of the keys hashing themselves (see Key2), and FNV-1a over the formatted key for any other type.
+// Floats hash by value, so -0 hashes like +0. Every NaN hashes alike, although, as in a map,
+// a NaN key never matches another key, itself included.
+func hashKey[K comparable](key K) uint64 {
+	switch k := any(key).(type) {
+	case string:
+		const offset, prime = 14695981039346656037, 1099511628211
+		h := uint64(offset)
+		for i := 0; i < len(k); i++ {
+			h = (h ^ uint64(k[i])) * prime
+		}
+		return h
+	case int:
+		return hashUint(uint64(k))
+	case int32:
+		return hashUint(uint64(k))
+	case int64:
+		return hashUint(uint64(k))
+	case uint:
+		return hashUint(uint64(k))
+	case uint32:
+		return hashUint(uint64(k))
+	case uint64:
+		return hashUint(k)
+	case float64:
+		return hashFloat(k)
+	case float32:
+		return hashFloat(float64(k))
+	}
+
+	// asserted apart from the switch, so the keys above don't escape to the heap
+	if h, ok := any(key).(keyHasher); ok {
+		return h.Hash()
+	}
+
+	f := fnv.New64a()
+	fmt.Fprint(f, key)
+	return f.Sum64()
+}
+
+// hashFloat hashes the bits of the float, normalizing -0 to +0 and every NaN to a single one.
+func hashFloat(f float64) uint64 {
+	switch {
+	case f == 0:
+		f = 0
+	case math.IsNaN(f):
+		f = math.NaN()
+	}
+
+	return hashUint(math.Float64bits(f))
+}
+
+func hashUint(v uint64) uint64 {
+	const offset, prime = 14695981039346656037, 1099511628211
+
+	h := uint64(offset)
+	for i := 0; i < 8; i++ {
+		h = (h ^ (v & 0xff)) * prime
+		v >>= 8
+	}
+
+	return h
+}
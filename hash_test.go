@@ -0,0 +1,33 @@
+package cachego
+
+import (
+	"hash/fnv"
+	"math"
+	"testing"
+)
+
+func TestHashKey(t *testing.T) {
+	// strings hash as FNV-1a
+	f := fnv.New64a()
+	f.Write([]byte("hello"))
+	if h := hashKey("hello"); h != f.Sum64() {
+		t.Errorf("expected %v, got %v", f.Sum64(), h)
+	}
+
+	if hashKey(1) == hashKey(2) {
+		t.Errorf("expected different hashes")
+	}
+
+	type point struct{ X, Y int }
+	if hashKey(point{1, 2}) != hashKey(point{1, 2}) || hashKey(point{1, 2}) == hashKey(point{2, 1}) {
+		t.Errorf("expected struct keys to hash by value")
+	}
+
+	// equal floats hash alike, even with different bits
+	if hashKey(math.Copysign(0, -1)) != hashKey(0.0) || hashKey(float32(math.Copysign(0, -1))) != hashKey(float32(0)) {
+		t.Errorf("expected -0 and +0 to hash alike")
+	}
+	if hashKey(math.NaN()) != hashKey(-math.NaN()) || hashKey(float32(math.NaN())) != hashKey(float32(-math.NaN())) {
+		t.Errorf("expected every NaN to hash alike")
+	}
+}
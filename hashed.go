@@ -0,0 +1,148 @@
+package cachego
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+const hashedLocks = 64
+
+// HashedOpts configures the keys of a HashedCache.
+type HashedOpts[K any] struct {
+	// Hasher returns the hash of a key. The equal keys must have the same hash. It is required.
+	Hasher func(key K) uint64
+	// Equals reports whether two keys are equal. Defaults to reflect.DeepEqual.
+	Equals func(a, b K) bool
+}
+
+// hashedEntry is an entry of a HashedCache, in the bucket of the hash of its key.
+type hashedEntry[K any, V any] struct {
+	key   K
+	value V
+}
+
+// HashedCache is a cache of keys of any type, including the types which aren't comparable such as slices,
+// maps or structs holding them, so they can be used as keys without formatting them into strings first.
+// The entries are stored in an LRU cache by the hash of their key, in buckets holding the keys of the same hash,
+// which only hold more than one entry on hash collisions. The capacity of the cache, and its eviction,
+// count the buckets. It is thread-safe.
+//
+// It doesn't implement Cache, which requires comparable keys, but has the same methods.
+type HashedCache[K any, V any] struct {
+	c      *lru[uint64, []hashedEntry[K, V]]
+	opts   HashedOpts[K]
+	stripe [hashedLocks]sync.Mutex // serializes the updates of the buckets, by hash
+}
+
+// NewHashedCache creates a cache of keys of any type, stored in an LRU cache configured by the options
+// (see NewLRUCacheWithOpts), except File, since the buckets can't be persisted. The keys must not be modified
+// once stored.
+// It panics if the hasher is nil.
+func NewHashedCache[K any, V any](opts Opts, hashed HashedOpts[K]) *HashedCache[K, V] {
+	if hashed.Hasher == nil {
+		panic("cachego: hashed cache without a hasher")
+	}
+	if hashed.Equals == nil {
+		hashed.Equals = func(a, b K) bool { return reflect.DeepEqual(a, b) }
+	}
+
+	opts.File = nil
+	c := newLRUWithOpts(opts, TypedOpts[uint64, []hashedEntry[K, V]]{})
+	c.start(opts)
+	return &HashedCache[K, V]{c: c, opts: hashed}
+}
+
+// find returns the index of the key in the bucket, or -1.
+func (h *HashedCache[K, V]) find(bucket []hashedEntry[K, V], key K) int {
+	for i, e := range bucket {
+		if h.opts.Equals(e.key, key) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Set stores the value under the key.
+// This method is thread-safe.
+func (h *HashedCache[K, V]) Set(key K, value V) error {
+	sum := h.opts.Hasher(key)
+	mx := &h.stripe[sum%hashedLocks]
+	mx.Lock()
+	defer mx.Unlock()
+
+	bucket, _ := h.c.peek(sum)
+	next := make([]hashedEntry[K, V], 0, len(bucket)+1)
+	for _, e := range bucket {
+		if !h.opts.Equals(e.key, key) {
+			next = append(next, e)
+		}
+	}
+
+	return h.c.Set(sum, append(next, hashedEntry[K, V]{key: key, value: value}))
+}
+
+// Get retrieves the value associated with the key.
+// If the key is not found, the zero value of the value type and an error wrapping ErrNotFound are returned.
+// This method is thread-safe.
+func (h *HashedCache[K, V]) Get(key K) (V, error) {
+	v, ok := h.Lookup(key)
+	if !ok {
+		return v, fmt.Errorf("key %v %w", key, ErrNotFound)
+	}
+
+	return v, nil
+}
+
+// Lookup retrieves the value associated with the key, reporting whether it was found.
+// This method is thread-safe.
+func (h *HashedCache[K, V]) Lookup(key K) (V, bool) {
+	bucket, _ := h.c.Lookup(h.opts.Hasher(key))
+	if i := h.find(bucket, key); i >= 0 {
+		return bucket[i].value, true
+	}
+
+	var empty V
+	return empty, false
+}
+
+// Delete removes the key.
+// If the key is not found, an error wrapping ErrNotFound is returned.
+// This method is thread-safe.
+func (h *HashedCache[K, V]) Delete(key K) error {
+	sum := h.opts.Hasher(key)
+	mx := &h.stripe[sum%hashedLocks]
+	mx.Lock()
+	defer mx.Unlock()
+
+	bucket, _ := h.c.peek(sum)
+	i := h.find(bucket, key)
+	if i < 0 {
+		return fmt.Errorf("key %v %w", key, ErrNotFound)
+	}
+	if len(bucket) == 1 {
+		return h.c.Delete(sum)
+	}
+
+	next := make([]hashedEntry[K, V], 0, len(bucket)-1)
+	next = append(append(next, bucket[:i]...), bucket[i+1:]...)
+	return h.c.Set(sum, next)
+}
+
+// Clear removes all the entries.
+// This method is thread-safe.
+func (h *HashedCache[K, V]) Clear() error {
+	return h.c.Clear()
+}
+
+// Stats returns a snapshot of the counters of the buckets.
+// This method is thread-safe.
+func (h *HashedCache[K, V]) Stats() Stats {
+	return h.c.Stats()
+}
+
+// Close stops the background work of the cache.
+func (h *HashedCache[K, V]) Close() error {
+	return h.c.Close()
+}
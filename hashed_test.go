@@ -0,0 +1,101 @@
+package cachego
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"testing"
+)
+
+func hashInts(key []int) uint64 {
+	f := fnv.New64a()
+	fmt.Fprint(f, key)
+	return f.Sum64()
+}
+
+// nolint:errcheck
+func TestHashedCache(t *testing.T) {
+	c := NewHashedCache[[]int, string](Opts{Size: 2}, HashedOpts[[]int]{Hasher: hashInts})
+	defer c.Close()
+
+	c.Set([]int{1, 2}, "a")
+	c.Set([]int{2, 1}, "b")
+	c.Set([]int{1, 2}, "c")
+
+	if v, err := c.Get([]int{1, 2}); err != nil || v != "c" {
+		t.Errorf("expected c, got %v (%v)", v, err)
+	}
+	if v, ok := c.Lookup([]int{2, 1}); !ok || v != "b" {
+		t.Errorf("expected b, got %v", v)
+	}
+	if _, err := c.Get([]int{3}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+
+	// evicts the least recently used key
+	c.Set([]int{3}, "d")
+	if _, ok := c.Lookup([]int{1, 2}); ok {
+		t.Errorf("expected [1 2] to be evicted")
+	}
+	if stats := c.Stats(); stats.Size != 2 || stats.Evictions != 1 {
+		t.Errorf("expected 2 entries and 1 eviction, got %+v", stats)
+	}
+
+	if err := c.Delete([]int{3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Delete([]int{3}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+	c.Clear()
+	if _, ok := c.Lookup([]int{2, 1}); ok {
+		t.Errorf("expected an empty cache")
+	}
+}
+
+// nolint:errcheck
+func TestHashedCacheCollisions(t *testing.T) {
+	type key struct {
+		tags map[string]string
+	}
+	c := NewHashedCache[key, int](Opts{Size: 10}, HashedOpts[key]{
+		Hasher: func(key) uint64 { return 1 },
+	})
+	defer c.Close()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.Set(key{map[string]string{"id": fmt.Sprint(i % 5)}}, i%5)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 5; i++ {
+		if v, err := c.Get(key{map[string]string{"id": fmt.Sprint(i)}}); err != nil || v != i {
+			t.Errorf("expected %v, got %v (%v)", i, v, err)
+		}
+	}
+	c.Delete(key{map[string]string{"id": "0"}})
+	if _, ok := c.Lookup(key{map[string]string{"id": "0"}}); ok {
+		t.Errorf("expected the key to be deleted")
+	}
+	if v, _ := c.Lookup(key{map[string]string{"id": "4"}}); v != 4 {
+		t.Errorf("expected 4, got %v", v)
+	}
+	if stats := c.Stats(); stats.Size != 1 {
+		t.Errorf("expected a single bucket, got %v", stats.Size)
+	}
+}
+
+func TestHashedCacheWithoutHasher(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic")
+		}
+	}()
+	NewHashedCache[[]int, int](Opts{}, HashedOpts[[]int]{})
+}
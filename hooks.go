@@ -0,0 +1,76 @@
+package cachego
+
+// Hooks holds functions invoked around the mutations of a cache.
+// Any of the hooks may be nil.
+type Hooks[K comparable, V any] struct {
+	// BeforeSet is called before a value is stored. If it returns an error, the value is not stored
+	// and Set returns that error.
+	BeforeSet func(key K, value V) error
+	// AfterSet is called after a value was stored (or failed to be stored) with the error returned by Set.
+	AfterSet func(key K, value V, err error)
+	// BeforeDelete is called before a key is deleted. If it returns an error, the key is not deleted
+	// and Delete returns that error.
+	BeforeDelete func(key K) error
+	// AfterDelete is called after a key was deleted (or failed to be deleted) with the error returned by Delete.
+	AfterDelete func(key K, err error)
+	// AfterClear is called after the cache was cleared with the error returned by Clear.
+	AfterClear func(err error)
+}
+
+type hooked[K comparable, V any] struct {
+	Cache[K, V]
+	hooks Hooks[K, V]
+}
+
+// WithHooks wraps the given cache so that the hooks are invoked around its mutations.
+// This makes it possible to layer cross-cutting concerns like audit logging, metrics or replication
+// on top of any Cache implementation.
+// The hooks are called synchronously by the calling goroutine, so they must be thread-safe if the cache is used concurrently.
+func WithHooks[K comparable, V any](c Cache[K, V], hooks Hooks[K, V]) Cache[K, V] {
+	return &hooked[K, V]{Cache: c, hooks: hooks}
+}
+
+// Set runs the BeforeSet hook, stores the value in the wrapped cache and runs the AfterSet hook.
+func (h *hooked[K, V]) Set(key K, value V) error {
+	if h.hooks.BeforeSet != nil {
+		if err := h.hooks.BeforeSet(key, value); err != nil {
+			return err
+		}
+	}
+
+	err := h.Cache.Set(key, value)
+
+	if h.hooks.AfterSet != nil {
+		h.hooks.AfterSet(key, value, err)
+	}
+
+	return err
+}
+
+// Delete runs the BeforeDelete hook, deletes the key from the wrapped cache and runs the AfterDelete hook.
+func (h *hooked[K, V]) Delete(key K) error {
+	if h.hooks.BeforeDelete != nil {
+		if err := h.hooks.BeforeDelete(key); err != nil {
+			return err
+		}
+	}
+
+	err := h.Cache.Delete(key)
+
+	if h.hooks.AfterDelete != nil {
+		h.hooks.AfterDelete(key, err)
+	}
+
+	return err
+}
+
+// Clear clears the wrapped cache and runs the AfterClear hook.
+func (h *hooked[K, V]) Clear() error {
+	err := h.Cache.Clear()
+
+	if h.hooks.AfterClear != nil {
+		h.hooks.AfterClear(err)
+	}
+
+	return err
+}
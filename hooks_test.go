@@ -0,0 +1,59 @@
+package cachego
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	var log []string
+
+	c := WithHooks(NewLRUCache[int, string](2), Hooks[int, string]{
+		BeforeSet: func(key int, value string) error {
+			if value == "" {
+				return fmt.Errorf("empty value")
+			}
+			return nil
+		},
+		AfterSet: func(key int, value string, err error) {
+			log = append(log, fmt.Sprintf("set %v=%v", key, value))
+		},
+		BeforeDelete: func(key int) error {
+			log = append(log, fmt.Sprintf("before delete %v", key))
+			return nil
+		},
+		AfterDelete: func(key int, err error) {
+			log = append(log, fmt.Sprintf("delete %v: %v", key, err))
+		},
+		AfterClear: func(err error) {
+			log = append(log, "clear")
+		},
+	})
+
+	// BeforeSet aborts the Set
+	if err := c.Set(1, ""); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	if _, err := c.Get(1); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	c.Set(1, "one")
+	c.Delete(1)
+	c.Delete(1)
+	c.Clear()
+
+	expected := []string{
+		"set 1=one",
+		"before delete 1",
+		"delete 1: <nil>",
+		"before delete 1",
+		"delete 1: key 1 not found",
+		"clear",
+	}
+
+	if fmt.Sprint(log) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, log)
+	}
+}
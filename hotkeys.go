@@ -0,0 +1,170 @@
+package cachego
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+const hotShards = 8
+
+// HotKey is a key with the estimated number of times it was read.
+type HotKey[K comparable] struct {
+	Key   K
+	Count uint64
+}
+
+// HotKeyTracker is implemented by caches that track their most frequently read keys.
+type HotKeyTracker[K comparable] interface {
+	// HotKeys returns the most frequently read keys, ordered from the hottest one.
+	// If the cache was not configured to track hot keys, it returns nil.
+	HotKeys() []HotKey[K]
+}
+
+// FrequencyEstimator is implemented by caches that count the reads of their keys, so applications can make
+// their own decisions from the popularity the cache observed, e.g. whether to prefetch or replicate a key.
+type FrequencyEstimator[K comparable] interface {
+	// EstimateFrequency returns the estimated number of recent reads of the key, hits and misses alike.
+	// The estimate may exceed the actual count, never fall below it, and decays as the counts are halved over time.
+	// If the cache doesn't count the reads, it returns 0.
+	EstimateFrequency(key K) uint64
+}
+
+// hotKeys approximates the Top-K most read keys.
+// Reads are counted in a sketch, and the keys with the highest estimates are kept as candidates,
+// spread over shards by the hash of the key. As the sketch halves its counts, keys that cool down
+// are eventually replaced by new hot keys.
+//
+// Recording a read takes no lock and doesn't allocate: a key is only admitted as a candidate,
+// under the lock of its shard, once its estimate exceeds the coldest candidate of the shard.
+type hotKeys[K comparable] struct {
+	k      int
+	sketch *sketch
+	shards [hotShards]hotShard[K]
+}
+
+// hotShard holds candidates in a map that is copied on every change, so reads look it up without a lock.
+type hotShard[K comparable] struct {
+	mx    sync.Mutex
+	top   atomic.Pointer[map[K]uint64] // candidate keys and their hashes
+	floor atomic.Uint64                // estimate to exceed to become a candidate, zero while the shard has room
+}
+
+func newHotKeys[K comparable](k int) *hotKeys[K] {
+	if k <= 0 {
+		return nil
+	}
+
+	h := &hotKeys[K]{k: k, sketch: newSketch(k * 64)}
+	h.sketch.aged = h.aged
+	for i := range h.shards {
+		top := make(map[K]uint64)
+		h.shards[i].top.Store(&top)
+	}
+
+	return h
+}
+
+// HotKeys returns the most frequently read keys, ordered from the hottest one.
+// If the cache was not configured to track hot keys, it returns nil.
+// This method is thread-safe.
+func (h *hotKeys[K]) HotKeys() []HotKey[K] {
+	if h == nil {
+		return nil
+	}
+
+	var keys []HotKey[K]
+	for i := range h.shards {
+		for k, sum := range *h.shards[i].top.Load() {
+			keys = append(keys, HotKey[K]{Key: k, Count: h.sketch.estimate(sum)})
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Count > keys[j].Count })
+
+	if len(keys) > h.k {
+		keys = keys[:h.k]
+	}
+
+	return keys
+}
+
+// EstimateFrequency returns the estimated number of recent reads of the key, hits and misses alike.
+// The counts are only kept if the cache tracks hot keys (see Opts.HotKeys); otherwise it returns 0.
+// This method is thread-safe.
+func (h *hotKeys[K]) EstimateFrequency(key K) uint64 {
+	if h == nil {
+		return 0
+	}
+
+	return h.sketch.estimate(hashKey(key))
+}
+
+// record counts a read of the key and admits it as a candidate if it is hot enough.
+func (h *hotKeys[K]) record(key K) {
+	if h == nil {
+		return
+	}
+
+	sum := hashKey(key)
+	n := h.sketch.increment(sum)
+
+	s := &h.shards[sum%hotShards]
+	if n > s.floor.Load() {
+		if _, ok := (*s.top.Load())[key]; !ok {
+			h.admit(s, key, sum, n)
+		}
+	}
+}
+
+// admit adds the key to the candidates of the shard, replacing the coldest one if the shard is full.
+func (h *hotKeys[K]) admit(s *hotShard[K], key K, sum uint64, n uint64) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	top := *s.top.Load()
+	if _, ok := top[key]; ok {
+		return
+	}
+
+	next := make(map[K]uint64, len(top)+1)
+	for k, v := range top {
+		next[k] = v
+	}
+
+	if len(next) >= h.k {
+		coldest, min := h.coldest(next)
+		if n <= min {
+			s.floor.Store(min)
+			return
+		}
+		delete(next, coldest)
+	}
+	next[key] = sum
+	s.top.Store(&next)
+
+	if len(next) >= h.k {
+		_, min := h.coldest(next)
+		s.floor.Store(min)
+	}
+}
+
+// coldest returns the candidate with the lowest estimate, and its estimate.
+func (h *hotKeys[K]) coldest(top map[K]uint64) (K, uint64) {
+	var coldest K
+	min := ^uint64(0)
+	for k, sum := range top {
+		if n := h.sketch.estimate(sum); n < min {
+			coldest, min = k, n
+		}
+	}
+
+	return coldest, min
+}
+
+// aged halves the admission floors along with the counts of the sketch.
+func (h *hotKeys[K]) aged() {
+	for i := range h.shards {
+		s := &h.shards[i]
+		s.floor.Store(s.floor.Load() / 2)
+	}
+}
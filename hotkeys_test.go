@@ -0,0 +1,121 @@
+package cachego
+
+import (
+	"sync"
+	"testing"
+)
+
+// nolint:errcheck
+func TestHotKeys(t *testing.T) {
+	cache := NewCache[int, string](Opts{Size: 100, HotKeys: 3})
+	for i := 0; i < 100; i++ {
+		cache.Set(i, "value")
+	}
+
+	// keys 0, 1 and 2 are read far more often than the rest
+	for i := 0; i < 100; i++ {
+		cache.Get(i)
+		cache.Get(i % 3)
+		cache.Get(i % 3)
+		cache.Get(0)
+	}
+
+	hot := cache.(HotKeyTracker[int]).HotKeys()
+	if len(hot) != 3 {
+		t.Fatalf("expected 3 hot keys, got %v", hot)
+	}
+
+	if hot[0].Key != 0 || hot[0].Count < 100 {
+		t.Errorf("expected key 0 to be the hottest, got %v", hot[0])
+	}
+
+	for _, h := range hot {
+		if h.Key > 2 {
+			t.Errorf("expected only keys 0, 1 and 2, got %v", hot)
+		}
+	}
+
+	// misses are counted too, so hot missing keys show up
+	lru := NewLRUCacheWithOpts[string, int](Opts{Size: 2, HotKeys: 1})
+	lru.Get("missing")
+	if hot := lru.(HotKeyTracker[string]).HotKeys(); len(hot) != 1 || hot[0].Key != "missing" {
+		t.Errorf("expected missing, got %v", hot)
+	}
+
+	// not tracking hot keys
+	if hot := NewLRUCache[int, int](2).(HotKeyTracker[int]).HotKeys(); hot != nil {
+		t.Errorf("expected nil, got %v", hot)
+	}
+}
+
+func TestHotKeysAging(t *testing.T) {
+	h := newHotKeys[int](1)
+	for i := 0; i < h.sketch.resetAt-1; i++ {
+		h.record(1)
+	}
+
+	if n := h.HotKeys()[0].Count; n != uint64(h.sketch.resetAt-1) {
+		t.Errorf("expected %v, got %v", h.sketch.resetAt-1, n)
+	}
+
+	// the next read halves every count
+	h.record(1)
+	if n := h.HotKeys()[0].Count; n != uint64(h.sketch.resetAt/2) {
+		t.Errorf("expected %v, got %v", h.sketch.resetAt/2, n)
+	}
+}
+
+// nolint:errcheck
+func TestHotKeysConcurrent(t *testing.T) {
+	cache := NewCache[int, int](Opts{Size: 100, HotKeys: 2})
+	for i := 0; i < 100; i++ {
+		cache.Set(i, i)
+	}
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				cache.Get(i % 2)
+				cache.Get(g*10 + i%10)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	hot := cache.(HotKeyTracker[int]).HotKeys()
+	if len(hot) != 2 || hot[0].Key > 1 || hot[1].Key > 1 {
+		t.Errorf("expected keys 0 and 1, got %v", hot)
+	}
+}
+
+// nolint:errcheck
+func TestEstimateFrequency(t *testing.T) {
+	cache := NewLRUCacheWithOpts[string, int](Opts{Size: 10, HotKeys: 1})
+	cache.Set("a", 1)
+	for i := 0; i < 5; i++ {
+		cache.Get("a")
+		cache.Get("missing")
+	}
+	cache.Get("missing")
+
+	f := cache.(FrequencyEstimator[string])
+	if n := f.EstimateFrequency("a"); n != 5 {
+		t.Errorf("expected 5, got %v", n)
+	}
+	if n := f.EstimateFrequency("missing"); n != 6 {
+		t.Errorf("expected 6, got %v", n)
+	}
+	if n := f.EstimateFrequency("b"); n != 0 {
+		t.Errorf("expected 0, got %v", n)
+	}
+
+	// not counting the reads
+	untracked := NewCache[string, int](Opts{})
+	untracked.Get("a")
+	if n := untracked.(FrequencyEstimator[string]).EstimateFrequency("a"); n != 0 {
+		t.Errorf("expected 0, got %v", n)
+	}
+}
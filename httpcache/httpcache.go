@@ -0,0 +1,376 @@
+// Package httpcache caches the responses of an http.Handler in a cachego.Cache, as a middleware:
+//
+//	m := httpcache.New(cachego.NewCache[string, httpcache.CachedResponse](cachego.Opts{Size: 1000}), httpcache.Opts{TTL: time.Minute})
+//	http.Handle("/", m.Handler(mux))
+//
+// Only the responses to the allowed methods with the allowed statuses are stored. Responses setting cookies,
+// marked with Cache-Control no-store or private, or larger than Opts.MaxBodyBytes are never stored.
+// The directives of the requests are ignored, so clients cannot bypass the cache.
+//
+// The middleware plugs into routers as is, or through Serve for the ones with a handler chain of their own,
+// with the same keys and time to live:
+//
+//	// chi
+//	r.Use(m.Handler)
+//
+//	// echo
+//	e.Use(echo.WrapMiddleware(m.Handler))
+//
+//	// gin
+//	r.Use(func(c *gin.Context) {
+//		hit := m.Serve(c.Writer, c.Request, func(w http.ResponseWriter) {
+//			c.Writer = ginWriter{c.Writer, w}
+//			c.Next()
+//		})
+//		if hit {
+//			c.Abort()
+//		}
+//	})
+//
+//	// ginWriter writes the responses of the handlers through the response writer recording them.
+//	type ginWriter struct {
+//		gin.ResponseWriter
+//		w http.ResponseWriter
+//	}
+//
+//	func (g ginWriter) WriteHeader(code int)              { g.w.WriteHeader(code) }
+//	func (g ginWriter) Write(b []byte) (int, error)       { return g.w.Write(b) }
+//	func (g ginWriter) WriteString(s string) (int, error) { return g.w.Write([]byte(s)) }
+//
+// On the client side, Transport caches the responses of an http.Client as a private cache, following RFC 7234.
+package httpcache
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// CachedResponse is a response stored in the cache.
+type CachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	// Stored is the time the response was stored, from which its Age is computed.
+	Stored time.Time
+	// Expires is the time the response expires at, or zero if it doesn't expire before the cache evicts it.
+	Expires time.Time
+	// VaryHeader holds the request headers named by the Vary header of the response, as sent when it was stored.
+	// It is only set by the Transport, which serves the response to the requests with the same values.
+	VaryHeader http.Header
+}
+
+// KeyFunc returns the key a request is cached under.
+type KeyFunc func(r *http.Request) string
+
+// DefaultKey is the key of the requests by default: the method, the host and the URI with its query parameters
+// sorted, e.g. "GET example.com/users?limit=10&offset=20".
+func DefaultKey(r *http.Request) string {
+	u := r.URL.EscapedPath()
+	if q := r.URL.Query(); len(q) > 0 {
+		// Encode sorts by key
+		u += "?" + q.Encode()
+	}
+
+	return r.Method + " " + r.Host + u
+}
+
+// VaryKey returns a key function caching the requests by the values of the headers on top of the key,
+// e.g. VaryKey(DefaultKey, "Accept-Encoding", "Accept-Language") for the responses negotiated on them.
+func VaryKey(key KeyFunc, headers ...string) KeyFunc {
+	names := make([]string, len(headers))
+	for i, h := range headers {
+		names[i] = http.CanonicalHeaderKey(h)
+	}
+	sort.Strings(names)
+
+	return func(r *http.Request) string {
+		b := &strings.Builder{}
+		b.WriteString(key(r))
+		for _, name := range names {
+			b.WriteString("\n")
+			b.WriteString(name)
+			b.WriteString(": ")
+			b.WriteString(url.QueryEscape(strings.Join(r.Header.Values(name), ", ")))
+		}
+		return b.String()
+	}
+}
+
+// Opts configures the responses cached.
+type Opts struct {
+	// Key is the key function of the requests. Defaults to DefaultKey.
+	Key KeyFunc
+	// Methods are the methods of the requests cached. Default to GET and HEAD.
+	Methods []string
+	// Statuses are the statuses of the responses cached. Default to 200, 203, 204, 300, 301, 404, 405, 410 and 414,
+	// the ones cacheable by default according to RFC 9110.
+	Statuses []int
+	// TTL is the time to live of the responses without a max-age or s-maxage directive, which take precedence.
+	// Defaults to the responses not expiring before the cache evicts them.
+	TTL time.Duration
+	// MaxBodyBytes bounds the size of the bodies of the responses cached. Defaults to 1 MiB.
+	MaxBodyBytes int
+	// Clock tells the time the responses are stored and expire at. Defaults to the system clock.
+	Clock cachego.Clock
+	// Logger receives the errors of the cache, which are otherwise served as misses. Defaults to discarding them.
+	Logger cachego.Logger
+}
+
+// Middleware caches the responses of handlers.
+type Middleware struct {
+	c        cachego.Cache[string, CachedResponse]
+	opts     Opts
+	methods  map[string]bool
+	statuses map[int]bool
+}
+
+// New creates a middleware caching the responses in the cache.
+func New(c cachego.Cache[string, CachedResponse], opts Opts) *Middleware {
+	if opts.Key == nil {
+		opts.Key = DefaultKey
+	}
+	if opts.Methods == nil {
+		opts.Methods = []string{http.MethodGet, http.MethodHead}
+	}
+	if opts.Statuses == nil {
+		opts.Statuses = []int{200, 203, 204, 300, 301, 404, 405, 410, 414}
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = 1 << 20
+	}
+	if opts.Logger == nil {
+		opts.Logger = nopLogger{}
+	}
+
+	m := &Middleware{c: c, opts: opts, methods: make(map[string]bool), statuses: make(map[int]bool)}
+	for _, method := range opts.Methods {
+		m.methods[method] = true
+	}
+	for _, status := range opts.Statuses {
+		m.statuses[status] = true
+	}
+
+	return m
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
+
+// Handler returns a handler serving the cached responses, and caching the responses of the next handler.
+// The responses served are marked with an X-Cache header set to HIT or MISS.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Serve(w, r, func(w http.ResponseWriter) {
+			next.ServeHTTP(w, r)
+		})
+	})
+}
+
+// Serve writes the cached response to the request if there is one, and reports it. Otherwise, it calls next
+// with a response writer recording the response written through it, and caches it.
+// It is the building block of Handler, for the routers whose handlers don't take an http.Handler.
+func (m *Middleware) Serve(w http.ResponseWriter, r *http.Request, next func(w http.ResponseWriter)) bool {
+	key, res, ok := m.Lookup(r)
+	if key == "" {
+		next(w)
+		return false
+	}
+	if ok {
+		res.Write(w, m.now())
+		return true
+	}
+
+	w.Header().Set("X-Cache", "MISS")
+	rec := &recorder{ResponseWriter: w, max: m.opts.MaxBodyBytes}
+	next(rec)
+	if !rec.truncated {
+		m.Store(key, rec.status(), rec.header, rec.body.Bytes())
+	}
+	return false
+}
+
+// Lookup returns the key of the request and its cached response if any. The key is empty for the requests
+// whose method isn't cached. Expired responses are deleted, and reported as missing.
+func (m *Middleware) Lookup(r *http.Request) (string, CachedResponse, bool) {
+	if !m.methods[r.Method] {
+		return "", CachedResponse{}, false
+	}
+
+	key := m.opts.Key(r)
+	res, err := m.c.Get(key)
+	if err != nil {
+		if !errors.Is(err, cachego.ErrNotFound) {
+			m.opts.Logger.Printf("httpcache: getting %q failed: %v", key, err)
+		}
+		return key, CachedResponse{}, false
+	}
+
+	if !res.Expires.IsZero() && !m.now().Before(res.Expires) {
+		m.c.Delete(key) // nolint:errcheck
+		return key, CachedResponse{}, false
+	}
+
+	return key, res, true
+}
+
+// Store caches the response under the key if it is cacheable, and reports whether it was stored.
+// The header and body are copied.
+func (m *Middleware) Store(key string, status int, header http.Header, body []byte) bool {
+	if !m.statuses[status] || len(body) > m.opts.MaxBodyBytes || len(header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+
+	ttl, ok := m.ttl(header)
+	if !ok {
+		return false
+	}
+
+	now := m.now()
+	res := CachedResponse{Status: status, Header: header.Clone(), Body: bytes.Clone(body), Stored: now}
+	if ttl > 0 {
+		res.Expires = now.Add(ttl)
+	}
+	res.Header.Del("X-Cache")
+
+	if err := m.c.Set(key, res); err != nil {
+		m.opts.Logger.Printf("httpcache: storing %q failed: %v", key, err)
+		return false
+	}
+	return true
+}
+
+// ttl returns the time to live of the response according to its Cache-Control header, or false if it must not be stored.
+func (m *Middleware) ttl(header http.Header) (time.Duration, bool) {
+	cc := cacheControl(header)
+	if cc.has("no-store") || cc.has("private") {
+		return 0, false
+	}
+
+	maxAge, ok := cc.seconds("s-maxage")
+	if !ok {
+		if maxAge, ok = cc.seconds("max-age"); !ok {
+			return m.opts.TTL, true
+		}
+	}
+
+	return maxAge, maxAge > 0
+}
+
+// directives are the directives of a Cache-Control header, by lowercase name, with their unquoted values.
+type directives map[string]string
+
+func cacheControl(h http.Header) directives {
+	cc := directives{}
+	for _, v := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(d), "=")
+			if name != "" {
+				cc[strings.ToLower(name)] = strings.Trim(value, `"`)
+			}
+		}
+	}
+
+	return cc
+}
+
+// has reports whether the directive is set.
+func (cc directives) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+// seconds returns the value of a directive counting seconds, e.g. max-age, and whether it is set and valid.
+func (cc directives) seconds(name string) (time.Duration, bool) {
+	n, err := strconv.Atoi(cc[name])
+	if err != nil || n < 0 {
+		return 0, false
+	}
+
+	return time.Duration(n) * time.Second, true
+}
+
+func (m *Middleware) now() time.Time {
+	if m.opts.Clock == nil {
+		return time.Now()
+	}
+
+	return m.opts.Clock.Now()
+}
+
+// Write writes the response, with its Age at the given time and an X-Cache header set to HIT.
+func (res CachedResponse) Write(w http.ResponseWriter, now time.Time) {
+	h := w.Header()
+	for name, values := range res.Header {
+		h[name] = append([]string(nil), values...)
+	}
+	h.Set("Age", strconv.Itoa(int(now.Sub(res.Stored)/time.Second)))
+	h.Set("X-Cache", "HIT")
+
+	w.WriteHeader(res.Status)
+	w.Write(res.Body) // nolint:errcheck
+}
+
+// recorder writes the response through, recording it up to max bytes.
+type recorder struct {
+	http.ResponseWriter
+	max       int
+	code      int
+	header    http.Header
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (r *recorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+		r.header = r.ResponseWriter.Header().Clone()
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+	if !r.truncated {
+		if r.body.Len()+len(b) > r.max {
+			r.truncated = true
+			r.body = bytes.Buffer{}
+		} else {
+			r.body.Write(b)
+		}
+	}
+
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *recorder) status() int {
+	if r.code == 0 {
+		// the handler wrote nothing
+		r.header = r.ResponseWriter.Header().Clone()
+		return http.StatusOK
+	}
+
+	return r.code
+}
+
+// Flush flushes the response, if the response writer supports it.
+func (r *recorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the response writer, for http.ResponseController.
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,199 @@
+package httpcache
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+type fakeClock struct {
+	mx  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) add(d time.Duration) {
+	c.mx.Lock()
+	c.now = c.now.Add(d)
+	c.mx.Unlock()
+}
+
+func (c *fakeClock) NewTimer(time.Duration) cachego.Timer { panic("not used") }
+
+func get(t *testing.T, h http.Handler, method, target string, header ...string) *http.Response {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func body(t *testing.T, res *http.Response) string {
+	t.Helper()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestMiddleware(t *testing.T) {
+	var calls atomic.Int32
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		switch r.URL.Path {
+		case "/cookie":
+			w.Header().Set("Set-Cookie", "a=b")
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/short":
+			w.Header().Set("Cache-Control", "public, max-age=10")
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		fmt.Fprintf(w, "%v %v", r.Method, n)
+	})
+	clock := &fakeClock{now: time.Now()}
+	m := New(cachego.NewCache[string, CachedResponse](cachego.Opts{Size: 100}), Opts{TTL: time.Minute, Clock: clock})
+	handler := m.Handler(h)
+
+	res := get(t, handler, http.MethodGet, "/users?b=2&a=1")
+	if b := body(t, res); b != "GET 1" || res.Header.Get("X-Cache") != "MISS" {
+		t.Errorf("expected a miss, got %v %v", b, res.Header)
+	}
+
+	clock.add(30 * time.Second)
+	res = get(t, handler, http.MethodGet, "/users?a=1&b=2")
+	if b := body(t, res); b != "GET 1" || res.Header.Get("X-Cache") != "HIT" || res.Header.Get("Age") != "30" {
+		t.Errorf("expected a hit, got %v %v", b, res.Header)
+	}
+
+	// the ttl expired
+	clock.add(30 * time.Second)
+	if b := body(t, get(t, handler, http.MethodGet, "/users?a=1&b=2")); b != "GET 2" {
+		t.Errorf("expected %v, got %v", "GET 2", b)
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		cached       bool
+	}{
+		{http.MethodHead, "/users", true},
+		{http.MethodPost, "/users", false},
+		{http.MethodGet, "/cookie", false},
+		{http.MethodGet, "/nostore", false},
+		{http.MethodGet, "/missing", true},
+		{http.MethodGet, "/error", false},
+	} {
+		get(t, handler, tc.method, tc.path)
+		before := calls.Load()
+		res := get(t, handler, tc.method, tc.path)
+		if cached := calls.Load() == before; cached != tc.cached {
+			t.Errorf("%v %v: expected cached %v, got %v (%v)", tc.method, tc.path, tc.cached, cached, res.Header)
+		}
+	}
+
+	// max-age takes precedence over the ttl
+	get(t, handler, http.MethodGet, "/short")
+	clock.add(11 * time.Second)
+	if res := get(t, handler, http.MethodGet, "/short"); res.Header.Get("X-Cache") != "MISS" {
+		t.Errorf("expected a miss, got %v", res.Header)
+	}
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("12345")) // nolint:errcheck
+		w.Write([]byte("678"))   // nolint:errcheck
+	})
+	handler := New(cachego.NewCache[string, CachedResponse](cachego.Opts{Size: 100}), Opts{MaxBodyBytes: 6}).Handler(h)
+
+	get(t, handler, http.MethodGet, "/")
+	res := get(t, handler, http.MethodGet, "/")
+	if b := body(t, res); b != "12345678" || res.Header.Get("X-Cache") != "MISS" {
+		t.Errorf("expected an uncached response, got %v %v", b, res.Header)
+	}
+}
+
+func TestVaryKey(t *testing.T) {
+	var calls atomic.Int32
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%v %v", r.Header.Get("Accept-Language"), calls.Add(1))
+	})
+	m := New(cachego.NewCache[string, CachedResponse](cachego.Opts{Size: 100}), Opts{Key: VaryKey(DefaultKey, "accept-language")})
+	handler := m.Handler(h)
+
+	for _, tc := range []struct {
+		lang, expected string
+	}{
+		{"en", "en 1"},
+		{"fr", "fr 2"},
+		{"en", "en 1"},
+		{"", " 3"},
+	} {
+		if b := body(t, get(t, handler, http.MethodGet, "/", "Accept-Language", tc.lang)); b != tc.expected {
+			t.Errorf("%v: expected %v, got %v", tc.lang, tc.expected, b)
+		}
+	}
+}
+
+func TestServe(t *testing.T) {
+	// a router passing a context along its handler chain, like gin
+	type context struct {
+		w       http.ResponseWriter
+		r       *http.Request
+		chain   []func(c *context)
+		index   int
+		aborted bool
+	}
+	next := func(c *context) {
+		for c.index++; c.index < len(c.chain) && !c.aborted; c.index++ {
+			c.chain[c.index](c)
+		}
+	}
+
+	var calls atomic.Int32
+	m := New(cachego.NewCache[string, CachedResponse](cachego.Opts{Size: 100}), Opts{})
+	chain := []func(c *context){
+		func(c *context) {
+			hit := m.Serve(c.w, c.r, func(w http.ResponseWriter) {
+				c.w = w
+				next(c)
+			})
+			if hit {
+				c.aborted = true
+			}
+		},
+		func(c *context) {
+			fmt.Fprintf(c.w, "%v", calls.Add(1))
+		},
+	}
+
+	for _, expected := range []string{"MISS", "HIT"} {
+		rec := httptest.NewRecorder()
+		c := &context{w: rec, r: httptest.NewRequest(http.MethodGet, "/", nil), chain: chain, index: -1}
+		next(c)
+		if res := rec.Result(); body(t, res) != "1" || res.Header.Get("X-Cache") != expected {
+			t.Errorf("expected a %v, got %v", expected, res.Header)
+		}
+	}
+}
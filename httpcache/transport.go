@@ -0,0 +1,316 @@
+package httpcache
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// TransportOpts configures a Transport.
+type TransportOpts struct {
+	// Transport sends the requests which cannot be served from the cache. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	// MaxBodyBytes bounds the size of the bodies of the responses cached. Defaults to 1 MiB.
+	MaxBodyBytes int
+	// Clock tells the time the responses are received and served at. Defaults to the system clock.
+	Clock cachego.Clock
+	// Logger receives the errors of the cache, which are otherwise served as misses. Defaults to discarding them.
+	Logger cachego.Logger
+}
+
+// Transport is an http.RoundTripper caching the responses to GET requests as a private cache, following RFC 7234:
+//   - the responses are served from the cache while they are fresh, according to their Cache-Control max-age,
+//     Expires or Last-Modified headers, and the Cache-Control max-age, no-cache and only-if-cached of the requests,
+//   - stale responses with an ETag or Last-Modified header are revalidated with If-None-Match or If-Modified-Since,
+//     and served from the cache again if the server replies 304 Not Modified,
+//   - the responses are only served to the requests with the same values of the headers named by their Vary header,
+//     and a single variant is stored by URL,
+//   - responses or requests marked with Cache-Control no-store are never stored, and unsafe requests (e.g. POST)
+//     invalidate the response of their URL.
+//
+// The responses served from the cache have an X-From-Cache header set to 1. Requests with a Range header or
+// conditional headers of their own are sent as is.
+type Transport struct {
+	c    cachego.Cache[string, CachedResponse]
+	opts TransportOpts
+}
+
+// NewTransport creates a transport caching the responses in the cache, under their URL.
+func NewTransport(c cachego.Cache[string, CachedResponse], opts TransportOpts) *Transport {
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultTransport
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = 1 << 20
+	}
+	if opts.Logger == nil {
+		opts.Logger = nopLogger{}
+	}
+
+	return &Transport{c: c, opts: opts}
+}
+
+// cacheable are the statuses of the responses cacheable by default, so with a heuristic freshness (RFC 7231 6.1).
+var cacheable = map[int]bool{200: true, 203: true, 204: true, 300: true, 301: true, 404: true, 405: true, 410: true, 414: true, 501: true}
+
+// RoundTrip serves the request from the cache if possible, and sends it otherwise, caching its response.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := req.URL.String()
+	if req.Method != http.MethodGet {
+		res, err := t.opts.Transport.RoundTrip(req)
+		if err == nil && unsafe(req.Method) && res.StatusCode < 400 {
+			t.delete(key)
+		}
+		return res, err
+	}
+	if req.Header.Get("Range") != "" || req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return t.opts.Transport.RoundTrip(req)
+	}
+
+	cc := cacheControl(req.Header)
+	cached, ok := t.lookup(key, req)
+	if ok && t.fresh(cached, cc) {
+		return cached.response(req, t.now()), nil
+	}
+	if cc.has("only-if-cached") {
+		return &http.Response{
+			Status:     "504 Gateway Timeout",
+			StatusCode: http.StatusGatewayTimeout,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+
+	out := req
+	etag, modified := cached.Header.Get("ETag"), cached.Header.Get("Last-Modified")
+	if ok && (etag != "" || modified != "") {
+		out = req.Clone(req.Context())
+		if etag != "" {
+			out.Header.Set("If-None-Match", etag)
+		}
+		if modified != "" {
+			out.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	res, err := t.opts.Transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	if out != req && res.StatusCode == http.StatusNotModified {
+		io.Copy(io.Discard, res.Body) // nolint:errcheck
+		res.Body.Close()
+		cached = t.revalidated(cached, res.Header)
+		t.set(key, cached)
+		return cached.response(req, t.now()), nil
+	}
+
+	if cc.has("no-store") {
+		return res, nil
+	}
+	return t.store(key, req, res)
+}
+
+func unsafe(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return false
+	}
+
+	return true
+}
+
+// lookup returns the response cached for the request, if it varies on the same headers.
+func (t *Transport) lookup(key string, req *http.Request) (CachedResponse, bool) {
+	cached, err := t.c.Get(key)
+	if err != nil {
+		if !errors.Is(err, cachego.ErrNotFound) {
+			t.opts.Logger.Printf("httpcache: getting %q failed: %v", key, err)
+		}
+		return CachedResponse{}, false
+	}
+
+	for _, name := range vary(cached.Header) {
+		if name == "*" || strings.Join(req.Header.Values(name), ", ") != strings.Join(cached.VaryHeader.Values(name), ", ") {
+			return CachedResponse{}, false
+		}
+	}
+
+	return cached, true
+}
+
+// fresh reports whether the cached response may be served to the request without revalidating it.
+func (t *Transport) fresh(cached CachedResponse, cc directives) bool {
+	if cc.has("no-cache") || cacheControl(cached.Header).has("no-cache") {
+		return false
+	}
+
+	now := t.now()
+	if maxAge, ok := cc.seconds("max-age"); ok && now.Sub(cached.Stored) > maxAge {
+		return false
+	}
+
+	return now.Before(cached.Expires)
+}
+
+// store caches the response if it is cacheable, and returns it with its body readable again.
+func (t *Transport) store(key string, req *http.Request, res *http.Response) (*http.Response, error) {
+	cc := cacheControl(res.Header)
+	if !cacheable[res.StatusCode] && !cc.has("max-age") && res.Header.Get("Expires") == "" {
+		return res, nil
+	}
+	if cc.has("no-store") {
+		return res, nil
+	}
+	for _, name := range vary(res.Header) {
+		if name == "*" {
+			return res, nil
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, int64(t.opts.MaxBodyBytes)+1))
+	if err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	if len(body) > t.opts.MaxBodyBytes {
+		res.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), res.Body), res.Body}
+		return res, nil
+	}
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	cached := CachedResponse{Status: res.StatusCode, Header: res.Header.Clone(), Body: body}
+	if names := vary(res.Header); len(names) > 0 {
+		cached.VaryHeader = http.Header{}
+		for _, name := range names {
+			if values := req.Header.Values(name); len(values) > 0 {
+				cached.VaryHeader[name] = append([]string(nil), values...)
+			}
+		}
+	}
+	t.age(&cached)
+
+	// the responses which are never fresh are only worth storing to revalidate them
+	if cached.Expires.After(cached.Stored) || cached.Header.Get("ETag") != "" || cached.Header.Get("Last-Modified") != "" {
+		t.set(key, cached)
+	}
+
+	return res, nil
+}
+
+// revalidated returns the cached response updated with the headers of the 304 Not Modified response validating it.
+func (t *Transport) revalidated(cached CachedResponse, header http.Header) CachedResponse {
+	cached.Header = cached.Header.Clone()
+	for name, values := range header {
+		if name != "Content-Length" {
+			cached.Header[name] = values
+		}
+	}
+	t.age(&cached)
+
+	return cached
+}
+
+// age sets the time the response was generated at, and the time it expires at, as of now (RFC 7234 4.2).
+func (t *Transport) age(cached *CachedResponse) {
+	now := t.now()
+	date, err := http.ParseTime(cached.Header.Get("Date"))
+	if err != nil || date.After(now) {
+		date = now
+	}
+
+	age := now.Sub(date)
+	if n, err := strconv.Atoi(cached.Header.Get("Age")); err == nil && time.Duration(n)*time.Second > age {
+		age = time.Duration(n) * time.Second
+	}
+	cached.Stored = now.Add(-age)
+
+	var lifetime time.Duration
+	cc := cacheControl(cached.Header)
+	if maxAge, ok := cc.seconds("max-age"); ok {
+		lifetime = maxAge
+	} else if expires := cached.Header.Get("Expires"); expires != "" {
+		// invalid dates, such as 0, mean the response already expired
+		if at, err := http.ParseTime(expires); err == nil {
+			lifetime = at.Sub(date)
+		}
+	} else if modified, err := http.ParseTime(cached.Header.Get("Last-Modified")); err == nil && cacheable[cached.Status] {
+		// heuristic freshness: 10% of the time since the last modification
+		lifetime = date.Sub(modified) / 10
+	}
+	if lifetime < 0 {
+		lifetime = 0
+	}
+	cached.Expires = cached.Stored.Add(lifetime)
+}
+
+func (t *Transport) set(key string, cached CachedResponse) {
+	if err := t.c.Set(key, cached); err != nil {
+		t.opts.Logger.Printf("httpcache: storing %q failed: %v", key, err)
+	}
+}
+
+func (t *Transport) delete(key string) {
+	if err := t.c.Delete(key); err != nil && !errors.Is(err, cachego.ErrNotFound) {
+		t.opts.Logger.Printf("httpcache: invalidating %q failed: %v", key, err)
+	}
+}
+
+func (t *Transport) now() time.Time {
+	if t.opts.Clock == nil {
+		return time.Now()
+	}
+
+	return t.opts.Clock.Now()
+}
+
+// vary returns the canonical names of the headers listed by the Vary header.
+func vary(h http.Header) []string {
+	var names []string
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+
+	return names
+}
+
+// response returns the cached response to the request, with its Age as of now.
+func (res CachedResponse) response(req *http.Request, now time.Time) *http.Response {
+	h := res.Header.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+	h.Set("Age", strconv.Itoa(int(now.Sub(res.Stored)/time.Second)))
+	h.Set("X-From-Cache", "1")
+
+	return &http.Response{
+		Status:        strconv.Itoa(res.Status) + " " + http.StatusText(res.Status),
+		StatusCode:    res.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(res.Body)),
+		ContentLength: int64(len(res.Body)),
+		Request:       req,
+	}
+}
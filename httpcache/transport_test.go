@@ -0,0 +1,125 @@
+package httpcache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+func TestTransport(t *testing.T) {
+	var calls, notModified atomic.Int32
+	clock := &fakeClock{now: time.Now()}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Date", clock.Now().UTC().Format(http.TimeFormat))
+		switch r.URL.Path {
+		case "/etag":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "accept-language")
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/none":
+			// neither fresh nor validated
+		}
+		fmt.Fprintf(w, "%v %v", r.Header.Get("Accept-Language"), n)
+	}))
+	defer s.Close()
+
+	client := &http.Client{Transport: NewTransport(cachego.NewCache[string, CachedResponse](cachego.Opts{Size: 100}), TransportOpts{Clock: clock})}
+
+	do := func(method, path string, header ...string) (string, bool) {
+		t.Helper()
+		req, err := http.NewRequest(method, s.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		return body(t, res), res.Header.Get("X-From-Cache") == "1"
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		header       []string
+		expected     string
+		cached       bool
+	}{
+		{http.MethodGet, "/etag", nil, " 1", false},
+		{http.MethodGet, "/etag", nil, " 1", true},
+		// the request requires a revalidation
+		{http.MethodGet, "/etag", []string{"Cache-Control", "no-cache"}, " 1", true},
+		{http.MethodGet, "/vary", []string{"Accept-Language", "en"}, "en 3", false},
+		{http.MethodGet, "/vary", []string{"Accept-Language", "en"}, "en 3", true},
+		{http.MethodGet, "/vary", []string{"Accept-Language", "fr"}, "fr 4", false},
+		{http.MethodGet, "/vary", []string{"Accept-Language", "fr"}, "fr 4", true},
+		{http.MethodGet, "/nostore", nil, " 5", false},
+		{http.MethodGet, "/nostore", nil, " 6", false},
+		{http.MethodGet, "/none", nil, " 7", false},
+		{http.MethodGet, "/none", nil, " 8", false},
+		{http.MethodGet, "/none", []string{"Cache-Control", "only-if-cached"}, "", false},
+	} {
+		if got, cached := do(tc.method, tc.path, tc.header...); got != tc.expected || cached != tc.cached {
+			t.Errorf("%v %v: expected %q (cached %v), got %q (cached %v)", tc.path, tc.header, tc.expected, tc.cached, got, cached)
+		}
+	}
+	if n := notModified.Load(); n != 1 {
+		t.Errorf("expected %v revalidation, got %v", 1, n)
+	}
+
+	// the response went stale, and is revalidated
+	clock.add(61 * time.Second)
+	if got, cached := do(http.MethodGet, "/etag"); got != " 1" || !cached || notModified.Load() != 2 {
+		t.Errorf("expected a revalidated response, got %q (cached %v)", got, cached)
+	}
+	if got, cached := do(http.MethodGet, "/etag"); got != " 1" || !cached || notModified.Load() != 2 {
+		t.Errorf("expected a fresh response, got %q (cached %v)", got, cached)
+	}
+
+	// unsafe requests invalidate the response of their URL
+	do(http.MethodPost, "/etag")
+	if got, cached := do(http.MethodGet, "/etag"); !strings.HasPrefix(got, " ") || cached {
+		t.Errorf("expected an uncached response, got %q (cached %v)", got, cached)
+	}
+}
+
+func TestFreshness(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	tr := NewTransport(cachego.NewNopCache[string, CachedResponse](), TransportOpts{Clock: &fakeClock{now: now}})
+
+	for _, tc := range []struct {
+		header   http.Header
+		age      time.Duration
+		lifetime time.Duration
+	}{
+		{http.Header{"Cache-Control": {"max-age=60"}, "Age": {"10"}}, 10 * time.Second, time.Minute},
+		{http.Header{"Date": {now.Add(-5 * time.Second).UTC().Format(http.TimeFormat)}, "Expires": {now.Add(time.Minute).UTC().Format(http.TimeFormat)}}, 5 * time.Second, 65 * time.Second},
+		{http.Header{"Expires": {"0"}}, 0, 0},
+		{http.Header{"Last-Modified": {now.Add(-100 * time.Second).UTC().Format(http.TimeFormat)}}, 0, 10 * time.Second},
+	} {
+		cached := CachedResponse{Status: http.StatusOK, Header: tc.header}
+		tr.age(&cached)
+		if age, lifetime := now.Sub(cached.Stored), cached.Expires.Sub(cached.Stored); age != tc.age || lifetime != tc.lifetime {
+			t.Errorf("%v: expected age %v and lifetime %v, got %v and %v", tc.header, tc.age, tc.lifetime, age, lifetime)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Package httpserver serves a cachego.Cache over a small REST API, to run a cache as a sidecar service:
+//
+//	GET    /keys/{key}  returns the value of the key
+//	PUT    /keys/{key}  stores the request body as the value of the key
+//	DELETE /keys/{key}  removes the key
+//	GET    /keys        lists the keys, if the cache implements Keys
+//	GET    /stats       returns the stats, if the cache implements cachego.StatsProvider
+//
+// Values are JSON by default. Errors are returned as {"error": "..."} with a status matching the error:
+// 404 for a missing key, 507 for a full cache, 413 for an entry too large, 403 for a read-only cache.
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/noam-g4/cachego"
+)
+
+// Opts configures the encoding of the keys and values.
+type Opts[K comparable, V any] struct {
+	// EncodeKey and DecodeKey convert the keys to and from the path segment of the URLs (unescaped).
+	// Default to the keys themselves for string keys, and to JSON otherwise.
+	EncodeKey func(key K) (string, error)
+	DecodeKey func(key string) (K, error)
+	// Marshal and Unmarshal encode and decode the values of the bodies. Default to encoding/json.
+	Marshal   func(value V) ([]byte, error)
+	Unmarshal func(data []byte, value *V) error
+	// ContentType is the content type of the values. Defaults to "application/json".
+	ContentType string
+	// MaxBodyBytes bounds the size of the values stored with PUT. Defaults to 1 MiB.
+	MaxBodyBytes int64
+}
+
+type handler[K comparable, V any] struct {
+	c    cachego.Cache[K, V]
+	opts Opts[K, V]
+}
+
+// New returns a handler serving the cache. It may be mounted under a prefix with http.StripPrefix.
+func New[K comparable, V any](c cachego.Cache[K, V], opts Opts[K, V]) http.Handler {
+	if opts.EncodeKey == nil {
+		opts.EncodeKey = func(key K) (string, error) {
+			if s, ok := any(key).(string); ok {
+				return s, nil
+			}
+			b, err := json.Marshal(key)
+			return string(b), err
+		}
+	}
+	if opts.DecodeKey == nil {
+		opts.DecodeKey = func(s string) (K, error) {
+			var key K
+			if p, ok := any(&key).(*string); ok {
+				*p = s
+				return key, nil
+			}
+			err := json.Unmarshal([]byte(s), &key)
+			return key, err
+		}
+	}
+	if opts.Marshal == nil {
+		opts.Marshal = func(value V) ([]byte, error) { return json.Marshal(value) }
+	}
+	if opts.Unmarshal == nil {
+		opts.Unmarshal = func(data []byte, value *V) error { return json.Unmarshal(data, value) }
+	}
+	if opts.ContentType == "" {
+		opts.ContentType = "application/json"
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = 1 << 20
+	}
+
+	return &handler[K, V]{c: c, opts: opts}
+}
+
+func (h *handler[K, V]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.EscapedPath()
+
+	switch {
+	case path == "/keys":
+		h.serveKeys(w, r)
+	case strings.HasPrefix(path, "/keys/"):
+		h.serveKey(w, r, strings.TrimPrefix(path, "/keys/"))
+	case path == "/stats":
+		h.serveStats(w, r)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (h *handler[K, V]) serveKey(w http.ResponseWriter, r *http.Request, escaped string) {
+	k, err := url.PathUnescape(escaped)
+	if err != nil || k == "" {
+		writeError(w, http.StatusBadRequest, "invalid key")
+		return
+	}
+	key, err := h.opts.DecodeKey(k)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid key: "+err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		v, err := cachego.GetCtx(ctx, h.c, key)
+		if err != nil {
+			writeCacheError(w, err)
+			return
+		}
+		data, err := h.opts.Marshal(v)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", h.opts.ContentType)
+		w.Write(data)
+
+	case http.MethodPut:
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.opts.MaxBodyBytes))
+		if err != nil {
+			writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		var v V
+		if err := h.opts.Unmarshal(data, &v); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid value: "+err.Error())
+			return
+		}
+		if err := cachego.SetCtx(ctx, h.c, key, v); err != nil {
+			writeCacheError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case http.MethodDelete:
+		if err := cachego.DeleteCtx(ctx, h.c, key); err != nil {
+			writeCacheError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *handler[K, V]) serveKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	c, ok := h.c.(interface{ Keys() []K })
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "the cache doesn't list its keys")
+		return
+	}
+
+	keys := c.Keys()
+	encoded := make([]string, 0, len(keys))
+	for _, key := range keys {
+		k, err := h.opts.EncodeKey(key)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		encoded = append(encoded, k)
+	}
+
+	writeJSON(w, http.StatusOK, encoded)
+}
+
+func (h *handler[K, V]) serveStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	p, ok := h.c.(cachego.StatsProvider)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "the cache doesn't keep stats")
+		return
+	}
+
+	s := p.Stats()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"hits":           s.Hits,
+		"misses":         s.Misses,
+		"sets":           s.Sets,
+		"deletes":        s.Deletes,
+		"evictions":      s.Evictions,
+		"expirations":    s.Expirations,
+		"rejections":     s.Rejections,
+		"size":           s.Size,
+		"bytes":          s.Bytes,
+		"hit_ratio":      s.HitRatio(),
+		"uptime_seconds": s.Uptime.Seconds(),
+	})
+}
+
+// writeCacheError writes the error of a cache operation with the status matching it.
+func writeCacheError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, cachego.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, cachego.ErrCacheFull):
+		status = http.StatusInsufficientStorage
+	case errors.Is(err, cachego.ErrEntryTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, cachego.ErrReadOnly):
+		status = http.StatusForbidden
+	}
+
+	writeError(w, status, err.Error())
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
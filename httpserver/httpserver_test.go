@@ -0,0 +1,109 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/noam-g4/cachego"
+)
+
+type response struct {
+	status int
+	body   string
+}
+
+func do(t *testing.T, h http.Handler, method, path, body string) response {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	b, err := io.ReadAll(rec.Result().Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return response{status: rec.Code, body: strings.TrimSpace(string(b))}
+}
+
+func TestServer(t *testing.T) {
+	h := New(cachego.NewCache[string, []int](cachego.Opts{Size: 2}), Opts[string, []int]{})
+
+	for _, tc := range []struct {
+		method, path, body string
+		expected           response
+	}{
+		{http.MethodGet, "/keys/a", "", response{http.StatusNotFound, `{"error":"key a not found"}`}},
+		{http.MethodPut, "/keys/a", "[1,2]", response{http.StatusNoContent, ""}},
+		{http.MethodGet, "/keys/a", "", response{http.StatusOK, "[1,2]"}},
+		{http.MethodPut, "/keys/b%2Fc", "[3]", response{http.StatusNoContent, ""}},
+		{http.MethodGet, "/keys/b%2Fc", "", response{http.StatusOK, "[3]"}},
+		{http.MethodPut, "/keys/d", "[4]", response{http.StatusInsufficientStorage, `{"error":"key d: cache is full"}`}},
+		{http.MethodPut, "/keys/d", "nope", response{http.StatusBadRequest, `{"error":"invalid value: invalid character 'o' in literal null (expecting 'u')"}`}},
+		{http.MethodDelete, "/keys/a", "", response{http.StatusNoContent, ""}},
+		{http.MethodDelete, "/keys/a", "", response{http.StatusNotFound, `{"error":"key a not found"}`}},
+		{http.MethodPost, "/keys/a", "", response{http.StatusMethodNotAllowed, `{"error":"method not allowed"}`}},
+		{http.MethodGet, "/keys/", "", response{http.StatusBadRequest, `{"error":"invalid key"}`}},
+		{http.MethodGet, "/other", "", response{http.StatusNotFound, `{"error":"not found"}`}},
+		{http.MethodGet, "/keys", "", response{http.StatusOK, `["b/c"]`}},
+	} {
+		if got := do(t, h, tc.method, tc.path, tc.body); got != tc.expected {
+			t.Errorf("%v %v: expected %+v, got %+v", tc.method, tc.path, tc.expected, got)
+		}
+	}
+
+	res := do(t, h, http.MethodGet, "/stats", "")
+	var stats map[string]float64
+	if err := json.Unmarshal([]byte(res.body), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if res.status != http.StatusOK || stats["hits"] != 2 || stats["sets"] != 2 || stats["size"] != 1 {
+		t.Errorf("expected 2 hits, 2 sets and 1 entry, got %v %v", res.status, res.body)
+	}
+}
+
+// nolint:errcheck
+func TestServerCodecs(t *testing.T) {
+	c := cachego.NewCache[int, string](cachego.Opts{Size: 10})
+	h := New(c, Opts[int, string]{
+		Marshal:     func(v string) ([]byte, error) { return []byte(v), nil },
+		Unmarshal:   func(data []byte, v *string) error { *v = string(data); return nil },
+		ContentType: "text/plain",
+	})
+
+	if got := do(t, h, http.MethodPut, "/keys/1", "one"); got.status != http.StatusNoContent {
+		t.Errorf("expected %v, got %+v", http.StatusNoContent, got)
+	}
+	if v, err := c.Get(1); err != nil || v != "one" {
+		t.Errorf("expected %v, got %v, %v", "one", v, err)
+	}
+	if got := do(t, h, http.MethodGet, "/keys/1", ""); got.body != "one" {
+		t.Errorf("expected %v, got %+v", "one", got)
+	}
+	if got := do(t, h, http.MethodGet, "/keys/x", ""); got.status != http.StatusBadRequest {
+		t.Errorf("expected %v, got %+v", http.StatusBadRequest, got)
+	}
+
+	c.Set(2, "two")
+	var keys []string
+	json.Unmarshal([]byte(do(t, h, http.MethodGet, "/keys", "").body), &keys)
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "1" || keys[1] != "2" {
+		t.Errorf("expected %v, got %v", []string{"1", "2"}, keys)
+	}
+
+	// read-only caches reject writes, and unsupported endpoints are reported
+	ro := New[string, string](cachego.ReadOnly(cachego.NewNopCache[string, string]()), Opts[string, string]{})
+	if got := do(t, ro, http.MethodPut, "/keys/a", `"a"`); got.status != http.StatusForbidden {
+		t.Errorf("expected %v, got %+v", http.StatusForbidden, got)
+	}
+	if got := do(t, ro, http.MethodGet, "/stats", ""); got.status != http.StatusNotImplemented {
+		t.Errorf("expected %v, got %+v", http.StatusNotImplemented, got)
+	}
+}
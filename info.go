@@ -0,0 +1,166 @@
+package cachego
+
+import (
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// EntryInfo describes a cache entry.
+type EntryInfo struct {
+	// Created is when the key was added to the cache (or restored from a snapshot).
+	Created time.Time
+	// Updated is when the current value was set.
+	Updated time.Time
+	// LastAccess is when the value was last read, or the zero time if it was never read.
+	LastAccess time.Time
+	// Accesses is the number of times the value was read since the key was added.
+	Accesses uint64
+	// Expires is when the entry expires, or the zero time if it doesn't.
+	Expires time.Time
+	// Size is an estimate of the memory held by the value, in bytes.
+	Size int
+}
+
+// Inspector is implemented by caches that keep metadata about their entries.
+type Inspector[K comparable, V any] interface {
+	// GetWithInfo retrieves the value associated with the given key along with its metadata.
+	// It doesn't count as an access: the entry metadata, its recency and the cache stats are left untouched.
+	// If the key is not found, the zero values and an error will be returned.
+	GetWithInfo(key K) (V, EntryInfo, error)
+}
+
+// entryMeta is the metadata kept for every entry.
+// The access fields are atomic, so reads can record accesses while holding a shared lock.
+type entryMeta struct {
+	created  time.Time
+	updated  time.Time
+	accessed atomic.Int64 // in unix nanoseconds
+	accesses atomic.Uint64
+	expires  time.Time
+	weight   int // size of the entry in bytes, if the cache is bounded by bytes
+}
+
+func newEntryMeta(now time.Time, ttl time.Duration) *entryMeta {
+	m := &entryMeta{created: now, updated: now}
+	if ttl > 0 {
+		m.expires = now.Add(ttl)
+	}
+
+	return m
+}
+
+// update records that a new value was set, resetting the expiry.
+func (m *entryMeta) update(now time.Time, ttl time.Duration) {
+	m.updated = now
+	m.expires = time.Time{}
+	if ttl > 0 {
+		m.expires = m.updated.Add(ttl)
+	}
+}
+
+// touch records a read of the value.
+func (m *entryMeta) touch(now time.Time) {
+	m.accessed.Store(now.UnixNano())
+	m.accesses.Add(1)
+}
+
+// snapshot returns the metadata, without the size of the value.
+func (m *entryMeta) snapshot() EntryInfo {
+	info := EntryInfo{
+		Created:  m.created,
+		Updated:  m.updated,
+		Accesses: m.accesses.Load(),
+		Expires:  m.expires,
+	}
+	if ns := m.accessed.Load(); ns > 0 {
+		info.LastAccess = time.Unix(0, ns)
+	}
+
+	return info
+}
+
+func (m *entryMeta) info(value any) EntryInfo {
+	info := m.snapshot()
+	info.Size = estimateSize(value)
+	return info
+}
+
+// estimateSize estimates the memory held by the value, following pointers, slices, maps and interfaces.
+// Memory reachable more than once (e.g. shared pointers) is only counted once.
+func estimateSize(value any) int {
+	if value == nil {
+		return 0
+	}
+
+	v := reflect.ValueOf(value)
+	return int(v.Type().Size()) + sizeOf(v, map[uintptr]struct{}{})
+}
+
+// sizeOf returns the memory referenced by the value, excluding the value itself.
+func sizeOf(v reflect.Value, seen map[uintptr]struct{}) int {
+	switch v.Kind() {
+	case reflect.String:
+		return v.Len()
+
+	case reflect.Pointer:
+		if v.IsNil() || visited(v.Pointer(), seen) {
+			return 0
+		}
+		return int(v.Type().Elem().Size()) + sizeOf(v.Elem(), seen)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		return int(v.Elem().Type().Size()) + sizeOf(v.Elem(), seen)
+
+	case reflect.Slice:
+		if v.IsNil() || visited(v.Pointer(), seen) {
+			return 0
+		}
+		n := v.Cap() * int(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			n += sizeOf(v.Index(i), seen)
+		}
+		return n
+
+	case reflect.Array:
+		n := 0
+		for i := 0; i < v.Len(); i++ {
+			n += sizeOf(v.Index(i), seen)
+		}
+		return n
+
+	case reflect.Map:
+		if v.IsNil() || visited(v.Pointer(), seen) {
+			return 0
+		}
+		t := v.Type()
+		n := v.Len() * int(t.Key().Size()+t.Elem().Size())
+		iter := v.MapRange()
+		for iter.Next() {
+			n += sizeOf(iter.Key(), seen) + sizeOf(iter.Value(), seen)
+		}
+		return n
+
+	case reflect.Struct:
+		n := 0
+		for i := 0; i < v.NumField(); i++ {
+			n += sizeOf(v.Field(i), seen)
+		}
+		return n
+
+	default:
+		return 0
+	}
+}
+
+func visited(p uintptr, seen map[uintptr]struct{}) bool {
+	if _, ok := seen[p]; ok {
+		return true
+	}
+
+	seen[p] = struct{}{}
+	return false
+}
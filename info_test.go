@@ -0,0 +1,97 @@
+package cachego
+
+import (
+	"testing"
+	"time"
+)
+
+// nolint:errcheck
+func TestGetWithInfo(t *testing.T) {
+	start := time.Now()
+
+	cache := NewCache[string, string](Opts{Size: 2, TTL: 60})
+	cache.Set("a", "hello")
+	cache.Get("a")
+	cache.Get("a")
+	cache.Set("a", "world")
+
+	v, info, err := cache.(Inspector[string, string]).GetWithInfo("a")
+	if err != nil || v != "world" {
+		t.Errorf("expected world, got %v (%v)", v, err)
+	}
+
+	if info.Accesses != 2 {
+		t.Errorf("expected 2 accesses, got %v", info.Accesses)
+	}
+
+	if info.Created.Before(start) || info.Updated.Before(info.Created) || info.LastAccess.Before(info.Created) {
+		t.Errorf("expected created <= last access, updated, got %+v", info)
+	}
+
+	if d := info.Expires.Sub(info.Updated); d != 60*time.Second {
+		t.Errorf("expected expiry 60s after the update, got %v", d)
+	}
+
+	if info.Size != 16+5 {
+		t.Errorf("expected size 21, got %v", info.Size)
+	}
+
+	// inspecting doesn't count as an access
+	_, info, _ = cache.(Inspector[string, string]).GetWithInfo("a")
+	if info.Accesses != 2 {
+		t.Errorf("expected 2 accesses, got %v", info.Accesses)
+	}
+
+	if _, _, err := cache.(Inspector[string, string]).GetWithInfo("b"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+// nolint:errcheck
+func TestLRUGetWithInfo(t *testing.T) {
+	cache := NewLRUCache[int, []int](2)
+	cache.Set(1, []int{1, 2, 3})
+	cache.Set(2, nil)
+	cache.Get(1)
+
+	_, info, err := cache.(Inspector[int, []int]).GetWithInfo(1)
+	if err != nil || info.Accesses != 1 || info.LastAccess.IsZero() || !info.Expires.IsZero() {
+		t.Errorf("expected one access and no expiry, got %+v (%v)", info, err)
+	}
+
+	// inspecting key 2 doesn't make it the most recently used
+	cache.(Inspector[int, []int]).GetWithInfo(2)
+	cache.Set(3, nil)
+	if _, err := cache.Get(2); err == nil {
+		t.Errorf("expected key 2 to be evicted")
+	}
+}
+
+func TestEstimateSize(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+
+	shared := &node{Name: "shared"}
+	a := &node{Name: "a", Next: shared}
+	shared.Next = a // cycle
+
+	tests := []struct {
+		value    any
+		expected int
+	}{
+		{nil, 0},
+		{int64(1), 8},
+		{"abc", 16 + 3},
+		{[]byte("abcd"), 24 + 4},
+		{[2]string{"a", "bc"}, 32 + 3},
+		{a, 8 + (24 + 1) + (24 + 6)},
+	}
+
+	for _, test := range tests {
+		if got := estimateSize(test.value); got != test.expected {
+			t.Errorf("expected %v, got %v (%#v)", test.expected, got, test.value)
+		}
+	}
+}
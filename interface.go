@@ -1,7 +1,19 @@
 package cachego
 
+import (
+	"context"
+	"errors"
+	"io"
+)
+
 const defaultSize = 100
 
+// ErrNotFound is returned (wrapped with the key) when a key is not found in the cache.
+var ErrNotFound = errors.New("not found")
+
+// ErrCacheFull is returned (wrapped with the key) by caches rejecting a new key once they reached their capacity.
+var ErrCacheFull = errors.New("cache is full")
+
 // Cache is an interface that represents a generic key-value cache.
 // Implementations of this interface are expected to provide mechanisms
 // for storing and retrieving data in an efficient manner based on the
@@ -14,12 +26,12 @@ type Cache[K comparable, V any] interface {
 
 	// Get retrieves the value associated with the given key from the cache.
 	// If the key is found in the cache, the corresponding value and nil error will be returned.
-	// If the key is not found, the zero value of the value type and an error will be returned.
+	// If the key is not found, the zero value of the value type and an error wrapping ErrNotFound will be returned.
 	Get(key K) (V, error)
 
 	// Delete removes the key-value pair associated with the given key from the cache.
 	// If the key is found in the cache, it will be deleted, and a nil error will be returned.
-	// If the key is not found, an error will be returned.
+	// If the key is not found, an error wrapping ErrNotFound will be returned.
 	Delete(key K) error
 
 	// Clear clears the entire cache, removing all key-value pairs.
@@ -29,11 +41,37 @@ type Cache[K comparable, V any] interface {
 	Clear() error
 }
 
+// ErrNoSnapshot is returned (wrapped) by the Load of a File holding no data yet,
+// e.g. a file that doesn't exist, so callers can tell a first start from a failed restore with errors.Is.
+var ErrNoSnapshot = errors.New("no snapshot")
+
+// ClosableCache is a cache holding resources, such as memory outside the Go heap, that are only released by Close.
+type ClosableCache[K comparable, V any] interface {
+	Cache[K, V]
+	io.Closer
+}
+
+// ExtendedCache is implemented by the built-in caches on top of Cache. Code written against Cache keeps working,
+// while the richer methods are discoverable with a type assertion.
+type ExtendedCache[K comparable, V any] interface {
+	Cache[K, V]
+	StatsProvider
+	io.Closer
+
+	// Len returns the number of entries in the cache.
+	Len() int
+	// Has reports whether the key is in the cache. It doesn't count as an access.
+	Has(key K) bool
+	// Keys returns the keys of the cache, in no particular order.
+	Keys() []K
+}
+
 // File represents an interface for loading from and dumping data to a file.
 type File interface {
 	// Load reads the contents of the file and returns the data read from the file as a byte slice.
 	// If the operation is successful, it returns the read data and a nil error.
-	// If an error occurs during the load operation, it returns a non-nil error.
+	// If an error occurs during the load operation, it returns a non-nil error,
+	// wrapping ErrNoSnapshot if nothing was dumped to the file yet.
 	Load() ([]byte, error)
 
 	// Dump writes the given data as a byte slice to the file.
@@ -41,3 +79,46 @@ type File interface {
 	// If an error occurs during the dump operation, it returns a non-nil error.
 	Dump(data []byte) error
 }
+
+// ContextFile is implemented by files that accept the context of the operation loading or dumping them,
+// so their requests can be traced or cancelled along with it.
+// The caches call LoadContext and DumpContext instead of Load and Dump when a file implements them.
+type ContextFile interface {
+	File
+	LoadContext(ctx context.Context) ([]byte, error)
+	DumpContext(ctx context.Context, data []byte) error
+}
+
+// Resizable is implemented by caches whose capacity can be changed at runtime.
+type Resizable interface {
+	// Resize changes the capacity of the cache to the given number of entries,
+	// evicting entries if the cache holds more than the new size.
+	// It returns an error if the size is less than or equal to zero.
+	Resize(size int32) error
+}
+
+// PolicySwitcher is implemented by caches whose eviction policy can be changed at runtime, keeping their entries,
+// e.g. to experiment with a policy in production behind a flag.
+type PolicySwitcher interface {
+	// SetPolicy switches the cache to the policy, rebuilding the eviction order of the entries from their metadata.
+	// It returns an error if the cache doesn't support the policy.
+	SetPolicy(policy Policy) error
+	// Policy returns the eviction policy of the cache.
+	Policy() Policy
+}
+
+// ContextCache is implemented by caches whose operations accept a context.
+// The operations return the error of the context instead of running once it is done,
+// and pass it on to the files and caches they depend on. See GetCtx to call them on any cache.
+type ContextCache[K comparable, V any] interface {
+	Cache[K, V]
+
+	// GetCtx retrieves the value just like Get.
+	GetCtx(ctx context.Context, key K) (V, error)
+	// SetCtx stores the value just like Set.
+	SetCtx(ctx context.Context, key K, value V) error
+	// DeleteCtx removes the key just like Delete.
+	DeleteCtx(ctx context.Context, key K) error
+	// ClearCtx clears the cache just like Clear, passing the context to the file the cache is persisted to.
+	ClearCtx(ctx context.Context) error
+}
@@ -0,0 +1,89 @@
+package cachego
+
+import (
+	"strings"
+	"sync"
+)
+
+const defaultInternMax = 1 << 16
+
+// Interner deduplicates strings, so that equal strings stored under many entries share their backing memory.
+// It holds up to a maximum number of distinct strings, and starts over once it is full: the strings already
+// interned stay shared, while the next ones are deduplicated from scratch, so the interner never grows unbounded.
+type Interner struct {
+	mx      *sync.Mutex
+	strings map[string]string
+	max     int
+}
+
+// NewInterner creates a new thread-safe interner holding up to max distinct strings.
+// If max is less than or equal to zero, 65536 will be used.
+func NewInterner(max int) *Interner {
+	if max <= 0 {
+		max = defaultInternMax
+	}
+
+	return &Interner{mx: &sync.Mutex{}, strings: make(map[string]string), max: max}
+}
+
+// Intern returns the shared copy of the string, interning a copy of it if it was not seen yet.
+// The copy detaches the interned string from any larger buffer the given string was sliced from.
+// This method is thread-safe.
+func (in *Interner) Intern(s string) string {
+	in.mx.Lock()
+	defer in.mx.Unlock()
+
+	if v, ok := in.strings[s]; ok {
+		return v
+	}
+
+	if len(in.strings) >= in.max {
+		in.strings = make(map[string]string)
+	}
+
+	s = strings.Clone(s)
+	in.strings[s] = s
+	return s
+}
+
+// InternOpts configures WithInterning.
+type InternOpts[V any] struct {
+	// Interner deduplicates the strings, and may be shared by several caches. Defaults to NewInterner(0).
+	Interner *Interner
+	// Value interns the strings held by values that aren't strings themselves (e.g. label sets),
+	// returning the value to store. String values are interned without it.
+	Value func(in *Interner, value V) V
+}
+
+type interned[K comparable, V any] struct {
+	Cache[K, V]
+	interner *Interner
+	value    func(in *Interner, value V) V
+}
+
+// WithInterning wraps the given cache so that string keys and values, and the strings within values
+// interned by InternOpts.Value, are interned on Set. Caches holding many duplicate strings then keep
+// a single copy of each, at the cost of a lookup in the interner on every Set.
+func WithInterning[K comparable, V any](c Cache[K, V], opts InternOpts[V]) Cache[K, V] {
+	w := &interned[K, V]{Cache: c, interner: opts.Interner, value: opts.Value}
+	if w.interner == nil {
+		w.interner = NewInterner(0)
+	}
+
+	return w
+}
+
+// Set interns the strings of the key and value, and stores them in the wrapped cache.
+func (c *interned[K, V]) Set(key K, value V) error {
+	if s, ok := any(key).(string); ok {
+		key = any(c.interner.Intern(s)).(K)
+	}
+
+	if s, ok := any(value).(string); ok {
+		value = any(c.interner.Intern(s)).(V)
+	} else if c.value != nil {
+		value = c.value(c.interner, value)
+	}
+
+	return c.Cache.Set(key, value)
+}
@@ -0,0 +1,64 @@
+package cachego
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestInterner(t *testing.T) {
+	in := NewInterner(2)
+
+	buf := "label=value,other"
+	a := in.Intern(buf[:11])
+	b := in.Intern(strings.Clone(a))
+
+	if unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Errorf("expected equal strings to share their memory")
+	}
+
+	// the interned string doesn't pin the buffer it was sliced from
+	if unsafe.StringData(a) == unsafe.StringData(buf) {
+		t.Errorf("expected the interned string to be a copy")
+	}
+
+	// once full, the interner starts over
+	in.Intern("two")
+	in.Intern("three")
+	if c := in.Intern(strings.Clone(a)); unsafe.StringData(c) == unsafe.StringData(a) {
+		t.Errorf("expected the interner to start over")
+	}
+}
+
+// nolint:errcheck
+func TestInterning(t *testing.T) {
+	inner := NewCache[string, []string](Opts{Size: 10})
+	c := WithInterning(inner, InternOpts[[]string]{
+		Value: func(in *Interner, labels []string) []string {
+			for i, l := range labels {
+				labels[i] = in.Intern(l)
+			}
+			return labels
+		},
+	})
+
+	c.Set(strings.Clone("a"), []string{strings.Clone("env=prod"), strings.Clone("region=eu")})
+	c.Set(strings.Clone("b"), []string{strings.Clone("env=prod"), strings.Clone("region=us")})
+
+	a, _ := c.Get("a")
+	b, _ := c.Get("b")
+	if unsafe.StringData(a[0]) != unsafe.StringData(b[0]) {
+		t.Errorf("expected the labels to share their memory")
+	}
+
+	// string values are interned without a Value func
+	s := WithInterning(NewCache[int, string](Opts{Size: 10}), InternOpts[string]{})
+	s.Set(1, strings.Clone("value"))
+	s.Set(2, strings.Clone("value"))
+
+	v1, _ := s.Get(1)
+	v2, _ := s.Get(2)
+	if unsafe.StringData(v1) != unsafe.StringData(v2) {
+		t.Errorf("expected the values to share their memory")
+	}
+}
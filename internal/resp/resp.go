@@ -0,0 +1,164 @@
+// Package resp encodes and decodes the Redis serialization protocol (RESP2),
+// shared by the Redis client and the RESP server.
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// The types of the RESP2 values.
+const (
+	SimpleString = '+'
+	Error        = '-'
+	Integer      = ':'
+	BulkString   = '$'
+	Array        = '*'
+)
+
+// maxBulk bounds the size of the bulk strings read, as Redis does.
+const maxBulk = 512 << 20
+
+// Value is a decoded RESP2 value.
+type Value struct {
+	Type  byte
+	Str   []byte  // of simple strings, errors and bulk strings
+	Int   int64   // of integers
+	Array []Value // of arrays
+	Null  bool    // for null bulk strings and arrays
+}
+
+// ErrProtocol is returned when the data read is not valid RESP2.
+var ErrProtocol = errors.New("resp: protocol error")
+
+// Read reads the next value.
+func Read(r *bufio.Reader) (Value, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return Value{}, err
+	}
+	if len(line) == 0 {
+		return Value{}, ErrProtocol
+	}
+
+	v := Value{Type: line[0]}
+	switch v.Type {
+	case SimpleString, Error:
+		v.Str = line[1:]
+
+	case Integer:
+		if v.Int, err = strconv.ParseInt(string(line[1:]), 10, 64); err != nil {
+			return Value{}, ErrProtocol
+		}
+
+	case BulkString:
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil || n < -1 || n > maxBulk {
+			return Value{}, ErrProtocol
+		}
+		if n == -1 {
+			v.Null = true
+			break
+		}
+		v.Str = make([]byte, n+2)
+		if _, err := io.ReadFull(r, v.Str); err != nil {
+			return Value{}, err
+		}
+		if v.Str[n] != '\r' || v.Str[n+1] != '\n' {
+			return Value{}, ErrProtocol
+		}
+		v.Str = v.Str[:n]
+
+	case Array:
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil || n < -1 || n > maxBulk {
+			return Value{}, ErrProtocol
+		}
+		if n == -1 {
+			v.Null = true
+			break
+		}
+		v.Array = make([]Value, n)
+		for i := range v.Array {
+			if v.Array[i], err = Read(r); err != nil {
+				return Value{}, err
+			}
+		}
+
+	default:
+		return Value{}, fmt.Errorf("%w: unknown type %q", ErrProtocol, v.Type)
+	}
+
+	return v, nil
+}
+
+// readLine reads a line terminated by CRLF, without the terminator.
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		return nil, ErrProtocol
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, ErrProtocol
+	}
+
+	return append([]byte(nil), line[:len(line)-2]...), nil
+}
+
+// WriteCommand writes a command, i.e. an array of bulk strings.
+func WriteCommand(w *bufio.Writer, args ...[]byte) error {
+	WriteArray(w, len(args))
+	for _, a := range args {
+		WriteBulk(w, a)
+	}
+
+	return w.Flush()
+}
+
+// WriteSimple writes a simple string.
+func WriteSimple(w *bufio.Writer, s string) {
+	w.WriteByte(SimpleString)
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+// WriteError writes an error. By convention, its message starts with an upper case error code (e.g. "ERR").
+func WriteError(w *bufio.Writer, msg string) {
+	w.WriteByte(Error)
+	w.WriteString(msg)
+	w.WriteString("\r\n")
+}
+
+// WriteInt writes an integer.
+func WriteInt(w *bufio.Writer, n int64) {
+	w.WriteByte(Integer)
+	w.WriteString(strconv.FormatInt(n, 10))
+	w.WriteString("\r\n")
+}
+
+// WriteBulk writes a bulk string, or the null bulk string if b is nil.
+func WriteBulk(w *bufio.Writer, b []byte) {
+	if b == nil {
+		w.WriteString("$-1\r\n")
+		return
+	}
+
+	w.WriteByte(BulkString)
+	w.WriteString(strconv.Itoa(len(b)))
+	w.WriteString("\r\n")
+	w.Write(b)
+	w.WriteString("\r\n")
+}
+
+// WriteArray writes the header of an array of n values, which are written next.
+func WriteArray(w *bufio.Writer, n int) {
+	w.WriteByte(Array)
+	w.WriteString(strconv.Itoa(n))
+	w.WriteString("\r\n")
+}
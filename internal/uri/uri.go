@@ -0,0 +1,27 @@
+// Package uri holds the URI encoding shared by the object storage backends.
+package uri
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Encode percent-encodes every byte except the RFC 3986 unreserved characters.
+// Slashes are kept as is unless encodeSlash is set.
+func Encode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
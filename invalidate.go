@@ -0,0 +1,197 @@
+package cachego
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Invalidation is a message telling the caches of other processes to drop keys they may hold stale copies of.
+type Invalidation struct {
+	// Source identifies the cache that published the message, so it can ignore its own messages.
+	Source string `json:"source"`
+	// Keys are the encoded keys to drop (see InvalidationOpts.EncodeKey).
+	Keys []string `json:"keys,omitempty"`
+	// All drops every key, after a Clear.
+	All bool `json:"all,omitempty"`
+}
+
+// Invalidator carries invalidation messages between the processes sharing a cache, e.g. over pub/sub.
+type Invalidator interface {
+	// Publish sends the message to every subscriber, including the ones of the publishing process.
+	Publish(ctx context.Context, msg Invalidation) error
+	// Subscribe calls handle with every message published, until the context is done or the subscription fails.
+	// It blocks, and returns the error of the subscription, or the error of the context once it is done.
+	Subscribe(ctx context.Context, handle func(msg Invalidation)) error
+}
+
+// InvalidationOpts configures WithInvalidation.
+type InvalidationOpts[K comparable] struct {
+	// Invalidator carries the messages. It is required.
+	Invalidator Invalidator
+	// EncodeKey and DecodeKey convert the keys to and from the strings carried by the messages.
+	// Default to the keys themselves for string keys, and to JSON otherwise.
+	EncodeKey func(key K) (string, error)
+	DecodeKey func(key string) (K, error)
+	// Logger receives the messages that cannot be decoded and the failed subscriptions,
+	// which are retried after RetryInterval. Defaults to discarding them.
+	Logger Logger
+	// RetryInterval is the time to wait before subscribing again after the subscription failed. Defaults to 1 second.
+	RetryInterval time.Duration
+}
+
+type invalidated[K comparable, V any] struct {
+	Cache[K, V]
+	id     string
+	opts   InvalidationOpts[K]
+	logger Logger
+	bg     background
+}
+
+// WithInvalidation wraps the given cache, typically a local one in every replica of a service, to keep
+// the replicas coherent: Set, Delete and Clear publish an invalidation once applied to the wrapped cache,
+// and the invalidations published by the other replicas remove the keys from the wrapped cache.
+// The subscription runs in the background until Close, which also closes the wrapped cache if it implements io.Closer.
+// Invalidations are delivered asynchronously, so the replicas may serve stale values for a short while.
+func WithInvalidation[K comparable, V any](c Cache[K, V], opts InvalidationOpts[K]) ClosableCache[K, V] {
+	if opts.EncodeKey == nil {
+		opts.EncodeKey = encodeKey[K]
+	}
+	if opts.DecodeKey == nil {
+		opts.DecodeKey = decodeKey[K]
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = time.Second
+	}
+
+	w := &invalidated[K, V]{Cache: c, id: newSourceID(), opts: opts, logger: loggerOrNop(opts.Logger), bg: newBackground()}
+	w.bg.run(w.subscribe)
+	return w
+}
+
+// Set stores the value in the wrapped cache, then publishes the invalidation of the key.
+// The error of the publication is returned if the value was stored.
+func (w *invalidated[K, V]) Set(key K, value V) error {
+	if err := w.Cache.Set(key, value); err != nil {
+		return err
+	}
+
+	return w.publish(key)
+}
+
+// Delete removes the key from the wrapped cache, then publishes its invalidation,
+// even if the key was missing, since the other replicas may hold it.
+// The error of the wrapped cache takes precedence over the one of the publication.
+func (w *invalidated[K, V]) Delete(key K) error {
+	err := w.Cache.Delete(key)
+	if perr := w.publish(key); err == nil {
+		err = perr
+	}
+
+	return err
+}
+
+// Clear clears the wrapped cache, then publishes the invalidation of every key.
+func (w *invalidated[K, V]) Clear() error {
+	if err := w.Cache.Clear(); err != nil {
+		return err
+	}
+
+	return w.opts.Invalidator.Publish(context.Background(), Invalidation{Source: w.id, All: true})
+}
+
+// Close stops the subscription, and closes the wrapped cache if it implements io.Closer.
+func (w *invalidated[K, V]) Close() error {
+	w.bg.stop()
+	if closer, ok := w.Cache.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+func (w *invalidated[K, V]) publish(key K) error {
+	k, err := w.opts.EncodeKey(key)
+	if err != nil {
+		return fmt.Errorf("encoding key %v for invalidation failed: %w", key, err)
+	}
+
+	return w.opts.Invalidator.Publish(context.Background(), Invalidation{Source: w.id, Keys: []string{k}})
+}
+
+// subscribe applies the messages of the other replicas, subscribing again whenever the subscription fails.
+func (w *invalidated[K, V]) subscribe(ctx context.Context) {
+	for {
+		err := w.opts.Invalidator.Subscribe(ctx, w.apply)
+		if ctx.Err() != nil {
+			return
+		}
+		w.logger.Printf("cachego: invalidation subscription failed, retrying in %v: %v", w.opts.RetryInterval, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.opts.RetryInterval):
+		}
+	}
+}
+
+// apply removes the keys of a message published by another replica from the wrapped cache.
+func (w *invalidated[K, V]) apply(msg Invalidation) {
+	if msg.Source == w.id {
+		return
+	}
+
+	if msg.All {
+		if err := w.Cache.Clear(); err != nil {
+			w.logger.Printf("cachego: clearing the cache for an invalidation failed: %v", err)
+		}
+		return
+	}
+
+	for _, k := range msg.Keys {
+		key, err := w.opts.DecodeKey(k)
+		if err != nil {
+			w.logger.Printf("cachego: decoding invalidated key %q failed: %v", k, err)
+			continue
+		}
+		if err := w.Cache.Delete(key); err != nil && !errors.Is(err, ErrNotFound) {
+			w.logger.Printf("cachego: invalidating key %v failed: %v", key, err)
+		}
+	}
+}
+
+func encodeKey[K comparable](key K) (string, error) {
+	if s, ok := any(key).(string); ok {
+		return s, nil
+	}
+
+	b, err := json.Marshal(key)
+	return string(b), err
+}
+
+func decodeKey[K comparable](s string) (K, error) {
+	var key K
+	if p, ok := any(&key).(*string); ok {
+		*p = s
+		return key, nil
+	}
+
+	err := json.Unmarshal([]byte(s), &key)
+	return key, err
+}
+
+// newSourceID returns a random identifier for the publisher of invalidations.
+func newSourceID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,109 @@
+package cachego
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// bus is an in-process Invalidator.
+type bus struct {
+	mx   sync.Mutex
+	subs map[int]chan Invalidation
+	next int
+}
+
+func newBus() *bus {
+	return &bus{subs: make(map[int]chan Invalidation)}
+}
+
+func (b *bus) Publish(ctx context.Context, msg Invalidation) error {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	for _, s := range b.subs {
+		s <- msg
+	}
+	return nil
+}
+
+func (b *bus) Subscribe(ctx context.Context, handle func(msg Invalidation)) error {
+	b.mx.Lock()
+	id, ch := b.next, make(chan Invalidation, 100)
+	b.subs[id] = ch
+	b.next++
+	b.mx.Unlock()
+
+	defer func() {
+		b.mx.Lock()
+		delete(b.subs, id)
+		b.mx.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg := <-ch:
+			handle(msg)
+		}
+	}
+}
+
+func (b *bus) subscribers() int {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	return len(b.subs)
+}
+
+// eventually waits for the condition to hold.
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the condition to hold")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// nolint:errcheck
+func TestInvalidation(t *testing.T) {
+	b := newBus()
+	a := WithInvalidation(NewCache[int, string](Opts{Size: 10}), InvalidationOpts[int]{Invalidator: b})
+	defer a.Close()
+	other := NewCache[int, string](Opts{Size: 10})
+	c := WithInvalidation(other, InvalidationOpts[int]{Invalidator: b})
+	defer c.Close()
+	eventually(t, func() bool { return b.subscribers() == 2 })
+
+	other.Set(1, "stale")
+	a.Set(1, "one")
+	eventually(t, func() bool {
+		_, err := other.Get(1)
+		return errors.Is(err, ErrNotFound)
+	})
+
+	// the publisher keeps its own value
+	if v, err := a.Get(1); err != nil || v != "one" {
+		t.Errorf("expected one, got %v (%v)", v, err)
+	}
+
+	other.Set(2, "two")
+	other.Set(3, "three")
+	a.Clear()
+	eventually(t, func() bool {
+		_, err := other.Get(2)
+		return errors.Is(err, ErrNotFound)
+	})
+	if _, err := other.Get(3); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+
+	a.Close()
+	eventually(t, func() bool { return b.subscribers() == 1 })
+}
@@ -0,0 +1,187 @@
+// Package kafka applies a Kafka topic of key updates to a cachego.Cache, and produces such updates
+// from the write path of a cache, so caches follow the changes of their source of truth.
+//
+// The records follow the conventions of compacted topics: the record key is the cache key,
+// and the record value is the encoded new value, or nil (a tombstone) when the key was deleted.
+//
+// The package doesn't depend on a Kafka client: the topic is read and written through the Reader
+// and Writer interfaces, implemented in a few lines over the client of your choice, e.g. with kafka-go:
+//
+//	type reader struct{ *kafkago.Reader }
+//
+//	func (r reader) ReadMessage(ctx context.Context) (kafka.Message, error) {
+//		m, err := r.Reader.ReadMessage(ctx)
+//		return kafka.Message{Key: m.Key, Value: m.Value}, err
+//	}
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/noam-g4/cachego"
+)
+
+// Message is a record of the topic.
+type Message struct {
+	Key []byte
+	// Value is the encoded new value, or nil if the key was deleted.
+	Value []byte
+}
+
+// Reader reads the records of the topic, blocking until the next one is available or the context is done.
+type Reader interface {
+	ReadMessage(ctx context.Context) (Message, error)
+}
+
+// Writer writes records to the topic.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...Message) error
+}
+
+// Opts configures the encoding of the records.
+type Opts[K comparable, V any] struct {
+	// EncodeKey and DecodeKey convert the keys to and from the record keys.
+	// Default to the keys themselves for string keys, and to JSON otherwise.
+	EncodeKey func(key K) ([]byte, error)
+	DecodeKey func(data []byte) (K, error)
+	// Marshal and Unmarshal encode and decode the values. Default to encoding/json.
+	Marshal   func(value V) ([]byte, error)
+	Unmarshal func(data []byte, value *V) error
+	// Invalidate makes Consume delete the updated keys instead of storing their new value,
+	// so the cache loads them again from the source of truth. Tombstones always delete the key.
+	Invalidate bool
+	// Logger receives the records that cannot be decoded or applied, which are skipped.
+	// Defaults to discarding them.
+	Logger cachego.Logger
+}
+
+func (o Opts[K, V]) withDefaults() Opts[K, V] {
+	if o.EncodeKey == nil {
+		o.EncodeKey = func(key K) ([]byte, error) {
+			if s, ok := any(key).(string); ok {
+				return []byte(s), nil
+			}
+			return json.Marshal(key)
+		}
+	}
+	if o.DecodeKey == nil {
+		o.DecodeKey = func(data []byte) (K, error) {
+			var key K
+			if p, ok := any(&key).(*string); ok {
+				*p = string(data)
+				return key, nil
+			}
+			err := json.Unmarshal(data, &key)
+			return key, err
+		}
+	}
+	if o.Marshal == nil {
+		o.Marshal = func(value V) ([]byte, error) { return json.Marshal(value) }
+	}
+	if o.Unmarshal == nil {
+		o.Unmarshal = func(data []byte, value *V) error { return json.Unmarshal(data, value) }
+	}
+	if o.Logger == nil {
+		o.Logger = nopLogger{}
+	}
+
+	return o
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
+
+// Consume reads the records of the topic and applies them to the cache: updates store the new value
+// (or delete the key if Opts.Invalidate is set) and tombstones delete the key.
+// It blocks until the context is done or the reader fails, and returns the error of the reader.
+// Records that cannot be decoded or applied (e.g. rejected by a full cache) are logged and skipped.
+func Consume[K comparable, V any](ctx context.Context, r Reader, c cachego.Cache[K, V], opts Opts[K, V]) error {
+	opts = opts.withDefaults()
+
+	for {
+		msg, err := r.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		if err := apply(ctx, c, msg, opts); err != nil {
+			opts.Logger.Printf("cachego: skipping record %q: %v", msg.Key, err)
+		}
+	}
+}
+
+func apply[K comparable, V any](ctx context.Context, c cachego.Cache[K, V], msg Message, opts Opts[K, V]) error {
+	key, err := opts.DecodeKey(msg.Key)
+	if err != nil {
+		return fmt.Errorf("decoding key failed: %w", err)
+	}
+
+	if msg.Value == nil || opts.Invalidate {
+		if err := cachego.DeleteCtx(ctx, c, key); err != nil && !errors.Is(err, cachego.ErrNotFound) {
+			return err
+		}
+		return nil
+	}
+
+	var v V
+	if err := opts.Unmarshal(msg.Value, &v); err != nil {
+		return fmt.Errorf("decoding value failed: %w", err)
+	}
+
+	return cachego.SetCtx(ctx, c, key, v)
+}
+
+type producer[K comparable, V any] struct {
+	cachego.Cache[K, V]
+	w    Writer
+	opts Opts[K, V]
+}
+
+// WithProducer wraps the given cache so that Set and Delete write their update to the topic
+// once applied to the cache. The error of the write is returned if the cache was updated.
+// Clear only clears the wrapped cache, since a topic of key updates cannot express it.
+func WithProducer[K comparable, V any](c cachego.Cache[K, V], w Writer, opts Opts[K, V]) cachego.Cache[K, V] {
+	return &producer[K, V]{Cache: c, w: w, opts: opts.withDefaults()}
+}
+
+// Set stores the value in the wrapped cache, then writes it to the topic.
+func (p *producer[K, V]) Set(key K, value V) error {
+	if err := p.Cache.Set(key, value); err != nil {
+		return err
+	}
+
+	k, err := p.opts.EncodeKey(key)
+	if err != nil {
+		return err
+	}
+	v, err := p.opts.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return p.w.WriteMessages(context.Background(), Message{Key: k, Value: v})
+}
+
+// Delete removes the key from the wrapped cache, then writes a tombstone to the topic,
+// even if the key was missing, since the other caches may hold it.
+// The error of the wrapped cache takes precedence over the one of the write.
+func (p *producer[K, V]) Delete(key K) error {
+	err := p.Cache.Delete(key)
+
+	k, kerr := p.opts.EncodeKey(key)
+	if kerr == nil {
+		kerr = p.w.WriteMessages(context.Background(), Message{Key: k})
+	}
+	if err == nil {
+		err = kerr
+	}
+
+	return err
+}
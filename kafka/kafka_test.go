@@ -0,0 +1,87 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// topic is an in-memory topic.
+type topic chan Message
+
+func (t topic) ReadMessage(ctx context.Context) (Message, error) {
+	select {
+	case <-ctx.Done():
+		return Message{}, ctx.Err()
+	case m := <-t:
+		return m, nil
+	}
+}
+
+func (t topic) WriteMessages(ctx context.Context, msgs ...Message) error {
+	for _, m := range msgs {
+		t <- m
+	}
+	return nil
+}
+
+// nolint:errcheck
+func TestKafka(t *testing.T) {
+	tp := make(topic, 10)
+	source := WithProducer(cachego.NewCache[int, []string](cachego.Opts{Size: 10}), tp, Opts[int, []string]{})
+	replica := cachego.NewCache[int, []string](cachego.Opts{Size: 10})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- Consume(ctx, tp, replica, Opts[int, []string]{}) }()
+
+	source.Set(1, []string{"a"})
+	source.Set(2, []string{"b"})
+	source.Delete(1)
+	tp <- Message{Key: []byte("not json"), Value: []byte("[]")} // skipped
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(tp) > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+
+	if _, err := replica.Get(1); !errors.Is(err, cachego.ErrNotFound) {
+		t.Errorf("expected %v, got %v", cachego.ErrNotFound, err)
+	}
+	if v, err := replica.Get(2); err != nil || len(v) != 1 || v[0] != "b" {
+		t.Errorf("expected [b], got %v (%v)", v, err)
+	}
+}
+
+// nolint:errcheck
+func TestKafkaInvalidate(t *testing.T) {
+	tp := make(topic, 1)
+	replica := cachego.NewCache[string, string](cachego.Opts{Size: 10})
+	replica.Set("a", "stale")
+	tp <- Message{Key: []byte("a"), Value: []byte(`"new"`)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- Consume(ctx, tp, replica, Opts[string, string]{Invalidate: true}) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := replica.Get("a"); errors.Is(err, cachego.ErrNotFound) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if _, err := replica.Get("a"); !errors.Is(err, cachego.ErrNotFound) {
+		t.Errorf("expected %v, got %v", cachego.ErrNotFound, err)
+	}
+}
@@ -0,0 +1,116 @@
+package cachego
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// keyEscaper escapes the separator of the parts of a key, and the escape character itself.
+var keyEscaper = strings.NewReplacer(`\`, `\\`, `:`, `\:`)
+
+// Key joins the parts into a single key, separated by colons, e.g. Key("users", 42, "profile") is "users:42:profile".
+// The colons and backslashes of the parts are escaped with a backslash, so distinct parts never build the same key,
+// unlike hand-concatenated keys: Key("a:b", "c") and Key("a", "b:c") differ.
+// Strings, byte slices, integers, floats and booleans are formatted the same on every platform and release;
+// the other parts are formatted with fmt.Sprint, e.g. with their String method.
+func Key(parts ...any) string {
+	var b strings.Builder
+	for i, p := range parts {
+		if i > 0 {
+			b.WriteByte(':')
+		}
+		keyEscaper.WriteString(&b, keyPart(p)) // nolint:errcheck
+	}
+
+	return b.String()
+}
+
+func keyPart(p any) string {
+	switch v := p.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int:
+		return strconv.FormatInt(int64(v), 10)
+	case int8:
+		return strconv.FormatInt(int64(v), 10)
+	case int16:
+		return strconv.FormatInt(int64(v), 10)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	return fmt.Sprint(p)
+}
+
+// keyHasher is implemented by the keys hashing themselves, such as Key2 and Key3.
+type keyHasher interface {
+	Hash() uint64
+}
+
+// combineHashes mixes the hashes of the parts of a key, in order.
+func combineHashes(sums ...uint64) uint64 {
+	const offset, prime = 14695981039346656037, 1099511628211
+
+	h := uint64(offset)
+	for _, s := range sums {
+		h = (h ^ s) * prime
+		h ^= h >> 29
+	}
+
+	return h
+}
+
+// Key2 is a key of two typed parts, usable as the key of any cache, without formatting its parts into a string.
+// Its hash, used by the sharded and ring caches, combines the hashes of its parts rather than formatting it.
+type Key2[A, B comparable] struct {
+	A A
+	B B
+}
+
+// String returns the parts joined like Key, e.g. for the caches needing string keys.
+func (k Key2[A, B]) String() string {
+	return Key(k.A, k.B)
+}
+
+// Hash returns the hash of the key, the same on every platform and run.
+func (k Key2[A, B]) Hash() uint64 {
+	return combineHashes(hashKey(k.A), hashKey(k.B))
+}
+
+// Key3 is a key of three typed parts, like Key2.
+type Key3[A, B, C comparable] struct {
+	A A
+	B B
+	C C
+}
+
+// String returns the parts joined like Key, e.g. for the caches needing string keys.
+func (k Key3[A, B, C]) String() string {
+	return Key(k.A, k.B, k.C)
+}
+
+// Hash returns the hash of the key, the same on every platform and run.
+func (k Key3[A, B, C]) Hash() uint64 {
+	return combineHashes(hashKey(k.A), hashKey(k.B), hashKey(k.C))
+}
@@ -0,0 +1,55 @@
+package cachego
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		parts    []any
+		expected string
+	}{
+		{[]any{"users", 42, "profile"}, "users:42:profile"},
+		{[]any{"a:b", "c"}, `a\:b:c`},
+		{[]any{"a", "b:c"}, `a:b\:c`},
+		{[]any{`a\`, "b"}, `a\\:b`},
+		{[]any{int8(-1), uint16(2), int64(3), uint64(4), 1.5, float32(0.25), true, []byte("x")}, "-1:2:3:4:1.5:0.25:true:x"},
+		{[]any{time.Second}, "1s"},
+		{[]any{}, ""},
+	}
+
+	for _, tt := range tests {
+		if k := Key(tt.parts...); k != tt.expected {
+			t.Errorf("expected %v, got %v", tt.expected, k)
+		}
+	}
+}
+
+// nolint:errcheck
+func TestTupleKeys(t *testing.T) {
+	k := Key2[string, int]{"users", 42}
+	if s := k.String(); s != "users:42" {
+		t.Errorf("expected users:42, got %v", s)
+	}
+	if hashKey(k) != k.Hash() || k.Hash() != (Key2[string, int]{"users", 42}).Hash() {
+		t.Errorf("expected a stable hash")
+	}
+	if k.Hash() == (Key2[string, int]{"users", 43}).Hash() || (Key2[int, int]{1, 2}).Hash() == (Key2[int, int]{2, 1}).Hash() {
+		t.Errorf("expected distinct hashes")
+	}
+
+	k3 := Key3[string, int, bool]{"a:b", 1, false}
+	if s := k3.String(); s != `a\:b:1:false` {
+		t.Errorf(`expected a\:b:1:false, got %v`, s)
+	}
+	if k3.Hash() == (Key3[string, int, bool]{"a:b", 1, true}).Hash() {
+		t.Errorf("expected distinct hashes")
+	}
+
+	c := NewShardedCache(ShardedOpts[Key2[string, int], string]{})
+	c.Set(k, "value")
+	if v, err := c.Get(Key2[string, int]{"users", 42}); err != nil || v != "value" {
+		t.Errorf("expected value, got %v (%v)", v, err)
+	}
+}
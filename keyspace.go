@@ -0,0 +1,73 @@
+package cachego
+
+import "fmt"
+
+// Publisher publishes a message on a pub/sub channel, e.g. a Redis client's PUBLISH command.
+type Publisher interface {
+	Publish(channel, message string) error
+}
+
+// KeyspaceOpts configures the Redis keyspace notifications published by NotifyKeyspace.
+type KeyspaceOpts[K comparable] struct {
+	// DB is the database index used in the channel names.
+	DB int
+	// Keyspace publishes the event name on "__keyspace@<db>__:<key>" (the "K" flag in Redis).
+	Keyspace bool
+	// Keyevent publishes the key on "__keyevent@<db>__:<event>" (the "E" flag in Redis).
+	// If neither Keyspace nor Keyevent is set, both are published.
+	Keyevent bool
+	// FormatKey formats keys for the channel names and messages. Defaults to fmt.Sprint.
+	FormatKey func(key K) string
+	// Logger receives the errors returned by the publisher. Defaults to discarding them.
+	Logger Logger
+}
+
+// NotifyKeyspace translates the cache events into Redis keyspace notifications
+// ("set", "del", "expired" and "evicted") and publishes them until the events channel is closed.
+// Entries removed with Clear are published as "del".
+// It blocks, so it is usually run in its own goroutine.
+func NotifyKeyspace[K comparable, V any](events <-chan Event[K, V], pub Publisher, opts KeyspaceOpts[K]) {
+	if !opts.Keyspace && !opts.Keyevent {
+		opts.Keyspace = true
+		opts.Keyevent = true
+	}
+
+	format := opts.FormatKey
+	if format == nil {
+		format = func(key K) string { return fmt.Sprint(key) }
+	}
+
+	logger := loggerOrNop(opts.Logger)
+
+	for e := range events {
+		name := keyspaceEvent(e)
+		key := format(e.Key)
+
+		if opts.Keyspace {
+			if err := pub.Publish(fmt.Sprintf("__keyspace@%d__:%s", opts.DB, key), name); err != nil {
+				logger.Printf("publishing keyspace notification failed: %v", err)
+			}
+		}
+
+		if opts.Keyevent {
+			if err := pub.Publish(fmt.Sprintf("__keyevent@%d__:%s", opts.DB, name), key); err != nil {
+				logger.Printf("publishing keyevent notification failed: %v", err)
+			}
+		}
+	}
+}
+
+func keyspaceEvent[K comparable, V any](e Event[K, V]) string {
+	switch e.Type {
+	case EventSet:
+		return "set"
+	case EventExpire:
+		return "expired"
+	case EventEvict:
+		if e.Reason == ReasonCapacity || e.Reason == ReasonMemory {
+			return "evicted"
+		}
+	}
+
+	return "del"
+}
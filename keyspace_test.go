@@ -0,0 +1,59 @@
+package cachego
+
+import (
+	"fmt"
+	"testing"
+)
+
+type recordingPublisher struct {
+	messages []string
+}
+
+func (p *recordingPublisher) Publish(channel, message string) error {
+	p.messages = append(p.messages, channel+" "+message)
+	return nil
+}
+
+func TestNotifyKeyspace(t *testing.T) {
+	events := make(chan Event[int, string], 4)
+	events <- Event[int, string]{Type: EventSet, Key: 1}
+	events <- Event[int, string]{Type: EventDelete, Key: 1, Reason: ReasonDeleted}
+	events <- Event[int, string]{Type: EventExpire, Key: 2, Reason: ReasonExpired}
+	events <- Event[int, string]{Type: EventEvict, Key: 3, Reason: ReasonCapacity}
+	close(events)
+
+	pub := &recordingPublisher{}
+	NotifyKeyspace(events, pub, KeyspaceOpts[int]{DB: 1, Keyevent: true})
+
+	expected := []string{
+		"__keyevent@1__:set 1",
+		"__keyevent@1__:del 1",
+		"__keyevent@1__:expired 2",
+		"__keyevent@1__:evicted 3",
+	}
+
+	if fmt.Sprint(pub.messages) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, pub.messages)
+	}
+}
+
+func TestNotifyKeyspaceDefaults(t *testing.T) {
+	events := make(chan Event[string, string], 2)
+	events <- Event[string, string]{Type: EventSet, Key: "a"}
+	events <- Event[string, string]{Type: EventEvict, Key: "a", Reason: ReasonCleared}
+	close(events)
+
+	pub := &recordingPublisher{}
+	NotifyKeyspace(events, pub, KeyspaceOpts[string]{FormatKey: func(key string) string { return "cache:" + key }})
+
+	expected := []string{
+		"__keyspace@0__:cache:a set",
+		"__keyevent@0__:set cache:a",
+		"__keyspace@0__:cache:a del",
+		"__keyevent@0__:del cache:a",
+	}
+
+	if fmt.Sprint(pub.messages) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, pub.messages)
+	}
+}
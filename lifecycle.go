@@ -0,0 +1,35 @@
+package cachego
+
+import (
+	"context"
+	"sync"
+)
+
+// background runs the goroutines a cache starts (expiry, reload, tuning, memory monitoring),
+// so they can be stopped along with the cache.
+type background struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     *sync.WaitGroup
+	once   *sync.Once
+}
+
+func newBackground() background {
+	ctx, cancel := context.WithCancel(context.Background())
+	return background{ctx: ctx, cancel: cancel, wg: &sync.WaitGroup{}, once: &sync.Once{}}
+}
+
+// run runs the function in a goroutine until the context it is given is done.
+func (b background) run(f func(ctx context.Context)) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		f(b.ctx)
+	}()
+}
+
+// stop cancels the goroutines and waits for them to return. It is safe to call more than once.
+func (b background) stop() {
+	b.once.Do(b.cancel)
+	b.wg.Wait()
+}
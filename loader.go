@@ -0,0 +1,24 @@
+package cachego
+
+import "context"
+
+// Loader loads the value of a key missing from a cache from its backing store (see TypedOpts.Loader).
+// It receives the context of the cache operation, e.g. the one given to GetCtx.
+type Loader[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+// readThrough loads the key missing from the cache with the loader and stores the loaded value in the cache.
+// The value is returned even if the cache rejects it. Without a loader, the key is reported as not found.
+func readThrough[K comparable, V any](ctx context.Context, c Cache[K, V], load Loader[K, V], key K) (V, error) {
+	var empty V
+	if load == nil {
+		return empty, notFound(key)
+	}
+
+	v, err := load(ctx, key)
+	if err != nil {
+		return empty, err
+	}
+
+	c.Set(key, v) // nolint:errcheck
+	return v, nil
+}
@@ -0,0 +1,79 @@
+package cachego
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestLoader(t *testing.T) {
+	errMissing := errors.New("missing")
+	var loaded []string
+	load := func(ctx context.Context, key string) (int, error) {
+		loaded = append(loaded, key)
+		if key == "missing" {
+			return 0, errMissing
+		}
+		return strconv.Atoi(key)
+	}
+
+	for name, policy := range map[string]Policy{"simple": PolicyNone, "lru": PolicyLRU, "lfu": PolicyLFU} {
+		t.Run(name, func(t *testing.T) {
+			loaded = nil
+			c := New[string, int](WithPolicy(policy), WithLoader(load))
+
+			// a miss is loaded and stored, so the next Get is a hit
+			for i := 0; i < 2; i++ {
+				if v, err := c.Get("1"); err != nil || v != 1 {
+					t.Errorf("expected 1, got %v (%v)", v, err)
+				}
+			}
+			if v, ok := c.(Lookuper[string, int]).Lookup("2"); !ok || v != 2 {
+				t.Errorf("expected 2, got %v (%v)", v, ok)
+			}
+			if v, err := GetCtx[string, int](context.Background(), c, "3"); err != nil || v != 3 {
+				t.Errorf("expected 3, got %v (%v)", v, err)
+			}
+			if len(loaded) != 3 {
+				t.Errorf("expected 3 loads, got %v", loaded)
+			}
+
+			// the error of the loader is returned, and nothing is stored
+			if _, err := c.Get("missing"); !errors.Is(err, errMissing) {
+				t.Errorf("expected %v, got %v", errMissing, err)
+			}
+			if _, ok := c.(Lookuper[string, int]).Lookup("missing"); ok {
+				t.Errorf("expected a miss")
+			}
+			if len(loaded) != 5 {
+				t.Errorf("expected 5 loads, got %v", loaded)
+			}
+
+			// a done context is not passed on to the loader
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if _, err := GetCtx[string, int](ctx, c, "4"); !errors.Is(err, context.Canceled) || len(loaded) != 5 {
+				t.Errorf("expected %v without loading, got %v (%v)", context.Canceled, err, loaded)
+			}
+		})
+	}
+}
+
+func TestLoaderFull(t *testing.T) {
+	c := NewCache[string, int](Opts{Size: 1}, TypedOpts[string, int]{
+		Loader: func(ctx context.Context, key string) (int, error) { return strconv.Atoi(key) },
+	})
+
+	if _, err := c.Get("1"); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	// the loaded value is returned even if the cache rejects it
+	if v, err := c.Get("2"); err != nil || v != 2 {
+		t.Errorf("expected 2, got %v (%v)", v, err)
+	}
+	if _, _, err := c.(Inspector[string, int]).GetWithInfo("2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+}
@@ -0,0 +1,19 @@
+package cachego
+
+// Logger is the minimal logging interface used to report internal problems,
+// such as persisted data that cannot be restored. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
+
+func loggerOrNop(l Logger) Logger {
+	if l == nil {
+		return nopLogger{}
+	}
+
+	return l
+}
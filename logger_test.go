@@ -0,0 +1,37 @@
+package cachego
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type recordingLogger struct {
+	lines []string
+}
+
+func (r *recordingLogger) Printf(format string, v ...any) {
+	r.lines = append(r.lines, fmt.Sprintf(format, v...))
+}
+
+func TestLogger(t *testing.T) {
+	file := NewMemoryCacheFile()
+	file.Dump([]byte("invalid"))
+
+	logger := &recordingLogger{}
+	NewCache[int, string](Opts{File: file, Logger: logger})
+	NewLRUCacheWithOpts[int, string](Opts{File: file, Logger: logger})
+
+	if len(logger.lines) != 2 {
+		t.Fatalf("expected 2 lines to be logged, got %v", logger.lines)
+	}
+
+	for _, line := range logger.lines {
+		if !strings.HasPrefix(line, "error unmarshalling cache data") {
+			t.Errorf("expected an unmarshalling error to be logged, got %v", line)
+		}
+	}
+
+	// without a logger, nothing is logged (nor panics)
+	NewCache[int, string](Opts{File: file})
+}
@@ -0,0 +1,31 @@
+package cachego
+
+import "fmt"
+
+// Lookuper is implemented by caches that can report a miss without building an error.
+type Lookuper[K comparable, V any] interface {
+	// Lookup retrieves the value associated with the given key just like Get,
+	// but reports a miss with false instead of an error, so misses don't allocate.
+	Lookup(key K) (V, bool)
+}
+
+// LookupValue retrieves the value associated with the given key from the cache, reporting whether it was found.
+// It uses Lookup if the cache implements Lookuper, so misses don't allocate, and falls back to Get otherwise.
+func LookupValue[K comparable, V any](c Cache[K, V], key K) (V, bool) {
+	if l, ok := c.(Lookuper[K, V]); ok {
+		return l.Lookup(key)
+	}
+
+	v, err := c.Get(key)
+	return v, err == nil
+}
+
+// notFound returns the error of a missing key.
+func notFound[K comparable](key K) error {
+	return fmt.Errorf("key %v %w", key, ErrNotFound)
+}
+
+// cacheFull returns the error of a new key rejected by a full cache.
+func cacheFull[K comparable](key K) error {
+	return fmt.Errorf("key %v: %w", key, ErrCacheFull)
+}
@@ -0,0 +1,123 @@
+package cachego
+
+import (
+	"errors"
+	"testing"
+)
+
+// nolint:errcheck
+func TestLookup(t *testing.T) {
+	caches := map[string]Cache[int, string]{
+		"simple":  NewCache[int, string](Opts{Size: 2}),
+		"lru":     NewLRUCache[int, string](2),
+		"cow":     NewCopyOnWriteCache[int, string](2),
+		"syncmap": NewSyncMapCache[int, string](2),
+		"sharded": NewShardedCache(ShardedOpts[int, string]{Shards: 2}),
+		"hooks":   WithHooks(NewCache[int, string](Opts{Size: 2}), Hooks[int, string]{}),
+	}
+
+	for name, c := range caches {
+		c.Set(1, "one")
+
+		if v, ok := LookupValue(c, 1); !ok || v != "one" {
+			t.Errorf("%s: expected one, got %v (%v)", name, v, ok)
+		}
+
+		if v, ok := LookupValue(c, 2); ok || v != "" {
+			t.Errorf("%s: expected a miss, got %v (%v)", name, v, ok)
+		}
+
+		if _, ok := c.(Lookuper[int, string]); !ok && name != "hooks" {
+			t.Errorf("%s: expected the cache to implement Lookuper", name)
+		}
+
+		allocs := testing.AllocsPerRun(100, func() {
+			LookupValue(c, 2)
+		})
+		if allocs != 0 && name != "hooks" {
+			t.Errorf("%s: expected no allocations on a miss, got %v", name, allocs)
+		}
+	}
+
+	// misses are counted like Get misses
+	c := caches["lru"]
+	if stats := c.(StatsProvider).Stats(); stats.Misses != 102 || stats.Hits != 1 {
+		t.Errorf("expected 102 misses and 1 hit, got %+v", stats)
+	}
+}
+
+// nolint:errcheck
+func TestSentinelErrors(t *testing.T) {
+	caches := map[string]Cache[int, string]{
+		"simple":  NewCache[int, string](Opts{Size: 1}),
+		"lru":     NewLRUCache[int, string](1),
+		"cow":     NewCopyOnWriteCache[int, string](1),
+		"syncmap": NewSyncMapCache[int, string](1),
+		"arena":   NewArenaCache[int, string](1),
+		"slab":    NewSlabCache[int, string](SlabOpts[string]{}),
+		"sharded": NewShardedCache(ShardedOpts[int, string]{Shards: 2}),
+	}
+
+	for name, c := range caches {
+		if _, err := c.Get(1); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected %v, got %v", name, ErrNotFound, err)
+		}
+
+		if err := c.Delete(1); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected %v, got %v", name, ErrNotFound, err)
+		}
+	}
+
+	// the caches rejecting new keys once full
+	for _, name := range []string{"simple", "cow", "arena"} {
+		c := caches[name]
+		c.Set(1, "one")
+		if err := c.Set(2, "two"); !errors.Is(err, ErrCacheFull) {
+			t.Errorf("%s: expected %v, got %v", name, ErrCacheFull, err)
+		}
+	}
+}
+
+// nolint:errcheck
+func TestExtendedCache(t *testing.T) {
+	caches := map[string]Cache[int, string]{
+		"simple":  NewCache[int, string](Opts{Size: 3}),
+		"lru":     NewLRUCache[int, string](3),
+		"cow":     NewCopyOnWriteCache[int, string](3),
+		"syncmap": NewSyncMapCache[int, string](3),
+		"arena":   NewArenaCache[int, string](3),
+		"sharded": NewShardedCache(ShardedOpts[int, string]{Shards: 2}),
+	}
+
+	for name, c := range caches {
+		e, ok := c.(ExtendedCache[int, string])
+		if !ok {
+			t.Errorf("%s: expected the cache to implement ExtendedCache", name)
+			continue
+		}
+
+		c.Set(1, "one")
+		c.Set(2, "two")
+		hits := e.Stats().Hits
+
+		if n := e.Len(); n != 2 {
+			t.Errorf("%s: expected 2, got %v", name, n)
+		}
+
+		if !e.Has(1) || e.Has(3) {
+			t.Errorf("%s: expected only 1 to be found", name)
+		}
+
+		if keys := e.Keys(); len(keys) != 2 || keys[0]+keys[1] != 3 {
+			t.Errorf("%s: expected keys 1 and 2, got %v", name, keys)
+		}
+
+		if h := e.Stats().Hits; h != hits {
+			t.Errorf("%s: expected Has not to count as a hit, got %v hits", name, h-hits)
+		}
+
+		if err := e.Close(); err != nil {
+			t.Errorf("%s: expected nil, got %v", name, err)
+		}
+	}
+}
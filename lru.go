@@ -1,17 +1,50 @@
 package cachego
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 type lru[K comparable, V any] struct {
-	size  int32
-	used  int32
-	head  *node[K, V]
-	tail  *node[K, V]
-	cache map[K]*node[K, V]
-	mx    *sync.Mutex
+	size   int32
+	used   int32
+	head   *node[K, V]
+	tail   *node[K, V]
+	cache  map[K]*node[K, V]
+	mx     *sync.RWMutex
+	file   File
+	victim Cache[K, V]
+	logger Logger
+	ttl    int16
+
+	onEvict  func(key K, value V, reason Reason)
+	onExpire func(key K, value V)
+	loader   Loader[K, V]
+	emitter[K, V]
+	reclaimer[K, V]
+	*watchers[K, V]
+	*hotKeys[K]
+	accesses *accessBuffer[K, V]
+	batch    int32   // number of entries evicted at once, capped at the size when used
+	low      float64 // fraction of the size evicted down to
+	bytes    *weigher[K, V]
+	stats    *counters
+	clock    Clock
+	bg       background
+	expiry   *expirer[K]
+	// doorkeeper keeps out the keys set once, and admission throttles the new keys under overload, if not nil.
+	doorkeeper *doorkeeper
+	admission  *admission
+	// policy orders the list by recency (PolicyLRU), or by frequency then recency (PolicyLFU),
+	// in which case freqs holds the first node of every frequency.
+	policy Policy
+	freqs  map[uint64]*node[K, V]
 }
 
 type node[K comparable, T any] struct {
@@ -19,69 +52,476 @@ type node[K comparable, T any] struct {
 	key   K
 	next  *node[K, T]
 	prev  *node[K, T]
+	meta  *entryMeta
+	freq  uint64 // number of uses, with PolicyLFU
 }
 
 // NewLRUCache creates a new thread-safe instance of an LRU cache with the given size.
 // It returns a Cache[K, V] interface that can be used to interact with the cache.
 func NewLRUCache[K comparable, V any](size int32) Cache[K, V] {
-	return &lru[K, V]{
-		size:  size,
-		cache: make(map[K]*node[K, V], size),
-		mx:    &sync.Mutex{},
+	return newLRU[K, V](size)
+}
+
+// NewLRUCacheWithFile creates a new thread-safe instance of an LRU cache with the given size,
+// restored from the snapshot in the given file.
+// The snapshot preserves the recency order, so a restored cache evicts the same entries the original one would have.
+// If the snapshot holds more entries than the size, only the most recently used ones are restored.
+// The snapshot is written to the file on Clear.
+func NewLRUCacheWithFile[K comparable, V any](size int32, file File) Cache[K, V] {
+	l := newLRU[K, V](size)
+	l.file = file
+
+	if err := l.load(context.Background()); err != nil {
+		l.logger.Printf("%v", err)
+	}
+
+	return l
+}
+
+// NewLRUCacheWithOpts creates a new thread-safe instance of an LRU cache configured by the given options.
+// If the size is less than or equal to zero, a default size of 100 will be used.
+// The TTL, File (see NewLRUCacheWithFile), Context, Events, Reclaim, Logger, Expvar, HotKeys, Tune, AccessBuffer,
+// EvictionBatch, LowWatermark, MaxBytes, MaxEntryBytes, Memory, Doorkeeper, Admission and Clock options
+// are supported, along with all the typed options. Shards and Reload are only supported by the simple cache.
+func NewLRUCacheWithOpts[K comparable, V any](opts Opts, typed ...TypedOpts[K, V]) Cache[K, V] {
+	l := newLRUWithOpts(opts, typedOpts(typed))
+
+	if l.file != nil {
+		if err := l.load(contextOrBackground(opts.Context)); err != nil {
+			l.logger.Printf("%v", err)
+		}
+	}
+
+	l.start(opts)
+	return l
+}
+
+func newLRUWithOpts[K comparable, V any](opts Opts, t TypedOpts[K, V]) *lru[K, V] {
+	s := int32(defaultSize)
+	if opts.Size > 0 {
+		s = opts.Size
+	}
+
+	l := newLRU[K, V](s)
+	l.file = opts.File
+	l.victim = t.Victim
+	l.onEvict = t.OnEvict
+	l.onExpire = t.OnExpire
+	l.loader = t.Loader
+	l.ttl = opts.TTL
+	l.clock = clockOrSystem(opts.Clock)
+	l.stats = newCounters(l.clock)
+	l.expiry = newExpirer(l.bg, l.clock, l.destroy)
+	l.emitter = newEmitter[K, V](opts.Events, l.clock)
+	l.reclaimer = newReclaimer[K, V](opts.Reclaim, l.bg, l.clock)
+	l.logger = loggerOrNop(opts.Logger)
+	l.hotKeys = newHotKeys[K](opts.HotKeys)
+	l.accesses = newAccessBuffer[K, V](opts.AccessBuffer)
+	l.doorkeeper = newDoorkeeper(opts.Doorkeeper, s)
+	l.admission = newAdmission(opts.Admission, s)
+	if opts.LowWatermark > 0 && opts.LowWatermark < 1 {
+		l.low = opts.LowWatermark
 	}
+	l.bytes = newWeigher(opts.MaxBytes, opts.MaxEntryBytes, t.Sizer)
+	if opts.EvictionBatch > 1 {
+		l.batch = opts.EvictionBatch
+	}
+
+	return l
+}
+
+// start runs the background work requested by the options.
+func (l *lru[K, V]) start(opts Opts) {
+	if opts.Expvar != "" {
+		if err := PublishExpvar(opts.Expvar, l); err != nil {
+			l.logger.Printf("%v", err)
+		}
+	}
+
+	if opts.Tune.enabled() {
+		l.bg.run(func(ctx context.Context) { tune(ctx, l.clock, l, opts.Tune) })
+	}
+
+	if opts.Memory.HeapLimit > 0 || opts.Memory.Threshold > 0 {
+		l.bg.run(func(ctx context.Context) { relieve(ctx, l, opts.Memory) })
+	}
+}
+
+func newLRU[K comparable, V any](size int32) *lru[K, V] {
+	l := &lru[K, V]{
+		size:     size,
+		cache:    make(map[K]*node[K, V], size),
+		mx:       &sync.RWMutex{},
+		logger:   nopLogger{},
+		stats:    newCounters(systemClock{}),
+		bg:       newBackground(),
+		watchers: newWatchers[K, V](),
+		batch:    1,
+		clock:    systemClock{},
+		policy:   PolicyLRU,
+	}
+	l.expiry = newExpirer(l.bg, l.clock, l.destroy)
+
+	return l
+}
+
+// load restores the snapshot from the file, keeping its recency order.
+func (l *lru[K, V]) load(ctx context.Context) error {
+	bytes, err := loadFile(ctx, l.file)
+	if err != nil {
+		return fmt.Errorf("loading cache data failed: %w", err)
+	}
+
+	var entries []entry[K, V]
+	if err := json.Unmarshal(bytes, &entries); err != nil {
+		return fmt.Errorf("error unmarshalling cache data: %w", err)
+	}
+
+	if n := int32(len(entries)); n > l.size {
+		l.logger.Printf("cache data size %v is larger than cache size %v, dropping the least recently used entries", n, l.size)
+		entries = entries[:l.size]
+	}
+
+	// restore from the least recently used entry so that the first entry ends up at the head
+	for i := len(entries) - 1; i >= 0; i-- {
+		n := &node[K, V]{key: entries[i].Key, value: entries[i].Value, meta: newEntryMeta(l.clock.Now(), 0)}
+		n.meta.weight = l.bytes.size(n.key, n.value)
+		if err := l.bytes.check(n.key, n.meta.weight); err != nil {
+			l.logger.Printf("skipping cache data entry: %v", err)
+			continue
+		}
+		if old, ok := l.cache[n.key]; ok {
+			l.pull(old)
+			l.bytes.add(-old.meta.weight)
+		} else {
+			l.used++
+		}
+		l.bytes.add(n.meta.weight)
+		l.unshift(n)
+		l.cache[n.key] = n
+	}
+
+	if l.bytes.over() {
+		dropped := l.evict(0)
+		l.logger.Printf("cache data is larger than cache max bytes %v, dropped %v least recently used entries", l.bytes.max, len(dropped))
+	}
+
+	return nil
 }
 
 // Set adds or updates a key-value pair in the LRU cache.
 // If the key already exists in the cache, it updates its value and moves the item to the front of the cache (MRU position).
 // If the key is new and the cache is already at its maximum size, it removes the least recently used item from the cache before adding the new item.
+// The removed item is handed to the victim cache, if one is configured.
+// If the cache is bounded by MaxBytes, the least recently used items are removed until the new item fits.
+// An item larger than MaxEntryBytes (or MaxBytes) is rejected with an error wrapping ErrEntryTooLarge.
+// If the cache has a ttl, the item is removed once it lapses, unless it is set again before.
+// A new key throttled under overload (see Opts.Admission) is rejected with an error wrapping ErrCacheFull,
+// and one set for the first time is not stored if the cache has a doorkeeper (see Opts.Doorkeeper).
 // Thread-safe.
 func (l *lru[K, V]) Set(key K, value V) error {
+	return l.store(key, value, cacheTTL)
+}
+
+// SetWithTTL stores the value just like Set, but removes it once the given ttl lapses rather than the ttl
+// of the cache. A ttl <= 0 means the entry doesn't expire.
+// Thread-safe.
+func (l *lru[K, V]) SetWithTTL(key K, value V, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	return l.store(key, value, ttl)
+}
+
+func (l *lru[K, V]) store(key K, value V, ttl time.Duration) error {
+	size := l.bytes.size(key, value)
+	if err := l.bytes.check(key, size); err != nil {
+		return err
+	}
+
+	evicted, expires, err := l.set(key, value, size, ttl)
+	if err != nil {
+		return err
+	}
+	for _, n := range evicted {
+		l.evicted(n.key, n.value, ReasonCapacity)
+	}
+
+	if !expires.IsZero() {
+		l.expiry.schedule(key, expires)
+	}
+
+	return nil
+}
+
+// set stores the value and returns the nodes evicted to make room for it, if any, and when the value expires,
+// or an error if the key is not admitted.
+func (l *lru[K, V]) set(key K, value V, size int, ttl time.Duration) ([]*node[K, V], time.Time, error) {
 	l.mx.Lock()
 	defer l.mx.Unlock()
 
+	ttl = resolveTTL(ttl, l.ttl)
+
+	if _, ok := l.cache[key]; !ok && (l.doorkeeper != nil || l.admission != nil) {
+		sum, now := hashKey(key), l.clock.Now()
+		if !l.doorkeeper.admit(sum, now) {
+			l.stats.rejections.Add(1)
+			return nil, time.Time{}, nil
+		}
+		if !l.admission.admit(sum, now, l.stats.evictions.Load()) {
+			l.stats.rejections.Add(1)
+			return nil, time.Time{}, cacheFull(key)
+		}
+	}
+
+	l.stats.sets.Add(1)
+	l.emit(EventSet, key, value, 0)
+	l.notify(key, value)
+
 	if n, ok := l.cache[key]; ok {
 		n.value = value
-		l.pull(n)
-		l.unshift(n)
-		return nil
+		n.meta.update(l.clock.Now(), ttl)
+		l.bytes.add(size - n.meta.weight)
+		n.meta.weight = size
+		l.touch(n)
+		if l.bytes.over() {
+			return l.evict(0), n.meta.expires, nil
+		}
+		return nil, n.meta.expires, nil
 	}
 
-	n := &node[K, V]{key: key, value: value}
-	l.unshift(n)
+	n := &node[K, V]{key: key, value: value, meta: newEntryMeta(l.clock.Now(), ttl)}
+	n.meta.weight = size
+	// with PolicyLFU, the new node is linked once room is made, or it would be the first one evicted
+	lfu := l.freqs != nil
+	if !lfu {
+		l.insert(n)
+	}
 	l.cache[key] = n
 	l.used++
+	l.bytes.add(size)
+	expires := n.meta.expires
 
+	var evicted []*node[K, V]
 	if l.used > l.size {
+		n := l.used - l.size
+		batch := l.batch
+		if batch > l.size {
+			batch = l.size
+		}
+		if n < batch {
+			n = batch
+		}
+		if l.low > 0 {
+			// at least the node being set is kept
+			low := int32(float64(l.size) * l.low)
+			if low < 1 {
+				low = 1
+			}
+			if l.used-low > n {
+				n = l.used - low
+			}
+		}
+		evicted = l.evict(n)
+	} else if l.bytes.over() {
+		evicted = l.evict(0)
+	}
+
+	if lfu {
+		l.insert(n)
+	}
+	return evicted, expires, nil
+}
+
+// evict removes up to n of the least recently (or frequently) used nodes, and more while the cache holds more
+// than its max bytes, and returns them.
+func (l *lru[K, V]) evict(n int32) []*node[K, V] {
+	evicted := make([]*node[K, V], 0, n)
+	for ; (n > 0 || l.bytes.over()) && l.tail != nil; n-- {
+		evicted = append(evicted, l.tail)
+		l.bytes.add(-l.tail.meta.weight)
 		l.pop()
 		l.used--
 	}
 
-	return nil
+	return evicted
 }
 
 // Get retrieves the value associated with the given key from the LRU cache.
 // If the key is found in the cache, it moves the corresponding item to the front (MRU position) and returns its value.
-// If the key is not found in the cache, it returns an error indicating that the key was not found.
+// If the key is only found in the victim cache, it is moved back from the victim cache into the LRU cache.
+// If the key is not found in either, it is loaded with the Loader if there is one (see TypedOpts.Loader).
+// Otherwise, it returns an error indicating that the key was not found.
 // Thread-safe.
 func (l *lru[K, V]) Get(key K) (V, error) {
+	return l.GetCtx(context.Background(), key)
+}
+
+// Lookup retrieves the value associated with the given key just like Get,
+// but reports a miss with false instead of building an error, so misses don't allocate.
+// Thread-safe.
+func (l *lru[K, V]) Lookup(key K) (V, bool) {
+	l.record(key)
+
+	v, ok := l.lookup(context.Background(), key)
+	l.stats.lookup(ok)
+	if ok || l.loader == nil {
+		return v, ok
+	}
+
+	v, err := readThrough[K, V](context.Background(), l, l.loader, key)
+	return v, err == nil
+}
+
+// GetCtx retrieves the value just like Get, passing the context to the victim cache and the loader.
+// If the context is done, it returns its error instead.
+// Thread-safe.
+func (l *lru[K, V]) GetCtx(ctx context.Context, key K) (V, error) {
+	var empty V
+	if err := ctx.Err(); err != nil {
+		return empty, err
+	}
+
+	l.record(key)
+	v, ok := l.lookup(ctx, key)
+	l.stats.lookup(ok)
+	if !ok {
+		return readThrough[K, V](ctx, l, l.loader, key)
+	}
+
+	return v, nil
+}
+
+// lookup retrieves the value from the cache or, failing that, moves it back from the victim cache.
+func (l *lru[K, V]) lookup(ctx context.Context, key K) (V, bool) {
+	if v, ok := l.get(key); ok {
+		return v, true
+	}
+
+	if l.victim != nil {
+		if v, err := GetCtx(ctx, l.victim, key); err == nil {
+			DeleteCtx(ctx, l.victim, key) // nolint:errcheck
+			l.Set(key, v)                 // nolint:errcheck
+			return v, true
+		}
+	}
+
+	var empty V
+	return empty, false
+}
+
+func (l *lru[K, V]) get(key K) (V, bool) {
+	if l.accesses != nil {
+		return l.getBuffered(key)
+	}
+
 	l.mx.Lock()
 	defer l.mx.Unlock()
 
 	if n, ok := l.cache[key]; ok {
-		l.pull(n)
-		l.unshift(n)
-		return n.value, nil
+		n.meta.touch(l.clock.Now())
+		l.touch(n)
+		return n.value, true
 	}
 
 	var empty V
-	return empty, fmt.Errorf("key %v not found", key)
+	return empty, false
+}
+
+// getBuffered retrieves the value under the read lock, and records the access in the access buffer
+// instead of promoting the node right away.
+func (l *lru[K, V]) getBuffered(key K) (V, bool) {
+	l.mx.RLock()
+	n, ok := l.cache[key]
+	var v V
+	if ok {
+		v = n.value
+		n.meta.touch(l.clock.Now())
+	}
+	l.mx.RUnlock()
+
+	if ok {
+		l.accesses.push(n, l.promote)
+	}
+
+	return v, ok
+}
+
+// promote moves the accessed nodes to the front of the list, in the order they were accessed.
+// Nodes that were removed since they were accessed are skipped.
+func (l *lru[K, V]) promote(nodes []*node[K, V]) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	for _, n := range nodes {
+		if l.cache[n.key] == n {
+			l.touch(n)
+		}
+	}
+}
+
+// GetWithInfo retrieves the value associated with the given key along with its metadata.
+// It doesn't count as an access: the entry metadata, its recency and the cache stats are left untouched,
+// and the victim cache is not looked up.
+// If the key is not found, the zero values and an error will be returned.
+// Thread-safe.
+func (l *lru[K, V]) GetWithInfo(key K) (V, EntryInfo, error) {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+
+	n, ok := l.cache[key]
+	if !ok {
+		var empty V
+		return empty, EntryInfo{}, notFound(key)
+	}
+
+	return n.value, n.meta.info(n.value), nil
 }
 
-// Delete removes the key-value pair associated with the given key from the LRU cache.
+// Delete removes the key-value pair associated with the given key from the LRU cache (and the victim cache).
 // If the key is found in the cache, it removes the corresponding item from the cache and updates the cache size accordingly.
 // If the key is not found in the cache, it returns an error indicating that the key was not found.
 // Thread-safe.
 func (l *lru[K, V]) Delete(key K) error {
+	return l.DeleteCtx(context.Background(), key)
+}
+
+// SetCtx stores the value just like Set, unless the context is done, in which case it returns its error.
+// Thread-safe.
+func (l *lru[K, V]) SetCtx(ctx context.Context, key K, value V) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return l.Set(key, value)
+}
+
+// DeleteCtx removes the key just like Delete, passing the context to the victim cache.
+// If the context is done, it returns its error instead.
+// Thread-safe.
+func (l *lru[K, V]) DeleteCtx(ctx context.Context, key K) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	n := l.remove(key)
+	if n != nil {
+		l.evicted(n.key, n.value, ReasonDeleted)
+	}
+
+	if l.victim != nil && DeleteCtx(ctx, l.victim, key) == nil {
+		return nil
+	}
+
+	if n == nil {
+		return notFound(key)
+	}
+
+	return nil
+}
+
+func (l *lru[K, V]) remove(key K) *node[K, V] {
 	l.mx.Lock()
 	defer l.mx.Unlock()
 
@@ -89,25 +529,305 @@ func (l *lru[K, V]) Delete(key K) error {
 		l.pull(n)
 		delete(l.cache, key)
 		l.used--
-		return nil
+		l.bytes.add(-n.meta.weight)
+		return n
 	}
 
-	return fmt.Errorf("key %v not found", key)
+	return nil
 }
 
-// Clear removes all items from the LRU cache, making it empty.
+// Clear removes all items from the LRU cache (and the victim cache), making it empty.
+// If the cache was created with a file, a snapshot of the entries is written to it first.
 // Thread-safe.
 func (l *lru[K, V]) Clear() error {
+	return l.ClearCtx(context.Background())
+}
+
+// ClearCtx clears the cache just like Clear, passing the context to the file the cache is persisted to
+// and to the victim cache if it implements ContextCache.
+// If the context is done, it returns its error without clearing the cache.
+// Thread-safe.
+func (l *lru[K, V]) ClearCtx(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	head, err := l.clear(ctx)
+	if err != nil {
+		return err
+	}
+
+	for n := head; n != nil; n = n.next {
+		l.evicted(n.key, n.value, ReasonCleared)
+	}
+
+	if v, ok := l.victim.(ContextCache[K, V]); ok {
+		return v.ClearCtx(ctx)
+	}
+	if l.victim != nil {
+		return l.victim.Clear()
+	}
+
+	return nil
+}
+
+// clear persists and empties the cache, returning the head of the removed items.
+func (l *lru[K, V]) clear(ctx context.Context) (*node[K, V], error) {
 	l.mx.Lock()
 	defer l.mx.Unlock()
 
+	if l.file != nil {
+		// unlike the simple cache snapshot, the entries are ordered from the most to the least recently used
+		entries := make([]entry[K, V], 0, l.used)
+		for n := l.head; n != nil; n = n.next {
+			entries = append(entries, entry[K, V]{Key: n.key, Value: n.value})
+		}
+
+		bytes, _ := json.Marshal(entries)
+		if err := dumpFile(ctx, l.file, bytes); err != nil {
+			return nil, err
+		}
+	}
+
+	head := l.head
 	l.head = nil
 	l.tail = nil
+	if l.freqs != nil {
+		l.freqs = make(map[uint64]*node[K, V])
+	}
 	l.cache = make(map[K]*node[K, V], l.size)
 	l.used = 0
+	l.bytes.reset()
+	return head, nil
+}
+
+func (l *lru[K, V]) evicted(key K, value V, reason Reason) {
+	l.stats.removed(reason)
+
+	// a demoted entry is still cached, so it is only reported once the victim cache drops it
+	if reason == ReasonCapacity && l.victim != nil && l.victim.Set(key, value) == nil {
+		return
+	}
+
+	l.emitRemoval(key, value, reason)
+	l.reclaim(key, value, reason)
+
+	if l.onEvict != nil {
+		l.onEvict(key, value, reason)
+	}
+}
+
+// Len returns the number of entries in the cache, not counting the victim cache.
+// Thread-safe.
+func (l *lru[K, V]) Len() int {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+
+	return len(l.cache)
+}
+
+// Has reports whether the key is in the cache, without counting as an access or looking up the victim cache.
+// Thread-safe.
+func (l *lru[K, V]) Has(key K) bool {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+
+	_, ok := l.cache[key]
+	return ok
+}
+
+// peek returns the value of the key like Has, without counting as an access.
+func (l *lru[K, V]) peek(key K) (V, bool) {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+
+	if n, ok := l.cache[key]; ok {
+		return n.value, true
+	}
+
+	var empty V
+	return empty, false
+}
+
+// Keys returns the keys of the cache, from the most to the least recently used (or frequently used, with PolicyLFU),
+// not counting the victim cache.
+// Thread-safe.
+func (l *lru[K, V]) Keys() []K {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+
+	keys := make([]K, 0, len(l.cache))
+	for n := l.head; n != nil; n = n.next {
+		keys = append(keys, n.key)
+	}
+
+	return keys
+}
+
+// Close stops the background work of the cache, such as expiring its entries or tuning its capacity,
+// and waits for it to return, so no goroutine outlives the cache. The cache remains usable without that work,
+// although its entries no longer expire. It always returns nil.
+// Thread-safe, and may be called more than once.
+func (l *lru[K, V]) Close() error {
+	l.bg.stop()
 	return nil
 }
 
+// Stats returns a snapshot of the cache counters.
+// Thread-safe.
+func (l *lru[K, V]) Stats() Stats {
+	l.mx.RLock()
+	used := l.used
+	bytes := l.bytes.bytes()
+	l.mx.RUnlock()
+
+	s := l.stats.snapshot(used)
+	s.Bytes = bytes
+	return s
+}
+
+// Distribution returns the current distribution of the entries by age and serialized size.
+// The values are encoded after the cache lock is released.
+// Thread-safe.
+func (l *lru[K, V]) Distribution() Distribution {
+	l.mx.RLock()
+	now := l.clock.Now()
+	ages := make([]time.Duration, 0, l.used)
+	values := make([]V, 0, l.used)
+	for n := l.head; n != nil; n = n.next {
+		ages = append(ages, now.Sub(n.meta.created))
+		values = append(values, n.value)
+	}
+	l.mx.RUnlock()
+
+	d := newDistribution()
+	for i, v := range values {
+		d.add(ages[i], v)
+	}
+
+	return d
+}
+
+// DebugDump writes the counters and the entries of the cache, with their metadata,
+// ordered from the most to the least recently used.
+// Thread-safe.
+func (l *lru[K, V]) DebugDump(w io.Writer, opts DebugOpts) error {
+	l.mx.Lock()
+	d := debugState{kind: "lru", order: "most to least recently used", used: l.used, size: l.size, now: l.clock.Now()}
+	if l.policy == PolicyLFU {
+		d.kind, d.order = "lfu", "most to least frequently used"
+	}
+	d.entries = make([]debugEntry, 0, l.used)
+	for n := l.head; n != nil; n = n.next {
+		d.entries = append(d.entries, debugEntry{key: n.key, value: n.value, info: n.meta.snapshot()})
+	}
+	l.mx.Unlock()
+
+	d.stats = l.Stats()
+	return d.write(w, opts)
+}
+
+// DebugString returns the debug dump of the cache with the default options.
+// Thread-safe.
+func (l *lru[K, V]) DebugString() string {
+	b := &strings.Builder{}
+	l.DebugDump(b, DebugOpts{}) // nolint:errcheck
+	return b.String()
+}
+
+func (l *lru[K, V]) capacity() int32 {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	return l.size
+}
+
+// Resize changes the capacity of the cache, evicting the least recently used entries
+// (into the victim cache, if one is configured) if the cache holds more than the new size.
+// It returns an error if the size is less than or equal to zero.
+// If the cache is tuned (see Opts.Tune), the tuner keeps the MinSize and MaxSize bounds it started with,
+// so it moves a capacity outside of them back within them on its next adjustment.
+// Thread-safe.
+func (l *lru[K, V]) Resize(size int32) error {
+	if size <= 0 {
+		return fmt.Errorf("invalid cache size %v", size)
+	}
+
+	l.resize(size)
+	return nil
+}
+
+// resize changes the capacity, evicting the least recently used entries if the cache holds more than the new size.
+func (l *lru[K, V]) resize(size int32) {
+	l.mx.Lock()
+	l.size = size
+	var removed []*node[K, V]
+	if l.used > l.size {
+		removed = l.evict(l.used - l.size)
+	}
+	l.mx.Unlock()
+
+	for _, n := range removed {
+		l.evicted(n.key, n.value, ReasonCapacity)
+	}
+}
+
+// shed evicts the n least recently used entries to relieve memory pressure.
+// They are not handed to the victim cache.
+func (l *lru[K, V]) shed(n int32) {
+	l.mx.Lock()
+	removed := l.evict(n)
+	l.mx.Unlock()
+
+	for _, n := range removed {
+		l.evicted(n.key, n.value, ReasonMemory)
+	}
+}
+
+// ttlSeconds returns the ttl of the entries set from now on.
+func (l *lru[K, V]) ttlSeconds() int16 {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+
+	return l.ttl
+}
+
+// setTTL changes the ttl of the entries set from now on.
+func (l *lru[K, V]) setTTL(ttl int16) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	l.ttl = ttl
+}
+
+// destroy removes the key once its deadline passed, unless it was set again since,
+// in which case the entry expires at another time and the deadline is stale.
+func (l *lru[K, V]) destroy(key K, expires time.Time) {
+	if n := l.expire(key, expires); n != nil {
+		l.evicted(n.key, n.value, ReasonExpired)
+		if l.onExpire != nil {
+			l.onExpire(n.key, n.value)
+		}
+	}
+}
+
+// expire removes the key if it still expires at the given time, returning its node if it was removed.
+func (l *lru[K, V]) expire(key K, expires time.Time) *node[K, V] {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	n, ok := l.cache[key]
+	if !ok || !n.meta.expires.Equal(expires) {
+		return nil
+	}
+
+	l.pull(n)
+	delete(l.cache, key)
+	l.used--
+	l.bytes.add(-n.meta.weight)
+	return n
+}
+
 func (l *lru[K, V]) unshift(n *node[K, V]) {
 	if l.head == nil {
 		l.head = n
@@ -121,17 +841,156 @@ func (l *lru[K, V]) unshift(n *node[K, V]) {
 }
 
 func (l *lru[K, V]) pop() {
-	delete(l.cache, l.tail.key)
-	l.tail.prev.next = nil
-	l.tail = l.tail.prev
+	n := l.tail
+	delete(l.cache, n.key)
+	l.pull(n)
 }
 
 func (l *lru[K, V]) pull(n *node[K, V]) {
+	if l.freqs != nil && l.freqs[n.freq] == n {
+		if n.next != nil && n.next.freq == n.freq {
+			l.freqs[n.freq] = n.next
+		} else {
+			delete(l.freqs, n.freq)
+		}
+	}
+
 	if n.prev != nil {
 		n.prev.next = n.next
+	} else {
+		l.head = n.next
 	}
 
 	if n.next != nil {
 		n.next.prev = n.prev
+	} else {
+		l.tail = n.prev
+	}
+
+	n.prev = nil
+	n.next = nil
+}
+
+// insertBefore links the node before the next one, or at the tail if next is nil.
+func (l *lru[K, V]) insertBefore(n, next *node[K, V]) {
+	if next == nil {
+		n.prev = l.tail
+		if l.tail != nil {
+			l.tail.next = n
+		} else {
+			l.head = n
+		}
+		l.tail = n
+		return
+	}
+
+	n.prev = next.prev
+	n.next = next
+	if next.prev != nil {
+		next.prev.next = n
+	} else {
+		l.head = n
+	}
+	next.prev = n
+}
+
+// insert links a new node: at the head with PolicyLRU, or first among the nodes used once with PolicyLFU.
+func (l *lru[K, V]) insert(n *node[K, V]) {
+	if l.freqs == nil {
+		l.unshift(n)
+		return
+	}
+
+	n.freq = 1
+	l.insertBefore(n, l.freqs[1])
+	l.freqs[1] = n
+}
+
+// touch records a use of the node: it moves to the head with PolicyLRU,
+// or first among the nodes used as often with PolicyLFU.
+func (l *lru[K, V]) touch(n *node[K, V]) {
+	if l.freqs == nil {
+		l.pull(n)
+		l.unshift(n)
+		return
+	}
+
+	next := n.next
+	l.pull(n)
+	n.freq++
+	switch {
+	case l.freqs[n.freq] != nil:
+		next = l.freqs[n.freq]
+	case l.freqs[n.freq-1] != nil:
+		next = l.freqs[n.freq-1]
+	}
+	l.insertBefore(n, next)
+	l.freqs[n.freq] = n
+}
+
+// Policy returns the eviction policy of the cache, PolicyLRU or PolicyLFU.
+// Thread-safe.
+func (l *lru[K, V]) Policy() Policy {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+
+	return l.policy
+}
+
+// SetPolicy switches the cache to evict the least recently used entries (PolicyLRU) or the least frequently used
+// ones (PolicyLFU, breaking ties by recency), keeping its entries. The eviction order is rebuilt from the metadata
+// of the entries: their last access for PolicyLRU, and their number of accesses for PolicyLFU.
+// It returns an error for the other policies.
+// Thread-safe.
+func (l *lru[K, V]) SetPolicy(policy Policy) error {
+	if policy != PolicyLRU && policy != PolicyLFU {
+		return fmt.Errorf("unsupported eviction policy %v", policy)
 	}
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	if policy == l.policy {
+		return nil
+	}
+
+	nodes := make([]*node[K, V], 0, l.used)
+	for n := l.head; n != nil; n = n.next {
+		nodes = append(nodes, n)
+	}
+
+	// the list is already ordered by recency, then by frequency, so the stable sorts keep it as the tie-breaker
+	if policy == PolicyLFU {
+		for _, n := range nodes {
+			n.freq = n.meta.accesses.Load() + 1
+		}
+		sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].freq > nodes[j].freq })
+	} else {
+		sort.SliceStable(nodes, func(i, j int) bool { return lastUse(nodes[i].meta) > lastUse(nodes[j].meta) })
+	}
+
+	l.head, l.tail = nil, nil
+	l.freqs = nil
+	if policy == PolicyLFU {
+		l.freqs = make(map[uint64]*node[K, V])
+	}
+	for _, n := range nodes {
+		n.prev, n.next = nil, nil
+		l.insertBefore(n, nil)
+		if l.freqs != nil && l.freqs[n.freq] == nil {
+			l.freqs[n.freq] = n
+		}
+	}
+	l.policy = policy
+
+	return nil
+}
+
+// lastUse returns the time the entry was last read or set, in unix nanoseconds.
+func lastUse(m *entryMeta) int64 {
+	if accessed := m.accessed.Load(); accessed > m.updated.UnixNano() {
+		return accessed
+	}
+
+	return m.updated.UnixNano()
 }
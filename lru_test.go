@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 )
 
 // nolint:errcheck
@@ -133,3 +134,121 @@ func TestLRUCacheConcurrency(t *testing.T) {
 		t.Errorf("Expected cache size to be %v, but got %v", 3, count)
 	}
 }
+
+// nolint:errcheck
+func TestLRUCacheFile(t *testing.T) {
+	file := NewMemoryCacheFile()
+	cache := NewLRUCacheWithFile[int, string](3, file)
+
+	cache.Set(1, "one")
+	cache.Set(2, "two")
+	cache.Set(3, "three")
+	cache.Get(1) // recency order is now 1, 3, 2
+
+	if err := cache.Clear(); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	// the restored cache evicts the same entry the original one would have
+	cache2 := NewLRUCacheWithFile[int, string](3, file)
+	cache2.Set(4, "four")
+
+	if _, err := cache2.Get(2); err == nil {
+		t.Errorf("Expected key %v to be evicted from the cache, but it was found", 2)
+	}
+
+	for key, val := range map[int]string{1: "one", 3: "three", 4: "four"} {
+		value, err := cache2.Get(key)
+		if err != nil {
+			t.Errorf("Expected key %v to be found in cache, but it was not found", key)
+		}
+		if value != val {
+			t.Errorf("Expected value '%v' for key %v, but got '%v'", val, key, value)
+		}
+	}
+
+	// restoring into a smaller cache keeps the most recently used entries
+	cache3 := NewLRUCacheWithFile[int, string](2, file)
+	if _, err := cache3.Get(2); err == nil {
+		t.Errorf("Expected key %v to be dropped on restore, but it was found", 2)
+	}
+
+	for _, key := range []int{1, 3} {
+		if _, err := cache3.Get(key); err != nil {
+			t.Errorf("Expected key %v to be found in cache, but it was not found", key)
+		}
+	}
+}
+
+// nolint:errcheck
+func TestLRUCacheResize(t *testing.T) {
+	cache := NewLRUCache[int, string](3)
+	cache.Set(1, "one")
+	cache.Set(2, "two")
+	cache.Set(3, "three")
+	cache.Get(1)
+
+	r := cache.(Resizable)
+	if err := r.Resize(-1); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	// shrinking evicts the least recently used entries
+	if err := r.Resize(2); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+	if _, err := cache.Get(2); err == nil {
+		t.Errorf("expected 2 to be evicted")
+	}
+	if _, err := cache.Get(1); err != nil {
+		t.Errorf("expected 1 to be kept, got %v", err)
+	}
+
+	// growing makes room without evicting
+	r.Resize(4)
+	cache.Set(4, "four")
+	cache.Set(5, "five")
+	if s := cache.(StatsProvider).Stats(); s.Size != 4 || s.Evictions != 1 {
+		t.Errorf("expected 4 entries after 1 eviction, got %+v", s)
+	}
+}
+
+// nolint:errcheck
+func TestLRUCacheTTL(t *testing.T) {
+	clock := newFakeClock()
+	expired := make(chan string, 2)
+	cache := NewLRUCacheWithOpts(Opts{Size: 2, TTL: 1, Clock: clock}, TypedOpts[string, string]{
+		OnExpire: func(key, value string) { expired <- key },
+	})
+
+	cache.Set("a", "one")
+	cache.Set("b", "two")
+	clock.wait(t)
+	clock.Advance(600 * time.Millisecond)
+	cache.Set("b", "two") // refreshing b restarts its ttl
+
+	if _, info, err := cache.(Inspector[string, string]).GetWithInfo("b"); err != nil || info.Expires.IsZero() {
+		t.Errorf("expected b to expire, got %v (%v)", info.Expires, err)
+	}
+
+	clock.Advance(400 * time.Millisecond)
+	select {
+	case key := <-expired:
+		if key != "a" {
+			t.Errorf("expected a to expire first, got %v", key)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected OnExpire to be called")
+	}
+
+	if _, err := cache.Get("a"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if v, err := cache.Get("b"); err != nil || v != "two" {
+		t.Errorf("expected two, got %v (%v)", v, err)
+	}
+
+	if size := cache.(StatsProvider).Stats().Expirations; size != 1 {
+		t.Errorf("expected 1, got %v", size)
+	}
+}
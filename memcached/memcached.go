@@ -0,0 +1,422 @@
+// Package memcached serves a cachego.Cache over the memcached text protocol, so an embedded Go cache
+// can replace a memcached dependency, e.g. in integration environments.
+//
+// The supported commands are get (with several keys), set, delete and flush_all, along with version and quit.
+// The flags and expiration times given to set are tracked by the server, since the cache only holds the data.
+package memcached
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// ErrServerClosed is returned by Serve and ListenAndServe after Close.
+var ErrServerClosed = errors.New("memcached: server closed")
+
+// maxKey is the longest key memcached accepts.
+const maxKey = 250
+
+// maxRelative is the largest expiration time taken as relative; larger ones are unix timestamps.
+const maxRelative = 30 * 24 * 60 * 60
+
+// Opts configures a server.
+type Opts struct {
+	// MaxItemBytes bounds the size of the values stored with set. Defaults to 1 MiB, as memcached.
+	MaxItemBytes int
+	// Logger receives the connections closed on an error. Defaults to discarding them.
+	Logger cachego.Logger
+}
+
+// Server serves a cache to memcached clients.
+type Server struct {
+	c    cachego.Cache[string, []byte]
+	opts Opts
+
+	mx        *sync.Mutex
+	items     map[string]*item // flags and expiration of the keys having any
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        *sync.WaitGroup
+}
+
+type item struct {
+	flags   uint32
+	expires time.Time
+	timer   *time.Timer
+}
+
+// NewServer creates a new server of the cache. It serves nothing until Serve or ListenAndServe.
+func NewServer(c cachego.Cache[string, []byte], opts Opts) *Server {
+	if opts.MaxItemBytes <= 0 {
+		opts.MaxItemBytes = 1 << 20
+	}
+	if opts.Logger == nil {
+		opts.Logger = nopLogger{}
+	}
+
+	return &Server{
+		c:         c,
+		opts:      opts,
+		mx:        &sync.Mutex{},
+		items:     make(map[string]*item),
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+		wg:        &sync.WaitGroup{},
+	}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
+
+// ListenAndServe listens on the TCP address and serves the connections until Close.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(l)
+}
+
+// Serve serves the connections accepted on the listener until Close, which closes it.
+// It always returns a non-nil error, ErrServerClosed after Close.
+func (s *Server) Serve(l net.Listener) error {
+	s.mx.Lock()
+	if s.closed {
+		s.mx.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	s.listeners[l] = struct{}{}
+	s.mx.Unlock()
+
+	defer func() {
+		s.mx.Lock()
+		delete(s.listeners, l)
+		s.mx.Unlock()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mx.Lock()
+			closed := s.closed
+			s.mx.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		s.mx.Lock()
+		if s.closed {
+			s.mx.Unlock()
+			conn.Close()
+			return ErrServerClosed
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mx.Unlock()
+
+		go s.serve(conn)
+	}
+}
+
+// Close closes the listeners and the connections, and stops the expiry of the keys.
+// It doesn't close the cache. It always returns nil, and may be called more than once.
+func (s *Server) Close() error {
+	s.mx.Lock()
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for c := range s.conns {
+		c.Close()
+	}
+	for key := range s.items {
+		s.forget(key)
+	}
+	s.mx.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+// serve runs the commands of the connection until it is closed.
+func (s *Server) serve(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mx.Lock()
+		delete(s.conns, conn)
+		s.mx.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		line, err := r.ReadSlice('\n')
+		if err != nil {
+			if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+				s.opts.Logger.Printf("memcached: closing connection from %v: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		quit, err := s.run(r, w, bytes.Fields(line))
+		if err != nil {
+			s.opts.Logger.Printf("memcached: closing connection from %v: %v", conn.RemoteAddr(), err)
+			return
+		}
+		if err := w.Flush(); err != nil || quit {
+			return
+		}
+	}
+}
+
+// run runs a command and writes its reply. It reports whether the connection must be closed,
+// and returns the errors reading the data of the command.
+func (s *Server) run(r *bufio.Reader, w *bufio.Writer, fields [][]byte) (bool, error) {
+	if len(fields) == 0 {
+		w.WriteString("ERROR\r\n")
+		return false, nil
+	}
+
+	args := fields[1:]
+	switch string(fields[0]) {
+	case "get", "gets":
+		if len(args) == 0 {
+			w.WriteString("ERROR\r\n")
+			return false, nil
+		}
+		for _, key := range args {
+			if v, flags, ok := s.get(string(key)); ok {
+				w.WriteString("VALUE ")
+				w.Write(key)
+				w.WriteString(" " + strconv.FormatUint(uint64(flags), 10) + " " + strconv.Itoa(len(v)) + "\r\n")
+				w.Write(v)
+				w.WriteString("\r\n")
+			}
+		}
+		w.WriteString("END\r\n")
+
+	case "set":
+		return false, s.runSet(r, w, args)
+
+	case "delete":
+		noreply := len(args) == 2 && string(args[1]) == "noreply"
+		if len(args) != 1 && !noreply {
+			w.WriteString("CLIENT_ERROR bad command line format\r\n")
+			return false, nil
+		}
+		reply := "NOT_FOUND\r\n"
+		if s.delete(string(args[0])) {
+			reply = "DELETED\r\n"
+		}
+		if !noreply {
+			w.WriteString(reply)
+		}
+
+	case "flush_all":
+		noreply := len(args) > 0 && string(args[len(args)-1]) == "noreply"
+		if noreply {
+			args = args[:len(args)-1]
+		}
+		// a delay isn't supported, but is accepted when zero
+		if len(args) > 1 || (len(args) == 1 && string(args[0]) != "0") {
+			w.WriteString("CLIENT_ERROR delayed flush_all is not supported\r\n")
+			return false, nil
+		}
+		reply := "OK\r\n"
+		if err := s.flush(); err != nil {
+			reply = "SERVER_ERROR " + err.Error() + "\r\n"
+		}
+		if !noreply {
+			w.WriteString(reply)
+		}
+
+	case "version":
+		w.WriteString("VERSION cachego\r\n")
+
+	case "quit":
+		return true, nil
+
+	default:
+		w.WriteString("ERROR\r\n")
+	}
+
+	return false, nil
+}
+
+// runSet runs set <key> <flags> <exptime> <bytes> [noreply], followed by the data block.
+func (s *Server) runSet(r *bufio.Reader, w *bufio.Writer, args [][]byte) error {
+	noreply := len(args) == 5 && string(args[4]) == "noreply"
+	if len(args) != 4 && !noreply {
+		w.WriteString("CLIENT_ERROR bad command line format\r\n")
+		return nil
+	}
+
+	flags, ferr := strconv.ParseUint(string(args[1]), 10, 32)
+	exptime, eerr := strconv.ParseInt(string(args[2]), 10, 64)
+	size, serr := strconv.Atoi(string(args[3]))
+	if ferr != nil || eerr != nil || serr != nil || size < 0 {
+		// the data block cannot be skipped without its size
+		w.WriteString("CLIENT_ERROR bad command line format\r\n")
+		return nil
+	}
+
+	if size > s.opts.MaxItemBytes {
+		// the data block is discarded, as memcached does
+		if _, err := io.CopyN(io.Discard, r, int64(size)+2); err != nil {
+			return err
+		}
+		w.WriteString("SERVER_ERROR object too large for cache\r\n")
+		return nil
+	}
+	data := make([]byte, size+2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return err
+	}
+	if !bytes.HasSuffix(data, []byte("\r\n")) {
+		w.WriteString("CLIENT_ERROR bad data chunk\r\n")
+		return nil
+	}
+
+	key := string(args[0])
+	reply := "STORED\r\n"
+	if len(key) > maxKey || !validKey(key) {
+		reply = "CLIENT_ERROR bad command line format\r\n"
+	} else if err := s.set(key, data[:size], uint32(flags), expiration(exptime)); err != nil {
+		reply = "SERVER_ERROR " + err.Error() + "\r\n"
+	}
+	if !noreply {
+		w.WriteString(reply)
+	}
+
+	return nil
+}
+
+// validKey reports whether the key holds no control characters.
+func validKey(key string) bool {
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return false
+		}
+	}
+
+	return true
+}
+
+// expiration returns the time an exptime expires at: zero never expires, expirations of up to 30 days
+// are relative, larger ones are unix timestamps, and negative ones have already expired.
+func expiration(exptime int64) time.Time {
+	switch {
+	case exptime == 0:
+		return time.Time{}
+	case exptime < 0:
+		return time.Unix(0, 0)
+	case exptime <= maxRelative:
+		return time.Now().Add(time.Duration(exptime) * time.Second)
+	}
+
+	return time.Unix(exptime, 0)
+}
+
+// get returns the value and flags of the key, unless it expired.
+func (s *Server) get(key string) ([]byte, uint32, bool) {
+	s.mx.Lock()
+	var flags uint32
+	if it, ok := s.items[key]; ok {
+		if !it.expires.IsZero() && !time.Now().Before(it.expires) {
+			s.mx.Unlock()
+			return nil, 0, false
+		}
+		flags = it.flags
+	}
+	s.mx.Unlock()
+
+	v, ok := cachego.LookupValue(s.c, key)
+	return v, flags, ok
+}
+
+// set stores the value with its flags and expiration, replacing the ones of the previous value.
+func (s *Server) set(key string, value []byte, flags uint32, expires time.Time) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.forget(key)
+	if !expires.IsZero() && !time.Now().Before(expires) {
+		// already expired: memcached drops the previous value too
+		s.c.Delete(key)
+		return nil
+	}
+
+	if err := s.c.Set(key, value); err != nil {
+		return err
+	}
+
+	if flags == 0 && expires.IsZero() {
+		return nil
+	}
+	it := &item{flags: flags, expires: expires}
+	if !expires.IsZero() {
+		it.timer = time.AfterFunc(time.Until(expires), func() {
+			s.mx.Lock()
+			defer s.mx.Unlock()
+
+			// the key may have been set again since
+			if s.items[key] == it {
+				delete(s.items, key)
+				s.c.Delete(key)
+			}
+		})
+	}
+	s.items[key] = it
+	return nil
+}
+
+// delete removes the key, and reports whether it was present.
+func (s *Server) delete(key string) bool {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	expired := false
+	if it, ok := s.items[key]; ok {
+		expired = !it.expires.IsZero() && !time.Now().Before(it.expires)
+		s.forget(key)
+	}
+
+	return s.c.Delete(key) == nil && !expired
+}
+
+// flush removes every key.
+func (s *Server) flush() error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	for key := range s.items {
+		s.forget(key)
+	}
+
+	return s.c.Clear()
+}
+
+// forget drops the flags and expiration of the key. It must be called with the lock held.
+func (s *Server) forget(key string) {
+	if it, ok := s.items[key]; ok {
+		if it.timer != nil {
+			it.timer.Stop()
+		}
+		delete(s.items, key)
+	}
+}
@@ -0,0 +1,128 @@
+package memcached
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// newServer serves the cache on a local port, and returns a client connected to it.
+func newServer(t *testing.T, c cachego.Cache[string, []byte], opts Opts) *client {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(c, opts)
+	done := make(chan error)
+	go func() { done <- s.Serve(l) }()
+	t.Cleanup(func() {
+		s.Close()
+		if err := <-done; !errors.Is(err, ErrServerClosed) {
+			t.Errorf("expected %v, got %v", ErrServerClosed, err)
+		}
+	})
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &client{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+// do sends the request, and reads the reply lines up to the one expected to end it.
+func (c *client) do(req, last string) string {
+	c.t.Helper()
+
+	if _, err := io.WriteString(c.conn, req); err != nil {
+		c.t.Fatal(err)
+	}
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	b := strings.Builder{}
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			c.t.Fatalf("reading the reply of %q: %v", req, err)
+		}
+		b.WriteString(line)
+		if line == last+"\r\n" || last == "" {
+			return b.String()
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	c := cachego.NewCache[string, []byte](cachego.Opts{Size: 3})
+	cl := newServer(t, c, Opts{MaxItemBytes: 10})
+
+	for _, tc := range []struct {
+		req, last, expected string
+	}{
+		{"get a\r\n", "END", "END\r\n"},
+		{"set a 0 0 3\r\none\r\n", "", "STORED\r\n"},
+		{"set b 42 0 3\r\ntwo\r\n", "", "STORED\r\n"},
+		{"get a b c\r\n", "END", "VALUE a 0 3\r\none\r\nVALUE b 42 3\r\ntwo\r\nEND\r\n"},
+		{"set b 0 0 0\r\n\r\n", "", "STORED\r\n"},
+		{"gets b\r\n", "END", "VALUE b 0 0\r\n\r\nEND\r\n"},
+		{"set big 0 0 11\r\n01234567890\r\n", "", "SERVER_ERROR object too large for cache\r\n"},
+		{"set c 0 0 1 noreply\r\nC\r\nget c\r\n", "END", "VALUE c 0 1\r\nC\r\nEND\r\n"},
+		{"set d 0 0 1\r\nd\r\n", "", "SERVER_ERROR key d: cache is full\r\n"},
+		{"set e 0 0 x\r\n", "", "CLIENT_ERROR bad command line format\r\n"},
+		{"set e 0 0 1\r\nabc", "", "CLIENT_ERROR bad data chunk\r\n"},
+		{"delete a\r\n", "", "DELETED\r\n"},
+		{"delete a\r\n", "", "NOT_FOUND\r\n"},
+		{"delete b noreply\r\nget b\r\n", "END", "END\r\n"},
+		{"incr a 1\r\n", "", "ERROR\r\n"},
+		{"version\r\n", "", "VERSION cachego\r\n"},
+		{"flush_all 10\r\n", "", "CLIENT_ERROR delayed flush_all is not supported\r\n"},
+		{"flush_all\r\n", "", "OK\r\n"},
+		{"get c\r\n", "END", "END\r\n"},
+	} {
+		if got := cl.do(tc.req, tc.last); got != tc.expected {
+			t.Errorf("%q: expected %q, got %q", tc.req, tc.expected, got)
+		}
+	}
+}
+
+// nolint:errcheck
+func TestServerExpiry(t *testing.T) {
+	c := cachego.NewCache[string, []byte](cachego.Opts{Size: 10})
+	cl := newServer(t, c, Opts{})
+
+	cl.do("set a 0 1 1\r\na\r\n", "")
+	cl.do("set b 7 1 1\r\nb\r\n", "")
+	cl.do("set b 0 0 1\r\nB\r\n", "")
+	cl.do("set c 0 -1 1\r\nc\r\n", "")
+
+	if got := cl.do("get a c\r\n", "END"); got != "VALUE a 0 1\r\na\r\nEND\r\n" {
+		t.Errorf("expected %q, got %q", "VALUE a 0 1\r\na\r\nEND\r\n", got)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+	if got := cl.do("get a b\r\n", "END"); got != "VALUE b 0 1\r\nB\r\nEND\r\n" {
+		t.Errorf("expected %q, got %q", "VALUE b 0 1\r\nB\r\nEND\r\n", got)
+	}
+	if _, err := c.Get("a"); !errors.Is(err, cachego.ErrNotFound) {
+		t.Errorf("expected %v, got %v", cachego.ErrNotFound, err)
+	}
+
+	io.WriteString(cl.conn, "quit\r\n")
+	if _, err := cl.r.ReadString('\n'); err != io.EOF {
+		t.Errorf("expected %v, got %v", io.EOF, err)
+	}
+}
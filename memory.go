@@ -0,0 +1,114 @@
+package cachego
+
+import (
+	"context"
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+	"time"
+)
+
+const (
+	defaultMemoryInterval = time.Second
+	defaultMemoryShed     = 0.1
+
+	heapMetric     = "/memory/classes/heap/objects:bytes"
+	gcCyclesMetric = "/gc/cycles/total:gc-cycles"
+)
+
+// MemoryOpts configures a monitor that evicts entries while the heap of the process is too large,
+// so the cache gives memory back before the process runs out of it.
+// The heap is measured as the bytes of heap objects reported by runtime/metrics.
+type MemoryOpts struct {
+	// HeapLimit is the heap size in bytes above which entries are evicted.
+	// If less than or equal to zero, Threshold is used instead.
+	HeapLimit uint64
+	// Threshold is the fraction of the runtime memory limit (GOMEMLIMIT) above which entries are evicted.
+	// It is only used without a HeapLimit, and has no effect if no memory limit is set.
+	// If both HeapLimit and Threshold are less than or equal to zero, the memory is not monitored.
+	Threshold float64
+	// Shed is the fraction of the entries evicted at once while the heap is too large. Defaults to 0.1.
+	// Entries are evicted again only after a garbage collection measured the heap without them.
+	Shed float64
+	// Interval is how often the heap is inspected. Defaults to a second.
+	Interval time.Duration
+}
+
+// shedder is implemented by the caches that can evict entries on demand.
+type shedder interface {
+	StatsProvider
+	shed(n int32)
+}
+
+// pressure decides when the entries of a cache are evicted for memory.
+type pressure struct {
+	limit   uint64
+	shed    float64
+	evicted bool
+	cycles  uint64 // gc cycles when entries were last evicted
+	read    func() (heap, cycles uint64)
+}
+
+func newPressure(opts MemoryOpts) *pressure {
+	p := &pressure{limit: opts.HeapLimit, shed: opts.Shed, read: readHeap}
+	if p.limit == 0 {
+		if limit := debug.SetMemoryLimit(-1); limit < math.MaxInt64 {
+			p.limit = uint64(float64(limit) * opts.Threshold)
+		}
+	}
+	if p.shed <= 0 || p.shed > 1 {
+		p.shed = defaultMemoryShed
+	}
+
+	return p
+}
+
+// relieve runs the monitor described by the options until the context is done.
+func relieve(ctx context.Context, s shedder, opts MemoryOpts) {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultMemoryInterval
+	}
+
+	p := newPressure(opts)
+	if p.limit == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.check(s)
+		}
+	}
+}
+
+// check evicts a fraction of the entries if the heap is above the limit, unless entries were already evicted
+// and no garbage collection happened since (the heap would still count the evicted entries).
+func (p *pressure) check(s shedder) {
+	heap, cycles := p.read()
+	if heap <= p.limit || (p.evicted && cycles == p.cycles) {
+		return
+	}
+
+	size := s.Stats().Size
+	if size == 0 {
+		return
+	}
+
+	n := int32(math.Ceil(float64(size) * p.shed))
+	s.shed(n)
+	p.evicted = true
+	p.cycles = cycles
+}
+
+func readHeap() (heap, cycles uint64) {
+	samples := []metrics.Sample{{Name: heapMetric}, {Name: gcCyclesMetric}}
+	metrics.Read(samples)
+
+	return samples[0].Value.Uint64(), samples[1].Value.Uint64()
+}
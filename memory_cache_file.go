@@ -0,0 +1,44 @@
+package cachego
+
+import (
+	"fmt"
+	"sync"
+)
+
+// memoryCacheFile is an implementation of the File interface.
+// It keeps the dumped data in memory, which is mostly useful for testing persistence without touching the disk.
+type memoryCacheFile struct {
+	data []byte
+	mx   *sync.Mutex
+}
+
+// NewMemoryCacheFile creates a new, empty instance of the File interface backed by a byte buffer.
+// It returns a pointer to the File interface.
+func NewMemoryCacheFile() File {
+	return &memoryCacheFile{mx: &sync.Mutex{}}
+}
+
+// Load returns a copy of the last dumped data.
+// If nothing was dumped yet, it returns an error wrapping ErrNoSnapshot.
+// This method is thread-safe.
+func (m *memoryCacheFile) Load() ([]byte, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if m.data == nil {
+		return nil, fmt.Errorf("memory cache file is empty: %w", ErrNoSnapshot)
+	}
+
+	return append([]byte(nil), m.data...), nil
+}
+
+// Dump replaces the buffer with a copy of the given data.
+// It always returns a nil error.
+// This method is thread-safe.
+func (m *memoryCacheFile) Dump(data []byte) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.data = append([]byte{}, data...)
+	return nil
+}
@@ -0,0 +1,34 @@
+package cachego
+
+import "testing"
+
+func TestMemoryCacheFile(t *testing.T) {
+	file := NewMemoryCacheFile()
+
+	// loading an empty file
+	if _, err := file.Load(); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	data := []byte(`{"1":"one"}`)
+	if err := file.Dump(data); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	// the buffer doesn't alias the dumped data
+	data[2] = '2'
+
+	cache := NewCache[int, string](Opts{Size: 1, File: file})
+	if v, err := cache.Get(1); err != nil || v != "one" {
+		t.Errorf("expected one, got %v (%v)", v, err)
+	}
+
+	// dumping an empty snapshot
+	if err := file.Dump(nil); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	if data, err := file.Load(); err != nil || len(data) != 0 {
+		t.Errorf("expected an empty snapshot, got %v (%v)", data, err)
+	}
+}
@@ -0,0 +1,96 @@
+package cachego
+
+import (
+	"io"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// nolint:errcheck
+func TestMemoryPressure(t *testing.T) {
+	var evicted []Reason
+	c := NewLRUCacheWithOpts(Opts{
+		Size: 100,
+	}, TypedOpts[int, int]{
+		OnEvict: func(key int, value int, reason Reason) {
+			evicted = append(evicted, reason)
+		},
+	})
+	for i := 0; i < 100; i++ {
+		c.Set(i, i)
+	}
+
+	heap, cycles := uint64(200), uint64(1)
+	p := newPressure(MemoryOpts{HeapLimit: 100, Shed: 0.25})
+	p.read = func() (uint64, uint64) { return heap, cycles }
+
+	p.check(c.(shedder))
+	if len(evicted) != 25 || evicted[0] != ReasonMemory {
+		t.Errorf("expected 25 entries evicted for memory, got %v", len(evicted))
+	}
+
+	// no gc measured the heap since the eviction
+	p.check(c.(shedder))
+	if len(evicted) != 25 {
+		t.Errorf("expected 25, got %v", len(evicted))
+	}
+
+	cycles++
+	p.check(c.(shedder))
+	if len(evicted) != 44 {
+		t.Errorf("expected 44, got %v", len(evicted))
+	}
+
+	// the least recently used entries are evicted first
+	if _, err := c.Get(0); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if _, err := c.Get(99); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	heap = 50
+	cycles++
+	p.check(c.(shedder))
+	if len(evicted) != 44 {
+		t.Errorf("expected 44, got %v", len(evicted))
+	}
+
+	if s := c.(StatsProvider).Stats(); s.Evictions != 44 {
+		t.Errorf("expected 44, got %v", s.Evictions)
+	}
+}
+
+// nolint:errcheck
+func TestMemoryMonitor(t *testing.T) {
+	var evicted atomic.Int32
+	c := NewCache(Opts{
+		Size: 10,
+		// any heap is above the limit
+		Memory: MemoryOpts{HeapLimit: 1, Shed: 1, Interval: 10 * time.Millisecond},
+	}, TypedOpts[int, int]{
+		OnEvict: func(key int, value int, reason Reason) {
+			evicted.Add(1)
+		},
+	})
+	defer c.(io.Closer).Close()
+	for i := 0; i < 10; i++ {
+		c.Set(i, i)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if n := evicted.Load(); n != 10 {
+		t.Errorf("expected 10, got %v", n)
+	}
+
+	// a closed cache is no longer monitored
+	c.(io.Closer).Close()
+	c.Set(1, 1)
+	runtime.GC()
+	time.Sleep(50 * time.Millisecond)
+	if n := evicted.Load(); n != 10 {
+		t.Errorf("expected 10, got %v", n)
+	}
+}
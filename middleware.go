@@ -0,0 +1,107 @@
+package cachego
+
+import "time"
+
+// Op identifies a Cache operation.
+type Op string
+
+const (
+	OpSet    Op = "set"
+	OpGet    Op = "get"
+	OpDelete Op = "delete"
+	OpClear  Op = "clear"
+)
+
+// Middleware decorates a cache, intercepting its operations.
+type Middleware[K comparable, V any] func(next Cache[K, V]) Cache[K, V]
+
+// Wrap applies the middleware to the given cache. The first middleware is the outermost one,
+// so it sees every operation first.
+// The returned cache only implements the Cache interface, even if the given cache implements more.
+func Wrap[K comparable, V any](c Cache[K, V], mw ...Middleware[K, V]) Cache[K, V] {
+	for i := len(mw) - 1; i >= 0; i-- {
+		c = mw[i](c)
+	}
+
+	return c
+}
+
+// Interceptor is called around every operation of a cache with the operation, its key
+// (the zero value for OpClear) and a function performing the operation on the wrapped cache.
+// It returns the error of the operation, which it may inspect or replace.
+type Interceptor[K comparable, V any] func(op Op, key K, call func() error) error
+
+// Intercept creates a middleware that runs every operation through the interceptor.
+func Intercept[K comparable, V any](i Interceptor[K, V]) Middleware[K, V] {
+	return func(next Cache[K, V]) Cache[K, V] {
+		return &intercepted[K, V]{next: next, intercept: i}
+	}
+}
+
+type intercepted[K comparable, V any] struct {
+	next      Cache[K, V]
+	intercept Interceptor[K, V]
+}
+
+func (c *intercepted[K, V]) Set(key K, value V) error {
+	return c.intercept(OpSet, key, func() error { return c.next.Set(key, value) })
+}
+
+func (c *intercepted[K, V]) Get(key K) (V, error) {
+	var v V
+	err := c.intercept(OpGet, key, func() (err error) {
+		v, err = c.next.Get(key)
+		return err
+	})
+
+	return v, err
+}
+
+func (c *intercepted[K, V]) Delete(key K) error {
+	return c.intercept(OpDelete, key, func() error { return c.next.Delete(key) })
+}
+
+func (c *intercepted[K, V]) Clear() error {
+	var empty K
+	return c.intercept(OpClear, empty, c.next.Clear)
+}
+
+// LoggingMiddleware logs every operation with its key, duration and error.
+func LoggingMiddleware[K comparable, V any](logger Logger) Middleware[K, V] {
+	return Intercept[K, V](func(op Op, key K, call func() error) error {
+		start := time.Now()
+		err := call()
+		logger.Printf("cachego: %v %v took %v, error: %v", op, key, time.Since(start), err)
+		return err
+	})
+}
+
+// MetricsRecorder receives the outcome of every cache operation.
+type MetricsRecorder interface {
+	ObserveOp(op Op, duration time.Duration, err error)
+}
+
+// MetricsMiddleware reports the duration and error of every operation to the recorder.
+func MetricsMiddleware[K comparable, V any](recorder MetricsRecorder) Middleware[K, V] {
+	return Intercept[K, V](func(op Op, key K, call func() error) error {
+		start := time.Now()
+		err := call()
+		recorder.ObserveOp(op, time.Since(start), err)
+		return err
+	})
+}
+
+// Tracer starts a span for a cache operation and returns a function ending it with the operation's error.
+type Tracer interface {
+	StartSpan(op Op, key any) (end func(err error))
+}
+
+// TracingMiddleware wraps every operation in a span started by the tracer.
+func TracingMiddleware[K comparable, V any](tracer Tracer) Middleware[K, V] {
+	return Intercept[K, V](func(op Op, key K, call func() error) error {
+		end := tracer.StartSpan(op, key)
+		err := call()
+		end(err)
+		return err
+	})
+}
@@ -0,0 +1,71 @@
+package cachego
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type opRecorder struct {
+	ops []string
+}
+
+func (r *opRecorder) ObserveOp(op Op, duration time.Duration, err error) {
+	r.ops = append(r.ops, fmt.Sprintf("%v:%v", op, err == nil))
+}
+
+func (r *opRecorder) StartSpan(op Op, key any) func(err error) {
+	r.ops = append(r.ops, fmt.Sprintf("start %v %v", op, key))
+	return func(err error) {
+		r.ops = append(r.ops, fmt.Sprintf("end %v %v", op, key))
+	}
+}
+
+// nolint:errcheck
+func TestWrap(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware[int, string] {
+		return Intercept[int, string](func(op Op, key int, call func() error) error {
+			order = append(order, name)
+			return call()
+		})
+	}
+
+	metrics := &opRecorder{}
+	tracer := &opRecorder{}
+	logger := &recordingLogger{}
+
+	c := Wrap(NewLRUCache[int, string](2),
+		mw("outer"),
+		mw("inner"),
+		LoggingMiddleware[int, string](logger),
+		MetricsMiddleware[int, string](metrics),
+		TracingMiddleware[int, string](tracer),
+	)
+
+	c.Set(1, "one")
+	if v, err := c.Get(1); err != nil || v != "one" {
+		t.Errorf("Expected one, got %v (%v)", v, err)
+	}
+	c.Get(2)
+	c.Delete(1)
+	c.Clear()
+
+	if fmt.Sprint(order[:2]) != "[outer inner]" {
+		t.Errorf("Expected the first middleware to be the outermost one, got %v", order)
+	}
+
+	expected := "[set:true get:true get:false delete:true clear:true]"
+	if fmt.Sprint(metrics.ops) != expected {
+		t.Errorf("Expected %v, got %v", expected, metrics.ops)
+	}
+
+	if len(tracer.ops) != 10 || tracer.ops[0] != "start set 1" || tracer.ops[1] != "end set 1" {
+		t.Errorf("Expected a span around every operation, got %v", tracer.ops)
+	}
+
+	if len(logger.lines) != 5 || !strings.HasPrefix(logger.lines[2], "cachego: get 2 took") {
+		t.Errorf("Expected every operation to be logged, got %v", logger.lines)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package cachego
+
+import "errors"
+
+func mmap(size int) ([]byte, error) {
+	return nil, errors.New("off-heap storage is not supported on this platform")
+}
+
+func munmap(region []byte) error {
+	return nil
+}
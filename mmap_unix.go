@@ -0,0 +1,14 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package cachego
+
+import "syscall"
+
+// mmap maps an anonymous private region of the given size, outside the Go heap.
+func mmap(size int) ([]byte, error) {
+	return syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+}
+
+func munmap(region []byte) error {
+	return syscall.Munmap(region)
+}
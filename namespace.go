@@ -0,0 +1,198 @@
+package cachego
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Quota bounds the entries of a namespace.
+type Quota struct {
+	// Size is the number of entries of the namespace. Defaults to 100.
+	Size int32
+	// MaxBytes bounds the approximate memory held by the entries of the namespace, in addition to Size.
+	// If less than or equal to zero, only the number of entries is bounded.
+	MaxBytes int64
+}
+
+// NamespacesOpts configures the namespaces of a cache.
+type NamespacesOpts[K comparable, V any] struct {
+	// Opts configures the LRU cache of every namespace, its Size and MaxBytes replaced by the quota of the namespace.
+	// File and Expvar are ignored, since the namespaces can't share them.
+	Opts Opts
+	// Typed holds the typed options of the caches of the namespaces, e.g. the Sizer weighing the entries.
+	Typed TypedOpts[K, V]
+	// Quotas holds the quota of the namespaces known in advance, by name.
+	Quotas map[string]Quota
+	// Default is the quota of the other namespaces, created on their first use.
+	// Defaults to the Size and MaxBytes of Opts.
+	Default Quota
+}
+
+// Namespaces partitions a cache into named namespaces, e.g. one per tenant, each evicting the least recently used
+// of its own entries once it reaches its quota, so the keys of one namespace can't evict the keys of the others.
+// The same key may be stored in several namespaces. It is thread-safe.
+type Namespaces[K comparable, V any] struct {
+	opts NamespacesOpts[K, V]
+
+	mx     sync.RWMutex
+	caches map[string]Cache[K, V]
+}
+
+// NewNamespaces creates a cache of namespaces, each created on its first use.
+func NewNamespaces[K comparable, V any](opts NamespacesOpts[K, V]) *Namespaces[K, V] {
+	if opts.Default.Size <= 0 && opts.Default.MaxBytes <= 0 {
+		opts.Default = Quota{Size: opts.Opts.Size, MaxBytes: opts.Opts.MaxBytes}
+	}
+
+	return &Namespaces[K, V]{opts: opts, caches: make(map[string]Cache[K, V])}
+}
+
+// Namespace returns the cache of the namespace, bounded by its quota.
+func (n *Namespaces[K, V]) Namespace(name string) Cache[K, V] {
+	n.mx.RLock()
+	c, ok := n.caches[name]
+	n.mx.RUnlock()
+	if ok {
+		return c
+	}
+
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	if c, ok := n.caches[name]; ok {
+		return c
+	}
+
+	quota, ok := n.opts.Quotas[name]
+	if !ok {
+		quota = n.opts.Default
+	}
+	opts := n.opts.Opts
+	opts.Size, opts.MaxBytes = quota.Size, quota.MaxBytes
+	opts.File, opts.Expvar = nil, ""
+	c = NewLRUCacheWithOpts(opts, n.opts.Typed)
+	n.caches[name] = c
+
+	return c
+}
+
+// Set stores the value under the key in the namespace.
+func (n *Namespaces[K, V]) Set(namespace string, key K, value V) error {
+	return n.Namespace(namespace).Set(key, value)
+}
+
+// Get retrieves the value of the key in the namespace.
+// If the key is not found, the zero value of the value type and an error wrapping ErrNotFound are returned.
+func (n *Namespaces[K, V]) Get(namespace string, key K) (V, error) {
+	return n.Namespace(namespace).Get(key)
+}
+
+// Lookup retrieves the value of the key in the namespace, reporting whether it was found.
+func (n *Namespaces[K, V]) Lookup(namespace string, key K) (V, bool) {
+	return LookupValue(n.Namespace(namespace), key)
+}
+
+// Delete removes the key from the namespace.
+// If the key is not found, an error wrapping ErrNotFound is returned.
+func (n *Namespaces[K, V]) Delete(namespace string, key K) error {
+	return n.Namespace(namespace).Delete(key)
+}
+
+// Names returns the names of the namespaces used so far, sorted.
+func (n *Namespaces[K, V]) Names() []string {
+	n.mx.RLock()
+	defer n.mx.RUnlock()
+
+	names := make([]string, 0, len(n.caches))
+	for name := range n.caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// NamespaceStats returns the counters of every namespace used so far, by name.
+func (n *Namespaces[K, V]) NamespaceStats() map[string]Stats {
+	n.mx.RLock()
+	defer n.mx.RUnlock()
+
+	stats := make(map[string]Stats, len(n.caches))
+	for name, c := range n.caches {
+		stats[name] = c.(StatsProvider).Stats()
+	}
+
+	return stats
+}
+
+// Stats returns the sum of the counters of the namespaces. The uptime is the one of the oldest namespace.
+func (n *Namespaces[K, V]) Stats() Stats {
+	var total Stats
+	for _, st := range n.NamespaceStats() {
+		total.add(st)
+	}
+
+	return total
+}
+
+// Resize changes the number of entries of the namespace, evicting its least recently used entries
+// if it holds more. A namespace not used yet is created with the new size on its first use.
+// It returns an error if the size is less than or equal to zero.
+func (n *Namespaces[K, V]) Resize(namespace string, size int32) error {
+	if size <= 0 {
+		return fmt.Errorf("invalid size %v of namespace %q", size, namespace)
+	}
+
+	n.mx.Lock()
+	quota, ok := n.opts.Quotas[namespace]
+	if !ok {
+		quota = n.opts.Default
+	}
+	quota.Size = size
+	quotas := make(map[string]Quota, len(n.opts.Quotas)+1)
+	for name, q := range n.opts.Quotas {
+		quotas[name] = q
+	}
+	quotas[namespace] = quota
+	n.opts.Quotas = quotas
+	c, ok := n.caches[namespace]
+	n.mx.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return c.(Resizable).Resize(size)
+}
+
+// Clear removes the entries of every namespace, returning the joined errors of the namespaces that failed to clear.
+func (n *Namespaces[K, V]) Clear() error {
+	n.mx.RLock()
+	defer n.mx.RUnlock()
+
+	var errs []error
+	for name, c := range n.caches {
+		if err := c.Clear(); err != nil {
+			errs = append(errs, fmt.Errorf("clearing namespace %q failed: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close closes the cache of every namespace, returning the joined errors of the namespaces that failed to close.
+func (n *Namespaces[K, V]) Close() error {
+	n.mx.RLock()
+	defer n.mx.RUnlock()
+
+	var errs []error
+	for name, c := range n.caches {
+		if err := c.(io.Closer).Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing namespace %q failed: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
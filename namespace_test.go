@@ -0,0 +1,94 @@
+package cachego
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// nolint:errcheck
+func TestNamespaces(t *testing.T) {
+	ns := NewNamespaces(NamespacesOpts[string, int]{
+		Opts:   Opts{Size: 3},
+		Quotas: map[string]Quota{"big": {Size: 10}},
+	})
+	defer ns.Close()
+
+	for i := 0; i < 10; i++ {
+		ns.Set("big", fmt.Sprint(i), i)
+	}
+	// a noisy namespace only evicts its own keys
+	for i := 0; i < 100; i++ {
+		ns.Set("noisy", fmt.Sprint(i), i)
+	}
+	for i := 0; i < 10; i++ {
+		if v, err := ns.Get("big", fmt.Sprint(i)); err != nil || v != i {
+			t.Errorf("expected %v, got %v (%v)", i, v, err)
+		}
+	}
+
+	// the same key in another namespace
+	ns.Set("other", "0", 100)
+	if v, _ := ns.Get("big", "0"); v != 0 {
+		t.Errorf("expected 0, got %v", v)
+	}
+	if v, ok := ns.Lookup("other", "0"); !ok || v != 100 {
+		t.Errorf("expected 100, got %v", v)
+	}
+	ns.Delete("other", "0")
+	if _, err := ns.Get("other", "0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+
+	if names := fmt.Sprint(ns.Names()); names != "[big noisy other]" {
+		t.Errorf("expected [big noisy other], got %v", names)
+	}
+	stats := ns.NamespaceStats()
+	if s := stats["noisy"]; s.Size != 3 || s.Evictions != 97 {
+		t.Errorf("expected 3 entries and 97 evictions, got %+v", s)
+	}
+	if s := stats["big"]; s.Size != 10 || s.Hits != 11 || s.Evictions != 0 {
+		t.Errorf("expected 10 entries, 11 hits and no evictions, got %+v", s)
+	}
+	if s := ns.Stats(); s.Size != 13 || s.Sets != 111 {
+		t.Errorf("expected 13 entries and 111 sets, got %+v", s)
+	}
+
+	if err := ns.Resize("big", 5); err != nil {
+		t.Fatal(err)
+	}
+	if s := ns.NamespaceStats()["big"]; s.Size != 5 {
+		t.Errorf("expected 5 entries, got %v", s.Size)
+	}
+	if err := ns.Resize("big", 0); err == nil {
+		t.Errorf("expected an error for an invalid size")
+	}
+	ns.Resize("new", 1)
+	ns.Set("new", "a", 1)
+	ns.Set("new", "b", 2)
+	if s := ns.NamespaceStats()["new"]; s.Size != 1 {
+		t.Errorf("expected 1 entry, got %v", s.Size)
+	}
+
+	ns.Clear()
+	if s := ns.Stats(); s.Size != 0 {
+		t.Errorf("expected no entries, got %v", s.Size)
+	}
+}
+
+// nolint:errcheck
+func TestNamespacesMaxBytes(t *testing.T) {
+	ns := NewNamespaces(NamespacesOpts[int, string]{
+		Opts:    Opts{Size: 100},
+		Typed:   TypedOpts[int, string]{Sizer: func(_ int, v string) int { return len(v) }},
+		Default: Quota{Size: 100, MaxBytes: 10},
+	})
+	defer ns.Close()
+
+	for i := 0; i < 5; i++ {
+		ns.Set("a", i, "abcd")
+	}
+	if s := ns.NamespaceStats()["a"]; s.Size != 2 || s.Bytes != 8 {
+		t.Errorf("expected 2 entries of 8 bytes, got %+v", s)
+	}
+}
@@ -0,0 +1,301 @@
+// Package nats provides a cachego.Invalidator carrying the invalidations over NATS,
+// so clusters already running NATS can keep their caches coherent without Redis.
+// It speaks the NATS client protocol directly, so the module stays free of a client dependency.
+package nats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// Opts configures an invalidator publishing over NATS.
+type Opts struct {
+	// Addr is the host:port of the NATS server. Defaults to "localhost:4222".
+	Addr string
+	// Token, or User and Password, authenticate the connections, if set.
+	Token    string
+	User     string
+	Password string
+	// Subject carries the invalidations. Defaults to "cachego.invalidations".
+	Subject string
+	// Timeout bounds connecting and publishing, on top of the context of the operation. Defaults to 5 seconds.
+	Timeout time.Duration
+	// Dial opens the connections. Defaults to a net.Dialer, and may be replaced to use TLS.
+	Dial func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// Invalidator is a cachego.Invalidator carrying the invalidations over a NATS subject, encoded as JSON.
+type Invalidator struct {
+	opts Opts
+	mx   *sync.Mutex
+	pub  *conn // connection of Publish, opened on demand
+}
+
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+// NewInvalidator creates a new invalidator publishing and subscribing to the subject.
+// Publish runs on a single shared connection, while every subscription holds its own connection.
+// Close closes the connection of Publish.
+func NewInvalidator(opts Opts) *Invalidator {
+	if opts.Addr == "" {
+		opts.Addr = "localhost:4222"
+	}
+	if opts.Subject == "" {
+		opts.Subject = "cachego.invalidations"
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.Dial == nil {
+		opts.Dial = (&net.Dialer{}).DialContext
+	}
+
+	return &Invalidator{opts: opts, mx: &sync.Mutex{}}
+}
+
+// Publish sends the message to the subject with PUB, and waits for the server to process it with PING.
+func (in *Invalidator) Publish(ctx context.Context, msg cachego.Invalidation) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, in.opts.Timeout)
+	defer cancel()
+
+	in.mx.Lock()
+	defer in.mx.Unlock()
+
+	if in.pub == nil {
+		if in.pub, err = in.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = in.publish(ctx, b); err != nil {
+		// the connection may be left mid-reply
+		in.pub.Close()
+		in.pub = nil
+	}
+
+	return err
+}
+
+func (in *Invalidator) publish(ctx context.Context, payload []byte) error {
+	deadline, _ := ctx.Deadline()
+	if err := in.pub.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(in.pub.w, "PUB %s %d\r\n", in.opts.Subject, len(payload))
+	in.pub.w.Write(payload)
+	in.pub.w.WriteString("\r\nPING\r\n")
+	if err := in.pub.w.Flush(); err != nil {
+		return err
+	}
+
+	for {
+		line, err := in.pub.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case bytes.Equal(line, []byte("PONG")):
+			return nil
+		case bytes.Equal(line, []byte("PING")):
+			if err := in.pub.send("PONG"); err != nil {
+				return err
+			}
+		case bytes.HasPrefix(line, []byte("-ERR")):
+			return serverErr(line)
+		}
+	}
+}
+
+// Subscribe subscribes to the subject with SUB on a new connection, and calls handle with every message,
+// until the context is done or the connection fails. Messages that cannot be decoded are skipped.
+func (in *Invalidator) Subscribe(ctx context.Context, handle func(msg cachego.Invalidation)) error {
+	connectCtx, cancel := context.WithTimeout(ctx, in.opts.Timeout)
+	cn, err := in.connect(connectCtx)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	// messages arrive at any time: the connection is only interrupted by the context
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		cn.Close()
+	}()
+
+	if err := cn.SetDeadline(time.Time{}); err != nil {
+		return err
+	}
+	if err := cn.send("SUB " + in.opts.Subject + " 1"); err != nil {
+		return contextErr(ctx, err)
+	}
+
+	for {
+		line, err := cn.readLine()
+		if err != nil {
+			return contextErr(ctx, err)
+		}
+
+		switch {
+		case bytes.Equal(line, []byte("PING")):
+			if err := cn.send("PONG"); err != nil {
+				return contextErr(ctx, err)
+			}
+
+		case bytes.HasPrefix(line, []byte("-ERR")):
+			return serverErr(line)
+
+		case bytes.HasPrefix(line, []byte("MSG ")):
+			// MSG <subject> <sid> [reply-to] <#bytes>
+			fields := bytes.Fields(line)
+			n, err := strconv.Atoi(string(fields[len(fields)-1]))
+			if err != nil || n < 0 {
+				return fmt.Errorf("nats: invalid message: %q", line)
+			}
+			payload := make([]byte, n+2)
+			if _, err := io.ReadFull(cn.r, payload); err != nil {
+				return contextErr(ctx, err)
+			}
+
+			var msg cachego.Invalidation
+			if json.Unmarshal(payload[:n], &msg) == nil {
+				handle(msg)
+			}
+		}
+	}
+}
+
+// Close closes the connection of Publish. It always returns nil.
+func (in *Invalidator) Close() error {
+	in.mx.Lock()
+	defer in.mx.Unlock()
+
+	if in.pub != nil {
+		in.pub.Close()
+		in.pub = nil
+	}
+
+	return nil
+}
+
+// connect opens a new connection: it reads the INFO of the server, and sends CONNECT.
+func (in *Invalidator) connect(ctx context.Context) (*conn, error) {
+	nc, err := in.opts.Dial(ctx, "tcp", in.opts.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+	if err := cn.handshake(ctx, in.opts); err != nil {
+		cn.Close()
+		return nil, fmt.Errorf("nats: connecting to %v failed: %w", in.opts.Addr, err)
+	}
+
+	return cn, nil
+}
+
+func (cn *conn) handshake(ctx context.Context, opts Opts) error {
+	deadline, _ := ctx.Deadline()
+	if err := cn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	line, err := cn.readLine()
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(line, []byte("INFO ")) {
+		return fmt.Errorf("unexpected greeting: %q", line)
+	}
+
+	options, err := json.Marshal(struct {
+		Verbose  bool   `json:"verbose"`
+		Pedantic bool   `json:"pedantic"`
+		Name     string `json:"name"`
+		Lang     string `json:"lang"`
+		Version  string `json:"version"`
+		Protocol int    `json:"protocol"`
+		Token    string `json:"auth_token,omitempty"`
+		User     string `json:"user,omitempty"`
+		Password string `json:"pass,omitempty"`
+	}{Name: "cachego", Lang: "go", Version: "1", Token: opts.Token, User: opts.User, Password: opts.Password})
+	if err != nil {
+		return err
+	}
+
+	// the PONG confirms the server accepted the connection
+	if err := cn.send("CONNECT " + string(options) + "\r\nPING"); err != nil {
+		return err
+	}
+	for {
+		line, err := cn.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case bytes.Equal(line, []byte("PONG")):
+			return nil
+		case bytes.HasPrefix(line, []byte("-ERR")):
+			return serverErr(line)
+		}
+	}
+}
+
+// send writes the line, terminated by CRLF.
+func (cn *conn) send(line string) error {
+	cn.w.WriteString(line)
+	cn.w.WriteString("\r\n")
+	return cn.w.Flush()
+}
+
+// readLine reads a control line, without its terminator.
+func (cn *conn) readLine() ([]byte, error) {
+	line, err := cn.r.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimRight(line, "\r\n"), nil
+}
+
+func serverErr(line []byte) error {
+	return errors.New("nats: " + string(bytes.Trim(bytes.TrimPrefix(line, []byte("-ERR")), " '")))
+}
+
+// contextErr returns the error of the context if it interrupted the connection.
+func contextErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
+}
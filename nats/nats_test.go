@@ -0,0 +1,148 @@
+package nats
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// server is a fake NATS server supporting a single subject, with token authentication.
+type server struct {
+	net.Listener
+	token string
+	mx    sync.Mutex
+	subs  []*bufio.Writer
+}
+
+func newServer(t *testing.T, token string) *server {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen returned error: %s", err)
+	}
+
+	s := &server{Listener: l, token: token}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+
+	return s
+}
+
+func (s *server) serve(c net.Conn) {
+	defer c.Close()
+
+	r, w := bufio.NewReader(c), bufio.NewWriter(c)
+	s.write(w, "INFO {\"server_id\":\"test\"}\r\n")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case strings.HasPrefix(line, "CONNECT "):
+			if !strings.Contains(line, `"auth_token":"`+s.token+`"`) {
+				s.write(w, "-ERR 'Authorization Violation'\r\n")
+				return
+			}
+		case line == "PING":
+			s.write(w, "PONG\r\n")
+		case strings.HasPrefix(line, "SUB "):
+			s.mx.Lock()
+			s.subs = append(s.subs, w)
+			s.mx.Unlock()
+		case strings.HasPrefix(line, "PUB "):
+			fields := strings.Fields(line)
+			n, _ := strconv.Atoi(fields[2])
+			payload := make([]byte, n+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+
+			s.mx.Lock()
+			for _, sub := range s.subs {
+				fmt.Fprintf(sub, "MSG %s 1 %d\r\n%s", fields[1], n, payload)
+				sub.Flush()
+			}
+			s.mx.Unlock()
+		}
+	}
+}
+
+func (s *server) write(w *bufio.Writer, data string) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	w.WriteString(data)
+	w.Flush()
+}
+
+func (s *server) subscribers() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return len(s.subs)
+}
+
+// nolint:errcheck
+func TestInvalidator(t *testing.T) {
+	s := newServer(t, "secret")
+	newLocal := func() (cachego.Cache[string, int], cachego.ClosableCache[string, int]) {
+		local := cachego.NewCache[string, int](cachego.Opts{Size: 10})
+		in := NewInvalidator(Opts{Addr: s.Addr().String(), Token: "secret"})
+		t.Cleanup(func() { in.Close() })
+		return local, cachego.WithInvalidation(local, cachego.InvalidationOpts[string]{Invalidator: in})
+	}
+
+	_, a := newLocal()
+	defer a.Close()
+	local, b := newLocal()
+	defer b.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for s.subscribers() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	local.Set("a", 0)
+	if err := a.Set("a", 1); err != nil {
+		t.Fatalf("Set returned error: %s", err)
+	}
+
+	for time.Now().Before(deadline) {
+		if _, err := local.Get("a"); errors.Is(err, cachego.ErrNotFound) {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Errorf("expected a to be invalidated")
+}
+
+func TestInvalidatorAuth(t *testing.T) {
+	s := newServer(t, "secret")
+	in := NewInvalidator(Opts{Addr: s.Addr().String(), Token: "wrong"})
+	defer in.Close()
+
+	err := in.Publish(context.Background(), cachego.Invalidation{Keys: []string{"a"}})
+	if err == nil || !strings.Contains(err.Error(), "Authorization Violation") {
+		t.Errorf("expected an authorization error, got %v", err)
+	}
+}
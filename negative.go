@@ -0,0 +1,105 @@
+package cachego
+
+import (
+	"sync"
+	"time"
+)
+
+// NegativeFilterOpts configures a NegativeFilter.
+type NegativeFilterOpts struct {
+	// Keys is the number of missing keys remembered per period, with the false positive rate. Defaults to 10000.
+	Keys int
+	// FalsePositive is the rate of the keys wrongly reported missing, between 0 and 1. Defaults to 0.01.
+	FalsePositive float64
+	// Rotate is the period after which the keys added are forgotten, so the keys created in the backing store
+	// are loaded again after at most two periods. Defaults to a minute.
+	Rotate time.Duration
+	// Clock tells the time the filter rotates at. Defaults to the system clock.
+	Clock Clock
+}
+
+// NegativeFilter remembers the keys known to be missing from the backing store of a cache,
+// so the repeated lookups of absent keys can be answered without calling the loader.
+// The keys are held in two bloom filters of constant size: the keys are added to the current one,
+// which replaces the previous one every period, or once it holds Keys keys, so the false positives stay bounded
+// and the keys added are forgotten after one to two periods. A key is reported missing if either filter holds it.
+// It is thread-safe.
+type NegativeFilter[K comparable] struct {
+	opts NegativeFilterOpts
+
+	mx      sync.Mutex
+	cur     *bloom
+	prev    *bloom
+	rotated time.Time
+}
+
+// NewNegativeFilter creates an empty filter.
+func NewNegativeFilter[K comparable](opts NegativeFilterOpts) *NegativeFilter[K] {
+	if opts.Keys <= 0 {
+		opts.Keys = 10000
+	}
+	if opts.FalsePositive <= 0 || opts.FalsePositive >= 1 {
+		opts.FalsePositive = 0.01
+	}
+	if opts.Rotate <= 0 {
+		opts.Rotate = time.Minute
+	}
+	opts.Clock = clockOrSystem(opts.Clock)
+
+	return &NegativeFilter[K]{
+		opts:    opts,
+		cur:     newBloom(opts.Keys, opts.FalsePositive),
+		prev:    newBloom(opts.Keys, opts.FalsePositive),
+		rotated: opts.Clock.Now(),
+	}
+}
+
+// Add remembers that the key is missing.
+func (f *NegativeFilter[K]) Add(key K) {
+	sum := hashKey(key)
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	f.rotate()
+	f.cur.add(sum)
+}
+
+// Missing reports whether the key was added within the last one to two periods, or is a false positive.
+func (f *NegativeFilter[K]) Missing(key K) bool {
+	sum := hashKey(key)
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	f.rotate()
+	return f.cur.has(sum) || f.prev.has(sum)
+}
+
+// Reset forgets all the keys, e.g. once keys were created in the backing store.
+func (f *NegativeFilter[K]) Reset() {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	f.cur.reset()
+	f.prev.reset()
+	f.rotated = f.opts.Clock.Now()
+}
+
+// rotate replaces the previous filter with the current one once the period passed or the current one is full.
+// It must be called with the lock held.
+func (f *NegativeFilter[K]) rotate() {
+	now := f.opts.Clock.Now()
+	elapsed := now.Sub(f.rotated)
+	if elapsed < f.opts.Rotate && !f.cur.full() {
+		return
+	}
+
+	f.prev, f.cur = f.cur, f.prev
+	f.cur.reset()
+	// after two periods without a rotation, the previous keys are forgotten too
+	if elapsed >= 2*f.opts.Rotate {
+		f.prev.reset()
+	}
+	f.rotated = now
+}
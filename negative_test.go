@@ -0,0 +1,60 @@
+package cachego
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNegativeFilter(t *testing.T) {
+	clock := newFakeClock()
+	f := NewNegativeFilter[string](NegativeFilterOpts{Rotate: time.Minute, Clock: clock})
+
+	f.Add("a")
+	if !f.Missing("a") || f.Missing("b") {
+		t.Errorf("expected only a to be missing")
+	}
+
+	// kept for the next period
+	clock.Advance(time.Minute)
+	f.Add("b")
+	if !f.Missing("a") || !f.Missing("b") {
+		t.Errorf("expected a and b to be missing")
+	}
+
+	// then forgotten
+	clock.Advance(time.Minute)
+	if f.Missing("a") || !f.Missing("b") {
+		t.Errorf("expected only b to be missing")
+	}
+	clock.Advance(2 * time.Minute)
+	if f.Missing("b") {
+		t.Errorf("expected b to be forgotten")
+	}
+
+	f.Add("c")
+	f.Reset()
+	if f.Missing("c") {
+		t.Errorf("expected c to be forgotten")
+	}
+}
+
+func TestNegativeFilterFull(t *testing.T) {
+	f := NewNegativeFilter[int](NegativeFilterOpts{Keys: 100, Clock: newFakeClock()})
+	for i := 0; i < 300; i++ {
+		f.Add(i)
+	}
+
+	// the filters rotated as they filled up, keeping the false positives bounded
+	if !f.Missing(299) || f.Missing(0) {
+		t.Errorf("expected the last keys to be kept and the first ones forgotten")
+	}
+	fp := 0
+	for i := 1000; i < 2000; i++ {
+		if f.Missing(i) {
+			fp++
+		}
+	}
+	if fp > 40 {
+		t.Errorf("expected about 2%% of false positives, got %v out of 1000", fp)
+	}
+}
@@ -0,0 +1,37 @@
+package cachego
+
+type nop[K comparable, V any] struct{}
+
+// NewNopCache creates a cache that stores nothing: Set succeeds and discards the value,
+// while Get and Delete always miss. It disables caching behind the Cache interface,
+// e.g. from configuration, without nil checks at the call sites.
+func NewNopCache[K comparable, V any]() Cache[K, V] {
+	return nop[K, V]{}
+}
+
+// Set discards the value. It always returns nil.
+func (nop[K, V]) Set(key K, value V) error {
+	return nil
+}
+
+// Get always returns the zero value and an error wrapping ErrNotFound.
+func (nop[K, V]) Get(key K) (V, error) {
+	var empty V
+	return empty, notFound(key)
+}
+
+// Lookup always reports a miss.
+func (nop[K, V]) Lookup(key K) (V, bool) {
+	var empty V
+	return empty, false
+}
+
+// Delete always returns an error wrapping ErrNotFound.
+func (nop[K, V]) Delete(key K) error {
+	return notFound(key)
+}
+
+// Clear does nothing. It always returns nil.
+func (nop[K, V]) Clear() error {
+	return nil
+}
@@ -0,0 +1,26 @@
+package cachego
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNopCache(t *testing.T) {
+	c := NewNopCache[string, int]()
+
+	if err := c.Set("a", 1); err != nil {
+		t.Errorf("Set returned error: %s", err)
+	}
+	if v, err := c.Get("a"); !errors.Is(err, ErrNotFound) || v != 0 {
+		t.Errorf("expected %v, got %v (%v)", ErrNotFound, v, err)
+	}
+	if _, ok := LookupValue(c, "a"); ok {
+		t.Errorf("expected a miss")
+	}
+	if err := c.Delete("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+	if err := c.Clear(); err != nil {
+		t.Errorf("Clear returned error: %s", err)
+	}
+}
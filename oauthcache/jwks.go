@@ -0,0 +1,317 @@
+// Package oauthcache caches the JWKS key sets of OAuth2 and OpenID Connect issuers, and the access tokens
+// of OAuth2 clients, by issuer, refreshing them ahead of their expiry in the background, so the requests
+// being served never wait for the authorization server once the cache is warm.
+//
+// Concurrent fetches of an issuer are coalesced, and the key sets keep being served when their issuer
+// cannot be reached, since the keys are rotated long after the new ones are published.
+package oauthcache
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// ErrUnknownKey is returned when the key set of the issuer has no key of the requested ID.
+var ErrUnknownKey = errors.New("oauthcache: unknown key")
+
+// JSONWebKey is a public key of a key set (RFC 7517). Only the members of RSA, EC and OKP (Ed25519) public keys
+// are kept.
+type JSONWebKey struct {
+	Kid string `json:"kid,omitempty"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// KeySet is the key set of an issuer stored in the cache.
+type KeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+	// URI is the jwks_uri the keys were fetched from.
+	URI string `json:"uri"`
+	// Expires is the time the key set expires at, after which it is fetched again.
+	Expires time.Time `json:"expires"`
+}
+
+// Key returns the key of the ID, or an error wrapping ErrUnknownKey.
+func (ks KeySet) Key(kid string) (JSONWebKey, error) {
+	for _, k := range ks.Keys {
+		if k.Kid == kid {
+			return k, nil
+		}
+	}
+
+	return JSONWebKey{}, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+}
+
+// PublicKey returns the public key: an *rsa.PublicKey, an *ecdsa.PublicKey or an ed25519.PublicKey.
+func (k JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("oauthcache: invalid RSA exponent of key %q", k.Kid)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("oauthcache: unsupported curve %q of key %q", k.Crv, k.Kid)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("oauthcache: invalid point of key %q", k.Kid)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("oauthcache: unsupported curve %q of key %q", k.Crv, k.Kid)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("oauthcache: invalid Ed25519 key %q", k.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("oauthcache: unsupported key type %q of key %q", k.Kty, k.Kid)
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("oauthcache: invalid key parameter %q", s)
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// JWKSOpts configures a JWKS.
+type JWKSOpts struct {
+	// Client fetches the discovery documents and key sets. Defaults to http.DefaultClient.
+	Client *http.Client
+	// URI returns the jwks_uri of an issuer. Defaults to reading it from the OpenID Connect discovery document
+	// of the issuer, at /.well-known/openid-configuration.
+	URI func(ctx context.Context, issuer string) (string, error)
+	// TTL is the lifetime of the key sets whose response has no Cache-Control max-age. Defaults to an hour.
+	TTL time.Duration
+	// RefreshAhead is how long before their expiry the key sets are refreshed in the background.
+	// Defaults to a tenth of the TTL.
+	RefreshAhead time.Duration
+	// MinRefreshInterval bounds how often the key set of an issuer is fetched again to look for a key it doesn't
+	// have, e.g. right after a rotation. Defaults to a minute.
+	MinRefreshInterval time.Duration
+	// Clock tells the time the key sets are fetched and expire at. Defaults to the system clock.
+	Clock cachego.Clock
+	// Logger receives the errors of the cache and of the background refreshes. Defaults to discarding them.
+	Logger cachego.Logger
+}
+
+// JWKS serves the key sets of issuers from the cache, keyed by issuer URL. It is thread-safe.
+type JWKS struct {
+	opts JWKSOpts
+	r    *refresher[KeySet]
+
+	mx      sync.Mutex
+	fetched map[string]time.Time // by issuer, the last fetch for an unknown key
+}
+
+// NewJWKS creates a JWKS caching the key sets in the cache.
+func NewJWKS(c cachego.Cache[string, KeySet], opts JWKSOpts) *JWKS {
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = time.Hour
+	}
+	if opts.RefreshAhead <= 0 {
+		opts.RefreshAhead = opts.TTL / 10
+	}
+	if opts.MinRefreshInterval <= 0 {
+		opts.MinRefreshInterval = time.Minute
+	}
+	if opts.Logger == nil {
+		opts.Logger = nopLogger{}
+	}
+
+	j := &JWKS{opts: opts, fetched: make(map[string]time.Time)}
+	if j.opts.URI == nil {
+		j.opts.URI = j.discover
+	}
+	j.r = &refresher[KeySet]{
+		c:       c,
+		fetch:   j.fetch,
+		expires: func(ks KeySet) time.Time { return ks.Expires },
+		ahead:   opts.RefreshAhead,
+		stale:   true,
+		clock:   opts.Clock,
+		logger:  opts.Logger,
+		name:    "key set",
+	}
+
+	return j
+}
+
+// KeySet returns the key set of the issuer.
+func (j *JWKS) KeySet(ctx context.Context, issuer string) (KeySet, error) {
+	return j.r.get(ctx, issuer)
+}
+
+// Key returns the public key of the ID in the key set of the issuer, e.g. the kid of the header of a JWT.
+// If the key set has no such key, it is fetched again, at most once per MinRefreshInterval, in case the issuer
+// rotated its keys; if it still has none, Key returns an error wrapping ErrUnknownKey.
+func (j *JWKS) Key(ctx context.Context, issuer, kid string) (crypto.PublicKey, error) {
+	ks, err := j.KeySet(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := ks.Key(kid)
+	if errors.Is(err, ErrUnknownKey) && j.refetch(issuer) {
+		fresh, ferr, _ := j.r.flight.Do(issuer, func() (KeySet, error) { return j.r.load(ctx, issuer) })
+		if ferr != nil {
+			return nil, ferr
+		}
+		k, err = fresh.Key(kid)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return k.PublicKey()
+}
+
+// refetch reports whether the key set of the issuer may be fetched again for an unknown key.
+func (j *JWKS) refetch(issuer string) bool {
+	j.mx.Lock()
+	defer j.mx.Unlock()
+
+	now := j.r.now()
+	if last, ok := j.fetched[issuer]; ok && now.Sub(last) < j.opts.MinRefreshInterval {
+		return false
+	}
+	j.fetched[issuer] = now
+	return true
+}
+
+func (j *JWKS) fetch(ctx context.Context, issuer string) (KeySet, error) {
+	uri, err := j.opts.URI(ctx, issuer)
+	if err != nil {
+		return KeySet{}, err
+	}
+
+	var ks KeySet
+	header, err := j.get(ctx, uri, &ks)
+	if err != nil {
+		return KeySet{}, err
+	}
+
+	ttl := j.opts.TTL
+	if maxAge, ok := maxAge(header); ok {
+		ttl = maxAge
+	}
+	ks.URI = uri
+	ks.Expires = j.r.now().Add(ttl)
+	return ks, nil
+}
+
+// discover reads the jwks_uri of the OpenID Connect discovery document of the issuer.
+func (j *JWKS) discover(ctx context.Context, issuer string) (string, error) {
+	var doc struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if _, err := j.get(ctx, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &doc); err != nil {
+		return "", err
+	}
+	if doc.JWKSURI == "" {
+		return "", fmt.Errorf("oauthcache: the discovery document of %q has no jwks_uri", issuer)
+	}
+
+	return doc.JWKSURI, nil
+}
+
+// get fetches the JSON document at the URL into v.
+func (j *JWKS) get(ctx context.Context, url string, v any) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := j.opts.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, res.Body) // nolint:errcheck
+		return nil, fmt.Errorf("oauthcache: GET %v: %v", url, res.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(v); err != nil {
+		return nil, fmt.Errorf("oauthcache: decoding %v: %w", url, err)
+	}
+
+	return res.Header, nil
+}
+
+// maxAge returns the max-age of the Cache-Control header, if any.
+func maxAge(h http.Header) (time.Duration, bool) {
+	for _, v := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(d), "=")
+			if !strings.EqualFold(name, "max-age") {
+				continue
+			}
+			if n, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && n > 0 {
+				return time.Duration(n) * time.Second, true
+			}
+		}
+	}
+
+	return 0, false
+}
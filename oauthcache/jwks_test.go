@@ -0,0 +1,160 @@
+package oauthcache
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+type fakeClock struct {
+	mx  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) add(d time.Duration) {
+	c.mx.Lock()
+	c.now = c.now.Add(d)
+	c.mx.Unlock()
+}
+
+func (c *fakeClock) NewTimer(time.Duration) cachego.Timer { panic("not used") }
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func TestJWKS(t *testing.T) {
+	ec, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mx sync.Mutex
+	keys := []JSONWebKey{{Kid: "1", Kty: "EC", Crv: "P-256", X: b64(ec.X.Bytes()), Y: b64(ec.Y.Bytes())}}
+	var fetches atomic.Int32
+	var down atomic.Bool
+	var s *httptest.Server
+	s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{"jwks_uri": s.URL + "/keys"}) // nolint:errcheck
+		case "/keys":
+			fetches.Add(1)
+			mx.Lock()
+			defer mx.Unlock()
+			w.Header().Set("Cache-Control", "public, max-age=600")
+			json.NewEncoder(w).Encode(map[string]any{"keys": keys}) // nolint:errcheck
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	clock := &fakeClock{now: time.Now()}
+	j := NewJWKS(cachego.NewCache[string, KeySet](cachego.Opts{Size: 10}), JWKSOpts{RefreshAhead: time.Minute, Clock: clock})
+	ctx := context.Background()
+
+	key, err := j.Key(ctx, s.URL, "1")
+	if pub, ok := key.(*ecdsa.PublicKey); err != nil || !ok || !pub.Equal(&ec.PublicKey) {
+		t.Fatalf("expected the EC key, got %v (%v)", key, err)
+	}
+	if ks, err := j.KeySet(ctx, s.URL); err != nil || ks.URI != s.URL+"/keys" || !ks.Expires.Equal(clock.Now().Add(10*time.Minute)) {
+		t.Errorf("expected the key set to expire after its max-age, got %+v (%v)", ks, err)
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("expected %v fetch, got %v", 1, n)
+	}
+
+	// the key set is refreshed ahead of its expiry
+	clock.add(9*time.Minute + time.Second)
+	if _, err := j.KeySet(ctx, s.URL); err != nil {
+		t.Fatal(err)
+	}
+	j.r.wg.Wait()
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("expected %v fetches, got %v", 2, n)
+	}
+
+	// an unknown key is looked for once per MinRefreshInterval
+	mx.Lock()
+	keys = append(keys, JSONWebKey{Kid: "2", Kty: "OKP", Crv: "Ed25519", X: b64(make([]byte, ed25519.PublicKeySize))})
+	mx.Unlock()
+	if _, err := j.Key(ctx, s.URL, "2"); err != nil {
+		t.Errorf("expected the rotated key, got %v", err)
+	}
+	if _, err := j.Key(ctx, s.URL, "3"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected %v, got %v", ErrUnknownKey, err)
+	}
+	if n := fetches.Load(); n != 3 {
+		t.Errorf("expected %v fetches, got %v", 3, n)
+	}
+
+	// the expired key set is served while the issuer is down
+	down.Store(true)
+	clock.add(time.Hour)
+	if _, err := j.Key(ctx, s.URL, "1"); err != nil {
+		t.Errorf("expected the stale key, got %v", err)
+	}
+	if _, err := j.KeySet(ctx, s.URL+"/other"); err == nil {
+		t.Errorf("expected an error for an unreachable issuer")
+	}
+}
+
+func TestPublicKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &rsaKey.PublicKey
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		key      JSONWebKey
+		expected interface{ Equal(x crypto.PublicKey) bool }
+	}{
+		{JSONWebKey{Kty: "RSA", N: b64(pub.N.Bytes()), E: b64(big.NewInt(int64(pub.E)).Bytes())}, pub},
+		{JSONWebKey{Kty: "OKP", Crv: "Ed25519", X: b64(edKey)}, edKey},
+		{JSONWebKey{Kty: "EC", Crv: "P-256", X: b64([]byte{1}), Y: b64([]byte{2})}, nil},
+		{JSONWebKey{Kty: "oct"}, nil},
+	} {
+		key, err := tc.key.PublicKey()
+		if tc.expected == nil {
+			if err == nil {
+				t.Errorf("%v: expected an error, got %v", tc.key.Kty, key)
+			}
+			continue
+		}
+		if err != nil || !tc.expected.Equal(key) {
+			t.Errorf("%v: expected %v, got %v (%v)", tc.key.Kty, tc.expected, key, err)
+		}
+	}
+}
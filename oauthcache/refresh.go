@@ -0,0 +1,109 @@
+package oauthcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/noam-g4/cachego"
+	"github.com/noam-g4/cachego/singleflight"
+)
+
+// refresher serves values from a cache by key, fetching them when they expire, and refreshing them
+// in the background when they are about to.
+type refresher[V any] struct {
+	c       cachego.Cache[string, V]
+	fetch   func(ctx context.Context, key string) (V, error)
+	expires func(v V) time.Time
+	ahead   time.Duration
+	// stale serves the expired values when fetching them again fails.
+	stale  bool
+	clock  cachego.Clock
+	logger cachego.Logger
+	name   string
+
+	flight     singleflight.Group[string, V]
+	mx         sync.Mutex
+	refreshing map[string]bool
+	wg         sync.WaitGroup // of the background refreshes
+}
+
+func (r *refresher[V]) get(ctx context.Context, key string) (V, error) {
+	v, err := cachego.GetCtx(ctx, r.c, key)
+	if err != nil && !errors.Is(err, cachego.ErrNotFound) {
+		if ctx.Err() != nil {
+			return v, err
+		}
+		r.logger.Printf("oauthcache: getting the %v of %q failed: %v", r.name, key, err)
+	}
+
+	if err == nil {
+		now, expires := r.now(), r.expires(v)
+		if now.Before(expires.Add(-r.ahead)) {
+			return v, nil
+		}
+		if now.Before(expires) {
+			r.refresh(key)
+			return v, nil
+		}
+	}
+
+	fresh, ferr, _ := r.flight.Do(key, func() (V, error) { return r.load(ctx, key) })
+	if ferr != nil && err == nil && r.stale {
+		r.logger.Printf("oauthcache: fetching the %v of %q failed, serving the expired one: %v", r.name, key, ferr)
+		return v, nil
+	}
+
+	return fresh, ferr
+}
+
+// refresh fetches the value again in the background, unless a refresh of the key is running.
+func (r *refresher[V]) refresh(key string) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if r.refreshing[key] {
+		return
+	}
+	if r.refreshing == nil {
+		r.refreshing = make(map[string]bool)
+	}
+	r.refreshing[key] = true
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer func() {
+			r.mx.Lock()
+			delete(r.refreshing, key)
+			r.mx.Unlock()
+		}()
+		if _, err, shared := r.flight.Do(key, func() (V, error) { return r.load(context.Background(), key) }); err != nil && !shared {
+			r.logger.Printf("oauthcache: refreshing the %v of %q failed: %v", r.name, key, err)
+		}
+	}()
+}
+
+func (r *refresher[V]) load(ctx context.Context, key string) (V, error) {
+	v, err := r.fetch(ctx, key)
+	if err != nil {
+		return v, err
+	}
+
+	if err := cachego.SetCtx(ctx, r.c, key, v); err != nil {
+		r.logger.Printf("oauthcache: storing the %v of %q failed: %v", r.name, key, err)
+	}
+	return v, nil
+}
+
+func (r *refresher[V]) now() time.Time {
+	if r.clock == nil {
+		return time.Now()
+	}
+
+	return r.clock.Now()
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
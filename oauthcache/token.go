@@ -0,0 +1,137 @@
+package oauthcache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// Token is an access token stored in the cache.
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type,omitempty"`
+	// Expiry is the time the token expires at, or zero if it doesn't.
+	Expiry time.Time `json:"expiry,omitempty"`
+}
+
+// TokenOpts configures a Tokens.
+type TokenOpts struct {
+	// Fetch requests a new token from the issuer. It is required; ClientCredentials.Fetch implements it
+	// for the client credentials grant.
+	Fetch func(ctx context.Context, issuer string) (Token, error)
+	// RefreshAhead is how long before their expiry the tokens are refreshed in the background. Defaults to a minute.
+	RefreshAhead time.Duration
+	// Clock tells the time the tokens expire at. Defaults to the system clock.
+	Clock cachego.Clock
+	// Logger receives the errors of the cache and of the background refreshes. Defaults to discarding them.
+	Logger cachego.Logger
+}
+
+// Tokens serves the access tokens of a client from the cache, keyed by issuer, or whatever identifies
+// the authorization server to Fetch, e.g. its token endpoint. It is thread-safe.
+type Tokens struct {
+	r *refresher[Token]
+}
+
+// never is the expiry of the tokens which don't expire.
+var never = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// NewTokens creates a Tokens caching the tokens in the cache.
+func NewTokens(c cachego.Cache[string, Token], opts TokenOpts) *Tokens {
+	if opts.RefreshAhead <= 0 {
+		opts.RefreshAhead = time.Minute
+	}
+	if opts.Logger == nil {
+		opts.Logger = nopLogger{}
+	}
+
+	return &Tokens{r: &refresher[Token]{
+		c:     c,
+		fetch: opts.Fetch,
+		expires: func(t Token) time.Time {
+			if t.Expiry.IsZero() {
+				return never
+			}
+			return t.Expiry
+		},
+		ahead:  opts.RefreshAhead,
+		clock:  opts.Clock,
+		logger: opts.Logger,
+		name:   "token",
+	}}
+}
+
+// Token returns a token of the issuer which didn't expire.
+func (t *Tokens) Token(ctx context.Context, issuer string) (Token, error) {
+	return t.r.get(ctx, issuer)
+}
+
+// ClientCredentials requests tokens with the client credentials grant (RFC 6749 4.4).
+type ClientCredentials struct {
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// Client sends the token requests. Defaults to http.DefaultClient.
+	Client *http.Client
+	// Clock tells the time the tokens expire at. Defaults to the system clock.
+	Clock cachego.Clock
+}
+
+// Fetch requests a token from the token endpoint, authenticating the client with HTTP basic authentication.
+func (cc ClientCredentials) Fetch(ctx context.Context, tokenURL string) (Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(cc.Scopes) > 0 {
+		form.Set("scope", strings.Join(cc.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(cc.ClientID), url.QueryEscape(cc.ClientSecret))
+
+	client := cc.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return Token{}, err
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+		Error       string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&body); err != nil && res.StatusCode == http.StatusOK {
+		return Token{}, fmt.Errorf("oauthcache: decoding the token of %v: %w", tokenURL, err)
+	}
+	if res.StatusCode != http.StatusOK || body.AccessToken == "" {
+		if body.Error != "" {
+			return Token{}, fmt.Errorf("oauthcache: POST %v: %v: %v", tokenURL, res.Status, body.Error)
+		}
+		return Token{}, fmt.Errorf("oauthcache: POST %v: %v", tokenURL, res.Status)
+	}
+
+	t := Token{AccessToken: body.AccessToken, TokenType: body.TokenType}
+	if body.ExpiresIn > 0 {
+		now := time.Now()
+		if cc.Clock != nil {
+			now = cc.Clock.Now()
+		}
+		t.Expiry = now.Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	return t, nil
+}
@@ -0,0 +1,63 @@
+package oauthcache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+func TestTokens(t *testing.T) {
+	var issued atomic.Int32
+	var down atomic.Bool
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, ok := r.BasicAuth(); !ok || id != "app" || secret != "s3cret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client"}`)
+			return
+		}
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{"access_token":"token-%v-%v","token_type":"Bearer","expires_in":300}`, issued.Add(1), r.FormValue("scope"))
+	}))
+	defer s.Close()
+
+	clock := &fakeClock{now: time.Now()}
+	cc := ClientCredentials{ClientID: "app", ClientSecret: "s3cret", Scopes: []string{"read", "write"}, Clock: clock}
+	tokens := NewTokens(cachego.NewCache[string, Token](cachego.Opts{Size: 10}), TokenOpts{Fetch: cc.Fetch, Clock: clock})
+	ctx := context.Background()
+
+	token := func(expected string) {
+		t.Helper()
+		if tok, err := tokens.Token(ctx, s.URL); err != nil || tok.AccessToken != expected {
+			t.Errorf("expected %v, got %v (%v)", expected, tok.AccessToken, err)
+		}
+	}
+
+	token("token-1-read write")
+	token("token-1-read write")
+
+	// the token is refreshed ahead of its expiry
+	clock.add(4*time.Minute + time.Second)
+	token("token-1-read write")
+	tokens.r.wg.Wait()
+	token("token-2-read write")
+
+	// expired tokens are never served
+	down.Store(true)
+	clock.add(5 * time.Minute)
+	if _, err := tokens.Token(ctx, s.URL); err == nil {
+		t.Errorf("expected an error once the token expired")
+	}
+
+	if _, err := (ClientCredentials{ClientID: "app"}).Fetch(ctx, s.URL); err == nil {
+		t.Errorf("expected an error for invalid credentials")
+	}
+}
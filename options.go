@@ -0,0 +1,132 @@
+package cachego
+
+import (
+	"context"
+	"fmt"
+)
+
+// Policy selects what a cache created by New does once it reaches its size.
+type Policy int
+
+const (
+	// PolicyNone rejects new keys once the cache is full, like NewCache.
+	PolicyNone Policy = iota
+	// PolicyLRU evicts the least recently used entries to make room for new keys, like NewLRUCacheWithOpts.
+	PolicyLRU
+	// PolicyLFU evicts the least frequently used entries to make room for new keys, the least recently used first
+	// among the ones used as often. It is an LRU cache switched to it (see PolicySwitcher).
+	PolicyLFU
+)
+
+func (p Policy) String() string {
+	switch p {
+	case PolicyNone:
+		return "none"
+	case PolicyLRU:
+		return "lru"
+	case PolicyLFU:
+		return "lfu"
+	}
+
+	return fmt.Sprintf("Policy(%d)", int(p))
+}
+
+// Option configures a cache created by New.
+type Option func(c *config)
+
+type config struct {
+	opts   Opts
+	policy Policy
+	typed  []any // TypedOpts of the key and value types of the cache
+}
+
+// WithOpts starts from the given options, so every field of Opts can be set.
+// The options passed after it override the fields they set.
+func WithOpts(opts Opts) Option {
+	return func(c *config) { c.opts = opts }
+}
+
+// WithSize sets the number of entries the cache holds (see Opts.Size).
+func WithSize(size int32) Option {
+	return func(c *config) { c.opts.Size = size }
+}
+
+// WithTTL sets the time to live of the entries, in seconds (see Opts.TTL).
+func WithTTL(ttl int16) Option {
+	return func(c *config) { c.opts.TTL = ttl }
+}
+
+// WithPolicy selects what the cache does once it is full. Defaults to PolicyNone.
+func WithPolicy(policy Policy) Option {
+	return func(c *config) { c.policy = policy }
+}
+
+// WithFile persists the cache in the given file (see Opts.File).
+func WithFile(file File) Option {
+	return func(c *config) { c.opts.File = file }
+}
+
+// WithContext sets the context the snapshot is loaded with on creation (see Opts.Context).
+func WithContext(ctx context.Context) Option {
+	return func(c *config) { c.opts.Context = ctx }
+}
+
+// WithLogger sets the logger of the cache (see Opts.Logger).
+func WithLogger(logger Logger) Option {
+	return func(c *config) { c.opts.Logger = logger }
+}
+
+// WithClock sets the clock the entries are set, read and expire by (see Opts.Clock).
+func WithClock(clock Clock) Option {
+	return func(c *config) { c.opts.Clock = clock }
+}
+
+// WithTypedOpts sets the options that depend on the key and value types of the cache (see TypedOpts).
+// Their types must match the ones the cache is created with.
+func WithTypedOpts[K comparable, V any](typed TypedOpts[K, V]) Option {
+	return func(c *config) { c.typed = append(c.typed, typed) }
+}
+
+// WithOnEvict sets the function called with every entry removed from the cache (see TypedOpts.OnEvict).
+// Its types must match the ones the cache is created with.
+func WithOnEvict[K comparable, V any](onEvict func(key K, value V, reason Reason)) Option {
+	return WithTypedOpts(TypedOpts[K, V]{OnEvict: onEvict})
+}
+
+// WithLoader makes the cache read-through, loading the missing keys with the loader (see TypedOpts.Loader).
+// Its types must match the ones the cache is created with.
+func WithLoader[K comparable, V any](loader Loader[K, V]) Option {
+	return WithTypedOpts(TypedOpts[K, V]{Loader: loader})
+}
+
+// New creates a new thread-safe cache configured by the given options, so options can be added
+// without breaking callers. It creates the cache of the selected policy (see WithPolicy)
+// with the default size of 100 unless WithSize is given.
+// It panics if a typed option doesn't match the key and value types of the cache, or the policy is unknown.
+func New[K comparable, V any](options ...Option) Cache[K, V] {
+	c := &config{}
+	for _, o := range options {
+		o(c)
+	}
+
+	typed := make([]TypedOpts[K, V], len(c.typed))
+	for i, t := range c.typed {
+		var ok bool
+		if typed[i], ok = t.(TypedOpts[K, V]); !ok {
+			panic(fmt.Sprintf("cachego: typed option %T doesn't match the cache types %T", t, TypedOpts[K, V]{}))
+		}
+	}
+
+	switch c.policy {
+	case PolicyNone:
+		return NewCache(c.opts, typed...)
+	case PolicyLRU:
+		return NewLRUCacheWithOpts(c.opts, typed...)
+	case PolicyLFU:
+		cache := NewLRUCacheWithOpts(c.opts, typed...)
+		cache.(PolicySwitcher).SetPolicy(PolicyLFU) // nolint:errcheck
+		return cache
+	}
+
+	panic(fmt.Sprintf("cachego: unknown policy %v", c.policy))
+}
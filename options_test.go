@@ -0,0 +1,51 @@
+package cachego
+
+import (
+	"errors"
+	"testing"
+)
+
+// nolint:errcheck
+func TestNew(t *testing.T) {
+	var evicted []string
+
+	lru := New[string, int](
+		WithSize(2),
+		WithPolicy(PolicyLRU),
+		WithOnEvict(func(key string, value int, reason Reason) { evicted = append(evicted, key) }),
+	)
+
+	lru.Set("a", 1)
+	lru.Set("b", 2)
+	lru.Set("c", 3)
+
+	if len(evicted) != 1 || evicted[0] != "a" {
+		t.Errorf("expected a to be evicted, got %v", evicted)
+	}
+
+	// the default policy rejects new keys once full
+	simple := New[string, int](WithOpts(Opts{Size: 10}), WithSize(1))
+	simple.Set("a", 1)
+	if err := simple.Set("b", 2); !errors.Is(err, ErrCacheFull) {
+		t.Errorf("expected %v, got %v", ErrCacheFull, err)
+	}
+
+	// persisting through a file
+	file := NewMemoryCacheFile()
+	c := New[string, int](WithFile(file))
+	c.Set("a", 1)
+	c.Clear()
+	if v, err := New[string, int](WithFile(file)).Get("a"); err != nil || v != 1 {
+		t.Errorf("expected 1, got %v (%v)", v, err)
+	}
+}
+
+func TestNewTypeMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic")
+		}
+	}()
+
+	New[string, int](WithOnEvict(func(key int, value int, reason Reason) {}))
+}
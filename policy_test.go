@@ -0,0 +1,110 @@
+package cachego
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// nolint:errcheck
+func TestSetPolicy(t *testing.T) {
+	c := NewLRUCacheWithOpts[string, int](Opts{Size: 3})
+	s := c.(PolicySwitcher)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+	for i := 0; i < 3; i++ {
+		c.Get("a")
+	}
+	c.Get("b")
+	c.Get("c")
+
+	if err := s.SetPolicy(PolicyLFU); err != nil || s.Policy() != PolicyLFU {
+		t.Fatalf("expected the LFU policy, got %v (%v)", s.Policy(), err)
+	}
+	if keys := fmt.Sprint(c.(ExtendedCache[string, int]).Keys()); keys != "[a c b]" {
+		t.Errorf("expected the keys by frequency then recency, got %v", keys)
+	}
+
+	// the new key is the least frequently used, so evicted first
+	c.Set("d", 4)
+	if keys := fmt.Sprint(c.(ExtendedCache[string, int]).Keys()); keys != "[a c d]" {
+		t.Errorf("expected b to be evicted, got %v", keys)
+	}
+	c.Set("e", 5)
+	if keys := fmt.Sprint(c.(ExtendedCache[string, int]).Keys()); keys != "[a c e]" {
+		t.Errorf("expected d to be evicted, got %v", keys)
+	}
+	c.Get("e")
+	c.Get("e")
+	c.Get("e")
+	if keys := fmt.Sprint(c.(ExtendedCache[string, int]).Keys()); keys != "[e a c]" {
+		t.Errorf("expected e to be used the most, got %v", keys)
+	}
+
+	// back to recency, from the last accesses
+	if err := s.SetPolicy(PolicyLRU); err != nil {
+		t.Fatal(err)
+	}
+	c.Get("c")
+	c.Set("f", 6)
+	if keys := fmt.Sprint(c.(ExtendedCache[string, int]).Keys()); keys != "[f c e]" {
+		t.Errorf("expected a to be evicted, got %v", keys)
+	}
+
+	if err := s.SetPolicy(PolicyNone); err == nil {
+		t.Errorf("expected an error for an unsupported policy")
+	}
+	if p := New[string, int](WithPolicy(PolicyLFU)).(PolicySwitcher).Policy(); p != PolicyLFU {
+		t.Errorf("expected %v, got %v", PolicyLFU, p)
+	}
+}
+
+// nolint:errcheck
+func TestSetPolicyConcurrent(t *testing.T) {
+	c := NewLRUCacheWithOpts[int, int](Opts{Size: 50, AccessBuffer: 8})
+	s := c.(PolicySwitcher)
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				c.Set((g*i)%80, i)
+				c.Get(i % 80)
+				if i%7 == 0 {
+					c.Delete(i % 80)
+				}
+			}
+		}(g)
+	}
+	for i := 0; i < 20; i++ {
+		s.SetPolicy(Policy(PolicyLRU + Policy(i%2)))
+	}
+	wg.Wait()
+
+	// the list and the frequency groups are consistent
+	l := c.(*lru[int, int])
+	s.SetPolicy(PolicyLFU)
+	for i := 0; i < 200; i++ {
+		c.Set(i%70, i)
+		c.Get(i % 13)
+	}
+	count := 0
+	for n := l.head; n != nil; n = n.next {
+		count++
+		if n.next != nil && n.next.freq > n.freq {
+			t.Fatalf("expected the nodes ordered by frequency, got %v before %v", n.freq, n.next.freq)
+		}
+		if n.prev == nil || n.prev.freq != n.freq {
+			if l.freqs[n.freq] != n {
+				t.Fatalf("expected %v to be the first node used %v times", n.key, n.freq)
+			}
+		}
+	}
+	if count != l.Len() || count != int(l.used) {
+		t.Errorf("expected %v nodes, got %v", l.Len(), count)
+	}
+}
@@ -0,0 +1,88 @@
+package cachego
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type prefixed[V any] struct {
+	c      Cache[string, V] // not embedded, so the view can't be asserted to the interfaces of the shared cache
+	prefix string
+}
+
+// Namespace returns a view of the cache storing its keys under the name and a colon, e.g. "users:42" for the key "42"
+// of the namespace "users", so several subsystems can share a cache without their keys colliding.
+// Clear only removes the keys of the namespace, which requires the cache to list its keys (see ExtendedCache).
+// Views can be nested, e.g. Namespace(Namespace(c, "users"), "sessions") stores its keys under "users:sessions:".
+//
+// Besides Cache, the view implements Lookuper, and the Has and Keys methods of ExtendedCache.
+// Unlike Namespaces, the views share the capacity of the cache, so the keys of one namespace may evict the others.
+func Namespace[V any](c Cache[string, V], name string) Cache[string, V] {
+	return &prefixed[V]{c: c, prefix: name + ":"}
+}
+
+// Set stores the value under the prefixed key.
+func (p *prefixed[V]) Set(key string, value V) error {
+	return p.c.Set(p.prefix+key, value)
+}
+
+// Get retrieves the value of the prefixed key.
+func (p *prefixed[V]) Get(key string) (V, error) {
+	return p.c.Get(p.prefix + key)
+}
+
+// Lookup retrieves the value of the prefixed key, reporting whether it was found.
+func (p *prefixed[V]) Lookup(key string) (V, bool) {
+	return LookupValue(p.c, p.prefix+key)
+}
+
+// Delete removes the prefixed key.
+func (p *prefixed[V]) Delete(key string) error {
+	return p.c.Delete(p.prefix + key)
+}
+
+// Has reports whether the prefixed key is in the cache. If the cache doesn't implement Has,
+// the key is looked up, which counts as an access.
+func (p *prefixed[V]) Has(key string) bool {
+	if h, ok := p.c.(interface{ Has(key string) bool }); ok {
+		return h.Has(p.prefix + key)
+	}
+
+	_, ok := LookupValue(p.c, p.prefix+key)
+	return ok
+}
+
+// Keys returns the keys of the namespace, without their prefix, or nil if the cache doesn't implement Keys.
+func (p *prefixed[V]) Keys() []string {
+	k, ok := p.c.(interface{ Keys() []string })
+	if !ok {
+		return nil
+	}
+
+	var keys []string
+	for _, key := range k.Keys() {
+		if strings.HasPrefix(key, p.prefix) {
+			keys = append(keys, key[len(p.prefix):])
+		}
+	}
+
+	return keys
+}
+
+// Clear removes the keys of the namespace, leaving the other keys of the cache. The keys set concurrently may be kept.
+// It returns an error if the cache doesn't implement Keys.
+func (p *prefixed[V]) Clear() error {
+	if _, ok := p.c.(interface{ Keys() []string }); !ok {
+		return fmt.Errorf("clearing namespace %q: the cache %T doesn't list its keys", strings.TrimSuffix(p.prefix, ":"), p.c)
+	}
+
+	var errs []error
+	for _, key := range p.Keys() {
+		if err := p.Delete(key); err != nil && !errors.Is(err, ErrNotFound) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,65 @@
+package cachego
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// nolint:errcheck
+func TestNamespace(t *testing.T) {
+	c := NewLRUCacheWithOpts[string, int](Opts{Size: 10})
+	users := Namespace(c, "users")
+	sessions := Namespace(users, "sessions")
+	orders := Namespace(c, "orders")
+
+	users.Set("1", 1)
+	sessions.Set("1", 10)
+	orders.Set("1", 100)
+	c.Set("1", 1000)
+
+	if v, err := c.Get("users:sessions:1"); err != nil || v != 10 {
+		t.Errorf("expected 10, got %v (%v)", v, err)
+	}
+	if v, ok := LookupValue(orders, "1"); !ok || v != 100 {
+		t.Errorf("expected 100, got %v", v)
+	}
+	if v, _ := users.Get("1"); v != 1 {
+		t.Errorf("expected 1, got %v", v)
+	}
+
+	keys := users.(interface{ Keys() []string }).Keys()
+	sort.Strings(keys)
+	if fmt.Sprint(keys) != "[1 sessions:1]" {
+		t.Errorf("expected [1 sessions:1], got %v", keys)
+	}
+
+	// clearing a namespace keeps the others
+	if err := users.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if users.(interface{ Has(string) bool }).Has("1") || c.(ExtendedCache[string, int]).Has("users:sessions:1") {
+		t.Errorf("expected the keys of users to be removed")
+	}
+	if n := c.(ExtendedCache[string, int]).Len(); n != 2 {
+		t.Errorf("expected 2 keys left, got %v", n)
+	}
+
+	if err := orders.Delete("1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := orders.Get("1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+}
+
+func TestNamespaceWithoutKeys(t *testing.T) {
+	n := Namespace(NewNopCache[string, int](), "a")
+	if err := n.Clear(); err == nil {
+		t.Errorf("expected an error clearing a cache without keys")
+	}
+	if keys := n.(interface{ Keys() []string }).Keys(); keys != nil {
+		t.Errorf("expected nil, got %v", keys)
+	}
+}
@@ -0,0 +1,74 @@
+package cachego
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrReadOnly is returned (wrapped with the operation) by the methods of a read-only cache that would modify it.
+var ErrReadOnly = errors.New("cache is read-only")
+
+// ReadOnlyCache is a view of a cache that can be read but not modified. See ReadOnly.
+type ReadOnlyCache[K comparable, V any] interface {
+	Cache[K, V]
+	// Has reports whether the key is in the cache.
+	Has(key K) bool
+	// Keys returns the keys of the cache, in no particular order.
+	Keys() []K
+}
+
+type readOnly[K comparable, V any] struct {
+	c Cache[K, V] // not embedded, so the view can't be asserted to the interfaces of the wrapped cache
+}
+
+// ReadOnly wraps the given cache in a view that reads through to it, but rejects Set, Delete and Clear
+// with an error wrapping ErrReadOnly, so it can be handed to code (e.g. plugins) that must not modify shared state.
+// The view only exposes the methods of ReadOnlyCache: the other methods of the wrapped cache can't be reached through it.
+func ReadOnly[K comparable, V any](c Cache[K, V]) ReadOnlyCache[K, V] {
+	return readOnly[K, V]{c: c}
+}
+
+// Get retrieves the value of the key from the wrapped cache.
+func (r readOnly[K, V]) Get(key K) (V, error) {
+	return r.c.Get(key)
+}
+
+// Lookup retrieves the value of the key from the wrapped cache, reporting whether it was found.
+func (r readOnly[K, V]) Lookup(key K) (V, bool) {
+	return LookupValue(r.c, key)
+}
+
+// Has reports whether the key is in the wrapped cache. If the wrapped cache doesn't implement Has,
+// the key is looked up, which counts as an access.
+func (r readOnly[K, V]) Has(key K) bool {
+	if h, ok := r.c.(interface{ Has(key K) bool }); ok {
+		return h.Has(key)
+	}
+
+	_, ok := LookupValue(r.c, key)
+	return ok
+}
+
+// Keys returns the keys of the wrapped cache, or nil if it doesn't implement Keys.
+func (r readOnly[K, V]) Keys() []K {
+	if k, ok := r.c.(interface{ Keys() []K }); ok {
+		return k.Keys()
+	}
+
+	return nil
+}
+
+// Set returns an error wrapping ErrReadOnly.
+func (r readOnly[K, V]) Set(key K, value V) error {
+	return fmt.Errorf("set key %v: %w", key, ErrReadOnly)
+}
+
+// Delete returns an error wrapping ErrReadOnly.
+func (r readOnly[K, V]) Delete(key K) error {
+	return fmt.Errorf("delete key %v: %w", key, ErrReadOnly)
+}
+
+// Clear returns an error wrapping ErrReadOnly.
+func (r readOnly[K, V]) Clear() error {
+	return fmt.Errorf("clear: %w", ErrReadOnly)
+}
@@ -0,0 +1,45 @@
+package cachego
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+// nolint:errcheck
+func TestReadOnly(t *testing.T) {
+	c := NewCache[string, int](Opts{Size: 10})
+	c.Set("a", 1)
+
+	r := ReadOnly(c)
+	if v, err := r.Get("a"); err != nil || v != 1 {
+		t.Errorf("expected 1, got %v (%v)", v, err)
+	}
+	if !r.Has("a") || r.Has("b") {
+		t.Errorf("expected only a to be in the cache")
+	}
+	if keys := r.Keys(); len(keys) != 1 || keys[0] != "a" {
+		t.Errorf("expected [a], got %v", keys)
+	}
+
+	if err := r.Set("b", 2); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected %v, got %v", ErrReadOnly, err)
+	}
+	if err := r.Delete("a"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected %v, got %v", ErrReadOnly, err)
+	}
+	if err := r.Clear(); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected %v, got %v", ErrReadOnly, err)
+	}
+	if v, err := c.Get("a"); err != nil || v != 1 {
+		t.Errorf("expected the cache to be unchanged, got %v (%v)", v, err)
+	}
+
+	// the wrapped cache can't be reached through the view
+	if _, ok := r.(io.Closer); ok {
+		t.Errorf("expected the view not to expose Close")
+	}
+	if _, ok := r.(Resizable); ok {
+		t.Errorf("expected the view not to expose Resize")
+	}
+}
@@ -0,0 +1,46 @@
+package cachego
+
+// Reclaimable is implemented by caches that can hand their removed values over for resource reclamation.
+type Reclaimable[K comparable, V any] interface {
+	// Reclaimed returns the channel every removed (evicted, expired, deleted or cleared) entry is delivered on,
+	// so a dedicated goroutine can release the resources tied to it.
+	// Unlike Events, removals are never dropped: when the buffer is full, the goroutine removing the entry
+	// blocks until there is room. The cache lock is not held meanwhile.
+	// Once the cache is closed, a removal that would block is dropped instead, so Close doesn't wait for the channel to be drained.
+	// If the cache was not configured to reclaim values, the returned channel is nil.
+	Reclaimed() <-chan Event[K, V]
+}
+
+// reclaimer delivers removed entries to a bounded channel, blocking when it is full until the cache is closed.
+type reclaimer[K comparable, V any] struct {
+	removed chan Event[K, V]
+	closed  <-chan struct{}
+	clock   Clock
+}
+
+func newReclaimer[K comparable, V any](buffer int, bg background, clock Clock) reclaimer[K, V] {
+	if buffer <= 0 {
+		return reclaimer[K, V]{}
+	}
+
+	return reclaimer[K, V]{removed: make(chan Event[K, V], buffer), closed: bg.ctx.Done(), clock: clock}
+}
+
+// Reclaimed returns the channel every removed (evicted, expired, deleted or cleared) entry is delivered on.
+// When its buffer is full, the goroutine removing the entry blocks until there is room,
+// or until the cache is closed, in which case the entry is dropped.
+// If the cache was not configured to reclaim values, the returned channel is nil.
+func (r reclaimer[K, V]) Reclaimed() <-chan Event[K, V] {
+	return r.removed
+}
+
+func (r reclaimer[K, V]) reclaim(key K, value V, reason Reason) {
+	if r.removed == nil {
+		return
+	}
+
+	select {
+	case r.removed <- Event[K, V]{Type: removalEvent(reason), Key: key, Value: value, Reason: reason, Time: r.clock.Now()}:
+	case <-r.closed:
+	}
+}
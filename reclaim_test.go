@@ -0,0 +1,90 @@
+package cachego
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+// nolint:errcheck
+func TestReclaimed(t *testing.T) {
+	c := NewLRUCacheWithOpts[int, string](Opts{Size: 1, Reclaim: 1})
+	removed := c.(Reclaimable[int, string]).Reclaimed()
+
+	c.Set(1, "one")
+	c.Set(2, "two") // 1 is evicted into the buffer
+
+	// the buffer is full, so evicting 2 blocks until there is room
+	done := make(chan struct{})
+	go func() {
+		c.Set(3, "three")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatalf("Expected Set to block while the reclaim buffer is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// the cache lock is not held meanwhile
+	if v, err := c.Get(3); err != nil || v != "three" {
+		t.Errorf("Expected three, got %v (%v)", v, err)
+	}
+
+	for _, val := range []string{"one", "two"} {
+		e := <-removed
+		if e.Value != val || e.Reason != ReasonCapacity {
+			t.Errorf("Expected %v to be reclaimed for capacity, got %+v", val, e)
+		}
+	}
+	<-done
+
+	c.Delete(3)
+	if e := <-removed; e.Value != "three" || e.Type != EventDelete {
+		t.Errorf("Expected three to be reclaimed on delete, got %+v", e)
+	}
+
+	// no values are reclaimed unless configured
+	if NewCache[int, string](Opts{}).(Reclaimable[int, string]).Reclaimed() != nil {
+		t.Errorf("Expected a nil channel")
+	}
+}
+
+// nolint:errcheck
+func TestReclaimedClose(t *testing.T) {
+	for name, create := range map[string]func(clock Clock) Cache[int, string]{
+		"simple": func(clock Clock) Cache[int, string] {
+			return NewCache[int, string](Opts{Size: 2, TTL: 1, Reclaim: 1, Clock: clock})
+		},
+		"lru": func(clock Clock) Cache[int, string] {
+			return NewLRUCacheWithOpts[int, string](Opts{Size: 2, TTL: 1, Reclaim: 1, Clock: clock})
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			clock := newFakeClock()
+			c := create(clock)
+			removed := c.(Reclaimable[int, string]).Reclaimed()
+
+			c.Set(1, "one")
+			c.Set(2, "two")
+
+			// the expiry goroutine blocks on the second entry, as nothing drains the channel
+			clock.wait(t)
+			clock.Advance(time.Second)
+			eventually(t, func() bool { return len(removed) == 1 })
+
+			done := make(chan struct{})
+			go func() {
+				c.(io.Closer).Close()
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatalf("Expected Close to return while the reclaim buffer is full")
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/noam-g4/cachego/internal/resp"
+)
+
+var errClosed = errors.New("redis: client is closed")
+
+// client runs commands on a pool of connections to a Redis server.
+type client struct {
+	addr     string
+	username string
+	password string
+	db       int
+	timeout  time.Duration
+	dial     func(ctx context.Context, network, addr string) (net.Conn, error)
+	idle     chan *conn
+	mx       *sync.Mutex
+	done     bool
+}
+
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+func newClient(addr, username, password string, db, poolSize int, timeout time.Duration,
+	dial func(ctx context.Context, network, addr string) (net.Conn, error)) *client {
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	if poolSize <= 0 {
+		poolSize = 10
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	return &client{
+		addr:     addr,
+		username: username,
+		password: password,
+		db:       db,
+		timeout:  timeout,
+		dial:     dial,
+		idle:     make(chan *conn, poolSize),
+		mx:       &sync.Mutex{},
+	}
+}
+
+// Close closes the idle connections. Commands still running close their connection once done.
+// It always returns nil, and may be called more than once.
+func (c *client) Close() error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if !c.done {
+		c.done = true
+		close(c.idle)
+		for cn := range c.idle {
+			cn.Close()
+		}
+	}
+
+	return nil
+}
+
+// do runs the command on a pooled connection, and returns its reply.
+// Error replies are returned as an Error.
+func (c *client) do(ctx context.Context, args ...[]byte) (resp.Value, error) {
+	if err := ctx.Err(); err != nil {
+		return resp.Value{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	cn, err := c.get(ctx)
+	if err != nil {
+		return resp.Value{}, err
+	}
+
+	reply, err := cn.do(ctx, args...)
+	if err != nil {
+		// the connection may be left mid-reply
+		cn.Close()
+		return resp.Value{}, err
+	}
+	c.put(cn)
+
+	if reply.Type == resp.Error {
+		return resp.Value{}, Error(reply.Str)
+	}
+
+	return reply, nil
+}
+
+// get returns an idle connection, or opens a new one.
+func (c *client) get(ctx context.Context) (*conn, error) {
+	c.mx.Lock()
+	if c.done {
+		c.mx.Unlock()
+		return nil, errClosed
+	}
+	select {
+	case cn := <-c.idle:
+		c.mx.Unlock()
+		return cn, nil
+	default:
+	}
+	c.mx.Unlock()
+
+	return c.open(ctx)
+}
+
+// open opens a new connection, authenticated and set to the database.
+func (c *client) open(ctx context.Context) (*conn, error) {
+	nc, err := c.dial(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+	if c.password != "" {
+		args := [][]byte{[]byte("AUTH"), []byte(c.password)}
+		if c.username != "" {
+			args = [][]byte{[]byte("AUTH"), []byte(c.username), []byte(c.password)}
+		}
+		if err := cn.init(ctx, args...); err != nil {
+			return nil, err
+		}
+	}
+	if c.db != 0 {
+		if err := cn.init(ctx, []byte("SELECT"), []byte(strconv.Itoa(c.db))); err != nil {
+			return nil, err
+		}
+	}
+
+	return cn, nil
+}
+
+// put returns the connection to the pool, or closes it if the pool is full or closed.
+func (c *client) put(cn *conn) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.done {
+		cn.Close()
+		return
+	}
+
+	select {
+	case c.idle <- cn:
+	default:
+		cn.Close()
+	}
+}
+
+// init runs a command setting up a new connection, closing it if the command fails.
+func (cn *conn) init(ctx context.Context, args ...[]byte) error {
+	reply, err := cn.do(ctx, args...)
+	if err == nil && reply.Type == resp.Error {
+		err = Error(reply.Str)
+	}
+	if err != nil {
+		cn.Close()
+		return fmt.Errorf("redis: %s: %w", args[0], err)
+	}
+
+	return nil
+}
+
+// do writes the command and reads its reply, within the deadline of the context.
+func (cn *conn) do(ctx context.Context, args ...[]byte) (resp.Value, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := cn.SetDeadline(deadline); err != nil {
+			return resp.Value{}, err
+		}
+	}
+
+	// a context cancelled before its deadline interrupts the command by expiring the connection;
+	// the watcher returns before the connection is reused, so it can't expire the next command
+	done, exited := make(chan struct{}), make(chan struct{})
+	defer func() {
+		close(done)
+		<-exited
+	}()
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			cn.SetDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+
+	if err := resp.WriteCommand(cn.w, args...); err != nil {
+		return resp.Value{}, contextErr(ctx, err)
+	}
+
+	reply, err := resp.Read(cn.r)
+	return reply, contextErr(ctx, err)
+}
+
+// contextErr returns the error of the context if it interrupted the command.
+func contextErr(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
+}
@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/noam-g4/cachego"
+	"github.com/noam-g4/cachego/internal/resp"
+)
+
+// InvalidatorOpts configures an invalidator publishing over Redis pub/sub.
+type InvalidatorOpts struct {
+	// Addr, Username, Password, DB, Timeout and Dial configure the connections, like the Opts of the cache.
+	Addr     string
+	Username string
+	Password string
+	DB       int
+	Timeout  time.Duration
+	Dial     func(ctx context.Context, network, addr string) (net.Conn, error)
+	// Channel is the pub/sub channel carrying the invalidations. Defaults to "cachego:invalidations".
+	Channel string
+}
+
+// Invalidator is a cachego.Invalidator carrying the invalidations over a Redis pub/sub channel, encoded as JSON.
+type Invalidator struct {
+	*client
+	channel string
+}
+
+// NewInvalidator creates a new invalidator publishing and subscribing to the channel.
+// Publish runs on a pooled connection, while every subscription holds its own connection.
+// Close closes the idle connections of the pool.
+func NewInvalidator(opts InvalidatorOpts) *Invalidator {
+	if opts.Channel == "" {
+		opts.Channel = "cachego:invalidations"
+	}
+
+	return &Invalidator{
+		client:  newClient(opts.Addr, opts.Username, opts.Password, opts.DB, 1, opts.Timeout, opts.Dial),
+		channel: opts.Channel,
+	}
+}
+
+// Publish sends the message to the channel with PUBLISH.
+func (in *Invalidator) Publish(ctx context.Context, msg cachego.Invalidation) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = in.do(ctx, []byte("PUBLISH"), []byte(in.channel), b)
+	return err
+}
+
+// Subscribe subscribes to the channel with SUBSCRIBE on a new connection, and calls handle with every message,
+// until the context is done or the connection fails. Messages that cannot be decoded are skipped.
+func (in *Invalidator) Subscribe(ctx context.Context, handle func(msg cachego.Invalidation)) error {
+	openCtx, cancel := context.WithTimeout(ctx, in.timeout)
+	cn, err := in.open(openCtx)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	reply, err := cn.do(openCtx, []byte("SUBSCRIBE"), []byte(in.channel))
+	cancel()
+	if err == nil && reply.Type == resp.Error {
+		err = Error(reply.Str)
+	}
+	if err != nil {
+		cn.Close()
+		return fmt.Errorf("redis: SUBSCRIBE: %w", err)
+	}
+
+	// messages arrive at any time: the connection is only interrupted by the context
+	if err := cn.SetDeadline(time.Time{}); err != nil {
+		cn.Close()
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		cn.Close()
+	}()
+
+	for {
+		v, err := resp.Read(cn.r)
+		if err != nil {
+			return contextErr(ctx, err)
+		}
+
+		// a message is the array ["message", channel, payload]
+		if len(v.Array) != 3 || string(v.Array[0].Str) != "message" {
+			continue
+		}
+
+		var msg cachego.Invalidation
+		if json.Unmarshal(v.Array[2].Str, &msg) == nil {
+			handle(msg)
+		}
+	}
+}
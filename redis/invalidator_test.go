@@ -0,0 +1,41 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// nolint:errcheck
+func TestInvalidator(t *testing.T) {
+	s := newServer(t)
+	newLocal := func() (cachego.Cache[string, int], cachego.ClosableCache[string, int]) {
+		local := cachego.NewCache[string, int](cachego.Opts{Size: 10})
+		in := NewInvalidator(InvalidatorOpts{Addr: s.Addr().String()})
+		t.Cleanup(func() { in.Close() })
+		return local, cachego.WithInvalidation(local, cachego.InvalidationOpts[string]{Invalidator: in})
+	}
+
+	_, a := newLocal()
+	defer a.Close()
+	local, b := newLocal()
+	defer b.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for s.subscribers() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	local.Set("a", 0)
+	a.Set("a", 1)
+
+	for time.Now().Before(deadline) {
+		if _, err := local.Get("a"); errors.Is(err, cachego.ErrNotFound) {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Errorf("expected a to be invalidated")
+}
@@ -0,0 +1,237 @@
+// Package redis provides a cachego.Cache implementation stored in Redis, so code written against cachego
+// can move from in-process to shared caching without rewrites.
+// It speaks the Redis protocol (RESP2) directly, so the core cachego package and this one stay free of a client dependency.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+// Opts configures a cache stored in Redis.
+type Opts[V any] struct {
+	// Addr is the host:port of the Redis server. Defaults to "localhost:6379".
+	Addr string
+	// Username and Password authenticate the connections with AUTH, if Password is set.
+	Username string
+	Password string
+	// DB is the database the connections SELECT. Defaults to 0.
+	DB int
+	// Prefix is prepended to every key, so several caches can share a database.
+	// Clear only removes the keys under the prefix. Defaults to "cachego:".
+	Prefix string
+	// TTL is the time to live of every entry, in seconds, set with SET EX.
+	// If less than or equal to zero, entries do not expire.
+	TTL int16
+	// Marshal and Unmarshal encode and decode the values. Default to encoding/json.
+	Marshal   func(value V) ([]byte, error)
+	Unmarshal func(data []byte, value *V) error
+	// PoolSize is the maximum number of idle connections kept open. Defaults to 10.
+	PoolSize int
+	// Timeout bounds every command, on top of the context of the operation. Defaults to 5 seconds.
+	Timeout time.Duration
+	// Dial opens the connections. Defaults to a net.Dialer, and may be replaced to use TLS.
+	Dial func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// Error is an error reply of the Redis server.
+type Error string
+
+func (e Error) Error() string { return "redis: " + string(e) }
+
+type cache[K comparable, V any] struct {
+	*client
+	opts Opts[V]
+}
+
+// NewCache creates a new thread-safe instance of a cache stored in Redis.
+// Set maps to SET (with EX if a ttl is set), Get to GET, Delete to DEL, and Clear to SCAN and DEL of the prefixed keys.
+// String keys are stored as is after the prefix, while other keys are encoded as JSON.
+// Connections are opened on demand and pooled; Close closes the idle ones.
+// The returned cache implements cachego.ContextCache, so its commands carry the context of the operation,
+// and cachego.TTLSetter, so entries may expire after a ttl of their own.
+func NewCache[K comparable, V any](opts Opts[V]) cachego.ClosableCache[K, V] {
+	if opts.Prefix == "" {
+		opts.Prefix = "cachego:"
+	}
+	if opts.Marshal == nil {
+		opts.Marshal = func(value V) ([]byte, error) { return json.Marshal(value) }
+	}
+	if opts.Unmarshal == nil {
+		opts.Unmarshal = func(data []byte, value *V) error { return json.Unmarshal(data, value) }
+	}
+
+	c := newClient(opts.Addr, opts.Username, opts.Password, opts.DB, opts.PoolSize, opts.Timeout, opts.Dial)
+	return &cache[K, V]{client: c, opts: opts}
+}
+
+// Set stores the value under the key with SET.
+func (c *cache[K, V]) Set(key K, value V) error {
+	return c.SetCtx(context.Background(), key, value)
+}
+
+// SetCtx stores the value under the key with SET, unless the context is done.
+func (c *cache[K, V]) SetCtx(ctx context.Context, key K, value V) error {
+	var ttl time.Duration
+	if c.opts.TTL > 0 {
+		ttl = time.Duration(c.opts.TTL) * time.Second
+	}
+
+	return c.set(ctx, key, value, ttl)
+}
+
+// SetWithTTL stores the value under the key with SET PX, expiring after the given ttl rather than the ttl
+// of the cache. A ttl <= 0 means the key doesn't expire.
+func (c *cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	return c.set(context.Background(), key, value, ttl)
+}
+
+func (c *cache[K, V]) set(ctx context.Context, key K, value V, ttl time.Duration) error {
+	k, err := c.key(key)
+	if err != nil {
+		return err
+	}
+
+	v, err := c.opts.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	args := [][]byte{[]byte("SET"), k, v}
+	switch {
+	case ttl <= 0:
+	case ttl%time.Second == 0:
+		args = append(args, []byte("EX"), []byte(strconv.FormatInt(int64(ttl/time.Second), 10)))
+	default:
+		ms := (ttl + time.Millisecond - 1) / time.Millisecond
+		args = append(args, []byte("PX"), []byte(strconv.FormatInt(int64(ms), 10)))
+	}
+
+	_, err = c.do(ctx, args...)
+	return err
+}
+
+// Get retrieves the value of the key with GET.
+// If the key is not found (or expired), it returns an error wrapping cachego.ErrNotFound.
+func (c *cache[K, V]) Get(key K) (V, error) {
+	return c.GetCtx(context.Background(), key)
+}
+
+// GetCtx retrieves the value of the key with GET, unless the context is done.
+func (c *cache[K, V]) GetCtx(ctx context.Context, key K) (V, error) {
+	var v V
+	k, err := c.key(key)
+	if err != nil {
+		return v, err
+	}
+
+	reply, err := c.do(ctx, []byte("GET"), k)
+	if err != nil {
+		return v, err
+	}
+	if reply.Null {
+		return v, fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+	}
+
+	err = c.opts.Unmarshal(reply.Str, &v)
+	return v, err
+}
+
+// Delete removes the key with DEL. If the key is not found, it returns an error wrapping cachego.ErrNotFound.
+func (c *cache[K, V]) Delete(key K) error {
+	return c.DeleteCtx(context.Background(), key)
+}
+
+// DeleteCtx removes the key with DEL, unless the context is done.
+func (c *cache[K, V]) DeleteCtx(ctx context.Context, key K) error {
+	k, err := c.key(key)
+	if err != nil {
+		return err
+	}
+
+	reply, err := c.do(ctx, []byte("DEL"), k)
+	if err != nil {
+		return err
+	}
+	if reply.Int == 0 {
+		return fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+	}
+
+	return nil
+}
+
+// Clear removes every key under the prefix, scanning them with SCAN and removing them with DEL.
+// Keys set concurrently may survive it.
+func (c *cache[K, V]) Clear() error {
+	return c.ClearCtx(context.Background())
+}
+
+// ClearCtx removes every key under the prefix, unless the context is done.
+func (c *cache[K, V]) ClearCtx(ctx context.Context) error {
+	match := []byte(escapeGlob(c.opts.Prefix) + "*")
+	cursor := []byte("0")
+	for {
+		reply, err := c.do(ctx, []byte("SCAN"), cursor, []byte("MATCH"), match, []byte("COUNT"), []byte("1000"))
+		if err != nil {
+			return err
+		}
+		if len(reply.Array) != 2 {
+			return fmt.Errorf("redis: unexpected SCAN reply")
+		}
+
+		if keys := reply.Array[1].Array; len(keys) > 0 {
+			args := make([][]byte, 0, len(keys)+1)
+			args = append(args, []byte("DEL"))
+			for _, k := range keys {
+				args = append(args, k.Str)
+			}
+			if _, err := c.do(ctx, args...); err != nil {
+				return err
+			}
+		}
+
+		cursor = reply.Array[0].Str
+		if string(cursor) == "0" {
+			return nil
+		}
+	}
+}
+
+// key returns the prefixed key.
+func (c *cache[K, V]) key(key K) ([]byte, error) {
+	if s, ok := any(key).(string); ok {
+		return []byte(c.opts.Prefix + s), nil
+	}
+
+	k, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(c.opts.Prefix), k...), nil
+}
+
+// escapeGlob escapes the characters of the prefix that are special to the MATCH pattern of SCAN.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,249 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+	"github.com/noam-g4/cachego/cachetest"
+	"github.com/noam-g4/cachego/internal/resp"
+)
+
+// server is a fake Redis server supporting the commands used by the cache.
+type server struct {
+	net.Listener
+	mx       sync.Mutex
+	data     map[string][]byte
+	commands []string
+	subs     []*bufio.Writer
+	block    chan struct{} // blocks GET while set
+}
+
+func newServer(t *testing.T) *server {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen returned error: %s", err)
+	}
+
+	s := &server{Listener: l, data: make(map[string][]byte)}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+
+	return s
+}
+
+func (s *server) serve(c net.Conn) {
+	defer c.Close()
+
+	r, w := bufio.NewReader(c), bufio.NewWriter(c)
+	for {
+		cmd, err := resp.Read(r)
+		if err != nil {
+			return
+		}
+
+		args := make([]string, len(cmd.Array))
+		for i, a := range cmd.Array {
+			args[i] = string(a.Str)
+		}
+		if args[0] == "GET" && s.block != nil {
+			<-s.block
+		}
+		if !s.reply(w, args) {
+			return
+		}
+	}
+}
+
+// reply writes the reply of the command, and reports whether it was sent.
+func (s *server) reply(w *bufio.Writer, args []string) bool {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.run(w, args)
+	return w.Flush() == nil
+}
+
+func (s *server) run(w *bufio.Writer, args []string) {
+
+	s.commands = append(s.commands, strings.Join(args, " "))
+	switch args[0] {
+	case "AUTH", "SELECT":
+		if args[len(args)-1] == "wrong" {
+			resp.WriteError(w, "WRONGPASS invalid password")
+			return
+		}
+		resp.WriteSimple(w, "OK")
+	case "SET":
+		s.data[args[1]] = []byte(args[2])
+		resp.WriteSimple(w, "OK")
+	case "GET":
+		v, ok := s.data[args[1]]
+		if !ok {
+			v = nil
+		}
+		resp.WriteBulk(w, v)
+	case "DEL":
+		n := 0
+		for _, k := range args[1:] {
+			if _, ok := s.data[k]; ok {
+				delete(s.data, k)
+				n++
+			}
+		}
+		resp.WriteInt(w, int64(n))
+	case "SCAN":
+		var keys []string
+		for k := range s.data {
+			if ok, _ := path.Match(args[3], k); ok {
+				keys = append(keys, k)
+			}
+		}
+		resp.WriteArray(w, 2)
+		resp.WriteBulk(w, []byte("0"))
+		resp.WriteArray(w, len(keys))
+		for _, k := range keys {
+			resp.WriteBulk(w, []byte(k))
+		}
+	case "SUBSCRIBE":
+		s.subs = append(s.subs, w)
+		resp.WriteArray(w, 3)
+		resp.WriteBulk(w, []byte("subscribe"))
+		resp.WriteBulk(w, []byte(args[1]))
+		resp.WriteInt(w, 1)
+	case "PUBLISH":
+		for _, sub := range s.subs {
+			resp.WriteArray(sub, 3)
+			resp.WriteBulk(sub, []byte("message"))
+			resp.WriteBulk(sub, []byte(args[1]))
+			resp.WriteBulk(sub, []byte(args[2]))
+			sub.Flush()
+		}
+		resp.WriteInt(w, int64(len(s.subs)))
+	default:
+		resp.WriteError(w, "ERR unknown command '"+args[0]+"'")
+	}
+}
+
+func (s *server) subscribers() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return len(s.subs)
+}
+
+func (s *server) command(i int) string {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if i < 0 {
+		i += len(s.commands)
+	}
+	return s.commands[i]
+}
+
+func TestConformance(t *testing.T) {
+	s := newServer(t)
+	cachetest.TestCache(t, func() cachego.Cache[string, string] {
+		c := NewCache[string, string](Opts[string]{Addr: s.Addr().String()})
+		if err := c.Clear(); err != nil {
+			t.Fatalf("Clear returned error: %s", err)
+		}
+		return c
+	})
+}
+
+// nolint:errcheck
+func TestCache(t *testing.T) {
+	s := newServer(t)
+	c := NewCache[int, []string](Opts[[]string]{
+		Addr:     s.Addr().String(),
+		Password: "secret",
+		DB:       2,
+		Prefix:   "app:",
+		TTL:      60,
+	})
+	defer c.Close()
+
+	if err := c.Set(1, []string{"a", "b"}); err != nil {
+		t.Fatalf("Set returned error: %s", err)
+	}
+	if cmd := s.command(-1); cmd != `SET app:1 ["a","b"] EX 60` {
+		t.Errorf("expected SET with a ttl, got %v", cmd)
+	}
+
+	// per-entry ttls take precedence
+	for _, tc := range []struct {
+		ttl      time.Duration
+		expected string
+	}{
+		{2 * time.Minute, `SET app:1 ["a","b"] EX 120`},
+		{1500 * time.Millisecond, `SET app:1 ["a","b"] PX 1500`},
+		{0, `SET app:1 ["a","b"]`},
+	} {
+		c.(cachego.TTLSetter[int, []string]).SetWithTTL(1, []string{"a", "b"}, tc.ttl)
+		if cmd := s.command(-1); cmd != tc.expected {
+			t.Errorf("%v: expected %v, got %v", tc.ttl, tc.expected, cmd)
+		}
+	}
+
+	if v, err := c.Get(1); err != nil || len(v) != 2 || v[1] != "b" {
+		t.Errorf("expected [a b], got %v (%v)", v, err)
+	}
+	if s.command(0) != "AUTH secret" || s.command(1) != "SELECT 2" {
+		t.Errorf("expected the connection to authenticate and select the database, got %v, %v", s.command(0), s.command(1))
+	}
+
+	// Clear only removes the keys under the prefix
+	s.mx.Lock()
+	s.data["other"] = []byte("1")
+	s.mx.Unlock()
+	c.Clear()
+	if cmd := s.command(-1); cmd != "DEL app:1" {
+		t.Errorf("expected only app:1 to be deleted, got %v", cmd)
+	}
+	if _, err := c.Get(1); !errors.Is(err, cachego.ErrNotFound) {
+		t.Errorf("expected %v, got %v", cachego.ErrNotFound, err)
+	}
+}
+
+func TestCacheErrors(t *testing.T) {
+	s := newServer(t)
+
+	c := NewCache[string, int](Opts[int]{Addr: s.Addr().String(), Password: "wrong"})
+	var redisErr Error
+	if err := c.Set("a", 1); !errors.As(err, &redisErr) || !strings.HasPrefix(string(redisErr), "WRONGPASS") {
+		t.Errorf("expected a WRONGPASS error, got %v", err)
+	}
+
+	// a cancelled context interrupts the command
+	s.block = make(chan struct{})
+	defer close(s.block)
+
+	c = NewCache[string, int](Opts[int]{Addr: s.Addr().String()})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if _, err := cachego.GetCtx[string, int](ctx, c, "a"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+
+	c.Close()
+	if err := c.Set("a", 1); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
@@ -0,0 +1,138 @@
+package cachego
+
+import (
+	"context"
+	"crypto/sha256"
+	"time"
+)
+
+// digest identifies the contents of a persisted snapshot.
+type digest [sha256.Size]byte
+
+// combineDigests returns the digest of a snapshot made of several parts (i.e. shards).
+func combineDigests(sums []digest) digest {
+	h := sha256.New()
+	for _, s := range sums {
+		h.Write(s[:])
+	}
+
+	var d digest
+	copy(d[:], h.Sum(nil))
+	return d
+}
+
+// watch polls the persisted snapshot at the given interval and reloads it whenever it changes,
+// until the context is done.
+func (c *simple[K, V]) watch(ctx context.Context, interval time.Duration, merge bool) {
+	every(ctx, c.clock, interval, func() { c.reload(ctx, merge) })
+}
+
+// reload loads the persisted snapshot and, if it differs from the last loaded or dumped one,
+// applies it to the cache. A changed snapshot that doesn't fit in the cache is logged and skipped,
+// and so is a snapshot loaded while the cache was dumping a newer one.
+// When the snapshot replaces the contents of the cache, the entries missing from it are removed with ReasonReloaded.
+func (c *simple[K, V]) reload(ctx context.Context, merge bool) {
+	dumps := c.dumps.Load()
+
+	files := c.shards
+	if len(files) == 0 {
+		files = []File{c.file}
+	}
+
+	parts := make([][]byte, len(files))
+	sums := make([]digest, len(files))
+	for i, f := range files {
+		bytes, err := loadFile(ctx, f)
+		if err != nil {
+			c.logger.Printf("reloading cache data failed: %v", err)
+			return
+		}
+		parts[i] = bytes
+		sums[i] = sha256.Sum256(bytes)
+	}
+
+	sum := combineDigests(sums)
+
+	for k, v := range c.apply(parts, sum, dumps, merge) {
+		c.evicted(k, v, ReasonReloaded)
+	}
+}
+
+// apply applies the changed snapshot to the cache, returning the entries it dropped.
+func (c *simple[K, V]) apply(parts [][]byte, sum digest, dumps uint64, merge bool) map[K]V {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if sum == c.digest || c.dumps.Load() != dumps {
+		return nil
+	}
+
+	data := make(map[K]V, c.size)
+	for _, bytes := range parts {
+		if err := unmarshalSnapshot(bytes, data); err != nil {
+			c.logger.Printf("error unmarshalling cache data: %v", err)
+			return nil
+		}
+	}
+
+	c.digest = sum
+
+	if !merge {
+		if l := int32(len(data)); l > c.size {
+			c.logger.Printf("cache data size %v is larger than cache size %v", l, c.size)
+			return nil
+		}
+
+		meta, bytes := c.weigh(data)
+		if !c.bytes.holds(bytes) {
+			c.logger.Printf("cache data size %v bytes is larger than cache max bytes %v", bytes, c.bytes.max)
+			return nil
+		}
+
+		// the entries missing from the snapshot are dropped, and the ttl timers of the others are stale
+		// since the restored entries don't expire
+		dropped := make(map[K]V)
+		for k, v := range c.data {
+			if _, ok := data[k]; !ok {
+				dropped[k] = v
+			}
+		}
+
+		c.data = data
+		c.meta = meta
+		c.used = int32(len(data))
+		c.bytes.reset()
+		c.bytes.add(bytes)
+		for k, v := range data {
+			c.notify(k, v)
+		}
+		return dropped
+	}
+
+	for k, v := range data {
+		size := c.bytes.size(k, v)
+		if m, ok := c.meta[k]; !ok {
+			if c.used >= c.size || !c.bytes.fits(size) {
+				c.logger.Printf("cache is full, skipping the rest of the reloaded cache data")
+				return nil
+			}
+			c.used++
+			c.bytes.add(size)
+			m = newEntryMeta(c.clock.Now(), 0)
+			m.weight = size
+			c.meta[k] = m
+		} else {
+			if !c.bytes.fits(size - m.weight) {
+				c.logger.Printf("cache is full, skipping the rest of the reloaded cache data")
+				return nil
+			}
+			c.bytes.add(size - m.weight)
+			m.weight = size
+			m.update(c.clock.Now(), 0)
+		}
+		c.data[k] = v
+		c.notify(k, v)
+	}
+
+	return nil
+}
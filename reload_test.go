@@ -0,0 +1,123 @@
+package cachego
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	os.WriteFile(path, []byte(`{"a":"one"}`), 0644)
+
+	cache := NewCache[string, string](Opts{Size: 2, File: NewSimpleCacheFile(path), Reload: 10 * time.Millisecond})
+	defer cache.(io.Closer).Close()
+	cache.Set("b", "two")
+
+	// an unchanged snapshot doesn't override the cache
+	time.Sleep(50 * time.Millisecond)
+	if _, err := cache.Get("b"); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	// a changed snapshot replaces the cache contents
+	os.WriteFile(path, []byte(`{"c":"three"}`), 0644)
+	time.Sleep(50 * time.Millisecond)
+
+	if v, err := cache.Get("c"); err != nil || v != "three" {
+		t.Errorf("expected three, got %v (%v)", v, err)
+	}
+
+	if _, err := cache.Get("a"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestReloadMerge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	os.WriteFile(path, []byte(`{"a":"one"}`), 0644)
+
+	cache := NewCache[string, string](Opts{
+		Size:        3,
+		File:        NewSimpleCacheFile(path),
+		Reload:      10 * time.Millisecond,
+		ReloadMerge: true,
+	})
+	defer cache.(io.Closer).Close()
+
+	// a changed snapshot is merged into the cache contents
+	os.WriteFile(path, []byte(`{"a":"uno","b":"two"}`), 0644)
+	time.Sleep(50 * time.Millisecond)
+
+	for key, val := range map[string]string{"a": "uno", "b": "two"} {
+		if v, err := cache.Get(key); err != nil || v != val {
+			t.Errorf("expected %v, got %v (%v)", val, v, err)
+		}
+	}
+
+	// a snapshot dumped by the cache itself is not reloaded
+	cache.Set("c", "three")
+	if err := cache.Clear(); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if _, err := cache.Get("c"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+// nolint:errcheck
+func TestReloadDropped(t *testing.T) {
+	file := NewMemoryCacheFile()
+	file.Dump([]byte(`{"a":"one"}`))
+
+	dropped := map[string]Reason{}
+	cache := NewCache(Opts{Size: 3, TTL: 1, File: file}, TypedOpts[string, string]{
+		OnEvict: func(key, value string, reason Reason) { dropped[key] = reason },
+	})
+	cache.Set("b", "two")
+	cache.Set("c", "three")
+
+	// the entries missing from a replacing snapshot are reported
+	file.Dump([]byte(`{"c":"tres"}`))
+	cache.(*simple[string, string]).reload(context.Background(), false)
+
+	if len(dropped) != 2 || dropped["a"] != ReasonReloaded || dropped["b"] != ReasonReloaded {
+		t.Errorf("expected a and b to be dropped, got %v", dropped)
+	}
+
+	if s := cache.(StatsProvider).Stats(); s.Deletes != 2 || s.Size != 1 {
+		t.Errorf("expected 2 deletes and 1 entry, got %+v", s)
+	}
+
+	// the ttl timer of c is stale, since the restored entry doesn't expire
+	time.Sleep(1200 * time.Millisecond)
+	if v, err := cache.Get("c"); err != nil || v != "tres" {
+		t.Errorf("expected tres, got %v (%v)", v, err)
+	}
+}
+
+func TestReloadClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	os.WriteFile(path, []byte(`{"a":"one"}`), 0644)
+
+	cache := NewCache[string, string](Opts{Size: 2, File: NewSimpleCacheFile(path), Reload: 10 * time.Millisecond})
+	if err := cache.(io.Closer).Close(); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	// a closed cache stops reloading
+	os.WriteFile(path, []byte(`{"b":"two"}`), 0644)
+	time.Sleep(50 * time.Millisecond)
+	if _, err := cache.Get("b"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	if err := cache.(io.Closer).Close(); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+package cachego
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ReplicatorOpts configures a Replicator.
+type ReplicatorOpts struct {
+	// ResyncInterval is the time between full resyncs of the replica, which repair the divergence caused
+	// by the events the primary dropped when the replicator fell behind. Zero disables the periodic resyncs.
+	ResyncInterval time.Duration
+	// RetryInterval is the time to wait before resyncing the replica after an event failed to apply to it,
+	// e.g. while a remote replica is unreachable. Defaults to 1 second.
+	RetryInterval time.Duration
+	// Logger receives the events that failed to apply and the failed resyncs. Defaults to discarding them.
+	Logger Logger
+}
+
+// ReplicationStats describes the progress of a Replicator.
+type ReplicationStats struct {
+	// Applied is the number of events applied to the replica.
+	Applied uint64
+	// Failed is the number of events that failed to apply to the replica.
+	Failed uint64
+	// Pending is the number of events emitted by the primary and not applied yet.
+	Pending int
+	// Lag is the time between the emission of the last applied event and its application.
+	Lag time.Duration
+	// Resyncs is the number of completed full resyncs, including the initial one.
+	Resyncs uint64
+	// LastResync is the time the last full resync completed.
+	LastResync time.Time
+	// InSync reports whether every event since the last resync applied successfully.
+	InSync bool
+}
+
+// Replicator asynchronously applies the mutations of a primary cache to a replica, local or remote,
+// to keep a warm standby. The replica is first synced with the whole primary, then follows its events:
+// stored values are stored in the replica, and removed entries (deleted, evicted, expired or cleared)
+// are deleted from it.
+type Replicator[K comparable, V any] struct {
+	primary Cache[K, V]
+	replica Cache[K, V]
+	events  <-chan Event[K, V]
+	opts    ReplicatorOpts
+	logger  Logger
+	resyncs chan chan error
+	bg      background
+
+	mx    *sync.Mutex
+	stats ReplicationStats
+}
+
+// NewReplicator starts replicating the primary cache into the replica until Close.
+// The primary must emit events (see Opts.Events), and the replicator must be the only consumer of its events.
+// The primary should implement Keys for the resyncs, which otherwise fail.
+func NewReplicator[K comparable, V any](primary, replica Cache[K, V], opts ReplicatorOpts) (*Replicator[K, V], error) {
+	src, ok := primary.(EventSource[K, V])
+	if !ok || src.Events() == nil {
+		return nil, errors.New("cachego: the primary cache doesn't emit events")
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = time.Second
+	}
+
+	r := &Replicator[K, V]{
+		primary: primary,
+		replica: replica,
+		events:  src.Events(),
+		opts:    opts,
+		logger:  loggerOrNop(opts.Logger),
+		resyncs: make(chan chan error),
+		bg:      newBackground(),
+		mx:      &sync.Mutex{},
+	}
+	r.bg.run(r.replicate)
+
+	return r, nil
+}
+
+// Resync copies every entry of the primary to the replica, and deletes the keys missing from the primary
+// if the replica implements Keys. The events emitted meanwhile are applied once it completes.
+// It blocks until the resync completes or the context is done.
+func (r *Replicator[K, V]) Resync(ctx context.Context) error {
+	done := make(chan error, 1)
+
+	select {
+	case r.resyncs <- done:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.bg.ctx.Done():
+		return errors.New("cachego: replicator is closed")
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns the progress of the replication.
+func (r *Replicator[K, V]) Stats() ReplicationStats {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	stats := r.stats
+	stats.Pending = len(r.events)
+	return stats
+}
+
+// Close stops the replication. It doesn't close the caches. It always returns nil, and may be called more than once.
+func (r *Replicator[K, V]) Close() error {
+	r.bg.stop()
+	return nil
+}
+
+// replicate runs the initial resync, then applies the events and the resyncs until the context is done.
+func (r *Replicator[K, V]) replicate(ctx context.Context) {
+	var retry <-chan time.Time
+	if err := r.resync(ctx); err != nil {
+		retry = time.After(r.opts.RetryInterval)
+	}
+
+	var tick <-chan time.Time
+	if r.opts.ResyncInterval > 0 {
+		ticker := time.NewTicker(r.opts.ResyncInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case ev := <-r.events:
+			if err := r.apply(ctx, ev); err != nil && retry == nil {
+				retry = time.After(r.opts.RetryInterval)
+			}
+
+		case done := <-r.resyncs:
+			err := r.resync(ctx)
+			done <- err
+			if err == nil {
+				retry = nil
+			}
+
+		case <-tick:
+			if err := r.resync(ctx); err == nil {
+				retry = nil
+			}
+
+		case <-retry:
+			retry = nil
+			if err := r.resync(ctx); err != nil {
+				retry = time.After(r.opts.RetryInterval)
+			}
+		}
+	}
+}
+
+// apply applies a single event to the replica.
+func (r *Replicator[K, V]) apply(ctx context.Context, ev Event[K, V]) error {
+	var err error
+	if ev.Type == EventSet {
+		err = SetCtx(ctx, r.replica, ev.Key, ev.Value)
+	} else if err = DeleteCtx(ctx, r.replica, ev.Key); errors.Is(err, ErrNotFound) {
+		err = nil
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if err != nil {
+		r.stats.Failed++
+		r.stats.InSync = false
+		r.logger.Printf("cachego: replicating %v of key %v failed: %v", ev.Type, ev.Key, err)
+		return err
+	}
+
+	r.stats.Applied++
+	r.stats.Lag = time.Since(ev.Time)
+	return nil
+}
+
+// resync copies the primary to the replica.
+func (r *Replicator[K, V]) resync(ctx context.Context) error {
+	err := r.copy(ctx)
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if err != nil {
+		r.stats.InSync = false
+		r.logger.Printf("cachego: resyncing the replica failed: %v", err)
+		return err
+	}
+
+	r.stats.Resyncs++
+	r.stats.LastResync = time.Now()
+	r.stats.InSync = true
+	return nil
+}
+
+func (r *Replicator[K, V]) copy(ctx context.Context) error {
+	p, ok := r.primary.(interface{ Keys() []K })
+	if !ok {
+		return errors.New("cachego: the primary cache doesn't list its keys")
+	}
+
+	keys := p.Keys()
+	present := make(map[K]struct{}, len(keys))
+	var errs []error
+	for _, k := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// the key may be gone since it was listed
+		v, ok := LookupValue(r.primary, k)
+		if !ok {
+			continue
+		}
+		present[k] = struct{}{}
+		if err := SetCtx(ctx, r.replica, k, v); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if rk, ok := r.replica.(interface{ Keys() []K }); ok {
+		for _, k := range rk.Keys() {
+			if _, ok := present[k]; ok {
+				continue
+			}
+			if err := DeleteCtx(ctx, r.replica, k); err != nil && !errors.Is(err, ErrNotFound) {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,129 @@
+package cachego
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flaky is a replica that fails its writes while down.
+type flaky[K comparable, V any] struct {
+	Cache[K, V]
+	down *atomic.Bool
+}
+
+func (f flaky[K, V]) Set(key K, value V) error {
+	if f.down.Load() {
+		return errDown
+	}
+	return f.Cache.Set(key, value)
+}
+
+func (f flaky[K, V]) Delete(key K) error {
+	if f.down.Load() {
+		return errDown
+	}
+	return f.Cache.Delete(key)
+}
+
+func (f flaky[K, V]) Keys() []K {
+	return f.Cache.(interface{ Keys() []K }).Keys()
+}
+
+// nolint:errcheck
+func TestReplicator(t *testing.T) {
+	primary := NewCache[int, string](Opts{Size: 10, Events: 100})
+	replica := NewCache[int, string](Opts{Size: 10})
+	primary.Set(1, "one")
+	replica.Set(9, "stale")
+
+	r, err := NewReplicator(primary, replica, ReplicatorOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	has := func(key int, value string) func() bool {
+		return func() bool {
+			v, err := replica.Get(key)
+			return err == nil && v == value
+		}
+	}
+	missing := func(key int) func() bool {
+		return func() bool {
+			_, ok := LookupValue(replica, key)
+			return !ok
+		}
+	}
+
+	// the initial resync copies the primary
+	eventually(t, has(1, "one"))
+	eventually(t, missing(9))
+
+	primary.Set(2, "two")
+	primary.Set(1, "uno")
+	eventually(t, has(2, "two"))
+	eventually(t, has(1, "uno"))
+
+	primary.Delete(2)
+	eventually(t, missing(2))
+
+	primary.Clear()
+	eventually(t, missing(1))
+
+	stats := r.Stats()
+	if stats.Applied != 5 || stats.Failed != 0 || stats.Resyncs != 1 || !stats.InSync {
+		t.Errorf("expected 5 applied, 1 resync in sync, got %+v", stats)
+	}
+}
+
+// nolint:errcheck
+func TestReplicatorResync(t *testing.T) {
+	primary := NewCache[int, string](Opts{Size: 10, Events: 100})
+	down := &atomic.Bool{}
+	replica := flaky[int, string]{Cache: NewCache[int, string](Opts{Size: 10}), down: down}
+
+	r, err := NewReplicator[int, string](primary, replica, ReplicatorOpts{RetryInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	eventually(t, func() bool { return r.Stats().Resyncs == 1 })
+
+	// the writes missed while the replica is down are resynced once it is back
+	down.Store(true)
+	primary.Set(1, "one")
+	eventually(t, func() bool { return r.Stats().Failed == 1 })
+	if r.Stats().InSync {
+		t.Errorf("expected the replica to be out of sync")
+	}
+
+	down.Store(false)
+	eventually(t, func() bool { return r.Stats().InSync })
+	if v, err := replica.Get(1); err != nil || v != "one" {
+		t.Errorf("expected %v, got %v, %v", "one", v, err)
+	}
+
+	// the events dropped by the primary are repaired on demand
+	replica.Cache.Set(2, "stale")
+	if err := r.Resync(context.Background()); err != nil {
+		t.Errorf("expected %v, got %v", nil, err)
+	}
+	if _, ok := LookupValue[int, string](replica, 2); ok {
+		t.Errorf("expected the stale key to be deleted")
+	}
+
+	r.Close()
+	if err := r.Resync(context.Background()); err == nil {
+		t.Errorf("expected an error after Close")
+	}
+}
+
+func TestReplicatorNoEvents(t *testing.T) {
+	_, err := NewReplicator(NewCache[int, string](Opts{Size: 10}), NewNopCache[int, string](), ReplicatorOpts{})
+	if err == nil {
+		t.Errorf("expected an error for a primary without events")
+	}
+}
@@ -0,0 +1,125 @@
+package cachego
+
+import (
+	"context"
+	"sync"
+)
+
+// contextKey is the key of the caches of keys K and values V in the contexts.
+type contextKey[K comparable, V any] struct{}
+
+// NewContext returns a copy of the context carrying the cache, retrieved with FromContext.
+// A context carries a single cache for each key and value types.
+func NewContext[K comparable, V any](ctx context.Context, c Cache[K, V]) context.Context {
+	return context.WithValue(ctx, contextKey[K, V]{}, c)
+}
+
+// FromContext returns the cache of keys K and values V carried by the context, if any.
+func FromContext[K comparable, V any](ctx context.Context) (Cache[K, V], bool) {
+	c, ok := ctx.Value(contextKey[K, V]{}).(Cache[K, V])
+	return c, ok
+}
+
+type request[K comparable, V any] struct {
+	mx      sync.Mutex
+	ctx     context.Context
+	entries map[K]V
+}
+
+// NewRequestCache creates an unbounded cache living as long as the context, e.g. the one of an http.Request,
+// to memoize the lookups repeated within a request without storing them in the process-wide cache.
+// Once the context is done, the entries are discarded and the operations return the error of the context.
+// It is thread-safe, so the goroutines serving the request may share it.
+//
+// Use WithRequestCache to carry it in the context, for the functions down the call stack.
+func NewRequestCache[K comparable, V any](ctx context.Context) Cache[K, V] {
+	return &request[K, V]{ctx: ctx, entries: make(map[K]V)}
+}
+
+// WithRequestCache returns a copy of the context carrying a new request cache bound to it.
+func WithRequestCache[K comparable, V any](ctx context.Context) context.Context {
+	return NewContext(ctx, NewRequestCache[K, V](ctx))
+}
+
+// done discards the entries once the context is done, and returns its error. It must be called with the lock held.
+func (r *request[K, V]) done() error {
+	err := r.ctx.Err()
+	if err != nil {
+		r.entries = nil
+	}
+
+	return err
+}
+
+// Set stores the value under the key.
+func (r *request[K, V]) Set(key K, value V) error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if err := r.done(); err != nil {
+		return err
+	}
+
+	r.entries[key] = value
+	return nil
+}
+
+// Get retrieves the value associated with the key, or an error wrapping ErrNotFound.
+func (r *request[K, V]) Get(key K) (V, error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if err := r.done(); err != nil {
+		var empty V
+		return empty, err
+	}
+
+	v, ok := r.entries[key]
+	if !ok {
+		return v, notFound(key)
+	}
+	return v, nil
+}
+
+// Lookup retrieves the value associated with the key, reporting whether it was found.
+func (r *request[K, V]) Lookup(key K) (V, bool) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if r.done() != nil {
+		var empty V
+		return empty, false
+	}
+
+	v, ok := r.entries[key]
+	return v, ok
+}
+
+// Delete removes the key, or returns an error wrapping ErrNotFound.
+func (r *request[K, V]) Delete(key K) error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if err := r.done(); err != nil {
+		return err
+	}
+
+	if _, ok := r.entries[key]; !ok {
+		return notFound(key)
+	}
+	delete(r.entries, key)
+	return nil
+}
+
+// Clear removes all the entries.
+func (r *request[K, V]) Clear() error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if err := r.done(); err != nil {
+		return err
+	}
+
+	r.entries = make(map[K]V)
+	return nil
+}
@@ -0,0 +1,38 @@
+package cachego
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// nolint:errcheck
+func TestRequestCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = WithRequestCache[int, string](ctx)
+
+	c, ok := FromContext[int, string](ctx)
+	if !ok {
+		t.Fatalf("expected the context to carry a cache")
+	}
+	if _, ok := FromContext[string, string](ctx); ok {
+		t.Errorf("expected no cache of string keys")
+	}
+
+	c.Set(1, "one")
+	if v, err := c.Get(1); err != nil || v != "one" {
+		t.Errorf("expected %v, got %v (%v)", "one", v, err)
+	}
+	if _, err := c.Get(2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+
+	// the entries are discarded once the request ends
+	cancel()
+	if _, ok := LookupValue(c, 1); ok {
+		t.Errorf("expected a miss after the context is done")
+	}
+	if err := c.Set(2, "two"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,463 @@
+// Package respserver serves a cachego.Cache over a subset of the Redis protocol (RESP2),
+// so the Redis clients of any language can talk to an embedded Go cache, e.g. during local development.
+//
+// The supported commands are GET, SET (with EX or PX), DEL, EXPIRE, TTL and FLUSHALL,
+// along with PING, SELECT 0, COMMAND and QUIT for the clients issuing them on connection.
+// The keys expiring with EXPIRE, SET EX or SET PX are tracked by the server, on top of the TTL of the cache.
+package respserver
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/noam-g4/cachego"
+	"github.com/noam-g4/cachego/internal/resp"
+)
+
+// ErrServerClosed is returned by Serve and ListenAndServe after Close.
+var ErrServerClosed = errors.New("respserver: server closed")
+
+// Opts configures a server.
+type Opts struct {
+	// Logger receives the connections closed on an error. Defaults to discarding them.
+	Logger cachego.Logger
+}
+
+// Server serves a cache to Redis clients.
+type Server struct {
+	c      cachego.Cache[string, []byte]
+	logger cachego.Logger
+
+	mx        *sync.Mutex
+	ttls      map[string]*deadline
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        *sync.WaitGroup
+}
+
+type deadline struct {
+	at    time.Time
+	timer *time.Timer
+}
+
+// NewServer creates a new server of the cache. It serves nothing until Serve or ListenAndServe.
+func NewServer(c cachego.Cache[string, []byte], opts Opts) *Server {
+	logger := opts.Logger
+	if logger == nil {
+		logger = nopLogger{}
+	}
+
+	return &Server{
+		c:         c,
+		logger:    logger,
+		mx:        &sync.Mutex{},
+		ttls:      make(map[string]*deadline),
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+		wg:        &sync.WaitGroup{},
+	}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
+
+// ListenAndServe listens on the TCP address and serves the connections until Close.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(l)
+}
+
+// Serve serves the connections accepted on the listener until Close, which closes it.
+// It always returns a non-nil error, ErrServerClosed after Close.
+func (s *Server) Serve(l net.Listener) error {
+	s.mx.Lock()
+	if s.closed {
+		s.mx.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	s.listeners[l] = struct{}{}
+	s.mx.Unlock()
+
+	defer func() {
+		s.mx.Lock()
+		delete(s.listeners, l)
+		s.mx.Unlock()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mx.Lock()
+			closed := s.closed
+			s.mx.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		s.mx.Lock()
+		if s.closed {
+			s.mx.Unlock()
+			conn.Close()
+			return ErrServerClosed
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mx.Unlock()
+
+		go s.serve(conn)
+	}
+}
+
+// Close closes the listeners and the connections, and stops the expiry of the keys.
+// It doesn't close the cache. It always returns nil, and may be called more than once.
+func (s *Server) Close() error {
+	s.mx.Lock()
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for c := range s.conns {
+		c.Close()
+	}
+	for key, d := range s.ttls {
+		d.timer.Stop()
+		delete(s.ttls, key)
+	}
+	s.mx.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+// serve runs the commands of the connection until it is closed.
+func (s *Server) serve(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mx.Lock()
+		delete(s.conns, conn)
+		s.mx.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		v, err := resp.Read(r)
+		if err != nil {
+			if errors.Is(err, resp.ErrProtocol) {
+				resp.WriteError(w, "ERR Protocol error")
+				w.Flush()
+			}
+			if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+				s.logger.Printf("respserver: closing connection from %v: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		args, ok := command(v)
+		if !ok {
+			resp.WriteError(w, "ERR Protocol error: expected an array of bulk strings")
+			w.Flush()
+			return
+		}
+
+		quit := s.run(w, args)
+		if err := w.Flush(); err != nil || quit {
+			return
+		}
+	}
+}
+
+// command returns the arguments of a command, an array of bulk strings.
+func command(v resp.Value) ([][]byte, bool) {
+	if v.Type != resp.Array || len(v.Array) == 0 {
+		return nil, false
+	}
+
+	args := make([][]byte, len(v.Array))
+	for i, a := range v.Array {
+		if a.Type != resp.BulkString || a.Null {
+			return nil, false
+		}
+		args[i] = a.Str
+	}
+
+	return args, true
+}
+
+// arities validate the number of arguments of the supported commands.
+var arities = map[string]func(n int) bool{
+	"ping":     func(n int) bool { return n <= 1 },
+	"quit":     func(n int) bool { return n == 0 },
+	"select":   func(n int) bool { return n == 1 },
+	"command":  func(n int) bool { return true },
+	"get":      func(n int) bool { return n == 1 },
+	"set":      func(n int) bool { return n == 2 || n == 4 },
+	"del":      func(n int) bool { return n >= 1 },
+	"expire":   func(n int) bool { return n == 2 },
+	"ttl":      func(n int) bool { return n == 1 },
+	"flushall": func(n int) bool { return n <= 1 },
+}
+
+// run runs a command and writes its reply. It reports whether the connection must be closed.
+func (s *Server) run(w *bufio.Writer, args [][]byte) bool {
+	name := strings.ToLower(string(args[0]))
+	args = args[1:]
+
+	valid, ok := arities[name]
+	if !ok {
+		resp.WriteError(w, fmt.Sprintf("ERR unknown command '%s'", truncate(name)))
+		return false
+	}
+	if !valid(len(args)) {
+		resp.WriteError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", name))
+		return false
+	}
+
+	switch name {
+	case "ping":
+		if len(args) == 1 {
+			resp.WriteBulk(w, args[0])
+		} else {
+			resp.WriteSimple(w, "PONG")
+		}
+
+	case "quit":
+		resp.WriteSimple(w, "OK")
+		return true
+
+	case "select":
+		if string(args[0]) != "0" {
+			resp.WriteError(w, "ERR DB index is out of range")
+		} else {
+			resp.WriteSimple(w, "OK")
+		}
+
+	case "command":
+		resp.WriteArray(w, 0)
+
+	case "get":
+		v, ok := s.get(string(args[0]))
+		if !ok {
+			v = nil
+		} else if v == nil {
+			v = []byte{}
+		}
+		resp.WriteBulk(w, v)
+
+	case "set":
+		var ttl time.Duration
+		if len(args) == 4 {
+			n, err := strconv.ParseInt(string(args[3]), 10, 64)
+			if err != nil || n <= 0 {
+				resp.WriteError(w, "ERR invalid expire time in 'set' command")
+				return false
+			}
+			switch strings.ToLower(string(args[2])) {
+			case "ex":
+				ttl = time.Duration(n) * time.Second
+			case "px":
+				ttl = time.Duration(n) * time.Millisecond
+			default:
+				resp.WriteError(w, "ERR syntax error")
+				return false
+			}
+		}
+		if err := s.set(string(args[0]), args[1], ttl); err != nil {
+			resp.WriteError(w, "ERR "+err.Error())
+		} else {
+			resp.WriteSimple(w, "OK")
+		}
+
+	case "del":
+		var n int64
+		for _, key := range args {
+			if s.delete(string(key)) {
+				n++
+			}
+		}
+		resp.WriteInt(w, n)
+
+	case "expire":
+		n, err := strconv.ParseInt(string(args[1]), 10, 64)
+		if err != nil {
+			resp.WriteError(w, "ERR value is not an integer or out of range")
+			return false
+		}
+		if s.expire(string(args[0]), time.Duration(n)*time.Second) {
+			resp.WriteInt(w, 1)
+		} else {
+			resp.WriteInt(w, 0)
+		}
+
+	case "ttl":
+		resp.WriteInt(w, s.ttl(string(args[0])))
+
+	case "flushall":
+		if err := s.flush(); err != nil {
+			resp.WriteError(w, "ERR "+err.Error())
+		} else {
+			resp.WriteSimple(w, "OK")
+		}
+	}
+
+	return false
+}
+
+// truncate bounds the unknown command names echoed in the errors.
+func truncate(name string) string {
+	if len(name) > 128 {
+		return name[:128]
+	}
+
+	return name
+}
+
+// get returns the value of the key, unless it expired.
+func (s *Server) get(key string) ([]byte, bool) {
+	s.mx.Lock()
+	if d, ok := s.ttls[key]; ok && !time.Now().Before(d.at) {
+		s.mx.Unlock()
+		return nil, false
+	}
+	s.mx.Unlock()
+
+	return cachego.LookupValue(s.c, key)
+}
+
+// set stores the value, with the given ttl if positive. Like Redis, it clears any previous ttl of the key.
+func (s *Server) set(key string, value []byte, ttl time.Duration) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if err := s.c.Set(key, value); err != nil {
+		return err
+	}
+
+	s.untrack(key)
+	if ttl > 0 {
+		s.track(key, ttl)
+	}
+	return nil
+}
+
+// delete removes the key, and reports whether it was present.
+func (s *Server) delete(key string) bool {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	expired := false
+	if d, ok := s.ttls[key]; ok {
+		expired = !time.Now().Before(d.at)
+		s.untrack(key)
+	}
+
+	return s.c.Delete(key) == nil && !expired
+}
+
+// expire sets the ttl of the key, deleting it if the ttl isn't positive, and reports whether it was present.
+func (s *Server) expire(key string, ttl time.Duration) bool {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if d, ok := s.ttls[key]; ok && !time.Now().Before(d.at) {
+		return false
+	}
+	if _, ok := cachego.LookupValue(s.c, key); !ok {
+		return false
+	}
+
+	s.untrack(key)
+	if ttl <= 0 {
+		s.c.Delete(key)
+		return true
+	}
+
+	s.track(key, ttl)
+	return true
+}
+
+// ttl returns the seconds left before the key expires, -1 if it doesn't, or -2 if it is missing.
+// Without a ttl set by the server, the expiry of the entry in the cache is reported, if the cache inspects its entries.
+func (s *Server) ttl(key string) int64 {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var at time.Time
+	if d, ok := s.ttls[key]; ok {
+		at = d.at
+	}
+
+	var present bool
+	if in, ok := s.c.(cachego.Inspector[string, []byte]); ok {
+		_, info, err := in.GetWithInfo(key)
+		present = err == nil
+		if at.IsZero() {
+			at = info.Expires
+		}
+	} else {
+		_, present = cachego.LookupValue(s.c, key)
+	}
+
+	left := time.Until(at)
+	switch {
+	case !present || (!at.IsZero() && left <= 0):
+		return -2
+	case at.IsZero():
+		return -1
+	}
+
+	return int64((left + time.Second/2) / time.Second)
+}
+
+// flush removes every key.
+func (s *Server) flush() error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	for key := range s.ttls {
+		s.untrack(key)
+	}
+
+	return s.c.Clear()
+}
+
+// track deletes the key once the ttl elapses. It must be called with the lock held.
+func (s *Server) track(key string, ttl time.Duration) {
+	d := &deadline{at: time.Now().Add(ttl)}
+	d.timer = time.AfterFunc(ttl, func() {
+		s.mx.Lock()
+		defer s.mx.Unlock()
+
+		// the key may have been set again since
+		if s.ttls[key] == d {
+			delete(s.ttls, key)
+			s.c.Delete(key)
+		}
+	})
+	s.ttls[key] = d
+}
+
+// untrack cancels the ttl of the key. It must be called with the lock held.
+func (s *Server) untrack(key string) {
+	if d, ok := s.ttls[key]; ok {
+		d.timer.Stop()
+		delete(s.ttls, key)
+	}
+}
@@ -0,0 +1,148 @@
+package respserver
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/noam-g4/cachego"
+	"github.com/noam-g4/cachego/internal/resp"
+)
+
+type client struct {
+	t *testing.T
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+// newServer serves the cache on a local port, and returns a client connected to it.
+func newServer(t *testing.T, c cachego.Cache[string, []byte]) *client {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(c, Opts{})
+	done := make(chan error)
+	go func() { done <- s.Serve(l) }()
+	t.Cleanup(func() {
+		s.Close()
+		if err := <-done; !errors.Is(err, ErrServerClosed) {
+			t.Errorf("expected %v, got %v", ErrServerClosed, err)
+		}
+	})
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &client{t: t, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+}
+
+// do sends the command and returns the reply, formatted as a string.
+func (c *client) do(args ...string) string {
+	c.t.Helper()
+
+	b := make([][]byte, len(args))
+	for i, a := range args {
+		b[i] = []byte(a)
+	}
+	if err := resp.WriteCommand(c.w, b...); err != nil {
+		c.t.Fatal(err)
+	}
+
+	v, err := resp.Read(c.r)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	switch {
+	case v.Null:
+		return "(nil)"
+	case v.Type == resp.Integer:
+		return "(integer) " + strconv.FormatInt(v.Int, 10)
+	case v.Type == resp.Error:
+		return "(error) " + string(v.Str)
+	case v.Type == resp.Array:
+		return "(array) " + strconv.Itoa(len(v.Array))
+	}
+
+	return string(v.Str)
+}
+
+func TestServer(t *testing.T) {
+	c := cachego.NewCache[string, []byte](cachego.Opts{Size: 3})
+	cl := newServer(t, c)
+
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"PING"}, "PONG"},
+		{[]string{"ping", "hi"}, "hi"},
+		{[]string{"SELECT", "0"}, "OK"},
+		{[]string{"COMMAND", "DOCS"}, "(array) 0"},
+		{[]string{"GET", "a"}, "(nil)"},
+		{[]string{"SET", "a", "1"}, "OK"},
+		{[]string{"GET", "a"}, "1"},
+		{[]string{"SET", "empty", ""}, "OK"},
+		{[]string{"GET", "empty"}, ""},
+		{[]string{"TTL", "a"}, "(integer) -1"},
+		{[]string{"TTL", "b"}, "(integer) -2"},
+		{[]string{"EXPIRE", "a", "100"}, "(integer) 1"},
+		{[]string{"TTL", "a"}, "(integer) 100"},
+		{[]string{"SET", "a", "2"}, "OK"},
+		{[]string{"TTL", "a"}, "(integer) -1"},
+		{[]string{"SET", "b", "3", "EX", "50"}, "OK"},
+		{[]string{"TTL", "b"}, "(integer) 50"},
+		{[]string{"SET", "c", "4"}, "(error) ERR key c: cache is full"},
+		{[]string{"EXPIRE", "c", "10"}, "(integer) 0"},
+		{[]string{"DEL", "a", "c", "b"}, "(integer) 2"},
+		{[]string{"GET", "b"}, "(nil)"},
+		{[]string{"SET", "a", "1", "NX", "1"}, "(error) ERR syntax error"},
+		{[]string{"SET", "a", "1", "EX", "0"}, "(error) ERR invalid expire time in 'set' command"},
+		{[]string{"GET"}, "(error) ERR wrong number of arguments for 'get' command"},
+		{[]string{"HGET", "a", "b"}, "(error) ERR unknown command 'hget'"},
+		{[]string{"SET", "a", "1"}, "OK"},
+		{[]string{"EXPIRE", "a", "0"}, "(integer) 1"},
+		{[]string{"GET", "a"}, "(nil)"},
+		{[]string{"SET", "a", "1"}, "OK"},
+		{[]string{"FLUSHALL"}, "OK"},
+		{[]string{"GET", "a"}, "(nil)"},
+		{[]string{"GET", "empty"}, "(nil)"},
+		{[]string{"QUIT"}, "OK"},
+	} {
+		if got := cl.do(tc.args...); got != tc.expected {
+			t.Errorf("%v: expected %q, got %q", tc.args, tc.expected, got)
+		}
+	}
+}
+
+func TestServerExpiry(t *testing.T) {
+	c := cachego.NewCache[string, []byte](cachego.Opts{Size: 10})
+	cl := newServer(t, c)
+
+	cl.do("SET", "a", "1", "PX", "20")
+	cl.do("SET", "b", "2")
+	cl.do("EXPIRE", "b", "1")
+	cl.do("SET", "b", "3")
+
+	time.Sleep(50 * time.Millisecond)
+	if got := cl.do("GET", "a"); got != "(nil)" {
+		t.Errorf("expected %q, got %q", "(nil)", got)
+	}
+	if _, err := c.Get("a"); !errors.Is(err, cachego.ErrNotFound) {
+		t.Errorf("expected %v, got %v", cachego.ErrNotFound, err)
+	}
+
+	// setting the key again cleared its ttl
+	time.Sleep(time.Second)
+	if got := cl.do("GET", "b"); got != "3" {
+		t.Errorf("expected %q, got %q", "3", got)
+	}
+}
@@ -0,0 +1,294 @@
+package cachego
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const defaultVirtualNodes = 100
+
+// ErrNoMembers is returned by the operations of a ring that has no members.
+var ErrNoMembers = errors.New("ring has no members")
+
+// RingOpts configures a Ring.
+type RingOpts[K comparable] struct {
+	// VirtualNodes is the number of points every member takes on the ring. More points spread the keys
+	// more evenly across the members, at the cost of a larger ring. Defaults to 100.
+	VirtualNodes int
+	// Replicas is the number of distinct members every key is stored in, capped at the number of members.
+	// Reads fall back to the next replicas when a member misses or fails. Defaults to 1.
+	Replicas int
+	// Hasher maps a key to its position on the ring. Defaults to a FNV-1a hash of the key.
+	Hasher func(key K) uint64
+}
+
+// Ring is a thread-safe cache that routes every key to a few of its member caches (local or remote)
+// with consistent hashing, so adding or removing a member only moves the keys of its share of the ring.
+// Moved keys are not migrated: they miss on their new members until they are set again.
+type Ring[K comparable, V any] struct {
+	mx       *sync.RWMutex
+	points   []ringPoint // sorted by hash
+	members  map[string]Cache[K, V]
+	vnodes   int
+	replicas int
+	hasher   func(key K) uint64
+}
+
+type ringPoint struct {
+	hash   uint64
+	member string
+}
+
+// NewRing creates a new ring over the given members, by name. The names place the members on the ring,
+// so a member keeps its share of the keys across restarts as long as its name is stable.
+func NewRing[K comparable, V any](members map[string]Cache[K, V], opts RingOpts[K]) *Ring[K, V] {
+	r := &Ring[K, V]{
+		mx:       &sync.RWMutex{},
+		members:  make(map[string]Cache[K, V], len(members)),
+		vnodes:   opts.VirtualNodes,
+		replicas: opts.Replicas,
+		hasher:   opts.Hasher,
+	}
+
+	if r.vnodes <= 0 {
+		r.vnodes = defaultVirtualNodes
+	}
+	if r.replicas <= 0 {
+		r.replicas = 1
+	}
+	if r.hasher == nil {
+		r.hasher = func(key K) uint64 { return hashUint(hashKey(key)) }
+	}
+
+	for name, c := range members {
+		r.add(name, c)
+	}
+	r.sort()
+
+	return r
+}
+
+// Add adds the member to the ring, replacing the member of the same name.
+func (r *Ring[K, V]) Add(name string, c Cache[K, V]) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if _, ok := r.members[name]; ok {
+		r.members[name] = c
+		return
+	}
+
+	r.add(name, c)
+	r.sort()
+}
+
+// Remove removes the member from the ring, if present. The member itself is left untouched.
+func (r *Ring[K, V]) Remove(name string) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if _, ok := r.members[name]; !ok {
+		return
+	}
+
+	delete(r.members, name)
+	points := r.points[:0]
+	for _, p := range r.points {
+		if p.member != name {
+			points = append(points, p)
+		}
+	}
+	r.points = points
+}
+
+// Owners returns the names of the members storing the key, the primary one first.
+func (r *Ring[K, V]) Owners(key K) []string {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+
+	owners := r.owners(key)
+	names := make([]string, len(owners))
+	for i, o := range owners {
+		names[i] = o.name
+	}
+
+	return names
+}
+
+// Set stores the value in every replica of the key, returning the joined errors of the replicas that failed.
+func (r *Ring[K, V]) Set(key K, value V) error {
+	return r.SetCtx(context.Background(), key, value)
+}
+
+// SetCtx stores the value just like Set, passing the context to the members.
+func (r *Ring[K, V]) SetCtx(ctx context.Context, key K, value V) error {
+	owners, err := r.route(key)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, o := range owners {
+		if err := SetCtx(ctx, o.cache, key, value); err != nil {
+			errs = append(errs, memberErr(o.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Get retrieves the value from the first replica of the key holding it.
+// If no replica holds it, it returns an error wrapping ErrNotFound, joined with the errors of the replicas that failed.
+func (r *Ring[K, V]) Get(key K) (V, error) {
+	return r.GetCtx(context.Background(), key)
+}
+
+// GetCtx retrieves the value just like Get, passing the context to the members.
+func (r *Ring[K, V]) GetCtx(ctx context.Context, key K) (V, error) {
+	var empty V
+	owners, err := r.route(key)
+	if err != nil {
+		return empty, err
+	}
+
+	errs := []error{notFound(key)}
+	for _, o := range owners {
+		v, err := GetCtx(ctx, o.cache, key)
+		if err == nil {
+			return v, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			errs = append(errs, memberErr(o.name, err))
+		}
+	}
+
+	return empty, errors.Join(errs...)
+}
+
+// Delete removes the key from every replica.
+// It returns an error wrapping ErrNotFound if no replica held the key, along with the errors of the replicas that failed.
+func (r *Ring[K, V]) Delete(key K) error {
+	return r.DeleteCtx(context.Background(), key)
+}
+
+// DeleteCtx removes the key just like Delete, passing the context to the members.
+func (r *Ring[K, V]) DeleteCtx(ctx context.Context, key K) error {
+	owners, err := r.route(key)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	var errs []error
+	for _, o := range owners {
+		err := DeleteCtx(ctx, o.cache, key)
+		switch {
+		case err == nil:
+			found = true
+		case !errors.Is(err, ErrNotFound):
+			errs = append(errs, memberErr(o.name, err))
+		}
+	}
+
+	if !found {
+		errs = append([]error{notFound(key)}, errs...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Clear clears every member, returning the joined errors of the members that failed.
+func (r *Ring[K, V]) Clear() error {
+	return r.ClearCtx(context.Background())
+}
+
+// ClearCtx clears every member just like Clear, passing the context to the members.
+func (r *Ring[K, V]) ClearCtx(ctx context.Context) error {
+	r.mx.RLock()
+	members := make(map[string]Cache[K, V], len(r.members))
+	for name, c := range r.members {
+		members[name] = c
+	}
+	r.mx.RUnlock()
+
+	var errs []error
+	for name, c := range members {
+		if err := ClearCtx(ctx, c); err != nil {
+			errs = append(errs, memberErr(name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+type ringOwner[K comparable, V any] struct {
+	name  string
+	cache Cache[K, V]
+}
+
+// route returns the replicas of the key, so they are called after the ring lock is released.
+func (r *Ring[K, V]) route(key K) ([]ringOwner[K, V], error) {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+
+	if len(r.members) == 0 {
+		return nil, ErrNoMembers
+	}
+
+	return r.owners(key), nil
+}
+
+// owners walks the ring clockwise from the key, collecting distinct members.
+func (r *Ring[K, V]) owners(key K) []ringOwner[K, V] {
+	n := r.replicas
+	if n > len(r.members) {
+		n = len(r.members)
+	}
+
+	h := r.hasher(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= h })
+
+	owners := make([]ringOwner[K, V], 0, n)
+	for j := 0; len(owners) < n && j < len(r.points); j++ {
+		p := r.points[(i+j)%len(r.points)]
+		if !hasOwner(owners, p.member) {
+			owners = append(owners, ringOwner[K, V]{name: p.member, cache: r.members[p.member]})
+		}
+	}
+
+	return owners
+}
+
+func hasOwner[K comparable, V any](owners []ringOwner[K, V], name string) bool {
+	for _, o := range owners {
+		if o.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// add places the virtual nodes of the member on the ring, which must then be sorted.
+func (r *Ring[K, V]) add(name string, c Cache[K, V]) {
+	r.members[name] = c
+	for i := 0; i < r.vnodes; i++ {
+		r.points = append(r.points, ringPoint{hash: hashUint(hashKey(fmt.Sprintf("%s#%d", name, i))), member: name})
+	}
+}
+
+func (r *Ring[K, V]) sort() {
+	sort.Slice(r.points, func(i, j int) bool {
+		if r.points[i].hash != r.points[j].hash {
+			return r.points[i].hash < r.points[j].hash
+		}
+		return r.points[i].member < r.points[j].member
+	})
+}
+
+func memberErr(name string, err error) error {
+	return fmt.Errorf("member %v: %w", name, err)
+}
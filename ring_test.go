@@ -0,0 +1,105 @@
+package cachego
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func newRingMembers(n int) map[string]Cache[int, int] {
+	members := make(map[string]Cache[int, int], n)
+	for i := 0; i < n; i++ {
+		members[fmt.Sprintf("node-%d", i)] = NewCache[int, int](Opts{Size: 10000})
+	}
+
+	return members
+}
+
+func TestRingBalance(t *testing.T) {
+	r := NewRing(newRingMembers(4), RingOpts[int]{})
+
+	counts := map[string]int{}
+	for k := 0; k < 10000; k++ {
+		counts[r.Owners(k)[0]]++
+	}
+
+	for name, n := range counts {
+		if n < 1500 || n > 3500 {
+			t.Errorf("expected about 2500 keys on %v, got %v", name, n)
+		}
+	}
+}
+
+func TestRingRebalance(t *testing.T) {
+	r := NewRing(newRingMembers(4), RingOpts[int]{})
+
+	before := make([]string, 10000)
+	for k := range before {
+		before[k] = r.Owners(k)[0]
+	}
+
+	// only the keys taken by the new member move
+	r.Add("node-4", NewCache[int, int](Opts{Size: 10000}))
+	moved := 0
+	for k, owner := range before {
+		if now := r.Owners(k)[0]; now != owner {
+			moved++
+			if now != "node-4" {
+				t.Fatalf("expected key %v to move to node-4, got %v", k, now)
+			}
+		}
+	}
+	if moved == 0 || moved > 3000 {
+		t.Errorf("expected about 2000 keys to move, got %v", moved)
+	}
+
+	// removing it moves them back
+	r.Remove("node-4")
+	for k, owner := range before {
+		if now := r.Owners(k)[0]; now != owner {
+			t.Fatalf("expected key %v on %v, got %v", k, owner, now)
+		}
+	}
+}
+
+// failing is a member that is down.
+type failing[K comparable, V any] struct{ Cache[K, V] }
+
+var errDown = errors.New("down")
+
+func (failing[K, V]) Get(key K) (V, error) {
+	var empty V
+	return empty, errDown
+}
+
+// nolint:errcheck
+func TestRingReplicas(t *testing.T) {
+	members := newRingMembers(3)
+	r := NewRing(members, RingOpts[int]{Replicas: 2})
+
+	r.Set(1, 1)
+	owners := r.Owners(1)
+	if len(owners) != 2 || owners[0] == owners[1] {
+		t.Fatalf("expected 2 distinct owners, got %v", owners)
+	}
+	for _, o := range owners {
+		if v, err := members[o].Get(1); err != nil || v != 1 {
+			t.Errorf("expected 1 on %v, got %v (%v)", o, v, err)
+		}
+	}
+
+	// reads fall back to the next replica when the primary is down
+	r.Add(owners[0], failing[int, int]{members[owners[0]]})
+	if v, err := r.Get(1); err != nil || v != 1 {
+		t.Errorf("expected 1, got %v (%v)", v, err)
+	}
+
+	r.Delete(1)
+	if _, err := r.Get(1); !errors.Is(err, ErrNotFound) || !errors.Is(err, errDown) {
+		t.Errorf("expected %v and %v, got %v", ErrNotFound, errDown, err)
+	}
+
+	if err := NewRing[int, int](nil, RingOpts[int]{}).Set(1, 1); !errors.Is(err, ErrNoMembers) {
+		t.Errorf("expected %v, got %v", ErrNoMembers, err)
+	}
+}
@@ -0,0 +1,233 @@
+// Package s3 provides a cachego.File implementation that persists the cache snapshot
+// as a single object in an S3 (or S3-compatible) bucket.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/noam-g4/cachego"
+	"github.com/noam-g4/cachego/internal/uri"
+)
+
+// Opts configures a cache file stored as a single object in an S3 (or S3-compatible) bucket.
+type Opts struct {
+	// Endpoint is the base URL of an S3-compatible service (e.g. "http://localhost:9000" for MinIO).
+	// If empty, the regional AWS endpoint is used with virtual-hosted style addressing.
+	// If set, requests use path-style addressing (endpoint/bucket/key).
+	Endpoint string
+	Region   string
+	Bucket   string
+	Key      string
+
+	// AccessKeyID, SecretAccessKey and SessionToken are the credentials used to sign requests.
+	// If AccessKeyID is empty, the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+	// environment variables are used instead.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// SSE is the server-side encryption algorithm to request on Dump ("AES256" or "aws:kms").
+	// SSEKMSKeyID optionally selects the KMS key when SSE is "aws:kms".
+	SSE         string
+	SSEKMSKeyID string
+
+	// Client is the HTTP client used for requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// file is an implementation of the cachego.File interface.
+// It loads and dumps the cache data from and to an object in an S3 bucket.
+type file struct {
+	opts Opts
+	now  func() time.Time
+}
+
+// NewFile creates a new instance of the cachego.File interface backed by an object in an S3 bucket.
+// The returned file implements cachego.ContextFile, so its requests carry the context of the cache operation.
+// Requests are signed with AWS Signature Version 4.
+// If the region is empty, "us-east-1" will be used.
+func NewFile(opts Opts) cachego.File {
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+
+	if opts.AccessKeyID == "" {
+		opts.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		opts.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		opts.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	return &file{opts: opts, now: time.Now}
+}
+
+// Load downloads the object and returns its contents as a byte slice.
+// If the operation is successful, it returns the read data and a nil error.
+// If the request fails, it returns a non-nil error, wrapping cachego.ErrNoSnapshot if the object does not exist.
+func (s *file) Load() ([]byte, error) {
+	return s.LoadContext(context.Background())
+}
+
+// LoadContext downloads the object just like Load, sending the request with the given context.
+func (s *file) LoadContext(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.do(req, nil)
+}
+
+// Dump uploads the given data as the object, replacing any previous contents.
+// If the operation is successful, it returns a nil error.
+// If the request fails, it returns a non-nil error.
+func (s *file) Dump(data []byte) error {
+	return s.DumpContext(context.Background(), data)
+}
+
+// DumpContext uploads the object just like Dump, sending the request with the given context.
+func (s *file) DumpContext(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.url(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	if s.opts.SSE != "" {
+		req.Header.Set("x-amz-server-side-encryption", s.opts.SSE)
+		if s.opts.SSEKMSKeyID != "" {
+			req.Header.Set("x-amz-server-side-encryption-aws-kms-key-id", s.opts.SSEKMSKeyID)
+		}
+	}
+
+	_, err = s.do(req, data)
+	return err
+}
+
+func (s *file) url() string {
+	key := uri.Encode(strings.TrimPrefix(s.opts.Key, "/"), false)
+	if s.opts.Endpoint == "" {
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.opts.Bucket, s.opts.Region, key)
+	}
+
+	return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(s.opts.Endpoint, "/"), s.opts.Bucket, key)
+}
+
+func (s *file) do(req *http.Request, payload []byte) ([]byte, error) {
+	sum := sha256.Sum256(payload)
+	req.Header.Set("x-amz-content-sha256", hex.EncodeToString(sum[:]))
+	req.Header.Set("x-amz-date", s.now().UTC().Format("20060102T150405Z"))
+	if s.opts.SessionToken != "" {
+		req.Header.Set("x-amz-security-token", s.opts.SessionToken)
+	}
+
+	signV4(req, s.opts.Region, "s3", s.opts.AccessKeyID, s.opts.SecretAccessKey)
+
+	res, err := s.opts.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotFound && req.Method == http.MethodGet {
+		return nil, fmt.Errorf("s3 %s %s: %s: %w", req.Method, req.URL.Path, res.Status, cachego.ErrNoSnapshot)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("s3 %s %s: %s", req.Method, req.URL.Path, res.Status)
+	}
+
+	return body, nil
+}
+
+// signV4 signs the request with AWS Signature Version 4, covering the host and every header
+// already set on the request. The x-amz-date and x-amz-content-sha256 headers must be set.
+func signV4(req *http.Request, region, service, accessKey, secretKey string) {
+	amzDate := req.Header.Get("x-amz-date")
+	date := amzDate[:8]
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uri.Encode(req.URL.Path, false),
+		canonicalQuery(req),
+		canonicalHeaders.String(),
+		signedHeaders,
+		req.Header.Get("x-amz-content-sha256"),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature,
+	))
+}
+
+func canonicalQuery(req *http.Request) string {
+	q := req.URL.Query()
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		vals := q[k]
+		sort.Strings(vals)
+		for _, v := range vals {
+			parts = append(parts, uri.Encode(k, true)+"="+uri.Encode(v, true))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
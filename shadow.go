@@ -0,0 +1,129 @@
+package cachego
+
+// ShadowStats compares the lookups of a shadowed cache with the ones of its shadow.
+type ShadowStats struct {
+	// Primary counts the operations served by the primary cache.
+	Primary Stats
+	// Shadow counts the same operations mirrored into the shadow cache.
+	Shadow Stats
+}
+
+// Gain returns the difference between the hit ratios of the shadow and the primary cache:
+// positive if the shadow would have served more lookups.
+func (s ShadowStats) Gain() float64 {
+	return s.Shadow.HitRatio() - s.Primary.HitRatio()
+}
+
+// ShadowCache serves a primary cache while mirroring its access stream into a shadow cache,
+// e.g. one of another policy or size, to compare their hit ratios on real traffic. See Shadow.
+type ShadowCache[K comparable, V any] struct {
+	primary Cache[K, V]
+	shadow  Cache[K, V]
+
+	pstats *counters
+	sstats *counters
+}
+
+// Shadow wraps the primary cache so every operation is also applied to the shadow cache, which is never served from.
+// A lookup missing the shadow but hitting the primary cache stores the value in the shadow, as the caller
+// would have after loading it, so the shadow sees the keys it would have been asked to cache.
+// The errors of the shadow are ignored.
+//
+// The shadow holds its own copy of the entries, and is called in line with the primary cache,
+// so it costs as much as a second cache for as long as it runs.
+func Shadow[K comparable, V any](primary, shadow Cache[K, V]) *ShadowCache[K, V] {
+	return &ShadowCache[K, V]{primary: primary, shadow: shadow, pstats: newCounters(systemClock{}), sstats: newCounters(systemClock{})}
+}
+
+// Get retrieves the value of the key from the primary cache, and looks it up in the shadow.
+func (s *ShadowCache[K, V]) Get(key K) (V, error) {
+	v, err := s.primary.Get(key)
+	s.pstats.lookup(err == nil)
+	s.mirror(key, v, err == nil)
+
+	return v, err
+}
+
+// Lookup retrieves the value of the key from the primary cache just like Get, reporting whether it was found.
+func (s *ShadowCache[K, V]) Lookup(key K) (V, bool) {
+	v, ok := LookupValue(s.primary, key)
+	s.pstats.lookup(ok)
+	s.mirror(key, v, ok)
+
+	return v, ok
+}
+
+// mirror looks the key up in the shadow, storing the value of the primary cache on a miss.
+func (s *ShadowCache[K, V]) mirror(key K, v V, found bool) {
+	_, ok := LookupValue(s.shadow, key)
+	s.sstats.lookup(ok)
+	if !ok && found {
+		s.setShadow(key, v)
+	}
+}
+
+// Set stores the value under the key in both caches, returning the error of the primary cache.
+func (s *ShadowCache[K, V]) Set(key K, value V) error {
+	err := s.primary.Set(key, value)
+	if err == nil {
+		s.pstats.sets.Add(1)
+	} else {
+		s.pstats.rejections.Add(1)
+	}
+	s.setShadow(key, value)
+
+	return err
+}
+
+func (s *ShadowCache[K, V]) setShadow(key K, value V) {
+	if s.shadow.Set(key, value) == nil {
+		s.sstats.sets.Add(1)
+	} else {
+		s.sstats.rejections.Add(1)
+	}
+}
+
+// Delete removes the key from both caches, returning the error of the primary cache.
+func (s *ShadowCache[K, V]) Delete(key K) error {
+	err := s.primary.Delete(key)
+	if err == nil {
+		s.pstats.deletes.Add(1)
+	}
+	if s.shadow.Delete(key) == nil {
+		s.sstats.deletes.Add(1)
+	}
+
+	return err
+}
+
+// Clear removes all the entries of both caches, returning the error of the primary cache.
+func (s *ShadowCache[K, V]) Clear() error {
+	err := s.primary.Clear()
+	s.shadow.Clear() // nolint:errcheck
+
+	return err
+}
+
+// Primary returns the primary cache.
+func (s *ShadowCache[K, V]) Primary() Cache[K, V] {
+	return s.primary
+}
+
+// Compare returns the counters of the operations served through the wrapper by the primary cache and its shadow,
+// with the sizes of the caches implementing Len.
+// This method is thread-safe.
+func (s *ShadowCache[K, V]) Compare() ShadowStats {
+	return ShadowStats{
+		Primary: s.pstats.snapshot(cacheLen(s.primary)),
+		Shadow:  s.sstats.snapshot(cacheLen(s.shadow)),
+	}
+}
+
+// cacheLen returns the number of entries of the cache, or 0 if it doesn't implement Len.
+func cacheLen[K comparable, V any](c Cache[K, V]) int32 {
+	if l, ok := c.(interface{ Len() int }); ok {
+		return int32(l.Len())
+	}
+
+	return 0
+}
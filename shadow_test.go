@@ -0,0 +1,86 @@
+package cachego
+
+import (
+	"errors"
+	"testing"
+)
+
+// nolint:errcheck
+func TestShadow(t *testing.T) {
+	primary := NewLRUCacheWithOpts[int, int](Opts{Size: 2})
+	shadow := NewLRUCacheWithOpts[int, int](Opts{Size: 3})
+	s := Shadow(primary, shadow)
+
+	// cycling over 3 keys thrashes an LRU cache of 2, but not one of 3
+	for i := 0; i < 30; i++ {
+		if _, err := s.Get(i % 3); errors.Is(err, ErrNotFound) {
+			s.Set(i%3, i)
+		}
+	}
+
+	stats := s.Compare()
+	if stats.Primary.Hits != 0 || stats.Primary.Misses != 30 {
+		t.Errorf("expected 30 misses of the primary cache, got %+v", stats.Primary)
+	}
+	if stats.Shadow.Hits != 27 || stats.Shadow.Misses != 3 {
+		t.Errorf("expected 27 hits of the shadow, got %+v", stats.Shadow)
+	}
+	if stats.Primary.Size != 2 || stats.Shadow.Size != 3 {
+		t.Errorf("expected the sizes 2 and 3, got %v and %v", stats.Primary.Size, stats.Shadow.Size)
+	}
+	if gain := stats.Gain(); gain != 0.9 {
+		t.Errorf("expected %v, got %v", 0.9, gain)
+	}
+}
+
+// nolint:errcheck
+func TestShadowFillsOnPrimaryHit(t *testing.T) {
+	primary := NewLRUCacheWithOpts[string, int](Opts{Size: 10})
+	primary.Set("a", 1)
+	shadow := NewCache[string, int](Opts{Size: 10})
+	s := Shadow(primary, shadow)
+
+	if v, ok := s.Lookup("a"); !ok || v != 1 {
+		t.Fatalf("expected 1, got %v", v)
+	}
+	if v, err := shadow.Get("a"); err != nil || v != 1 {
+		t.Errorf("expected the shadow to store the value of the primary hit, got %v (%v)", v, err)
+	}
+	s.Lookup("a")
+
+	stats := s.Compare()
+	if stats.Primary.Hits != 2 || stats.Shadow.Hits != 1 || stats.Shadow.Misses != 1 || stats.Shadow.Sets != 1 {
+		t.Errorf("expected 2 primary hits and a shadow miss then hit, got %+v and %+v", stats.Primary, stats.Shadow)
+	}
+
+	s.Delete("a")
+	if _, err := shadow.Get("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the shadow to delete the key, got %v", err)
+	}
+	s.Set("b", 2)
+	s.Clear()
+	if _, err := shadow.Get("b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the shadow to be cleared, got %v", err)
+	}
+	if s.Primary() != primary {
+		t.Errorf("expected the primary cache")
+	}
+}
+
+// nolint:errcheck
+func TestShadowErrors(t *testing.T) {
+	primary := NewCache[int, int](Opts{Size: 1})
+	shadow := NewCache[int, int](Opts{Size: 2})
+	s := Shadow(primary, shadow)
+
+	s.Set(1, 1)
+	if err := s.Set(2, 2); !errors.Is(err, ErrCacheFull) {
+		t.Errorf("expected the error of the primary cache, got %v", err)
+	}
+	s.Set(3, 3)
+
+	stats := s.Compare()
+	if stats.Primary.Rejections != 2 || stats.Shadow.Rejections != 1 {
+		t.Errorf("expected 2 and 1 rejections, got %v and %v", stats.Primary.Rejections, stats.Shadow.Rejections)
+	}
+}
@@ -0,0 +1,163 @@
+package cachego
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+const defaultShardCount = 16
+
+// ShardedOpts configures a sharded cache.
+type ShardedOpts[K comparable, V any] struct {
+	// Shards is the number of independently locked segments. Defaults to 16.
+	Shards int
+	// Hasher maps a key to the segment holding it. Defaults to a FNV-1a hash of the key,
+	// which is allocation free for strings and integers.
+	Hasher func(key K) uint64
+	// New creates the cache of every segment, so each segment gets its own lock, capacity and options.
+	// Defaults to a simple cache of the default size.
+	New func(shard int) Cache[K, V]
+}
+
+type sharded[K comparable, V any] struct {
+	shards []Cache[K, V]
+	hasher func(key K) uint64
+}
+
+// NewShardedCache creates a new thread-safe cache that partitions the keys across independently locked segments,
+// so concurrent operations on keys of different segments don't contend for a single lock.
+// The capacity of the cache is the sum of the capacities of its segments.
+func NewShardedCache[K comparable, V any](opts ShardedOpts[K, V]) Cache[K, V] {
+	n := opts.Shards
+	if n <= 0 {
+		n = defaultShardCount
+	}
+
+	if opts.Hasher == nil {
+		opts.Hasher = hashKey[K]
+	}
+
+	if opts.New == nil {
+		opts.New = func(int) Cache[K, V] { return NewCache[K, V](Opts{}) }
+	}
+
+	s := &sharded[K, V]{shards: make([]Cache[K, V], n), hasher: opts.Hasher}
+	for i := range s.shards {
+		s.shards[i] = opts.New(i)
+	}
+
+	return s
+}
+
+func (s *sharded[K, V]) shard(key K) Cache[K, V] {
+	return s.shards[s.hasher(key)%uint64(len(s.shards))]
+}
+
+// Set stores the provided value under the given key in the segment of the key.
+// This method is thread-safe.
+func (s *sharded[K, V]) Set(key K, value V) error {
+	return s.shard(key).Set(key, value)
+}
+
+// Get retrieves the value associated with the given key from the segment of the key.
+// This method is thread-safe.
+func (s *sharded[K, V]) Get(key K) (V, error) {
+	return s.shard(key).Get(key)
+}
+
+// Lookup retrieves the value from the segment of the key, reporting a miss with false (see LookupValue).
+// This method is thread-safe.
+func (s *sharded[K, V]) Lookup(key K) (V, bool) {
+	return LookupValue(s.shard(key), key)
+}
+
+// Delete removes the key-value pair associated with the given key from the segment of the key.
+// This method is thread-safe.
+func (s *sharded[K, V]) Delete(key K) error {
+	return s.shard(key).Delete(key)
+}
+
+// Clear clears every segment, returning the joined errors of the segments that failed to clear.
+// This method is thread-safe.
+func (s *sharded[K, V]) Clear() error {
+	errs := make([]error, len(s.shards))
+	for i, c := range s.shards {
+		if err := c.Clear(); err != nil {
+			errs[i] = fmt.Errorf("clearing shard %v failed: %w", i, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Len returns the number of entries of the segments that implement ExtendedCache.
+// This method is thread-safe.
+func (s *sharded[K, V]) Len() int {
+	n := 0
+	for _, c := range s.shards {
+		if e, ok := c.(ExtendedCache[K, V]); ok {
+			n += e.Len()
+		}
+	}
+
+	return n
+}
+
+// Has reports whether the key is in its segment, without counting as an access.
+// If the segment doesn't implement ExtendedCache, it falls back to LookupValue, which counts as an access.
+// This method is thread-safe.
+func (s *sharded[K, V]) Has(key K) bool {
+	c := s.shard(key)
+	if e, ok := c.(ExtendedCache[K, V]); ok {
+		return e.Has(key)
+	}
+
+	_, ok := LookupValue(c, key)
+	return ok
+}
+
+// Keys returns the keys of the segments that implement ExtendedCache, in no particular order.
+// This method is thread-safe.
+func (s *sharded[K, V]) Keys() []K {
+	var keys []K
+	for _, c := range s.shards {
+		if e, ok := c.(ExtendedCache[K, V]); ok {
+			keys = append(keys, e.Keys()...)
+		}
+	}
+
+	return keys
+}
+
+// Close closes every segment that implements io.Closer, returning the joined errors of the segments that failed to close.
+// This method is thread-safe.
+func (s *sharded[K, V]) Close() error {
+	errs := make([]error, len(s.shards))
+	for i, c := range s.shards {
+		if closer, ok := c.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs[i] = fmt.Errorf("closing shard %v failed: %w", i, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Stats returns the sum of the counters of the segments that keep counters.
+// The uptime is the one of the oldest segment.
+// This method is thread-safe.
+func (s *sharded[K, V]) Stats() Stats {
+	var total Stats
+	for _, c := range s.shards {
+		p, ok := c.(StatsProvider)
+		if !ok {
+			continue
+		}
+
+		total.add(p.Stats())
+	}
+
+	return total
+}
@@ -0,0 +1,59 @@
+package cachego
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// nolint:errcheck
+func TestShardedCache(t *testing.T) {
+	sizes := map[int]int{}
+	cache := NewShardedCache(ShardedOpts[string, int]{
+		Shards: 4,
+		New: func(shard int) Cache[string, int] {
+			return NewLRUCache[string, int](100)
+		},
+	})
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				key := fmt.Sprintf("%d-%d", g, i)
+				cache.Set(key, i)
+				if v, err := cache.Get(key); err != nil || v != i {
+					t.Errorf("expected %v, got %v (%v)", i, v, err)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	s := cache.(*sharded[string, int])
+	for i, c := range s.shards {
+		sizes[i] = int(c.(StatsProvider).Stats().Size)
+	}
+
+	// the keys are spread across every shard
+	for i := 0; i < 4; i++ {
+		if sizes[i] == 0 {
+			t.Errorf("expected shard %v to hold keys, got %v", i, sizes)
+		}
+	}
+
+	if stats := cache.(StatsProvider).Stats(); stats.Size != 400 || stats.Hits != 400 {
+		t.Errorf("expected 400 entries and hits, got %+v", stats)
+	}
+
+	if err := cache.Delete("0-0"); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	cache.Clear()
+	if _, err := cache.Get("1-1"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
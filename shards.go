@@ -0,0 +1,117 @@
+package cachego
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// loadShards loads and unmarshals every shard in parallel and merges them into a single map.
+// Shards that fail to load or unmarshal are skipped.
+// It also returns the digest of the loaded shards and the joined errors of every skipped shard.
+func loadShards[K comparable, V any](ctx context.Context, shards []File) (map[K]V, digest, error) {
+	parts := make([]map[K]V, len(shards))
+	sums := make([]digest, len(shards))
+	errs := make([]error, len(shards))
+	wg := sync.WaitGroup{}
+
+	wg.Add(len(shards))
+	for i, f := range shards {
+		go func(i int, f File) {
+			defer wg.Done()
+
+			bytes, err := loadFile(ctx, f)
+			if err != nil {
+				errs[i] = fmt.Errorf("loading cache data shard %v failed: %w", i, err)
+				return
+			}
+			sums[i] = sha256.Sum256(bytes)
+
+			part := make(map[K]V)
+			if err := unmarshalSnapshot(bytes, part); err != nil {
+				errs[i] = fmt.Errorf("error unmarshalling cache data shard %v: %w", i, err)
+				return
+			}
+
+			parts[i] = part
+		}(i, f)
+	}
+	wg.Wait()
+
+	var l int
+	for _, part := range parts {
+		l += len(part)
+	}
+
+	data := make(map[K]V, l)
+	for _, part := range parts {
+		for k, v := range part {
+			data[k] = v
+		}
+	}
+
+	return data, combineDigests(sums), errors.Join(errs...)
+}
+
+// noSnapshot reports whether the load failed only because nothing was dumped yet,
+// to the file or to every shard that failed to load.
+func noSnapshot(err error) bool {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return errors.Is(err, ErrNoSnapshot)
+	}
+
+	for _, err := range joined.Unwrap() {
+		if !errors.Is(err, ErrNoSnapshot) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dumpShards splits the data by key hash across the shards and dumps them in parallel.
+// It returns the digest of the dumped shards and the joined errors of every shard that failed to dump.
+func dumpShards[K comparable, V any](ctx context.Context, shards []File, data map[K]V) (digest, error) {
+	parts := make([]map[K]V, len(shards))
+	for i := range parts {
+		parts[i] = make(map[K]V, len(data)/len(shards))
+	}
+
+	for k, v := range data {
+		parts[shardOf(k, len(shards))][k] = v
+	}
+
+	errs := make([]error, len(shards))
+	sums := make([]digest, len(shards))
+	wg := sync.WaitGroup{}
+
+	wg.Add(len(shards))
+	for i, f := range shards {
+		go func(i int, f File) {
+			defer wg.Done()
+
+			bytes, err := marshalSnapshot(parts[i])
+			if err == nil {
+				sums[i] = sha256.Sum256(bytes)
+				err = dumpFile(ctx, f, bytes)
+			}
+
+			if err != nil {
+				errs[i] = fmt.Errorf("dumping cache data shard %v failed: %w", i, err)
+			}
+		}(i, f)
+	}
+	wg.Wait()
+
+	return combineDigests(sums), errors.Join(errs...)
+}
+
+func shardOf[K comparable](key K, n int) int {
+	h := fnv.New32a()
+	fmt.Fprint(h, key)
+	return int(h.Sum32() % uint32(n))
+}
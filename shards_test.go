@@ -0,0 +1,47 @@
+package cachego
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShards(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	shards := NewSimpleCacheShards(path, 4)
+	cache := NewCache[string, int](Opts{Size: 100, Shards: shards})
+
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprintf("key%v", i), i)
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		if _, err := os.Stat(fmt.Sprintf("%v.%v", path, i)); err != nil {
+			t.Errorf("expected shard %v to be written, got %v", i, err)
+		}
+	}
+
+	// setting up a new cache with the same shards
+	cache2 := NewCache[string, int](Opts{Size: 100, Shards: shards})
+	for i := 0; i < 100; i++ {
+		val, err := cache2.Get(fmt.Sprintf("key%v", i))
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		if val != i {
+			t.Errorf("expected %v, got %v", i, val)
+		}
+	}
+
+	// the merged shards are larger than the cache (shards should be discarded)
+	cache3 := NewCache[string, int](Opts{Size: 10, Shards: shards})
+	if _, err := cache3.Get("key0"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
@@ -2,95 +2,428 @@ package cachego
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/sha256"
 	"fmt"
-	"log"
+	"io"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type simple[K comparable, V any] struct {
-	size int32
-	used int32
-	ttl  int16 // in seconds
-	data map[K]V
-	mx   *sync.Mutex
-	file File
+	size   int32
+	used   int32
+	ttl    int16 // in seconds
+	data   map[K]V
+	meta   map[K]*entryMeta
+	mx     *sync.RWMutex
+	file   File
+	shards []File
+	digest digest        // of the last loaded or dumped snapshot
+	dumps  atomic.Uint64 // snapshots dumped, so a reload racing a dump doesn't apply an older snapshot
+	bg     background
+	logger Logger
+
+	onEvict  func(key K, value V, reason Reason)
+	onExpire func(key K, value V)
+	loader   Loader[K, V]
+	emitter[K, V]
+	reclaimer[K, V]
+	*watchers[K, V]
+	*hotKeys[K]
+	bytes  *weigher[K, V]
+	stats  *counters
+	clock  Clock
+	expiry *expirer[K]
 }
 
+// Opts configures a cache. The options that depend on the key and value types of the cache
+// are set with TypedOpts.
 type Opts struct {
 	Size int32
 	TTL  int16
 	File File
+	// Shards splits the persisted data across several files by key hash.
+	// The shards are loaded and dumped in parallel. If set, File is ignored.
+	Shards []File
+	// Context is passed to the File (or Shards) loading the snapshot on creation (see ContextFile),
+	// e.g. so the load is traced as part of the operation creating the cache. Defaults to context.Background().
+	Context context.Context
+	// Reload polls the File (or Shards) at the given interval and applies the snapshot
+	// to the cache whenever it was changed by another process.
+	// If less than or equal to zero, the snapshot is only loaded on creation. The polling stops on Close.
+	Reload time.Duration
+	// ReloadMerge merges a changed snapshot into the cache instead of replacing its contents.
+	ReloadMerge bool
+	// Events is the buffer size of the channel returned by Events.
+	// If less than or equal to zero, no events are emitted.
+	Events int
+	// Logger receives the problems the cache cannot report through its return values,
+	// such as persisted data that cannot be restored. Defaults to discarding them.
+	Logger Logger
+	// Reclaim is the buffer size of the channel returned by Reclaimed.
+	// If less than or equal to zero, removed values are not delivered.
+	Reclaim int
+	// Expvar publishes the cache stats under expvar with the given name prefix (see PublishExpvar).
+	// If empty, the stats are not published.
+	Expvar string
+	// HotKeys is the number of most frequently read keys tracked by the cache and returned by HotKeys.
+	// The counts are approximate, and also serve EstimateFrequency (see FrequencyEstimator).
+	// If less than or equal to zero, no keys are tracked.
+	HotKeys int
+	// Tune adapts the capacity (and the ttl) of the cache to hold a target hit ratio.
+	// See TuneOpts. The tuning stops on Close.
+	Tune TuneOpts
+	// AccessBuffer makes the LRU cache record the accesses of Get in batches of the given size,
+	// applying them to the recency order once a batch is full. Gets then only take a read lock,
+	// at the cost of a less exact recency order. If less than or equal to zero, every Get promotes its entry right away.
+	// It is only supported by the LRU cache.
+	AccessBuffer int
+	// EvictionBatch is the number of least recently used entries the LRU cache evicts at once when it is full,
+	// so a sustained stream of new keys only pays for eviction once every batch rather than on every Set.
+	// It is capped at the cache size. Defaults to 1. It is only supported by the LRU cache.
+	EvictionBatch int32
+	// LowWatermark makes the LRU cache, once its size (the high watermark) is exceeded,
+	// evict the least recently used entries down to the given fraction of its size, between 0 and 1.
+	// The entry being set is always kept, even when the fraction rounds down to no entries.
+	// Eviction then happens in bursts rather than on every Set at the limit.
+	// If less than or equal to zero, or greater than or equal to one, only the entries over the size
+	// (or EvictionBatch) are evicted. It is only supported by the LRU cache.
+	LowWatermark float64
+	// MaxBytes bounds the cache by the approximate memory held by its entries, in addition to Size.
+	// When it is reached, the simple cache rejects the entries that don't fit,
+	// while the LRU cache evicts the least recently used entries.
+	// If less than or equal to zero, only the number of entries is bounded.
+	MaxBytes int64
+	// MaxEntryBytes rejects the entries larger than the given size with an error wrapping ErrEntryTooLarge,
+	// so a single huge value can't take over the memory of the cache.
+	// If less than or equal to zero, the size of the entries is not checked.
+	MaxEntryBytes int
+	// Memory evicts entries while the heap of the process is too large. See MemoryOpts.
+	// The monitoring stops on Close.
+	Memory MemoryOpts
+	// Doorkeeper only stores the new keys of the LRU cache once they are set twice. See DoorkeeperOpts.
+	// It is only supported by the LRU cache.
+	Doorkeeper DoorkeeperOpts
+	// Admission throttles the new keys of the LRU cache while it evicts too many entries. See AdmissionOpts.
+	// It is only supported by the LRU cache.
+	Admission AdmissionOpts
+	// Clock tells the time the entries are set, read and expire at. Defaults to the system clock.
+	// It also times the events, the stats uptime, and the Reload and Tune intervals.
+	// A fake clock makes the ttl testable without waiting for it.
+	Clock Clock
+}
+
+// TypedOpts holds the options of a cache that depend on its key and value types.
+// It is passed to the constructors after Opts, which keeps Opts usable by the callers
+// that don't need any of these options.
+type TypedOpts[K comparable, V any] struct {
+	// OnEvict is called with every entry removed from the cache and the reason it was removed.
+	// It is called after the cache lock is released, so it may safely use the cache.
+	OnEvict func(key K, value V, reason Reason)
+	// OnExpire is called with every entry whose ttl lapsed, in addition to OnEvict.
+	// It is called after the cache lock is released, so it may safely use the cache.
+	OnExpire func(key K, value V)
+	// Victim is a secondary cache receiving the entries evicted for capacity (e.g. by the LRU cache)
+	// instead of dropping them. Keys missing from the cache are looked up in the victim cache,
+	// and moved back into the cache when found. Demoted entries are not reported to OnEvict,
+	// Opts.Reclaim or Opts.Events, unless the victim cache rejects them.
+	Victim Cache[K, V]
+	// Sizer returns the size of an entry in bytes, used with Opts.MaxBytes and Opts.MaxEntryBytes.
+	// Defaults to a reflection based estimate of the memory held by the key and the value.
+	Sizer func(key K, value V) int
+	// Loader makes the cache read-through: a key missing from the cache is loaded with it by Get, GetCtx
+	// and Lookup, and the loaded value is stored in the cache. Get returns the error of the loader, if any.
+	// Concurrent misses of the same key each call the loader; the singleflight package can share the calls.
+	Loader Loader[K, V]
+}
+
+// typedOpts merges the given options, the fields set in later ones taking precedence.
+func typedOpts[K comparable, V any](typed []TypedOpts[K, V]) TypedOpts[K, V] {
+	var t TypedOpts[K, V]
+	for _, o := range typed {
+		if o.OnEvict != nil {
+			t.OnEvict = o.OnEvict
+		}
+		if o.OnExpire != nil {
+			t.OnExpire = o.OnExpire
+		}
+		if o.Victim != nil {
+			t.Victim = o.Victim
+		}
+		if o.Sizer != nil {
+			t.Sizer = o.Sizer
+		}
+		if o.Loader != nil {
+			t.Loader = o.Loader
+		}
+	}
+
+	return t
 }
 
 // NewCache creates a new thread-safe instance of a cache with the specified size and ttl.
 // If the size is less than or equal to zero, a default size of 100 will be used.
 // If the ttl is less than or equal to zero, the cache will not expire.
-func NewCache[K comparable, V any](opts Opts) Cache[K, V] {
+// If the persisted data cannot be restored, the error is logged to the Logger and the cache starts empty.
+// The options depending on the key and value types, such as OnEvict, are set with TypedOpts.
+func NewCache[K comparable, V any](opts Opts, typed ...TypedOpts[K, V]) Cache[K, V] {
+	c := newSimple(opts, typedOpts(typed))
+
+	if c.file != nil || len(c.shards) > 0 {
+		if _, err := c.load(contextOrBackground(opts.Context)); err != nil {
+			c.logger.Printf("%v", err)
+		}
+	}
+
+	c.start(opts)
+	return c
+}
+
+// NewCacheE creates a new thread-safe instance of a cache just like NewCache,
+// but returns an error instead of logging it if the persisted data cannot be loaded,
+// unmarshalled, or is larger than the cache size.
+// A file (or every failing shard) holding no snapshot yet is not an error: the cache simply starts empty.
+// If only some of the shards fail to load, the cache restored from the rest is returned along with the error.
+func NewCacheE[K comparable, V any](opts Opts, typed ...TypedOpts[K, V]) (Cache[K, V], error) {
+	c := newSimple(opts, typedOpts(typed))
+
+	var err error
+	if c.file != nil || len(c.shards) > 0 {
+		var restored bool
+		if restored, err = c.load(contextOrBackground(opts.Context)); noSnapshot(err) {
+			err = nil
+		} else if err != nil && !restored {
+			return nil, err
+		}
+	}
+
+	c.start(opts)
+	return c, err
+}
+
+func newSimple[K comparable, V any](opts Opts, typed TypedOpts[K, V]) *simple[K, V] {
 	s := int32(defaultSize)
 	if opts.Size > 0 {
 		s = opts.Size
 	}
 
-	var used int32
-	data := make(map[K]V, s)
+	clock := clockOrSystem(opts.Clock)
+	c := &simple[K, V]{
+		size:   s,
+		data:   make(map[K]V, s),
+		meta:   make(map[K]*entryMeta, s),
+		mx:     &sync.RWMutex{},
+		ttl:    opts.TTL,
+		file:   opts.File,
+		shards: opts.Shards,
+		bg:     newBackground(),
+		logger: loggerOrNop(opts.Logger),
+		clock:  clock,
+
+		onEvict:  typed.OnEvict,
+		onExpire: typed.OnExpire,
+		loader:   typed.Loader,
+		emitter:  newEmitter[K, V](opts.Events, clock),
+		watchers: newWatchers[K, V](),
+		hotKeys:  newHotKeys[K](opts.HotKeys),
+		bytes:    newWeigher(opts.MaxBytes, opts.MaxEntryBytes, typed.Sizer),
+		stats:    newCounters(clock),
+	}
+	c.expiry = newExpirer(c.bg, c.clock, c.destroy)
+	c.reclaimer = newReclaimer[K, V](opts.Reclaim, c.bg, c.clock)
+
+	return c
+}
 
-	if opts.File != nil {
+// start runs the background work requested by the options.
+func (c *simple[K, V]) start(opts Opts) {
+	if opts.Expvar != "" {
+		if err := PublishExpvar(opts.Expvar, c); err != nil {
+			c.logger.Printf("%v", err)
+		}
+	}
 
-		if bytes, err := opts.File.Load(); err == nil {
+	if opts.Tune.enabled() {
+		c.bg.run(func(ctx context.Context) { tune(ctx, c.clock, c, opts.Tune) })
+	}
 
-			if err = json.Unmarshal(bytes, &data); err != nil {
-				log.Printf("error unmarshalling cache data: %v", err)
-			} else {
+	if opts.Memory.HeapLimit > 0 || opts.Memory.Threshold > 0 {
+		c.bg.run(func(ctx context.Context) { relieve(ctx, c, opts.Memory) })
+	}
 
-				l := int32(len(data))
-				if l > s {
-					log.Printf("cache data size %v is larger than cache size %v", l, s)
-					data = make(map[K]V, s)
-				} else {
-					used = l
-				}
+	if opts.Reload > 0 && (c.file != nil || len(c.shards) > 0) {
+		c.bg.run(func(ctx context.Context) { c.watch(ctx, opts.Reload, opts.ReloadMerge) })
+	}
+}
+
+// Len returns the number of entries in the cache.
+// This method is thread-safe.
+func (c *simple[K, V]) Len() int {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	return len(c.data)
+}
 
-			}
+// Has reports whether the key is in the cache, without counting as an access.
+// This method is thread-safe.
+func (c *simple[K, V]) Has(key K) bool {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	_, ok := c.data[key]
+	return ok
+}
+
+// Keys returns the keys of the cache, in no particular order.
+// This method is thread-safe.
+func (c *simple[K, V]) Keys() []K {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	keys := make([]K, 0, len(c.data))
+	for k := range c.data {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// Close stops the background work of the cache, such as expiring its entries or reloading its snapshot,
+// and waits for it to return, so no goroutine outlives the cache. The cache remains usable without that work,
+// although its entries no longer expire. It always returns nil.
+// This method is thread-safe, and may be called more than once.
+func (c *simple[K, V]) Close() error {
+	c.bg.stop()
+	return nil
+}
 
-		} else {
-			log.Printf("loading cache data failed: %v", err)
+// load restores the persisted data from the file (or shards) into the cache, and reports whether it did.
+// If the data cannot be restored, the cache is left empty and an error is returned.
+// Shards that fail to load are reported, while the rest of the shards are still restored.
+func (c *simple[K, V]) load(ctx context.Context) (bool, error) {
+	var data map[K]V
+	var sum digest
+	var loadErr error
+
+	if len(c.shards) > 0 {
+		data, sum, loadErr = loadShards[K, V](ctx, c.shards)
+	} else {
+		bytes, err := loadFile(ctx, c.file)
+		if err != nil {
+			return false, fmt.Errorf("loading cache data failed: %w", err)
+		}
+
+		sum = combineDigests([]digest{sha256.Sum256(bytes)})
+		data = make(map[K]V, c.size)
+		if err := unmarshalSnapshot(bytes, data); err != nil {
+			return false, fmt.Errorf("error unmarshalling cache data: %w", err)
 		}
+	}
 
+	if l := int32(len(data)); l > c.size {
+		return false, fmt.Errorf("cache data size %v is larger than cache size %v", l, c.size)
 	}
 
-	return &simple[K, V]{
-		size: s,
-		used: used,
-		data: data,
-		mx:   &sync.Mutex{},
-		ttl:  opts.TTL,
-		file: opts.File,
+	meta, bytes := c.weigh(data)
+	if !c.bytes.holds(bytes) {
+		return false, fmt.Errorf("cache data size %v bytes is larger than cache max bytes %v", bytes, c.bytes.max)
 	}
+
+	c.data = data
+	c.meta = meta
+	c.used = int32(len(data))
+	c.bytes.reset()
+	c.bytes.add(bytes)
+	c.digest = sum
+	return true, loadErr
+}
+
+// weigh returns new metadata for the restored entries, and their total size in bytes.
+// Entries that are too large are logged and dropped from the data.
+func (c *simple[K, V]) weigh(data map[K]V) (map[K]*entryMeta, int) {
+	meta := make(map[K]*entryMeta, len(data))
+	total := 0
+	for k, v := range data {
+		m := newEntryMeta(c.clock.Now(), 0)
+		m.weight = c.bytes.size(k, v)
+		if err := c.bytes.check(k, m.weight); err != nil {
+			c.logger.Printf("skipping cache data entry: %v", err)
+			delete(data, k)
+			continue
+		}
+		total += m.weight
+		meta[k] = m
+	}
+
+	return meta, total
 }
 
 // Set stores the provided value under the given key in the cache.
-// If the cache is full (reached its capacity), it returns an error "cache is full".
+// If the cache is full (reached its capacity) or the value doesn't fit within MaxBytes, it returns an error wrapping ErrCacheFull.
 // If the key already exists in the cache, the associated value will be updated.
+// An entry larger than MaxEntryBytes (or MaxBytes) is rejected with an error wrapping ErrEntryTooLarge.
 // This method is thread-safe.
 func (c *simple[K, V]) Set(key K, value V) error {
+	return c.store(key, value, cacheTTL)
+}
+
+// SetWithTTL stores the value just like Set, but removes it once the given ttl lapses rather than the ttl
+// of the cache. A ttl <= 0 means the entry doesn't expire.
+// This method is thread-safe.
+func (c *simple[K, V]) SetWithTTL(key K, value V, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	return c.store(key, value, ttl)
+}
+
+func (c *simple[K, V]) store(key K, value V, ttl time.Duration) error {
+	size := c.bytes.size(key, value)
+	if err := c.bytes.check(key, size); err != nil {
+		return err
+	}
+
 	c.mx.Lock()
 	defer c.mx.Unlock()
 
+	ttl = resolveTTL(ttl, c.ttl)
+
 	if c.used >= c.size {
-		return fmt.Errorf("cache is full")
+		c.stats.rejections.Add(1)
+		return cacheFull(key)
 	}
 
-	if _, ok := c.data[key]; !ok {
+	if m, ok := c.meta[key]; ok {
+		if !c.bytes.fits(size - m.weight) {
+			c.stats.rejections.Add(1)
+			return cacheFull(key)
+		}
+		c.bytes.add(size - m.weight)
+		m.weight = size
+		m.update(c.clock.Now(), ttl)
+	} else {
+		if !c.bytes.fits(size) {
+			c.stats.rejections.Add(1)
+			return cacheFull(key)
+		}
 		c.used++
+		c.bytes.add(size)
+		m := newEntryMeta(c.clock.Now(), ttl)
+		m.weight = size
+		c.meta[key] = m
 	}
 
 	c.data[key] = value
+	c.stats.sets.Add(1)
+	c.emit(EventSet, key, value, 0)
+	c.notify(key, value)
 
-	if c.ttl > 0 {
-		ctx, _ := c.setDeadline(key)
-		go c.destroy(ctx, key)
+	if expires := c.meta[key].expires; !expires.IsZero() {
+		c.expiry.schedule(key, expires)
 	}
 
 	return nil
@@ -98,18 +431,64 @@ func (c *simple[K, V]) Set(key K, value V) error {
 
 // Get retrieves the value associated with the given key from the cache.
 // If the key is found in the cache, the corresponding value and nil error will be returned.
-// If the key is not found, the zero value of the value type and an error will be returned.
-// This method is thread-safe.
+// If the key is not found, it is loaded with the Loader if there is one (see TypedOpts.Loader).
+// Otherwise, the zero value of the value type and an error will be returned.
+// This method is thread-safe. Concurrent Gets only share a read lock, so they don't serialize.
 func (c *simple[K, V]) Get(key K) (V, error) {
-	c.mx.Lock()
-	defer c.mx.Unlock()
+	return c.get(context.Background(), key)
+}
+
+// Lookup retrieves the value associated with the given key just like Get,
+// but reports a miss with false instead of building an error, so misses don't allocate.
+// This method is thread-safe.
+func (c *simple[K, V]) Lookup(key K) (V, bool) {
+	if v, ok := c.find(key); ok || c.loader == nil {
+		return v, ok
+	}
+
+	v, err := readThrough[K, V](context.Background(), c, c.loader, key)
+	return v, err == nil
+}
 
-	if v, ok := c.data[key]; ok {
+// get retrieves the value from the cache or, failing that, from the loader.
+func (c *simple[K, V]) get(ctx context.Context, key K) (V, error) {
+	if v, ok := c.find(key); ok {
 		return v, nil
 	}
 
-	var empty V
-	return empty, fmt.Errorf("key %v not found", key)
+	return readThrough[K, V](ctx, c, c.loader, key)
+}
+
+// find retrieves the value from the cache, counting the access.
+func (c *simple[K, V]) find(key K) (V, bool) {
+	c.record(key)
+
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	v, ok := c.data[key]
+	c.stats.lookup(ok)
+	if ok {
+		c.meta[key].touch(c.clock.Now())
+	}
+
+	return v, ok
+}
+
+// GetWithInfo retrieves the value associated with the given key along with its metadata.
+// It doesn't count as an access: the entry metadata and the cache stats are left untouched.
+// If the key is not found, the zero values and an error will be returned.
+// This method is thread-safe.
+func (c *simple[K, V]) GetWithInfo(key K) (V, EntryInfo, error) {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	v, ok := c.data[key]
+	if !ok {
+		return v, EntryInfo{}, notFound(key)
+	}
+
+	return v, c.meta[key].info(v), nil
 }
 
 // Delete removes the key-value pair associated with the given key from the cache.
@@ -117,15 +496,12 @@ func (c *simple[K, V]) Get(key K) (V, error) {
 // If the key is not found, an error will be returned.
 // This method is thread-safe.
 func (c *simple[K, V]) Delete(key K) error {
-	c.mx.Lock()
-	defer c.mx.Unlock()
-
-	if _, ok := c.data[key]; !ok {
-		return fmt.Errorf("key %v not found", key)
+	v, ok := c.remove(key)
+	if !ok {
+		return notFound(key)
 	}
 
-	delete(c.data, key)
-	c.used--
+	c.evicted(key, v, ReasonDeleted)
 	return nil
 }
 
@@ -133,26 +509,280 @@ func (c *simple[K, V]) Delete(key K) error {
 // After this operation, the cache will be empty, and a nil error will be returned.
 // This method is thread-safe.
 func (c *simple[K, V]) Clear() error {
+	return c.ClearCtx(context.Background())
+}
+
+// GetCtx retrieves the value just like Get, passing the context to the loader.
+// If the context is done, it returns its error instead.
+// This method is thread-safe.
+func (c *simple[K, V]) GetCtx(ctx context.Context, key K) (V, error) {
+	if err := ctx.Err(); err != nil {
+		var empty V
+		return empty, err
+	}
+
+	return c.get(ctx, key)
+}
+
+// SetCtx stores the value just like Set, unless the context is done, in which case it returns its error.
+// This method is thread-safe.
+func (c *simple[K, V]) SetCtx(ctx context.Context, key K, value V) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return c.Set(key, value)
+}
+
+// DeleteCtx removes the key just like Delete, unless the context is done, in which case it returns its error.
+// This method is thread-safe.
+func (c *simple[K, V]) DeleteCtx(ctx context.Context, key K) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return c.Delete(key)
+}
+
+// ClearCtx clears the cache just like Clear, passing the context to the file (or shards) the cache is persisted to.
+// If the context is done, it returns its error without clearing the cache.
+// This method is thread-safe.
+func (c *simple[K, V]) ClearCtx(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := c.clear(ctx)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range data {
+		c.evicted(k, v, ReasonCleared)
+	}
+
+	return nil
+}
+
+// clear persists and empties the cache, returning the removed entries.
+func (c *simple[K, V]) clear(ctx context.Context) (map[K]V, error) {
 	c.mx.Lock()
 	defer c.mx.Unlock()
 
-	if c.file != nil {
-		bytes, _ := json.Marshal(c.data)
-		if err := c.file.Dump(bytes); err != nil {
-			return err
+	if c.file != nil || len(c.shards) > 0 {
+		c.dumps.Add(1)
+	}
+
+	if len(c.shards) > 0 {
+		sum, err := dumpShards(ctx, c.shards, c.data)
+		if err != nil {
+			return nil, err
+		}
+		c.digest = sum
+	} else if c.file != nil {
+		bytes, _ := marshalSnapshot(c.data)
+		if err := dumpFile(ctx, c.file, bytes); err != nil {
+			return nil, err
 		}
+		c.digest = combineDigests([]digest{sha256.Sum256(bytes)})
 	}
 
+	data := c.data
 	c.data = make(map[K]V, c.size)
+	c.meta = make(map[K]*entryMeta, c.size)
 	c.used = 0
+	c.bytes.reset()
+	return data, nil
+}
+
+// remove deletes the key from the cache, returning its value and whether it was found.
+func (c *simple[K, V]) remove(key K) (V, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	v, ok := c.data[key]
+	if ok {
+		c.bytes.add(-c.meta[key].weight)
+		delete(c.data, key)
+		delete(c.meta, key)
+		c.used--
+	}
+
+	return v, ok
+}
+
+func (c *simple[K, V]) evicted(key K, value V, reason Reason) {
+	c.stats.removed(reason)
+	c.emitRemoval(key, value, reason)
+	c.reclaim(key, value, reason)
+
+	if c.onEvict != nil {
+		c.onEvict(key, value, reason)
+	}
+}
+
+// Stats returns a snapshot of the cache counters.
+// This method is thread-safe.
+func (c *simple[K, V]) Stats() Stats {
+	c.mx.RLock()
+	used := c.used
+	bytes := c.bytes.bytes()
+	c.mx.RUnlock()
+
+	s := c.stats.snapshot(used)
+	s.Bytes = bytes
+	return s
+}
+
+// Distribution returns the current distribution of the entries by age and serialized size.
+// The values are encoded after the cache lock is released.
+// This method is thread-safe.
+func (c *simple[K, V]) Distribution() Distribution {
+	c.mx.RLock()
+	now := c.clock.Now()
+	ages := make([]time.Duration, 0, len(c.data))
+	values := make([]V, 0, len(c.data))
+	for k, v := range c.data {
+		ages = append(ages, now.Sub(c.meta[k].created))
+		values = append(values, v)
+	}
+	c.mx.RUnlock()
+
+	d := newDistribution()
+	for i, v := range values {
+		d.add(ages[i], v)
+	}
+
+	return d
+}
+
+// DebugDump writes the counters and the entries of the cache, with their metadata, ordered by pending expiration.
+// This method is thread-safe.
+func (c *simple[K, V]) DebugDump(w io.Writer, opts DebugOpts) error {
+	c.mx.RLock()
+	d := debugState{kind: "simple", order: "by expiry", used: c.used, size: c.size, ttl: c.ttl, now: c.clock.Now()}
+	d.entries = make([]debugEntry, 0, len(c.data))
+	for k, v := range c.data {
+		d.entries = append(d.entries, debugEntry{key: k, value: v, info: c.meta[k].snapshot()})
+	}
+	c.mx.RUnlock()
+
+	d.stats = c.Stats()
+	sortByExpiry(d.entries)
+	return d.write(w, opts)
+}
+
+// DebugString returns the debug dump of the cache with the default options.
+// This method is thread-safe.
+func (c *simple[K, V]) DebugString() string {
+	b := &strings.Builder{}
+	c.DebugDump(b, DebugOpts{}) // nolint:errcheck
+	return b.String()
+}
+
+func (c *simple[K, V]) capacity() int32 {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	return c.size
+}
+
+// Resize changes the capacity of the cache, removing arbitrary entries if the cache holds more than the new size.
+// The removed entries are reported with ReasonCapacity.
+// It returns an error if the size is less than or equal to zero.
+// If the cache is tuned (see Opts.Tune), the tuner keeps the MinSize and MaxSize bounds it started with,
+// so it moves a capacity outside of them back within them on its next adjustment.
+// This method is thread-safe.
+func (c *simple[K, V]) Resize(size int32) error {
+	if size <= 0 {
+		return fmt.Errorf("invalid cache size %v", size)
+	}
+
+	c.resize(size)
 	return nil
 }
 
-func (c *simple[K, V]) setDeadline(key K) (context.Context, context.CancelFunc) {
-	return context.WithDeadline(context.Background(), time.Now().Add(time.Duration(c.ttl)*time.Second))
+// resize changes the capacity, removing arbitrary entries if the cache holds more than the new size.
+func (c *simple[K, V]) resize(size int32) {
+	c.mx.Lock()
+	c.size = size
+	removed := c.evict(c.used - c.size)
+	c.mx.Unlock()
+
+	for k, v := range removed {
+		c.evicted(k, v, ReasonCapacity)
+	}
+}
+
+// shed removes n arbitrary entries to relieve memory pressure.
+func (c *simple[K, V]) shed(n int32) {
+	c.mx.Lock()
+	removed := c.evict(n)
+	c.mx.Unlock()
+
+	for k, v := range removed {
+		c.evicted(k, v, ReasonMemory)
+	}
 }
 
-func (c *simple[K, V]) destroy(ctx context.Context, key K) {
-	<-ctx.Done()
-	c.Delete(key)
+// evict removes up to n arbitrary entries and returns them.
+func (c *simple[K, V]) evict(n int32) map[K]V {
+	removed := make(map[K]V)
+	for k, v := range c.data {
+		if n <= 0 {
+			break
+		}
+		c.bytes.add(-c.meta[k].weight)
+		delete(c.data, k)
+		delete(c.meta, k)
+		c.used--
+		removed[k] = v
+		n--
+	}
+
+	return removed
+}
+
+func (c *simple[K, V]) ttlSeconds() int16 {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	return c.ttl
+}
+
+// setTTL changes the ttl of the entries set from now on.
+func (c *simple[K, V]) setTTL(ttl int16) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.ttl = ttl
+}
+
+// destroy removes the key once its deadline passed, unless it was set again (or restored) since,
+// in which case the entry expires at another time and the deadline is stale.
+func (c *simple[K, V]) destroy(key K, expires time.Time) {
+	if v, ok := c.expire(key, expires); ok {
+		c.evicted(key, v, ReasonExpired)
+		if c.onExpire != nil {
+			c.onExpire(key, v)
+		}
+	}
+}
+
+// expire deletes the key if it still expires at the given time, returning its value and whether it was deleted.
+func (c *simple[K, V]) expire(key K, expires time.Time) (V, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if m, ok := c.meta[key]; !ok || !m.expires.Equal(expires) {
+		var empty V
+		return empty, false
+	}
+
+	v := c.data[key]
+	c.bytes.add(-c.meta[key].weight)
+	delete(c.data, key)
+	delete(c.meta, key)
+	c.used--
+	return v, true
 }
@@ -1,6 +1,11 @@
 package cachego
 
-import "os"
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
 
 // simpleCacheFile is an implementation of the File interface.
 // It represents a simple cache file that can be used for loading and dumping data.
@@ -18,8 +23,14 @@ func NewSimpleCacheFile(path string) File {
 // Load reads the contents of the cache file and returns the data read from the file as a byte slice.
 // If the operation is successful, it returns the read data and a nil error.
 // If an error occurs during the load operation, it returns a non-nil error.
+// If the file doesn't exist, the error wraps both ErrNoSnapshot and fs.ErrNotExist.
 func (s *simpleCacheFile) Load() ([]byte, error) {
-	return os.ReadFile(s.path)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %w", ErrNoSnapshot, err)
+	}
+
+	return data, err
 }
 
 // Dump writes the given data as a byte slice to the cache file.
@@ -28,3 +39,14 @@ func (s *simpleCacheFile) Load() ([]byte, error) {
 func (s *simpleCacheFile) Dump(data []byte) error {
 	return os.WriteFile(s.path, data, 0644)
 }
+
+// NewSimpleCacheShards creates n instances of the File interface to be used as Opts.Shards.
+// The shard files are associated with the specified path, suffixed with the shard index (e.g. "cache.json.0").
+func NewSimpleCacheShards(path string, n int) []File {
+	shards := make([]File, n)
+	for i := range shards {
+		shards[i] = NewSimpleCacheFile(fmt.Sprintf("%s.%d", path, i))
+	}
+
+	return shards
+}
@@ -1,7 +1,10 @@
 package cachego
 
 import (
+	"errors"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -53,3 +56,63 @@ func TestSimpleCacheFile(t *testing.T) {
 
 	os.Remove(filename)
 }
+
+func TestNewCacheE(t *testing.T) {
+	file := NewMemoryCacheFile()
+
+	// loading an empty file starts an empty cache
+	if cache, err := NewCacheE[int, string](Opts{Size: 1, File: file}); err != nil || cache == nil {
+		t.Errorf("expected a cache, got %v (%v)", cache, err)
+	}
+
+	// loading a missing file
+	missing := NewSimpleCacheFile(filepath.Join(t.TempDir(), "missing.json"))
+	if _, err := missing.Load(); !errors.Is(err, ErrNoSnapshot) || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected %v, got %v", ErrNoSnapshot, err)
+	}
+
+	// loading an invalid file
+	file.Dump([]byte("invalid"))
+	if _, err := NewCacheE[int, string](Opts{Size: 1, File: file}); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	// loading a file that is too large
+	file.Dump([]byte(`{"1":"one","2":"two"}`))
+	if _, err := NewCacheE[int, string](Opts{Size: 1, File: file}); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	cache, err := NewCacheE[int, string](Opts{Size: 2, File: file})
+	if err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	if v, err := cache.Get(2); err != nil || v != "two" {
+		t.Errorf("expected two, got %v (%v)", v, err)
+	}
+
+	// creating a cache without a file
+	if _, err := NewCacheE[int, string](Opts{}); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	// loading shards that were never dumped starts an empty cache
+	path := filepath.Join(t.TempDir(), "cache.json")
+	shards := NewSimpleCacheShards(path, 2)
+	if cache, err := NewCacheE[int, string](Opts{Size: 2, Shards: shards}); err != nil || cache == nil {
+		t.Errorf("expected a cache, got %v (%v)", cache, err)
+	}
+
+	// a shard failing to load is reported along with the cache restored from the others
+	os.WriteFile(path+".0", []byte("invalid"), 0644)
+	os.WriteFile(path+".1", []byte(`{"2":"two"}`), 0644)
+
+	cache, err = NewCacheE[int, string](Opts{Size: 2, Shards: shards})
+	if err == nil || cache == nil {
+		t.Fatalf("expected a cache and an error, got %v (%v)", cache, err)
+	}
+	if v, err := cache.Get(2); err != nil || v != "two" {
+		t.Errorf("expected two, got %v (%v)", v, err)
+	}
+}
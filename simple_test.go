@@ -106,15 +106,81 @@ func TestSimpleCacheConcurrency(t *testing.T) {
 }
 
 func TestCacheTTL(t *testing.T) {
-	c := NewCache[int, string](Opts{Size: 1, TTL: 1})
+	clock := newFakeClock()
+	expired := make(chan int, 1)
+	c := NewCache(Opts{Size: 1, TTL: 1, Clock: clock}, TypedOpts[int, string]{
+		OnExpire: func(key int, value string) { expired <- key },
+	})
 
 	if err := c.Set(1, "one"); err != nil {
 		t.Errorf("Set returned error: %s", err)
 	}
 
-	time.Sleep(2 * time.Second)
+	clock.wait(t)
+	clock.Advance(2 * time.Second)
+	select {
+	case <-expired:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected 1 to expire")
+	}
 
 	if _, err := c.Get(1); err == nil {
 		t.Errorf("Get returned nil error after TTL")
 	}
 }
+
+func TestCacheConcurrentReads(t *testing.T) {
+	c := NewCache[int, string](Opts{Size: 1})
+	if err := c.Set(1, "one"); err != nil {
+		t.Errorf("Set returned error: %s", err)
+	}
+
+	// a reader holding the lock doesn't block other readers
+	s := c.(*simple[int, string])
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if v, err := c.Get(1); err != nil || v != "one" {
+			t.Errorf("Get returned %v, %v", v, err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("Get blocked on a concurrent reader")
+	}
+}
+
+func TestSimpleCacheResize(t *testing.T) {
+	c := NewCache[int, string](Opts{Size: 4})
+	for i := 0; i < 4; i++ {
+		c.Set(i, "v") // nolint:errcheck
+	}
+
+	r := c.(Resizable)
+	if err := r.Resize(0); err == nil {
+		t.Errorf("Resize returned nil error for an invalid size")
+	}
+
+	if err := r.Resize(2); err != nil {
+		t.Errorf("Resize returned error: %s", err)
+	}
+	if s := c.(StatsProvider).Stats(); s.Size != 2 || s.Evictions != 2 {
+		t.Errorf("expected 2 entries after 2 evictions, got %+v", s)
+	}
+
+	if err := c.Set(10, "v"); err == nil {
+		t.Errorf("Set returned nil error when cache is full")
+	}
+
+	if err := r.Resize(3); err != nil {
+		t.Errorf("Resize returned error: %s", err)
+	}
+	if err := c.Set(10, "v"); err != nil {
+		t.Errorf("Set returned error after growing: %s", err)
+	}
+}
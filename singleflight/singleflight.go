@@ -0,0 +1,95 @@
+// Package singleflight deduplicates concurrent calls for the same key: while a call is running,
+// the other callers wait for it and share its result, instead of repeating the work.
+// Nothing is kept once the call returns, so it fits the work whose result must not be cached,
+// and complements a cache for the work whose result is:
+//
+//	var g singleflight.Group[string, *User]
+//
+//	user, err, _ := g.Do(id, func() (*User, error) {
+//		return db.GetUser(ctx, id)
+//	})
+package singleflight
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Group runs a single call per key at a time. The zero value is ready to use, and it is thread-safe.
+type Group[K comparable, V any] struct {
+	mx    sync.Mutex
+	calls map[K]*call[V]
+}
+
+type call[V any] struct {
+	done   chan struct{}
+	value  V
+	err    error
+	shared bool
+}
+
+// PanicError is the error returned to the callers sharing a call whose function panicked.
+// The caller running the function panics with the original value.
+type PanicError struct {
+	Value any
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("singleflight: the call panicked: %v", e.Value)
+}
+
+// Do calls fn and returns its result, unless a call for the key is running, in which case it waits for it
+// and returns its result. shared reports whether the result was returned to several callers.
+func (g *Group[K, V]) Do(key K, fn func() (V, error)) (v V, err error, shared bool) {
+	g.mx.Lock()
+	if g.calls == nil {
+		g.calls = make(map[K]*call[V])
+	}
+	if c, ok := g.calls[key]; ok {
+		c.shared = true
+		g.mx.Unlock()
+		<-c.done
+		return c.value, c.err, true
+	}
+
+	c := &call[V]{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mx.Unlock()
+
+	g.run(key, c, fn)
+	return c.value, c.err, c.shared
+}
+
+// run calls fn for the call, and releases the callers waiting for it, even if fn panics or exits its goroutine.
+func (g *Group[K, V]) run(key K, c *call[V], fn func() (V, error)) {
+	normal := false
+	defer func() {
+		var recovered any
+		if !normal {
+			// recover returns nil when fn called runtime.Goexit, which carries on after this function
+			recovered = recover()
+			c.err = &PanicError{Value: recovered}
+		}
+
+		g.mx.Lock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		g.mx.Unlock()
+		close(c.done)
+
+		if recovered != nil {
+			panic(recovered)
+		}
+	}()
+
+	c.value, c.err = fn()
+	normal = true
+}
+
+// Forget makes the next calls for the key run their function, rather than wait for the running call.
+func (g *Group[K, V]) Forget(key K) {
+	g.mx.Lock()
+	delete(g.calls, key)
+	g.mx.Unlock()
+}
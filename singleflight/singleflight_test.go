@@ -0,0 +1,130 @@
+package singleflight
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDo(t *testing.T) {
+	var g Group[string, int]
+	var calls atomic.Int32
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	fn := func() (int, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	var shared atomic.Int32
+	first := func() {
+		defer wg.Done()
+		v, err, s := g.Do("a", fn)
+		if err != nil || v != 42 {
+			t.Errorf("expected %v, got %v (%v)", 42, v, err)
+		}
+		if s {
+			shared.Add(1)
+		}
+	}
+	wg.Add(1)
+	go first()
+	<-started
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go first()
+	}
+	// wait for the callers to join the running call
+	for {
+		g.mx.Lock()
+		joined := g.calls["a"].shared
+		g.mx.Unlock()
+		if joined {
+			break
+		}
+		runtime.Gosched()
+	}
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected %v call, got %v", 1, n)
+	}
+	if n := shared.Load(); n != 5 {
+		t.Errorf("expected %v shared results, got %v", 5, n)
+	}
+
+	// the results aren't kept
+	boom := errors.New("boom")
+	if _, err, s := g.Do("a", func() (int, error) { return 0, boom }); err != boom || s {
+		t.Errorf("expected %v, got %v (shared %v)", boom, err, s)
+	}
+}
+
+func TestDoPanic(t *testing.T) {
+	var g Group[int, int]
+	started, release := make(chan struct{}), make(chan struct{})
+	waited := make(chan error)
+
+	go func() {
+		defer func() { recover() }()
+		g.Do(1, func() (int, error) { // nolint:errcheck
+			close(started)
+			<-release
+			panic("oops")
+		})
+	}()
+	<-started
+	go func() {
+		_, err, _ := g.Do(1, func() (int, error) { return 0, nil })
+		waited <- err
+	}()
+	for {
+		g.mx.Lock()
+		joined := g.calls[1].shared
+		g.mx.Unlock()
+		if joined {
+			break
+		}
+		runtime.Gosched()
+	}
+	close(release)
+
+	var pe *PanicError
+	if err := <-waited; !errors.As(err, &pe) || pe.Value != "oops" {
+		t.Errorf("expected a panic error, got %v", err)
+	}
+	if v, err, _ := g.Do(1, func() (int, error) { return 1, nil }); err != nil || v != 1 {
+		t.Errorf("expected %v, got %v (%v)", 1, v, err)
+	}
+}
+
+func TestForget(t *testing.T) {
+	var g Group[int, int]
+	started, release := make(chan struct{}), make(chan struct{})
+
+	done := make(chan struct{})
+	go func() {
+		g.Do(1, func() (int, error) { // nolint:errcheck
+			close(started)
+			<-release
+			return 1, nil
+		})
+		close(done)
+	}()
+	<-started
+
+	g.Forget(1)
+	if v, _, s := g.Do(1, func() (int, error) { return 2, nil }); v != 2 || s {
+		t.Errorf("expected %v, got %v (shared %v)", 2, v, s)
+	}
+	close(release)
+	<-done
+}
@@ -0,0 +1,86 @@
+package cachego
+
+import "sync/atomic"
+
+const sketchDepth = 4
+
+// sketch estimates how often keys were seen with a count-min sketch of atomic counters, indexed by the hash
+// of the key. Once it has counted ten increments per counter, every count is halved, so the estimates follow
+// the recent frequencies. Counting takes no lock and doesn't allocate.
+type sketch struct {
+	counters [sketchDepth][]atomic.Uint32
+	mask     uint64
+	count    atomic.Int64
+	resetAt  int
+	aging    atomic.Bool
+	// aged is called after the counts are halved, if not nil.
+	aged func()
+}
+
+// newSketch creates a sketch of at least the given number of counters per row, rounded up to a power of two.
+func newSketch(width int) *sketch {
+	w := 1024
+	for w < width {
+		w *= 2
+	}
+
+	s := &sketch{mask: uint64(w - 1), resetAt: w * 10}
+	for i := range s.counters {
+		s.counters[i] = make([]atomic.Uint32, w)
+	}
+
+	return s
+}
+
+// increment adds one to the counters of the hash and returns its new estimate, the minimum of its counters.
+func (s *sketch) increment(sum uint64) uint64 {
+	h1, h2 := sum, sum>>32|1
+
+	min := ^uint32(0)
+	for i := range s.counters {
+		if c := s.counters[i][(h1+uint64(i)*h2)&s.mask].Add(1); c < min {
+			min = c
+		}
+	}
+
+	if s.count.Add(1) >= int64(s.resetAt) {
+		s.age()
+	}
+
+	return uint64(min)
+}
+
+// estimate returns the minimum of the counters of the hash.
+func (s *sketch) estimate(sum uint64) uint64 {
+	h1, h2 := sum, sum>>32|1
+
+	min := ^uint32(0)
+	for i := range s.counters {
+		if c := s.counters[i][(h1+uint64(i)*h2)&s.mask].Load(); c < min {
+			min = c
+		}
+	}
+
+	return uint64(min)
+}
+
+// age halves every counter. Only one caller ages the sketch at a time,
+// and increments counted meanwhile may be lost, which the estimates tolerate.
+func (s *sketch) age() {
+	if !s.aging.CompareAndSwap(false, true) {
+		return
+	}
+	defer s.aging.Store(false)
+
+	for i := range s.counters {
+		for j := range s.counters[i] {
+			c := &s.counters[i][j]
+			c.Store(c.Load() / 2)
+		}
+	}
+	if s.aged != nil {
+		s.aged()
+	}
+
+	s.count.Store(int64(s.resetAt / 2))
+}
@@ -0,0 +1,391 @@
+package cachego
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var errSlabClosed = errors.New("cache is closed")
+
+const (
+	defaultSlabShards      = 16
+	defaultSlabSegmentSize = 1 << 20
+	defaultSlabMaxBytes    = 64 << 20
+	slabHeaderSize         = 16 // key hash, key length, value length
+)
+
+// SlabOpts configures a slab cache.
+type SlabOpts[V any] struct {
+	// Shards is the number of independently locked partitions. Defaults to 16.
+	Shards int
+	// MaxBytes bounds the memory held by the slabs. Defaults to 64MB.
+	MaxBytes int
+	// SegmentSize is the size of every slab segment, and so the maximum size of an encoded entry. Defaults to 1MB.
+	SegmentSize int
+	// Marshal and Unmarshal encode and decode the values. Default to encoding/json.
+	// The data passed to Unmarshal is only valid during the call: the decoded value must not alias it,
+	// since its bytes are overwritten once their segment is reused.
+	// The off-heap cache passes a copy instead, so a value aliasing it never points into unmapped memory.
+	Marshal   func(value V) ([]byte, error)
+	Unmarshal func(data []byte, value *V) error
+}
+
+// slab is a cache whose entries are encoded into large byte segments, BigCache-style.
+// The index maps key hashes to entry locations, and holds no pointers, so however many entries are cached,
+// the garbage collector only has a few large byte slices to scan.
+// Every shard appends entries to its newest segment and, once it runs out of segments, drops the oldest one
+// and reuses its memory, so entries are evicted in insertion order. Updated and deleted entries leave
+// their old bytes behind until their segment is dropped.
+type slab[K comparable, V any] struct {
+	shards      []*slabShard
+	segmentSize int
+	marshal     func(value V) ([]byte, error)
+	unmarshal   func(data []byte, value *V) error
+	stats       *counters
+	region      []byte // the memory mapped segments of an off-heap cache
+}
+
+type slabShard struct {
+	mx       *sync.RWMutex
+	index    map[uint64]uint64 // key hash -> segment id << 32 | offset
+	segments [][]byte          // ring of segments, the one of id i is at i % len(segments)
+	oldest   uint32            // id of the oldest live segment
+	newest   uint32            // id of the segment being appended to
+	entries  int32
+	closed   bool
+}
+
+// NewSlabCache creates a new thread-safe cache that stores encoded keys and values in large reusable byte segments
+// instead of individual heap objects, so millions of entries don't become millions of pointers for the garbage collector.
+// The oldest entries are evicted once MaxBytes is used. Keys are hashed with FNV-1a over their encoding
+// (their bytes for strings, JSON otherwise); the rare keys whose hashes collide evict each other.
+// Every value read is decoded into a fresh copy.
+func NewSlabCache[K comparable, V any](opts SlabOpts[V]) Cache[K, V] {
+	return newSlab[K, V](opts)
+}
+
+// NewOffHeapSlabCache creates a new thread-safe slab cache (see NewSlabCache) whose segments live in
+// a memory mapped region outside the Go heap, leaving only the index on the heap.
+// The whole MaxBytes are mapped up front, and are only released by Close, after which the cache cannot be used.
+// It returns an error if the region cannot be mapped, or memory mapping is not supported on the platform.
+// Every value read is decoded from a copy of its bytes, so decoded values never alias the region.
+func NewOffHeapSlabCache[K comparable, V any](opts SlabOpts[V]) (ClosableCache[K, V], error) {
+	s := newSlab[K, V](opts)
+
+	size := len(s.shards) * len(s.shards[0].segments) * s.segmentSize
+	region, err := mmap(size)
+	if err != nil {
+		return nil, fmt.Errorf("mapping %v bytes failed: %w", size, err)
+	}
+	s.region = region
+
+	off := 0
+	for _, shard := range s.shards {
+		for i := range shard.segments {
+			shard.segments[i] = region[off : off : off+s.segmentSize]
+			off += s.segmentSize
+		}
+	}
+
+	return s, nil
+}
+
+// newSlab creates a slab cache whose segments are yet to be allocated.
+func newSlab[K comparable, V any](opts SlabOpts[V]) *slab[K, V] {
+	if opts.Shards <= 0 {
+		opts.Shards = defaultSlabShards
+	}
+	if opts.SegmentSize <= slabHeaderSize {
+		opts.SegmentSize = defaultSlabSegmentSize
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = defaultSlabMaxBytes
+	}
+	if opts.Marshal == nil {
+		opts.Marshal = func(v V) ([]byte, error) { return json.Marshal(v) }
+	}
+	if opts.Unmarshal == nil {
+		opts.Unmarshal = func(b []byte, v *V) error { return json.Unmarshal(b, v) }
+	}
+
+	segments := opts.MaxBytes / (opts.Shards * opts.SegmentSize)
+	if segments < 2 {
+		segments = 2
+	}
+
+	s := &slab[K, V]{
+		shards:      make([]*slabShard, opts.Shards),
+		segmentSize: opts.SegmentSize,
+		marshal:     opts.Marshal,
+		unmarshal:   opts.Unmarshal,
+		stats:       newCounters(systemClock{}),
+	}
+	for i := range s.shards {
+		s.shards[i] = &slabShard{
+			mx:       &sync.RWMutex{},
+			index:    make(map[uint64]uint64),
+			segments: make([][]byte, segments),
+		}
+	}
+
+	return s
+}
+
+func (s *slab[K, V]) locate(key K) ([]byte, uint64, *slabShard, error) {
+	var k []byte
+	if str, ok := any(key).(string); ok {
+		k = []byte(str)
+	} else {
+		var err error
+		if k, err = json.Marshal(key); err != nil {
+			return nil, 0, nil, err
+		}
+	}
+
+	h := uint64(14695981039346656037)
+	for _, c := range k {
+		h = (h ^ uint64(c)) * 1099511628211
+	}
+
+	return k, h, s.shards[h%uint64(len(s.shards))], nil
+}
+
+// Set encodes and appends the entry to the newest segment of the shard of the key,
+// evicting the oldest segment of the shard if it runs out of room.
+// It returns an error if the key or value cannot be encoded, or one wrapping ErrEntryTooLarge
+// if the entry doesn't fit in a segment.
+// This method is thread-safe.
+func (s *slab[K, V]) Set(key K, value V) error {
+	k, h, shard, err := s.locate(key)
+	if err != nil {
+		return err
+	}
+
+	v, err := s.marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if n := slabHeaderSize + len(k) + len(v); n > s.segmentSize {
+		return fmt.Errorf("key %v: %w: %v bytes, the segment size is %v", key, ErrEntryTooLarge, n, s.segmentSize)
+	}
+
+	shard.mx.Lock()
+	if shard.closed {
+		shard.mx.Unlock()
+		return errSlabClosed
+	}
+	evicted := shard.append(h, k, v, s.segmentSize)
+	shard.mx.Unlock()
+
+	s.stats.sets.Add(1)
+	s.stats.evictions.Add(uint64(evicted))
+	return nil
+}
+
+// Get decodes the value associated with the given key.
+// If the key is not found, the zero value of the value type and an error will be returned.
+// This method is thread-safe.
+func (s *slab[K, V]) Get(key K) (V, error) {
+	var value V
+
+	k, h, shard, err := s.locate(key)
+	if err != nil {
+		return value, err
+	}
+
+	shard.mx.RLock()
+	if shard.closed {
+		shard.mx.RUnlock()
+		return value, errSlabClosed
+	}
+	v, ok := shard.get(h, k)
+	if ok && s.region != nil {
+		v = append([]byte(nil), v...)
+	}
+	if ok {
+		err = s.unmarshal(v, &value)
+	}
+	shard.mx.RUnlock()
+
+	s.stats.lookup(ok)
+	if !ok {
+		return value, notFound(key)
+	}
+
+	return value, err
+}
+
+// Delete removes the key from the index. Its bytes are reclaimed once its segment is dropped.
+// If the key is not found, an error will be returned.
+// This method is thread-safe.
+func (s *slab[K, V]) Delete(key K) error {
+	k, h, shard, err := s.locate(key)
+	if err != nil {
+		return err
+	}
+
+	shard.mx.Lock()
+	_, ok := shard.get(h, k)
+	if ok {
+		delete(shard.index, h)
+		shard.entries--
+	}
+	shard.mx.Unlock()
+
+	if !ok {
+		return notFound(key)
+	}
+
+	s.stats.deletes.Add(1)
+	return nil
+}
+
+// Clear removes every entry, keeping the allocated segments for reuse.
+// This method is thread-safe.
+func (s *slab[K, V]) Clear() error {
+	for _, shard := range s.shards {
+		shard.mx.Lock()
+		shard.index = make(map[uint64]uint64)
+		for i := range shard.segments {
+			if shard.segments[i] != nil {
+				shard.segments[i] = shard.segments[i][:0]
+			}
+		}
+		shard.oldest = shard.newest
+		shard.entries = 0
+		shard.mx.Unlock()
+	}
+
+	return nil
+}
+
+// Close releases the memory mapped segments of an off-heap cache. Afterwards, every Set and Get fails.
+// It is a no-op for a cache whose segments are on the heap.
+// This method is thread-safe.
+func (s *slab[K, V]) Close() error {
+	if s.region == nil {
+		return nil
+	}
+
+	for _, shard := range s.shards {
+		shard.mx.Lock()
+		defer shard.mx.Unlock()
+	}
+
+	if s.shards[0].closed {
+		return nil
+	}
+
+	for _, shard := range s.shards {
+		shard.closed = true
+		shard.index = make(map[uint64]uint64)
+		shard.segments = nil
+		shard.entries = 0
+	}
+
+	return munmap(s.region)
+}
+
+// Stats returns a snapshot of the cache counters.
+// This method is thread-safe.
+func (s *slab[K, V]) Stats() Stats {
+	var n int32
+	for _, shard := range s.shards {
+		shard.mx.RLock()
+		n += shard.entries
+		shard.mx.RUnlock()
+	}
+
+	return s.stats.snapshot(n)
+}
+
+// append writes the entry to the newest segment and indexes it, returning the number of live entries
+// evicted to make room for it. Segments are allocated on the heap the first time they are used.
+func (sh *slabShard) append(h uint64, k, v []byte, segmentSize int) int {
+	evicted := 0
+	seg := sh.segment(sh.newest)
+	n := slabHeaderSize + len(k) + len(v)
+
+	if seg == nil {
+		seg = make([]byte, 0, segmentSize)
+	} else if len(seg)+n > cap(seg) {
+		sh.newest++
+		if int(sh.newest-sh.oldest) >= len(sh.segments) {
+			evicted = sh.drop()
+		}
+		i := sh.newest % uint32(len(sh.segments))
+		if sh.segments[i] == nil {
+			sh.segments[i] = make([]byte, 0, segmentSize)
+		}
+		seg = sh.segments[i][:0]
+	}
+
+	offset := len(seg)
+	seg = binary.BigEndian.AppendUint64(seg, h)
+	seg = binary.BigEndian.AppendUint32(seg, uint32(len(k)))
+	seg = binary.BigEndian.AppendUint32(seg, uint32(len(v)))
+	seg = append(seg, k...)
+	seg = append(seg, v...)
+	sh.segments[sh.newest%uint32(len(sh.segments))] = seg
+
+	if _, ok := sh.index[h]; !ok {
+		sh.entries++
+	}
+	sh.index[h] = uint64(sh.newest)<<32 | uint64(offset)
+
+	return evicted
+}
+
+// drop evicts the oldest segment, removing the entries still indexed in it,
+// and returns the number of removed entries.
+func (sh *slabShard) drop() int {
+	evicted := 0
+	seg := sh.segment(sh.oldest)
+	for off := 0; off < len(seg); {
+		h := binary.BigEndian.Uint64(seg[off:])
+		kl := int(binary.BigEndian.Uint32(seg[off+8:]))
+		vl := int(binary.BigEndian.Uint32(seg[off+12:]))
+
+		if loc, ok := sh.index[h]; ok && loc == uint64(sh.oldest)<<32|uint64(off) {
+			delete(sh.index, h)
+			sh.entries--
+			evicted++
+		}
+
+		off += slabHeaderSize + kl + vl
+	}
+
+	sh.segments[sh.oldest%uint32(len(sh.segments))] = seg[:0]
+	sh.oldest++
+	return evicted
+}
+
+// get returns the encoded value of the key, if it is indexed and its encoded key matches
+// (i.e. the hash didn't collide with another key).
+func (sh *slabShard) get(h uint64, k []byte) ([]byte, bool) {
+	loc, ok := sh.index[h]
+	if !ok {
+		return nil, false
+	}
+
+	seg := sh.segment(uint32(loc >> 32))
+	off := int(uint32(loc))
+	kl := int(binary.BigEndian.Uint32(seg[off+8:]))
+	vl := int(binary.BigEndian.Uint32(seg[off+12:]))
+	start := off + slabHeaderSize
+
+	if !bytes.Equal(seg[start:start+kl], k) {
+		return nil, false
+	}
+
+	return seg[start+kl : start+kl+vl], true
+}
+
+func (sh *slabShard) segment(id uint32) []byte {
+	return sh.segments[id%uint32(len(sh.segments))]
+}
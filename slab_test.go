@@ -0,0 +1,169 @@
+package cachego
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// nolint:errcheck
+func TestSlabCache(t *testing.T) {
+	type user struct {
+		Name string
+		Age  int
+	}
+
+	cache := NewSlabCache[int, user](SlabOpts[user]{Shards: 2})
+
+	if err := cache.Set(1, user{"alice", 30}); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+	cache.Set(1, user{"alice", 31})
+
+	if v, err := cache.Get(1); err != nil || v != (user{"alice", 31}) {
+		t.Errorf("expected alice 31, got %v (%v)", v, err)
+	}
+
+	if _, err := cache.Get(2); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	if err := cache.Delete(1); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+	if err := cache.Delete(1); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	cache.Set(3, user{"bob", 40})
+	cache.Clear()
+	if _, err := cache.Get(3); err == nil {
+		t.Errorf("expected error after Clear, got nil")
+	}
+
+	if stats := cache.(StatsProvider).Stats(); stats.Size != 0 || stats.Sets != 3 || stats.Hits != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+// nolint:errcheck
+func TestSlabCacheEviction(t *testing.T) {
+	// one shard of two 1KB segments
+	cache := NewSlabCache[string, string](SlabOpts[string]{Shards: 1, SegmentSize: 1024, MaxBytes: 2048})
+
+	value := strings.Repeat("a", 100)
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprint(i), value)
+	}
+
+	// the most recent entries are still there, the oldest were evicted with their segment
+	if _, err := cache.Get("99"); err != nil {
+		t.Errorf("expected 99 to be cached, got %v", err)
+	}
+	if _, err := cache.Get("0"); err == nil {
+		t.Errorf("expected 0 to be evicted")
+	}
+
+	stats := cache.(StatsProvider).Stats()
+	if stats.Evictions == 0 || int(stats.Size)+int(stats.Evictions) != 100 {
+		t.Errorf("expected every entry to be either cached or evicted, got %+v", stats)
+	}
+
+	// an entry larger than a segment
+	if err := cache.Set("big", strings.Repeat("a", 2000)); !errors.Is(err, ErrEntryTooLarge) {
+		t.Errorf("expected %v, got %v", ErrEntryTooLarge, err)
+	}
+}
+
+// nolint:errcheck
+func TestSlabCacheConcurrent(t *testing.T) {
+	cache := NewSlabCache[string, int](SlabOpts[int]{Shards: 4, SegmentSize: 4096, MaxBytes: 1 << 16})
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := fmt.Sprint(g, "-", i)
+				cache.Set(key, i)
+				if v, err := cache.Get(key); err == nil && v != i {
+					t.Errorf("expected %v, got %v", i, v)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+// nolint:errcheck
+func TestOffHeapSlabCache(t *testing.T) {
+	cache, err := NewOffHeapSlabCache[string, string](SlabOpts[string]{Shards: 2, SegmentSize: 1024, MaxBytes: 8192})
+	if err != nil {
+		t.Skipf("off-heap storage is not available: %v", err)
+	}
+
+	value := strings.Repeat("a", 100)
+	for i := 0; i < 200; i++ {
+		if err := cache.Set(fmt.Sprint(i), value); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+	}
+
+	if v, err := cache.Get("199"); err != nil || v != value {
+		t.Errorf("expected the value, got %v (%v)", v, err)
+	}
+
+	// the segments are the mapped region, reused in place
+	s := cache.(*slab[string, string])
+	for _, shard := range s.shards {
+		for _, seg := range shard.segments {
+			if cap(seg) != 1024 {
+				t.Errorf("expected segments of 1024 bytes, got %v", cap(seg))
+			}
+		}
+	}
+
+	if err := cache.Close(); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	if err := cache.Set("a", value); err == nil {
+		t.Errorf("expected error after Close, got nil")
+	}
+	if _, err := cache.Get("199"); err == nil {
+		t.Errorf("expected error after Close, got nil")
+	}
+
+	// closing twice
+	if err := cache.Close(); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
+
+// nolint:errcheck
+func TestOffHeapSlabCacheUnmarshal(t *testing.T) {
+	var data []byte
+	cache, err := NewOffHeapSlabCache[string, []byte](SlabOpts[[]byte]{
+		Shards: 1, SegmentSize: 1024, MaxBytes: 2048,
+		Marshal: func(v []byte) ([]byte, error) { return v, nil },
+		Unmarshal: func(b []byte, v *[]byte) error {
+			data, *v = b, b // aliases the data it is given
+			return nil
+		},
+	})
+	if err != nil {
+		t.Skipf("off-heap storage is not available: %v", err)
+	}
+
+	cache.Set("a", []byte("value"))
+	v, _ := cache.Get("a")
+	cache.Close()
+
+	// the value aliases a copy, so it survives the region being unmapped
+	if string(v) != "value" || string(data) != "value" {
+		t.Errorf("expected value, got %s", v)
+	}
+}
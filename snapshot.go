@@ -0,0 +1,69 @@
+package cachego
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+// entry is the persisted form of a single cache entry.
+// Snapshots are lists of entries rather than JSON objects, so keys of any comparable type
+// (e.g. structs, floats or bools) round-trip, not only strings, integers and text marshalers.
+type entry[K comparable, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+func marshalSnapshot[K comparable, V any](data map[K]V) ([]byte, error) {
+	entries := make([]entry[K, V], 0, len(data))
+	for k, v := range data {
+		entries = append(entries, entry[K, V]{Key: k, Value: v})
+	}
+
+	return json.Marshal(entries)
+}
+
+// unmarshalSnapshot decodes the snapshot into the given map.
+// Snapshots persisted as a JSON object (the format used before entry lists) are still supported.
+func unmarshalSnapshot[K comparable, V any](b []byte, data map[K]V) error {
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '{' {
+		return json.Unmarshal(b, &data)
+	}
+
+	var entries []entry[K, V]
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		data[e.Key] = e.Value
+	}
+
+	return nil
+}
+
+func contextOrBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+
+	return ctx
+}
+
+// loadFile loads the file, passing it the context if it is a ContextFile.
+func loadFile(ctx context.Context, f File) ([]byte, error) {
+	if cf, ok := f.(ContextFile); ok {
+		return cf.LoadContext(ctx)
+	}
+
+	return f.Load()
+}
+
+// dumpFile dumps the data to the file, passing it the context if it is a ContextFile.
+func dumpFile(ctx context.Context, f File, data []byte) error {
+	if cf, ok := f.(ContextFile); ok {
+		return cf.DumpContext(ctx, data)
+	}
+
+	return f.Dump(data)
+}
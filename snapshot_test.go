@@ -0,0 +1,87 @@
+package cachego
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSnapshotKeys(t *testing.T) {
+	type point struct{ X, Y int }
+
+	file := NewMemoryCacheFile()
+	cache := NewCache[point, string](Opts{Size: 2, File: file})
+	cache.Set(point{1, 2}, "a")
+	cache.Set(point{3, 4}, "b")
+
+	if err := cache.Clear(); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	cache2, err := NewCacheE[point, string](Opts{Size: 2, File: file})
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	for key, val := range map[point]string{{1, 2}: "a", {3, 4}: "b"} {
+		if v, err := cache2.Get(key); err != nil || v != val {
+			t.Errorf("expected %v, got %v (%v)", val, v, err)
+		}
+	}
+}
+
+func TestSnapshotLegacyFormat(t *testing.T) {
+	file := NewMemoryCacheFile()
+	file.Dump([]byte(` {"1":"one","2":"two"}`))
+
+	cache, err := NewCacheE[int, string](Opts{Size: 2, File: file})
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	if v, err := cache.Get(2); err != nil || v != "two" {
+		t.Errorf("expected two, got %v (%v)", v, err)
+	}
+}
+
+type contextFile struct {
+	File
+	ctx context.Context
+}
+
+func (f *contextFile) LoadContext(ctx context.Context) ([]byte, error) {
+	f.ctx = ctx
+	return f.Load()
+}
+
+func (f *contextFile) DumpContext(ctx context.Context, data []byte) error {
+	f.ctx = ctx
+	return f.Dump(data)
+}
+
+type ctxKey struct{}
+
+// nolint:errcheck
+func TestContextFile(t *testing.T) {
+	file := &contextFile{File: NewMemoryCacheFile()}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "clear")
+
+	cache := NewCache[int, string](Opts{Size: 2, File: file})
+	cache.Set(1, "one")
+	if err := cache.(ContextCache[int, string]).ClearCtx(ctx); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	if file.ctx == nil || file.ctx.Value(ctxKey{}) != "clear" {
+		t.Errorf("expected the context of ClearCtx to be passed to DumpContext")
+	}
+
+	lru := NewLRUCacheWithFile[int, string](2, file)
+	if v, err := lru.Get(1); err != nil || v != "one" {
+		t.Errorf("expected one, got %v (%v)", v, err)
+	}
+
+	lru.(ContextCache[int, string]).ClearCtx(ctx)
+	if file.ctx.Value(ctxKey{}) != "clear" {
+		t.Errorf("expected the context of ClearCtx to be passed to DumpContext")
+	}
+}
@@ -0,0 +1,166 @@
+// Package sqlite provides a cachego.Cache implementation stored in a SQLite table,
+// for durable caching without running a separate server.
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/noam-g4/cachego"
+)
+
+type cache[K comparable, V any] struct {
+	db    *sql.DB
+	table string
+	ttl   int16        // in seconds
+	purge atomic.Int64 // unix nano time of the next purge of the expired rows
+}
+
+type Opts struct {
+	// Table is the name of the table holding the entries. Defaults to "cachego".
+	// It is created on NewCache if it doesn't exist.
+	Table string
+	// TTL is the time to live of every entry, in seconds. If less than or equal to zero, entries do not expire.
+	TTL int16
+}
+
+// NewCache creates a new thread-safe instance of a cache stored in a SQLite table with the columns
+// (key, value, expires_at). Keys and values are encoded as JSON.
+// The database is opened (with any SQLite driver) and closed by the caller.
+// Expired rows are purged on creation and by Set, at most once per TTL; in between, they are filtered out on Get.
+// It returns an error if the table cannot be created.
+func NewCache[K comparable, V any](db *sql.DB, opts Opts) (cachego.Cache[K, V], error) {
+	table := opts.Table
+	if table == "" {
+		table = "cachego"
+	}
+
+	c := &cache[K, V]{
+		db:    db,
+		table: `"` + strings.ReplaceAll(table, `"`, `""`) + `"`,
+		ttl:   opts.TTL,
+	}
+
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + c.table + ` (
+		key TEXT PRIMARY KEY,
+		value BLOB NOT NULL,
+		expires_at INTEGER NOT NULL DEFAULT 0
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := c.purgeExpired(now.UnixNano()); err != nil {
+		return nil, err
+	}
+	c.purge.Store(now.Add(time.Duration(c.ttl) * time.Second).UnixNano())
+
+	return c, nil
+}
+
+// Set upserts the provided value under the given key.
+// If the key already exists in the cache, the associated value will be updated and its ttl reset.
+// With a TTL, it also purges the expired rows, at most once per TTL.
+// This method is thread-safe.
+func (c *cache[K, V]) Set(key K, value V) error {
+	k, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	v, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	var expires int64
+	if c.ttl > 0 {
+		now := time.Now()
+		expires = now.Add(time.Duration(c.ttl) * time.Second).UnixNano()
+		// a single Set purges when concurrent ones are due
+		if next := c.purge.Load(); now.UnixNano() >= next && c.purge.CompareAndSwap(next, expires) {
+			if err := c.purgeExpired(now.UnixNano()); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = c.db.Exec(`INSERT INTO `+c.table+` (key, value, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at`,
+		string(k), v, expires,
+	)
+	return err
+}
+
+// Get retrieves the value associated with the given key.
+// If the key is not found or has expired, the zero value of the value type and an error will be returned.
+// This method is thread-safe.
+func (c *cache[K, V]) Get(key K) (V, error) {
+	var empty V
+
+	k, err := json.Marshal(key)
+	if err != nil {
+		return empty, err
+	}
+
+	var v []byte
+	err = c.db.QueryRow(`SELECT value FROM `+c.table+` WHERE key = ? AND (expires_at = 0 OR expires_at > ?)`,
+		string(k), time.Now().UnixNano(),
+	).Scan(&v)
+	if err == sql.ErrNoRows {
+		return empty, fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+	}
+	if err != nil {
+		return empty, err
+	}
+
+	var value V
+	if err := json.Unmarshal(v, &value); err != nil {
+		return empty, err
+	}
+
+	return value, nil
+}
+
+// Delete removes the row associated with the given key.
+// If the key is not found or has expired, an error will be returned.
+// This method is thread-safe.
+func (c *cache[K, V]) Delete(key K) error {
+	k, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.db.Exec(`DELETE FROM `+c.table+` WHERE key = ? AND (expires_at = 0 OR expires_at > ?)`,
+		string(k), time.Now().UnixNano(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("key %v %w", key, cachego.ErrNotFound)
+	}
+
+	return nil
+}
+
+// Clear removes every row from the table.
+// This method is thread-safe.
+func (c *cache[K, V]) Clear() error {
+	_, err := c.db.Exec(`DELETE FROM ` + c.table)
+	return err
+}
+
+// purgeExpired removes the rows whose ttl lapsed.
+func (c *cache[K, V]) purgeExpired(now int64) error {
+	_, err := c.db.Exec(`DELETE FROM `+c.table+` WHERE expires_at > 0 AND expires_at <= ?`, now)
+	return err
+}
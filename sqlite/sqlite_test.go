@@ -0,0 +1,135 @@
+package sqlite
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openDB(t *testing.T, path string) *sql.DB {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	return db
+}
+
+func TestSQLiteCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	db := openDB(t, path)
+
+	c, err := NewCache[int, string](db, Opts{})
+	if err != nil {
+		t.Fatalf("NewCache returned error: %s", err)
+	}
+
+	// Set
+	if err := c.Set(1, "one"); err != nil {
+		t.Errorf("Set returned error: %s", err)
+	}
+
+	// Set (upsert)
+	if err := c.Set(1, "uno"); err != nil {
+		t.Errorf("Set returned error: %s", err)
+	}
+
+	// Get
+	v, err := c.Get(1)
+	if err != nil {
+		t.Errorf("Get returned error: %s", err)
+	}
+
+	if v != "uno" {
+		t.Errorf("Get returned incorrect value: %s", v)
+	}
+
+	// Get (not found)
+	if _, err := c.Get(2); err == nil {
+		t.Errorf("Get returned nil error when key not found")
+	}
+
+	// Delete
+	if err := c.Set(2, "two"); err != nil {
+		t.Errorf("Set returned error: %s", err)
+	}
+
+	if err := c.Delete(2); err != nil {
+		t.Errorf("Delete returned error: %s", err)
+	}
+
+	if err := c.Delete(2); err == nil {
+		t.Errorf("Delete returned nil error when key not found")
+	}
+
+	db.Close()
+
+	// reopening the database restores the entries
+	db = openDB(t, path)
+	defer db.Close()
+
+	c, err = NewCache[int, string](db, Opts{})
+	if err != nil {
+		t.Fatalf("NewCache returned error: %s", err)
+	}
+
+	if v, err := c.Get(1); err != nil || v != "uno" {
+		t.Errorf("Get returned %v (%v) after reopening", v, err)
+	}
+
+	// Clear
+	if err := c.Clear(); err != nil {
+		t.Errorf("Clear returned error: %s", err)
+	}
+
+	if _, err := c.Get(1); err == nil {
+		t.Errorf("Get returned nil error after Clear")
+	}
+}
+
+func TestSQLiteCacheTTL(t *testing.T) {
+	db := openDB(t, filepath.Join(t.TempDir(), "cache.db"))
+	defer db.Close()
+
+	c, err := NewCache[string, string](db, Opts{TTL: 1})
+	if err != nil {
+		t.Fatalf("NewCache returned error: %s", err)
+	}
+
+	if err := c.Set("a", "one"); err != nil {
+		t.Errorf("Set returned error: %s", err)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+
+	if _, err := c.Get("a"); err == nil {
+		t.Errorf("Get returned nil error after TTL")
+	}
+
+	if err := c.Delete("a"); err == nil {
+		t.Errorf("Delete returned nil error after TTL")
+	}
+}
+
+func TestSQLiteCachePurge(t *testing.T) {
+	db := openDB(t, filepath.Join(t.TempDir(), "cache.db"))
+	defer db.Close()
+
+	c, err := NewCache[string, string](db, Opts{TTL: 1})
+	if err != nil {
+		t.Fatalf("NewCache returned error: %s", err)
+	}
+
+	c.Set("a", "one") // nolint:errcheck
+	time.Sleep(1100 * time.Millisecond)
+
+	// a Set after the TTL purges the expired rows, even though they are never read again
+	c.Set("b", "two") // nolint:errcheck
+
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM cachego WHERE key = '"a"'`).Scan(&n); err != nil || n != 0 {
+		t.Errorf("expected the expired row to be purged, got %v (%v)", n, err)
+	}
+}
@@ -0,0 +1,104 @@
+package cachego
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of the counters of a cache.
+type Stats struct {
+	Hits        uint64
+	Misses      uint64
+	Sets        uint64
+	Deletes     uint64 // entries removed with Delete or dropped by a reload
+	Evictions   uint64 // entries evicted for capacity or memory pressure
+	Expirations uint64
+	Rejections  uint64 // Sets rejected because the cache was full
+	// Size is the current number of entries.
+	Size int32
+	// Bytes is the approximate memory held by the entries, if the cache is bounded by bytes (see Opts.MaxBytes).
+	Bytes int64
+	// Uptime is the time elapsed since the cache was created.
+	Uptime time.Duration
+}
+
+// HitRatio returns the ratio of hits out of all lookups, or 0 if there were no lookups.
+func (s Stats) HitRatio() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+
+	return 0
+}
+
+// add sums the counters and sizes of the other stats into these, keeping the longest uptime.
+func (s *Stats) add(o Stats) {
+	s.Hits += o.Hits
+	s.Misses += o.Misses
+	s.Sets += o.Sets
+	s.Deletes += o.Deletes
+	s.Evictions += o.Evictions
+	s.Expirations += o.Expirations
+	s.Rejections += o.Rejections
+	s.Size += o.Size
+	s.Bytes += o.Bytes
+	if o.Uptime > s.Uptime {
+		s.Uptime = o.Uptime
+	}
+}
+
+// StatsProvider is implemented by caches that keep counters of their operations.
+type StatsProvider interface {
+	// Stats returns a snapshot of the cache counters.
+	Stats() Stats
+}
+
+// counters holds the atomic counters behind Stats.
+type counters struct {
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	sets        atomic.Uint64
+	deletes     atomic.Uint64
+	evictions   atomic.Uint64
+	expirations atomic.Uint64
+	rejections  atomic.Uint64
+	created     time.Time
+	clock       Clock
+}
+
+func newCounters(clock Clock) *counters {
+	return &counters{created: clock.Now(), clock: clock}
+}
+
+func (c *counters) lookup(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+func (c *counters) removed(reason Reason) {
+	switch reason {
+	case ReasonCapacity, ReasonMemory:
+		c.evictions.Add(1)
+	case ReasonExpired:
+		c.expirations.Add(1)
+	case ReasonDeleted, ReasonReloaded:
+		c.deletes.Add(1)
+	}
+}
+
+func (c *counters) snapshot(size int32) Stats {
+	return Stats{
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		Sets:        c.sets.Load(),
+		Deletes:     c.deletes.Load(),
+		Evictions:   c.evictions.Load(),
+		Expirations: c.expirations.Load(),
+		Rejections:  c.rejections.Load(),
+		Size:        size,
+		Uptime:      c.clock.Now().Sub(c.created),
+	}
+}
@@ -0,0 +1,53 @@
+package cachego
+
+import (
+	"testing"
+	"time"
+)
+
+// nolint:errcheck
+func TestStats(t *testing.T) {
+	c := NewCache[int, string](Opts{Size: 3, TTL: 1})
+
+	c.Set(1, "one")
+	c.Set(2, "two")
+	c.Get(1)
+	c.Get(3)
+	c.Delete(2)
+	time.Sleep(1100 * time.Millisecond)
+
+	s := c.(StatsProvider).Stats()
+	expected := Stats{Hits: 1, Misses: 1, Sets: 2, Deletes: 1, Expirations: 1, Size: 0}
+
+	s.Uptime, expected.Uptime = 0, 0
+	if s != expected {
+		t.Errorf("expected %+v, got %+v", expected, s)
+	}
+
+	if s.HitRatio() != 0.5 {
+		t.Errorf("expected a hit ratio of 0.5, got %v", s.HitRatio())
+	}
+}
+
+// nolint:errcheck
+func TestLRUStats(t *testing.T) {
+	c := NewLRUCache[int, string](1)
+
+	c.Set(1, "one")
+	c.Set(2, "two")
+	c.Get(2)
+	c.Get(1)
+
+	s := c.(StatsProvider).Stats()
+	if s.Evictions != 1 || s.Hits != 1 || s.Misses != 1 || s.Sets != 2 || s.Size != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	if s.Uptime <= 0 {
+		t.Errorf("expected a positive uptime, got %v", s.Uptime)
+	}
+
+	if (Stats{}).HitRatio() != 0 {
+		t.Errorf("expected a hit ratio of 0 without lookups")
+	}
+}
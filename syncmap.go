@@ -0,0 +1,143 @@
+package cachego
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type syncMap[K comparable, V any] struct {
+	size  int32
+	data  sync.Map // of K to *V, so updates can compare and swap any value type
+	len   atomic.Int32
+	stats *counters
+}
+
+// NewSyncMapCache creates a new thread-safe cache backed by a sync.Map, for read-mostly workloads on many cores
+// where the ordering of an LRU isn't needed: reads and updates of existing keys never take a lock,
+// so they don't contend with each other.
+// If the size is less than or equal to zero, a default size of 100 will be used.
+// Like the simple cache, it returns an error wrapping ErrCacheFull when setting a new key once the size is reached.
+func NewSyncMapCache[K comparable, V any](size int32) Cache[K, V] {
+	if size <= 0 {
+		size = defaultSize
+	}
+
+	return &syncMap[K, V]{size: size, stats: newCounters(systemClock{})}
+}
+
+// Set stores the value under the key.
+// If the key is new and the cache is full, it returns an error wrapping ErrCacheFull.
+// This method is thread-safe.
+func (c *syncMap[K, V]) Set(key K, value V) error {
+	v := &value
+
+	// reserve a slot for the key, released if it turns out to exist
+	if c.len.Add(1) <= c.size {
+		if _, loaded := c.data.Swap(key, v); loaded {
+			c.len.Add(-1)
+		}
+		c.stats.sets.Add(1)
+		return nil
+	}
+	c.len.Add(-1)
+
+	// full: only update the key if it exists
+	for {
+		old, ok := c.data.Load(key)
+		if !ok {
+			c.stats.rejections.Add(1)
+			return cacheFull(key)
+		}
+		if c.data.CompareAndSwap(key, old, v) {
+			c.stats.sets.Add(1)
+			return nil
+		}
+	}
+}
+
+// Get retrieves the value associated with the key, without locking.
+// If the key is not found, the zero value of the value type and an error will be returned.
+// This method is thread-safe.
+func (c *syncMap[K, V]) Get(key K) (V, error) {
+	v, ok := c.Lookup(key)
+	if !ok {
+		return v, notFound(key)
+	}
+
+	return v, nil
+}
+
+// Lookup retrieves the value just like Get, but reports a miss with false instead of building an error.
+// This method is thread-safe.
+func (c *syncMap[K, V]) Lookup(key K) (V, bool) {
+	v, ok := c.data.Load(key)
+	c.stats.lookup(ok)
+	if !ok {
+		var empty V
+		return empty, false
+	}
+
+	return *v.(*V), true
+}
+
+// Delete removes the key.
+// If the key is not found, an error will be returned.
+// This method is thread-safe.
+func (c *syncMap[K, V]) Delete(key K) error {
+	if _, loaded := c.data.LoadAndDelete(key); !loaded {
+		return notFound(key)
+	}
+
+	c.len.Add(-1)
+	c.stats.deletes.Add(1)
+	return nil
+}
+
+// Clear removes all the entries. The keys set concurrently may be kept.
+// This method is thread-safe.
+func (c *syncMap[K, V]) Clear() error {
+	c.data.Range(func(key, _ any) bool {
+		if _, loaded := c.data.LoadAndDelete(key); loaded {
+			c.len.Add(-1)
+		}
+		return true
+	})
+
+	return nil
+}
+
+// Stats returns a snapshot of the cache counters.
+// This method is thread-safe.
+func (c *syncMap[K, V]) Stats() Stats {
+	return c.stats.snapshot(int32(c.Len()))
+}
+
+// Len returns the number of entries.
+// This method is thread-safe.
+func (c *syncMap[K, V]) Len() int {
+	return int(c.len.Load())
+}
+
+// Has reports whether the key is in the cache, without counting as an access.
+// This method is thread-safe.
+func (c *syncMap[K, V]) Has(key K) bool {
+	_, ok := c.data.Load(key)
+	return ok
+}
+
+// Keys returns the keys of the cache, in no particular order.
+// This method is thread-safe.
+func (c *syncMap[K, V]) Keys() []K {
+	var keys []K
+	c.data.Range(func(key, _ any) bool {
+		keys = append(keys, key.(K))
+		return true
+	})
+
+	return keys
+}
+
+// Close does nothing, since the cache starts no background work. It always returns nil.
+func (c *syncMap[K, V]) Close() error {
+	return nil
+}
@@ -0,0 +1,73 @@
+package cachego
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// nolint:errcheck
+func TestSyncMapCache(t *testing.T) {
+	c := NewSyncMapCache[string, []int](2)
+
+	if err := c.Set("a", []int{1}); err != nil {
+		t.Errorf("Set returned error: %s", err)
+	}
+	c.Set("b", nil)
+
+	// Set (full)
+	if err := c.Set("c", nil); !errors.Is(err, ErrCacheFull) {
+		t.Errorf("expected %v, got %v", ErrCacheFull, err)
+	}
+
+	// Set (update when full), of values which aren't comparable
+	if err := c.Set("b", []int{2}); err != nil {
+		t.Errorf("Set returned error when updating a key: %s", err)
+	}
+	if v, err := c.Get("b"); err != nil || len(v) != 1 || v[0] != 2 {
+		t.Errorf("expected [2], got %v (%v)", v, err)
+	}
+
+	if err := c.Delete("a"); err != nil {
+		t.Errorf("Delete returned error: %s", err)
+	}
+	if _, err := c.Get("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+	if err := c.Set("c", nil); err != nil {
+		t.Errorf("Set returned error after Delete: %s", err)
+	}
+
+	c.Clear()
+	if stats := c.(StatsProvider).Stats(); stats.Size != 0 || stats.Sets != 4 || stats.Deletes != 1 || stats.Rejections != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+// nolint:errcheck
+func TestSyncMapCacheConcurrent(t *testing.T) {
+	c := NewSyncMapCache[int, int](100)
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func(g int) {
+			defer wg.Done()
+			// every key is set by two goroutines, and a hundred more are rejected
+			for i := 0; i < 100; i++ {
+				c.Set((g%2)*50+i, i)
+			}
+		}(g)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				c.Get(i % 100)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if size := c.(StatsProvider).Stats().Size; size != 100 {
+		t.Errorf("expected 100 entries, got %v", size)
+	}
+}
@@ -0,0 +1,146 @@
+package cachego
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// TieredOpts configures a tiered cache.
+type TieredOpts struct {
+	// L1TTLRatio sets the ttl of the L1 cache to the given fraction of the ttl of the L2 cache, between 0 and 1,
+	// so the local copies are refreshed from L2 before L2 expires. It only applies when both tiers are
+	// simple or LRU caches and L2 has a ttl. If less than or equal to zero, or greater than one,
+	// every tier keeps the ttl it was created with (e.g. with Opts.TTL).
+	L1TTLRatio float64
+}
+
+type tiered[K comparable, V any] struct {
+	l1 Cache[K, V]
+	l2 Cache[K, V]
+}
+
+// ttlCache is implemented by the caches whose ttl can be read and changed.
+type ttlCache interface {
+	ttlSeconds() int16
+	setTTL(ttl int16)
+}
+
+// NewTieredCache creates a cache composed of two tiers, typically a small in-process cache (L1)
+// in front of a larger or shared one (L2), such as the Redis cache.
+// Reads go through L1 then L2, and the values found in L2 are promoted into L1.
+// Writes go through both tiers, L2 first. The cache is thread-safe if both tiers are.
+func NewTieredCache[K comparable, V any](l1, l2 Cache[K, V], opts TieredOpts) Cache[K, V] {
+	if opts.L1TTLRatio > 0 && opts.L1TTLRatio <= 1 {
+		c1, ok1 := l1.(ttlCache)
+		c2, ok2 := l2.(ttlCache)
+		if ok1 && ok2 && c2.ttlSeconds() > 0 {
+			ttl := int16(float64(c2.ttlSeconds()) * opts.L1TTLRatio)
+			if ttl < 1 {
+				ttl = 1
+			}
+			c1.setTTL(ttl)
+		}
+	}
+
+	return &tiered[K, V]{l1: l1, l2: l2}
+}
+
+// Get retrieves the value from L1, or from L2 on a miss, promoting the value found into L1.
+// If neither tier holds the key, it returns the error of L2.
+func (t *tiered[K, V]) Get(key K) (V, error) {
+	return t.GetCtx(context.Background(), key)
+}
+
+// GetCtx retrieves the value just like Get, passing the context to the tiers.
+func (t *tiered[K, V]) GetCtx(ctx context.Context, key K) (V, error) {
+	if v, err := GetCtx(ctx, t.l1, key); err == nil {
+		return v, nil
+	}
+
+	v, err := GetCtx(ctx, t.l2, key)
+	if err != nil {
+		return v, err
+	}
+
+	// L1 may be full: the value is still served from L2
+	_ = SetCtx(ctx, t.l1, key, v)
+	return v, nil
+}
+
+// Set stores the value in L2, then in L1. If L2 fails, L1 is left untouched and the error is returned.
+// If L1 fails (e.g. it is full), the key is removed from L1 so it doesn't serve a stale value,
+// and the value is still read from L2.
+func (t *tiered[K, V]) Set(key K, value V) error {
+	return t.SetCtx(context.Background(), key, value)
+}
+
+// SetCtx stores the value just like Set, passing the context to the tiers.
+func (t *tiered[K, V]) SetCtx(ctx context.Context, key K, value V) error {
+	if err := SetCtx(ctx, t.l2, key, value); err != nil {
+		return err
+	}
+
+	if err := SetCtx(ctx, t.l1, key, value); err != nil {
+		_ = DeleteCtx(ctx, t.l1, key)
+	}
+
+	return nil
+}
+
+// Delete removes the key from both tiers.
+// It returns an error wrapping ErrNotFound if neither tier held the key, along with any other error of the tiers.
+func (t *tiered[K, V]) Delete(key K) error {
+	return t.DeleteCtx(context.Background(), key)
+}
+
+// DeleteCtx removes the key just like Delete, passing the context to the tiers.
+func (t *tiered[K, V]) DeleteCtx(ctx context.Context, key K) error {
+	err2 := DeleteCtx(ctx, t.l2, key)
+	err1 := DeleteCtx(ctx, t.l1, key)
+
+	if errors.Is(err1, ErrNotFound) && errors.Is(err2, ErrNotFound) {
+		return notFound(key)
+	}
+	if errors.Is(err1, ErrNotFound) {
+		err1 = nil
+	}
+	if errors.Is(err2, ErrNotFound) {
+		err2 = nil
+	}
+
+	return errors.Join(tierErr("L2", err2), tierErr("L1", err1))
+}
+
+// Clear clears both tiers, returning the joined errors of the tiers that failed.
+func (t *tiered[K, V]) Clear() error {
+	return t.ClearCtx(context.Background())
+}
+
+// ClearCtx clears both tiers just like Clear, passing the context to the tiers.
+func (t *tiered[K, V]) ClearCtx(ctx context.Context) error {
+	err2 := ClearCtx(ctx, t.l2)
+	err1 := ClearCtx(ctx, t.l1)
+	return errors.Join(tierErr("L2", err2), tierErr("L1", err1))
+}
+
+// Close closes the tiers that implement io.Closer, returning the joined errors of the tiers that failed to close.
+func (t *tiered[K, V]) Close() error {
+	var errs []error
+	for i, c := range []Cache[K, V]{t.l1, t.l2} {
+		if closer, ok := c.(io.Closer); ok {
+			errs = append(errs, tierErr(fmt.Sprintf("L%d", i+1), closer.Close()))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func tierErr(tier string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("%s: %w", tier, err)
+}
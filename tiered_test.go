@@ -0,0 +1,68 @@
+package cachego
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// nolint:errcheck
+func TestTieredCache(t *testing.T) {
+	l1 := NewLRUCache[string, int](1)
+	l2 := NewCache[string, int](Opts{Size: 10})
+	c := NewTieredCache(l1, l2, TieredOpts{})
+
+	c.Set("a", 1)
+	if v, err := l2.Get("a"); err != nil || v != 1 {
+		t.Errorf("expected the value to be written to L2, got %v (%v)", v, err)
+	}
+
+	// b evicts a from L1, and reading a promotes it back
+	c.Set("b", 2)
+	if _, err := l1.Get("a"); err == nil {
+		t.Errorf("expected a to be evicted from L1")
+	}
+	if v, err := c.Get("a"); err != nil || v != 1 {
+		t.Errorf("expected 1, got %v (%v)", v, err)
+	}
+	if v, err := l1.Get("a"); err != nil || v != 1 {
+		t.Errorf("expected a to be promoted into L1, got %v (%v)", v, err)
+	}
+
+	if err := c.Delete("a"); err != nil {
+		t.Errorf("Delete returned error: %s", err)
+	}
+	if _, err := c.Get("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+	if err := c.Delete("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+}
+
+// nolint:errcheck
+func TestTieredCacheL1Rejects(t *testing.T) {
+	l1 := NewCache[string, string](Opts{Size: 10, MaxEntryBytes: 64})
+	c := NewTieredCache(l1, NewCache[string, string](Opts{Size: 10}), TieredOpts{})
+
+	c.Set("a", "small")
+	large := strings.Repeat("x", 128)
+	if err := c.Set("a", large); err != nil {
+		t.Errorf("expected L1 rejecting the value not to fail Set, got %v", err)
+	}
+
+	// L1 doesn't keep serving the previous value
+	if v, err := c.Get("a"); err != nil || v != large {
+		t.Errorf("expected the large value, got %v (%v)", v, err)
+	}
+}
+
+func TestTieredCacheTTLRatio(t *testing.T) {
+	l1 := NewLRUCacheWithOpts[string, int](Opts{Size: 10})
+	l2 := NewCache[string, int](Opts{Size: 10, TTL: 60})
+	NewTieredCache(l1, l2, TieredOpts{L1TTLRatio: 0.25})
+
+	if ttl := l1.(ttlCache).ttlSeconds(); ttl != 15 {
+		t.Errorf("expected 15, got %v", ttl)
+	}
+}
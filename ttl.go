@@ -0,0 +1,22 @@
+package cachego
+
+import "time"
+
+// TTLSetter is implemented by caches whose entries may each expire after a ttl of their own.
+type TTLSetter[K comparable, V any] interface {
+	// SetWithTTL stores the value just like Set, but removes it once the given ttl lapses rather than the ttl
+	// of the cache. A ttl <= 0 means the entry doesn't expire.
+	SetWithTTL(key K, value V, ttl time.Duration) error
+}
+
+// cacheTTL is the ttl the entries set with Set are stored with: the ttl of the cache at the time they are set.
+const cacheTTL time.Duration = -1
+
+// resolveTTL returns the ttl of an entry, given the ttl of the cache in seconds.
+func resolveTTL(ttl time.Duration, seconds int16) time.Duration {
+	if ttl == cacheTTL {
+		return time.Duration(seconds) * time.Second
+	}
+
+	return ttl
+}
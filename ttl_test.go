@@ -0,0 +1,52 @@
+package cachego
+
+import (
+	"testing"
+	"time"
+)
+
+// nolint:errcheck
+func TestSetWithTTL(t *testing.T) {
+	for name, create := range map[string]func(clock Clock) Cache[string, int]{
+		"simple": func(clock Clock) Cache[string, int] {
+			return NewCache[string, int](Opts{Size: 10, TTL: 60, Clock: clock})
+		},
+		"lru": func(clock Clock) Cache[string, int] {
+			return NewLRUCacheWithOpts[string, int](Opts{Size: 10, TTL: 60, Clock: clock})
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			clock := newFakeClock()
+			c := create(clock)
+			s := c.(TTLSetter[string, int])
+
+			s.SetWithTTL("short", 1, time.Second)
+			s.SetWithTTL("forever", 2, 0)
+			c.Set("default", 3)
+
+			for key, expected := range map[string]time.Time{
+				"short":   clock.Now().Add(time.Second),
+				"forever": {},
+				"default": clock.Now().Add(time.Minute),
+			} {
+				if _, info, _ := c.(Inspector[string, int]).GetWithInfo(key); !info.Expires.Equal(expected) {
+					t.Errorf("%v: expected %v, got %v", key, expected, info.Expires)
+				}
+			}
+
+			clock.wait(t)
+			clock.Advance(time.Second)
+			eventually(t, func() bool {
+				_, err := c.Get("short")
+				return err != nil
+			})
+
+			// setting the key again replaces its ttl
+			s.SetWithTTL("default", 4, 0)
+			clock.Advance(time.Minute)
+			if v, err := c.Get("default"); err != nil || v != 4 {
+				t.Errorf("expected %v, got %v, %v", 4, v, err)
+			}
+		})
+	}
+}
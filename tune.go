@@ -0,0 +1,159 @@
+package cachego
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultTuneInterval = time.Minute
+	defaultTuneStep     = 0.1
+	// tuneMargin is how far above the target the hit ratio has to be for the cache to shrink,
+	// so the size doesn't oscillate around the target.
+	tuneMargin = 0.05
+)
+
+// TuneOpts configures a controller that periodically adapts the capacity (and the ttl) of a cache
+// to hold a target hit ratio and a bounded eviction rate: it grows the cache while the hit ratio is below the target
+// or too many entries are evicted, and shrinks it back while the hit ratio is comfortably above the target
+// and few entries are evicted.
+type TuneOpts struct {
+	// TargetHitRatio is the hit ratio to hold, between 0 and 1.
+	// If less than or equal to zero, the hit ratio is not considered.
+	TargetHitRatio float64
+	// MaxEvictionRate is the fraction of the capacity that may be evicted in an interval before the cache grows.
+	// For the caches that reject new keys when full instead of evicting, such as the simple cache,
+	// the rejected Sets (see Stats.Rejections) count as evictions.
+	// The cache only shrinks while less than half of that rate is evicted.
+	// If less than or equal to zero, the evictions are not considered.
+	// If both TargetHitRatio and MaxEvictionRate are less than or equal to zero, the cache is not tuned.
+	MaxEvictionRate float64
+	// MinSize and MaxSize bound the capacity. They default to the cache size.
+	MinSize int32
+	MaxSize int32
+	// MinTTL and MaxTTL bound the ttl, in seconds. If MaxTTL is less than or equal to zero,
+	// or the cache has no ttl, the ttl is not tuned.
+	MinTTL int16
+	MaxTTL int16
+	// Interval is how often the hit ratio is inspected. Defaults to a minute.
+	Interval time.Duration
+	// Step is the fraction by which the capacity (and the ttl) is grown or shrunk at once. Defaults to 0.1.
+	Step float64
+}
+
+// tunable is implemented by the caches whose capacity (and ttl) can be changed at runtime.
+type tunable interface {
+	StatsProvider
+	capacity() int32
+	resize(size int32)
+	ttlSeconds() int16
+	setTTL(ttl int16)
+}
+
+func (o TuneOpts) enabled() bool {
+	return o.TargetHitRatio > 0 || o.MaxEvictionRate > 0
+}
+
+// tuner adapts the capacity (and the ttl) of a cache on every step, from the stats observed since the previous one.
+type tuner struct {
+	t    tunable
+	opts TuneOpts
+	last Stats
+}
+
+func newTuner(t tunable, opts TuneOpts) *tuner {
+	size := t.capacity()
+	if opts.MinSize <= 0 || opts.MinSize > size {
+		opts.MinSize = size
+	}
+	if opts.MaxSize < size {
+		opts.MaxSize = size
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultTuneInterval
+	}
+	if opts.Step <= 0 {
+		opts.Step = defaultTuneStep
+	}
+
+	return &tuner{t: t, opts: opts, last: t.Stats()}
+}
+
+// tune runs the controller described by the options, at intervals told by the clock, until the context is done.
+func tune(ctx context.Context, clock Clock, t tunable, opts TuneOpts) {
+	tn := newTuner(t, opts)
+	every(ctx, clock, tn.opts.Interval, tn.step)
+}
+
+// step adapts the cache to the stats observed since the previous step.
+// Sets rejected by a full cache count as evictions, since they are the pressure of the caches that don't evict.
+func (tn *tuner) step() {
+	s := tn.t.Stats()
+	hits, misses := s.Hits-tn.last.Hits, s.Misses-tn.last.Misses
+	evictions := s.Evictions - tn.last.Evictions + s.Rejections - tn.last.Rejections
+	tn.last = s
+
+	if hits+misses == 0 && evictions == 0 {
+		return
+	}
+
+	// without lookups, the hit ratio is considered on target so only the evictions count
+	ratio := tn.opts.TargetHitRatio
+	if hits+misses > 0 {
+		ratio = float64(hits) / float64(hits+misses)
+	}
+
+	size := tn.t.capacity()
+	tn.t.resize(tn.opts.nextSize(size, ratio, float64(evictions)/float64(size)))
+
+	// the entries evicted by shrinking the cache are not pressure
+	tn.last.Evictions = tn.t.Stats().Evictions
+
+	if ttl := tn.t.ttlSeconds(); ttl > 0 && tn.opts.MaxTTL > 0 && tn.opts.TargetHitRatio > 0 {
+		tn.t.setTTL(tn.opts.nextTTL(ttl, ratio))
+	}
+}
+
+// nextSize returns the capacity to use after observing the given hit ratio and eviction rate.
+func (o TuneOpts) nextSize(size int32, ratio, evictionRate float64) int32 {
+	grow := (o.TargetHitRatio > 0 && ratio < o.TargetHitRatio) ||
+		(o.MaxEvictionRate > 0 && evictionRate > o.MaxEvictionRate)
+	shrink := (o.TargetHitRatio <= 0 || ratio > o.TargetHitRatio+tuneMargin) &&
+		(o.MaxEvictionRate <= 0 || evictionRate < o.MaxEvictionRate/2)
+
+	return int32(o.next(float64(size), grow, !grow && shrink, float64(o.MinSize), float64(o.MaxSize)))
+}
+
+// nextTTL returns the ttl to use after observing the given hit ratio.
+func (o TuneOpts) nextTTL(ttl int16, ratio float64) int16 {
+	min := float64(o.MinTTL)
+	if min <= 0 {
+		min = 1
+	}
+
+	grow, shrink := ratio < o.TargetHitRatio, ratio > o.TargetHitRatio+tuneMargin
+	return int16(o.next(float64(ttl), grow, shrink, min, float64(o.MaxTTL)))
+}
+
+// next grows or shrinks the value by a step, within the bounds.
+func (o TuneOpts) next(v float64, grow, shrink bool, min, max float64) float64 {
+	delta := v * o.Step
+	if delta < 1 {
+		delta = 1
+	}
+
+	switch {
+	case grow:
+		v += delta
+	case shrink:
+		v -= delta
+	}
+
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
@@ -0,0 +1,161 @@
+package cachego
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestTuneNextSize(t *testing.T) {
+	opts := TuneOpts{TargetHitRatio: 0.8, MinSize: 50, MaxSize: 200, Step: 0.1}
+
+	tests := []struct {
+		size     int32
+		ratio    float64
+		expected int32
+	}{
+		{100, 0.5, 110},  // below the target, grow
+		{195, 0.5, 200},  // bounded by MaxSize
+		{100, 0.82, 100}, // within the margin, hold
+		{100, 0.9, 90},   // above the target, shrink
+		{52, 0.9, 50},    // bounded by MinSize
+		{5, 0.5, 50},     // below MinSize
+	}
+
+	for _, test := range tests {
+		if got := opts.nextSize(test.size, test.ratio, 0); got != test.expected {
+			t.Errorf("expected %v, got %v (size %v, ratio %v)", test.expected, got, test.size, test.ratio)
+		}
+	}
+
+	rateOpts := TuneOpts{MaxEvictionRate: 0.2, MinSize: 50, MaxSize: 200, Step: 0.1}
+
+	rateTests := []struct {
+		ratio    float64
+		rate     float64
+		expected int32
+	}{
+		{0, 0.5, 110},  // too many evictions, grow
+		{0, 0.15, 100}, // within the rate, hold
+		{0, 0.05, 90},  // few evictions, shrink
+	}
+
+	for _, test := range rateTests {
+		if got := rateOpts.nextSize(100, test.ratio, test.rate); got != test.expected {
+			t.Errorf("expected %v, got %v (rate %v)", test.expected, got, test.rate)
+		}
+	}
+
+	// both: evictions grow the cache even with a high hit ratio, and hold it from shrinking
+	opts.MaxEvictionRate = 0.2
+	if got := opts.nextSize(100, 0.9, 0.5); got != 110 {
+		t.Errorf("expected 110, got %v", got)
+	}
+	if got := opts.nextSize(100, 0.9, 0.15); got != 100 {
+		t.Errorf("expected 100, got %v", got)
+	}
+
+	ttlOpts := TuneOpts{TargetHitRatio: 0.8, MaxTTL: 10}
+	ttlOpts.Step = defaultTuneStep
+	if got := ttlOpts.nextTTL(5, 0.5); got != 6 {
+		t.Errorf("expected 6, got %v", got)
+	}
+	if got := ttlOpts.nextTTL(1, 0.9); got != 1 {
+		t.Errorf("expected 1, got %v", got)
+	}
+}
+
+// nolint:errcheck
+func TestTune(t *testing.T) {
+	evicted := 0
+	cache := NewLRUCacheWithOpts(Opts{Size: 10}, TypedOpts[int, int]{
+		OnEvict: func(int, int, Reason) { evicted++ },
+	})
+	tn := newTuner(cache.(tunable), TuneOpts{TargetHitRatio: 0.9, MaxSize: 20, Step: 0.5})
+
+	// every lookup misses, so the cache grows up to MaxSize
+	for _, expected := range []int32{15, 20, 20} {
+		for i := 0; i < 5; i++ {
+			cache.Get(i)
+		}
+		tn.step()
+
+		if size := cache.(tunable).capacity(); size != expected {
+			t.Errorf("expected the cache to grow to %v, got %v", expected, size)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		cache.Set(i, i)
+	}
+
+	// every lookup hits, so the cache shrinks back to its initial size
+	for _, expected := range []int32{10, 10} {
+		cache.Get(0)
+		tn.step()
+
+		if size := cache.(tunable).capacity(); size != expected {
+			t.Errorf("expected the cache to shrink to %v, got %v", expected, size)
+		}
+	}
+
+	if evicted != 10 {
+		t.Errorf("expected 10 entries to be evicted while shrinking, got %v", evicted)
+	}
+
+	// no lookups and no evictions, hold
+	tn.step()
+	if size := cache.(tunable).capacity(); size != 10 {
+		t.Errorf("expected 10, got %v", size)
+	}
+}
+
+// nolint:errcheck
+func TestTuneRejections(t *testing.T) {
+	cache := NewCache[int, int](Opts{Size: 10})
+	tn := newTuner(cache.(tunable), TuneOpts{MaxEvictionRate: 0.2, MaxSize: 20, Step: 0.5})
+
+	// the simple cache rejects new keys when full, which counts as eviction pressure
+	for i := 0; i < 15; i++ {
+		cache.Set(i, i)
+	}
+	if s := cache.(StatsProvider).Stats(); s.Rejections != 5 {
+		t.Errorf("expected 5 rejections, got %v", s.Rejections)
+	}
+
+	tn.step()
+	if size := cache.(tunable).capacity(); size != 15 {
+		t.Errorf("expected the cache to grow to 15, got %v", size)
+	}
+
+	// the rejected keys now fit
+	for i := 10; i < 15; i++ {
+		if err := cache.Set(i, i); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+	}
+
+	tn.step()
+	if size := cache.(tunable).capacity(); size != 15 {
+		t.Errorf("expected 15, got %v", size)
+	}
+}
+
+// nolint:errcheck
+func TestTuneClose(t *testing.T) {
+	cache := NewCache[int, int](Opts{
+		Size: 10,
+		Tune: TuneOpts{TargetHitRatio: 0.9, MaxSize: 20, Interval: 10 * time.Millisecond},
+	})
+	cache.(io.Closer).Close()
+
+	// a closed cache is no longer tuned
+	for i := 0; i < 10; i++ {
+		cache.Get(i)
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if size := cache.(tunable).capacity(); size != 10 {
+		t.Errorf("expected 10, got %v", size)
+	}
+}
@@ -0,0 +1,83 @@
+package cachego
+
+import "testing"
+
+// nolint:errcheck
+func TestVictimCache(t *testing.T) {
+	victim := NewLRUCache[int, string](10)
+	var evicted []int
+
+	cache := NewLRUCacheWithOpts(Opts{Size: 2}, TypedOpts[int, string]{
+		Victim: victim,
+		OnEvict: func(key int, value string, reason Reason) {
+			if reason == ReasonCapacity {
+				evicted = append(evicted, key)
+			}
+		},
+	})
+
+	cache.Set(1, "one")
+	cache.Set(2, "two")
+	cache.Set(3, "three") // 1 is demoted into the victim cache
+
+	if len(evicted) != 0 {
+		t.Errorf("Expected demoted keys not to be reported, got %v", evicted)
+	}
+
+	if v, err := victim.Get(1); err != nil || v != "one" {
+		t.Errorf("Expected key 1 to be in the victim cache, got %v (%v)", v, err)
+	}
+
+	// a victim hit moves the entry back into the cache, demoting the least recently used one
+	if v, err := cache.Get(1); err != nil || v != "one" {
+		t.Errorf("Expected key 1 to be found, got %v (%v)", v, err)
+	}
+
+	if _, err := victim.Get(1); err == nil {
+		t.Errorf("Expected key 1 to be moved out of the victim cache")
+	}
+
+	if _, err := victim.Get(2); err != nil {
+		t.Errorf("Expected key 2 to be demoted into the victim cache")
+	}
+
+	// deleting a key also deletes it from the victim cache
+	if err := cache.Delete(2); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+
+	if _, err := cache.Get(2); err == nil {
+		t.Errorf("Expected key 2 to be deleted")
+	}
+
+	if err := cache.Delete(2); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+
+	// clearing the cache also clears the victim cache
+	cache.Set(4, "four") // 3 is demoted into the victim cache
+	cache.Clear()
+
+	if _, err := cache.Get(3); err == nil {
+		t.Errorf("Expected key 3 to be cleared")
+	}
+}
+
+// nolint:errcheck
+func TestVictimCacheRejected(t *testing.T) {
+	victim := NewCache[int, string](Opts{Size: 1})
+	victim.Set(0, "zero")
+	var evicted []int
+
+	cache := NewLRUCacheWithOpts(Opts{Size: 1}, TypedOpts[int, string]{
+		Victim:  victim,
+		OnEvict: func(key int, _ string, _ Reason) { evicted = append(evicted, key) },
+	})
+
+	cache.Set(1, "one")
+	cache.Set(2, "two") // the full victim cache rejects 1, so it is dropped
+
+	if len(evicted) != 1 || evicted[0] != 1 {
+		t.Errorf("Expected key 1 to be evicted, got %v", evicted)
+	}
+}
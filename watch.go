@@ -0,0 +1,63 @@
+package cachego
+
+import "sync"
+
+// Watchable is implemented by caches that can notify about changes of a single key.
+type Watchable[K comparable, V any] interface {
+	// Watch returns a channel that receives the new value whenever the given key is set,
+	// and a function that stops the watch and closes the channel.
+	Watch(key K) (<-chan V, func())
+}
+
+// watchers tracks the channels watching each key.
+type watchers[K comparable, V any] struct {
+	mx    *sync.Mutex
+	chans map[K]map[chan V]struct{}
+}
+
+func newWatchers[K comparable, V any]() *watchers[K, V] {
+	return &watchers[K, V]{mx: &sync.Mutex{}, chans: make(map[K]map[chan V]struct{})}
+}
+
+// Watch returns a channel that receives the new value whenever the given key is set,
+// and a function that stops the watch and closes the channel.
+// The channel holds a single value: if the receiver falls behind, it only gets the latest value.
+// This method is thread-safe.
+func (w *watchers[K, V]) Watch(key K) (<-chan V, func()) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	ch := make(chan V, 1)
+	if w.chans[key] == nil {
+		w.chans[key] = make(map[chan V]struct{})
+	}
+	w.chans[key][ch] = struct{}{}
+
+	once := sync.Once{}
+	return ch, func() {
+		once.Do(func() {
+			w.mx.Lock()
+			defer w.mx.Unlock()
+
+			delete(w.chans[key], ch)
+			if len(w.chans[key]) == 0 {
+				delete(w.chans, key)
+			}
+			close(ch)
+		})
+	}
+}
+
+// notify delivers the value to every channel watching the key, replacing any value not received yet.
+func (w *watchers[K, V]) notify(key K, value V) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	for ch := range w.chans[key] {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- value
+	}
+}
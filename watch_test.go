@@ -0,0 +1,65 @@
+package cachego
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	for name, c := range map[string]Cache[string, string]{
+		"simple": NewCache[string, string](Opts{Size: 3}),
+		"lru":    NewLRUCacheWithOpts[string, string](Opts{Size: 3}),
+		"lfu":    New[string, string](WithSize(3), WithPolicy(PolicyLFU)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			values, cancel := c.(Watchable[string, string]).Watch("config")
+
+			c.Set("other", "ignored")
+			c.Set("config", "v1")
+
+			if v := <-values; v != "v1" {
+				t.Errorf("expected v1, got %v", v)
+			}
+
+			// a slow receiver only gets the latest value
+			c.Set("config", "v2")
+			c.Set("config", "v3")
+
+			if v := <-values; v != "v3" {
+				t.Errorf("expected v3, got %v", v)
+			}
+
+			cancel()
+			cancel() // cancelling twice is safe
+
+			if _, ok := <-values; ok {
+				t.Errorf("expected the channel to be closed")
+			}
+
+			c.Set("config", "v4") // setting a key that is no longer watched
+		})
+	}
+}
+
+func TestWatchReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	os.WriteFile(path, []byte(`{"config":"v1"}`), 0644)
+
+	c := NewCache[string, string](Opts{Size: 2, File: NewSimpleCacheFile(path), Reload: 10 * time.Millisecond})
+	values, cancel := c.(Watchable[string, string]).Watch("config")
+	defer cancel()
+
+	// a reloaded snapshot notifies the watchers
+	os.WriteFile(path, []byte(`{"config":"v2"}`), 0644)
+
+	select {
+	case v := <-values:
+		if v != "v2" {
+			t.Errorf("expected v2, got %v", v)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("expected the reloaded value to be delivered")
+	}
+}
@@ -0,0 +1,100 @@
+package cachego
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrEntryTooLarge is returned (wrapped) by Set for an entry larger than the max entry bytes of the cache
+// (see Opts.MaxEntryBytes), larger than its max bytes, or larger than a segment of a slab cache (see SlabOpts).
+var ErrEntryTooLarge = errors.New("entry is too large")
+
+// weigher bounds a cache, and the entries it accepts, by their approximate memory.
+// It is not thread-safe: the cache updates it while holding its lock.
+// A nil weigher doesn't bound the cache.
+type weigher[K comparable, V any] struct {
+	max      int64 // zero if the total is not bounded
+	maxEntry int   // zero if the entries are not bounded
+	used     int64
+	sizer    func(key K, value V) int
+}
+
+func newWeigher[K comparable, V any](max int64, maxEntry int, sizer func(key K, value V) int) *weigher[K, V] {
+	if max <= 0 && maxEntry <= 0 {
+		return nil
+	}
+
+	if sizer == nil {
+		sizer = func(key K, value V) int {
+			return estimateSize(key) + estimateSize(value)
+		}
+	}
+
+	w := &weigher[K, V]{sizer: sizer}
+	if max > 0 {
+		w.max = max
+	}
+	if maxEntry > 0 {
+		w.maxEntry = maxEntry
+	}
+
+	return w
+}
+
+// size returns the size of the entry, or 0 if the cache is not bounded by bytes.
+func (w *weigher[K, V]) size(key K, value V) int {
+	if w == nil {
+		return 0
+	}
+
+	return w.sizer(key, value)
+}
+
+// check returns an error wrapping ErrEntryTooLarge if an entry of the given size can never be stored.
+func (w *weigher[K, V]) check(key K, size int) error {
+	switch {
+	case w == nil:
+		return nil
+	case w.maxEntry > 0 && size > w.maxEntry:
+		return fmt.Errorf("key %v: %w: %v bytes, the max entry bytes are %v", key, ErrEntryTooLarge, size, w.maxEntry)
+	case !w.holds(size):
+		return fmt.Errorf("key %v: %w: %v bytes, the cache max bytes are %v", key, ErrEntryTooLarge, size, w.max)
+	}
+
+	return nil
+}
+
+// holds reports whether the given number of bytes fits in an empty cache.
+func (w *weigher[K, V]) holds(size int) bool {
+	return w == nil || w.max == 0 || int64(size) <= w.max
+}
+
+// fits reports whether the cache stays within its bound after growing by delta bytes.
+func (w *weigher[K, V]) fits(delta int) bool {
+	return w == nil || w.max == 0 || w.used+int64(delta) <= w.max
+}
+
+// over reports whether the cache holds more than its bound.
+func (w *weigher[K, V]) over() bool {
+	return w != nil && w.max > 0 && w.used > w.max
+}
+
+func (w *weigher[K, V]) add(delta int) {
+	if w != nil {
+		w.used += int64(delta)
+	}
+}
+
+func (w *weigher[K, V]) reset() {
+	if w != nil {
+		w.used = 0
+	}
+}
+
+func (w *weigher[K, V]) bytes() int64 {
+	if w == nil {
+		return 0
+	}
+
+	return w.used
+}
@@ -0,0 +1,133 @@
+package cachego
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEstimatedSizer(t *testing.T) {
+	w := newWeigher[string, []byte](1000, 0, nil)
+
+	small := w.size("a", make([]byte, 10))
+	large := w.size("a", make([]byte, 100))
+	if large-small != 90 {
+		t.Errorf("expected a difference of 90 bytes, got %v", large-small)
+	}
+
+	if newWeigher[string, []byte](0, 0, nil) != nil {
+		t.Errorf("expected no weigher without max bytes")
+	}
+}
+
+// nolint:errcheck
+func TestSimpleCacheMaxBytes(t *testing.T) {
+	sizer := func(key string, value []byte) int { return len(value) }
+	c := NewCache(Opts{Size: 10, MaxBytes: 100}, TypedOpts[string, []byte]{Sizer: sizer})
+
+	if err := c.Set("a", make([]byte, 60)); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	// new key that doesn't fit
+	if err := c.Set("b", make([]byte, 50)); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	if err := c.Set("b", make([]byte, 40)); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	// update that doesn't fit
+	if err := c.Set("a", make([]byte, 61)); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	if err := c.Set("a", make([]byte, 10)); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	if bytes := c.(StatsProvider).Stats().Bytes; bytes != 50 {
+		t.Errorf("expected 50, got %v", bytes)
+	}
+
+	c.Delete("b")
+	if bytes := c.(StatsProvider).Stats().Bytes; bytes != 10 {
+		t.Errorf("expected 10, got %v", bytes)
+	}
+
+	c.Clear()
+	if bytes := c.(StatsProvider).Stats().Bytes; bytes != 0 {
+		t.Errorf("expected 0, got %v", bytes)
+	}
+}
+
+// nolint:errcheck
+func TestLRUCacheMaxBytes(t *testing.T) {
+	sizer := func(key string, value []byte) int { return len(value) }
+	var evicted []string
+	c := NewLRUCacheWithOpts(Opts{Size: 10, MaxBytes: 100}, TypedOpts[string, []byte]{
+		Sizer: sizer,
+		OnEvict: func(key string, value []byte, reason Reason) {
+			evicted = append(evicted, key)
+		},
+	})
+
+	c.Set("a", make([]byte, 40))
+	c.Set("b", make([]byte, 40))
+	c.Get("a")
+
+	// evicts the least recently used entry to make room
+	c.Set("c", make([]byte, 30))
+	if len(evicted) != 1 || evicted[0] != "b" {
+		t.Errorf("expected [b], got %v", evicted)
+	}
+
+	// growing an entry evicts the others
+	c.Set("a", make([]byte, 90))
+	if len(evicted) != 2 || evicted[1] != "c" {
+		t.Errorf("expected [b c], got %v", evicted)
+	}
+
+	if _, err := c.Get("a"); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	// larger than the whole cache
+	if err := c.Set("d", make([]byte, 101)); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	if stats := c.(StatsProvider).Stats(); stats.Bytes != 90 || stats.Size != 1 {
+		t.Errorf("expected 90 bytes in 1 entry, got %v bytes in %v", stats.Bytes, stats.Size)
+	}
+}
+
+// nolint:errcheck
+func TestMaxEntryBytes(t *testing.T) {
+	sizer := func(key string, value []byte) int { return len(value) }
+	caches := map[string]Cache[string, []byte]{
+		"simple": NewCache(Opts{MaxEntryBytes: 10}, TypedOpts[string, []byte]{Sizer: sizer}),
+		"lru":    NewLRUCacheWithOpts(Opts{MaxEntryBytes: 10}, TypedOpts[string, []byte]{Sizer: sizer}),
+	}
+
+	for name, c := range caches {
+		if err := c.Set("a", make([]byte, 10)); err != nil {
+			t.Errorf("%s: expected nil, got %v", name, err)
+		}
+
+		err := c.Set("a", make([]byte, 11))
+		if !errors.Is(err, ErrEntryTooLarge) {
+			t.Errorf("%s: expected ErrEntryTooLarge, got %v", name, err)
+		}
+
+		// the rejected value doesn't replace the stored one
+		if v, _ := c.Get("a"); len(v) != 10 {
+			t.Errorf("%s: expected 10 bytes, got %v", name, len(v))
+		}
+	}
+
+	c := NewCache(Opts{MaxBytes: 10}, TypedOpts[string, []byte]{Sizer: sizer})
+	if err := c.Set("a", make([]byte, 11)); !errors.Is(err, ErrEntryTooLarge) {
+		t.Errorf("expected ErrEntryTooLarge, got %v", err)
+	}
+}